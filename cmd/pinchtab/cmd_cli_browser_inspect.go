@@ -57,6 +57,18 @@ var textCmd = &cobra.Command{
 	},
 }
 
+var tableCmd = &cobra.Command{
+	Use:   "table [ref|selector]",
+	Short: "Extract tables as JSON or CSV",
+	Long:  "Find <table> elements, ARIA grids and repeated lists on the page, or inside the element given by ref or selector, and print their rows as JSON or CSV.",
+	Args:  cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		runCLI(func(rt cliRuntime) {
+			browseractions.Tables(rt.client, rt.base, rt.token, args, cmd)
+		})
+	},
+}
+
 var downloadCmd = &cobra.Command{
 	Use:   "download <url>",
 	Short: "Download a file via browser session",
@@ -102,6 +114,28 @@ var waitCmd = &cobra.Command{
 	},
 }
 
+var throttleCmd = &cobra.Command{
+	Use:   "throttle [none|offline|slow-3g|fast-3g|custom|off]",
+	Short: "Show or emulate network conditions (latency, throughput, offline)",
+	Args:  cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		runCLI(func(rt cliRuntime) {
+			browseractions.Throttle(rt.client, rt.base, rt.token, cmd, args)
+		})
+	},
+}
+
+var proxyCmd = &cobra.Command{
+	Use:   "proxy [check]",
+	Short: "Show or health-check the upstream proxy",
+	Args:  cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		runCLI(func(rt cliRuntime) {
+			browseractions.Proxy(rt.client, rt.base, rt.token, cmd, args)
+		})
+	},
+}
+
 var networkCmd = &cobra.Command{
 	Use:   "network [requestId]",
 	Short: "List or inspect network requests",
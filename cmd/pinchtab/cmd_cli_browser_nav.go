@@ -87,6 +87,9 @@ var tabNewCmd = &cobra.Command{
 			if len(args) > 0 {
 				body["url"] = urls.Normalize(args[0])
 			}
+			if p := browseractions.ProxyFromFlags(cmd); p != nil {
+				body["proxy"] = p
+			}
 			browseractions.TabNew(rt.client, rt.base, rt.token, body)
 		})
 	},
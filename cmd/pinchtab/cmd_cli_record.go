@@ -0,0 +1,66 @@
+package main
+
+import (
+	browseractions "github.com/pinchtab/pinchtab/internal/cli/actions"
+	"github.com/spf13/cobra"
+)
+
+var recordCmd = &cobra.Command{
+	Use:   "record",
+	Short: "Record tab actions as a replayable script",
+	Long:  "Commands for recording the navigations, actions and waits sent for a tab and exporting them as a versioned JSON script.",
+}
+
+var recordStartCmd = &cobra.Command{
+	Use:   "start",
+	Short: "Start recording a tab",
+	Long:  "Start recording the navigations, actions and waits sent for a tab. Starting again discards the current recording.",
+	Run: func(cmd *cobra.Command, args []string) {
+		runCLI(func(rt cliRuntime) {
+			browseractions.RecordStart(rt.client, rt.base, rt.token, cmd)
+		})
+	},
+}
+
+var recordStopCmd = &cobra.Command{
+	Use:   "stop",
+	Short: "Stop recording and export the script",
+	Long:  "Stop recording a tab and print the script. Use --output to save it for `pinchtab replay`.",
+	Run: func(cmd *cobra.Command, args []string) {
+		runCLI(func(rt cliRuntime) {
+			browseractions.RecordStop(rt.client, rt.base, rt.token, cmd)
+		})
+	},
+}
+
+var recordShowCmd = &cobra.Command{
+	Use:   "show",
+	Short: "Show the recording so far",
+	Long:  "Print a tab's recording without stopping it.",
+	Run: func(cmd *cobra.Command, args []string) {
+		runCLI(func(rt cliRuntime) {
+			browseractions.RecordShow(rt.client, rt.base, rt.token, cmd)
+		})
+	},
+}
+
+var replayCmd = &cobra.Command{
+	Use:   "replay <script.json>",
+	Short: "Replay a recorded script",
+	Long:  "Replay a script exported by `pinchtab record stop`. Targets whose refs no longer match are re-resolved through the snapshot, stable attributes and semantic find.",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		runCLI(func(rt cliRuntime) {
+			browseractions.Replay(rt.client, rt.base, rt.token, args[0], cmd)
+		})
+	},
+}
+
+func init() {
+	recordCmd.AddCommand(recordStartCmd, recordStopCmd, recordShowCmd)
+
+	recordStopCmd.Flags().StringP("output", "o", "", "Save the script to file path")
+	recordShowCmd.Flags().StringP("output", "o", "", "Save the script to file path")
+	replayCmd.Flags().Bool("continue-on-error", false, "Keep going after a failed step")
+	addTabFlag(recordStartCmd, recordStopCmd, recordShowCmd, replayCmd)
+}
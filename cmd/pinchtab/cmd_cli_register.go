@@ -28,6 +28,7 @@ func registerBrowserCommands() {
 		evalCmd,
 		pdfCmd,
 		textCmd,
+		tableCmd,
 		downloadCmd,
 		uploadCmd,
 		findCmd,
@@ -35,6 +36,8 @@ func registerBrowserCommands() {
 		checkCmd,
 		uncheckCmd,
 		networkCmd,
+		throttleCmd,
+		proxyCmd,
 		waitCmd,
 		keyboardCmd,
 		keydownCmd,
@@ -47,6 +50,9 @@ func registerBrowserCommands() {
 		cacheCmd,
 		storageCmd,
 		stateCmd,
+		recordCmd,
+		replayCmd,
+		visualCmd,
 	)
 
 	tabsCmd.AddCommand(tabNewCmd, tabCloseCmd)
@@ -76,6 +82,7 @@ func registerBrowserCommands() {
 		evalCmd,
 		pdfCmd,
 		textCmd,
+		tableCmd,
 		downloadCmd,
 		uploadCmd,
 		findCmd,
@@ -83,6 +90,8 @@ func registerBrowserCommands() {
 		checkCmd,
 		uncheckCmd,
 		networkCmd,
+		throttleCmd,
+		proxyCmd,
 		waitCmd,
 		keyboardCmd,
 		keydownCmd,
@@ -95,6 +104,9 @@ func registerBrowserCommands() {
 		cacheCmd,
 		storageCmd,
 		stateCmd,
+		recordCmd,
+		replayCmd,
+		visualCmd,
 	)
 }
 
@@ -128,13 +140,19 @@ func configureBrowserFlags() {
 	snapCmd.Flags().BoolP("interactive", "i", false, "Filter interactive elements only")
 	snapCmd.Flags().BoolP("compact", "c", false, "Compact output format")
 	snapCmd.Flags().Bool("text", false, "Text output format")
+	snapCmd.Flags().String("format", "", "Output format: json, yaml, text, compact, markdown or structured")
 	snapCmd.Flags().BoolP("diff", "d", false, "Show diff from previous snapshot")
 	snapCmd.Flags().StringP("selector", "s", "", "CSS selector to scope snapshot")
+	snapCmd.Flags().StringArray("region", nil, "Landmark (main, dialog, nav, form, ...) or selector to snapshot as its own region (repeatable)")
 	snapCmd.Flags().String("max-tokens", "", "Maximum token budget")
 	snapCmd.Flags().String("depth", "", "Tree depth limit")
+	snapCmd.Flags().Bool("geometry", false, "Include bounding boxes and viewport visibility")
+	snapCmd.Flags().Bool("watch", false, "Stream deltas as the page changes")
+	snapCmd.Flags().String("debounce", "", "Quiet period in ms before a delta is sent (with --watch)")
 
 	screenshotCmd.Flags().StringP("output", "o", "", "Save screenshot to file path")
 	screenshotCmd.Flags().StringP("quality", "q", "", "JPEG quality (0-100)")
+	screenshotCmd.Flags().Bool("marks", false, "Draw snapshot refs on the image and print their boxes")
 
 	pdfCmd.Flags().StringP("output", "o", "", "Save PDF to file path")
 	pdfCmd.Flags().Bool("landscape", false, "Landscape orientation")
@@ -160,6 +178,13 @@ func configureBrowserFlags() {
 	findCmd.Flags().Bool("ref-only", false, "Output just the element ref")
 
 	textCmd.Flags().Bool("raw", false, "Raw extraction mode")
+	textCmd.Flags().Bool("readability", false, "Main article only, as Markdown with title, byline and date")
+
+	tableCmd.Flags().String("format", "", "Output format: json (default) or csv")
+	tableCmd.Flags().String("index", "", "Only the table at this index")
+	tableCmd.Flags().String("kind", "", "Comma-separated kinds: table, grid, list")
+	tableCmd.Flags().String("max-rows", "", "Maximum rows per table")
+	tableCmd.Flags().StringP("output", "o", "", "Save output to file path")
 
 	navCmd.Flags().Bool("new-tab", false, "Open in new tab")
 	navCmd.Flags().Bool("block-images", false, "Block image loading")
@@ -175,6 +200,7 @@ func configureBrowserFlags() {
 		pdfCmd,
 		findCmd,
 		textCmd,
+		tableCmd,
 		clickCmd,
 		dblclickCmd,
 		hoverCmd,
@@ -203,13 +229,26 @@ func configureBrowserFlags() {
 	networkCmd.Flags().String("filter", "", "URL pattern filter")
 	networkCmd.Flags().String("method", "", "HTTP method filter (GET, POST, etc)")
 	networkCmd.Flags().String("status", "", "Status code range (e.g. 4xx, 5xx, 200)")
-	networkCmd.Flags().String("type", "", "Resource type filter (xhr, fetch, document, etc)")
+	networkCmd.Flags().String("type", "", "Resource type filter (xhr, fetch, document, websocket, etc)")
+	networkCmd.Flags().String("message", "", "WebSocket/EventSource message payload filter")
+	networkCmd.Flags().String("direction", "", "WebSocket message direction filter (send, receive)")
 	networkCmd.Flags().String("limit", "", "Maximum entries to return")
 	networkCmd.Flags().Bool("body", false, "Include response body (with requestId)")
 	networkCmd.Flags().Bool("clear", false, "Clear captured network data")
 	networkCmd.Flags().String("buffer-size", "", "Per-tab network buffer size (default 100)")
 	networkCmd.Flags().Bool("stream", false, "Stream network entries in real-time (like tail -f)")
 
+	addTabFlag(throttleCmd)
+	addNetworkConditionFlags(throttleCmd)
+	throttleCmd.Flags().String("scope", "", "tab (default) or instance for all open and new tabs")
+
+	addTabFlag(proxyCmd)
+	addProxyFlags(proxyCmd)
+	proxyCmd.Flags().String("target", "", "URL to fetch through the proxy for check")
+	proxyCmd.Flags().Int("timeout", 0, "Check timeout in milliseconds")
+
+	addProxyFlags(tabNewCmd)
+
 	waitCmd.Flags().String("text", "", "Wait for text on page")
 	waitCmd.Flags().String("url", "", "Wait for URL glob match")
 	waitCmd.Flags().String("load", "", "Wait for load state (networkidle)")
@@ -230,6 +269,9 @@ func configureManagementFlags() {
 	startInstanceCmd.Flags().String("mode", "", "Instance mode")
 	startInstanceCmd.Flags().String("port", "", "Port number")
 	startInstanceCmd.Flags().StringArray("extension", nil, "Load browser extension (repeatable)")
+	startInstanceCmd.Flags().String("network", "", "Network profile for all tabs: offline, slow-3g, fast-3g or custom")
+	addNetworkConditionFlags(startInstanceCmd)
+	addProxyFlags(startInstanceCmd)
 
 	activityCmd.PersistentFlags().Int("limit", 200, "Maximum number of events to return")
 	activityCmd.PersistentFlags().Int("age-sec", 0, "Only include events from the last N seconds")
@@ -251,6 +293,18 @@ func addTabFlag(cmds ...*cobra.Command) {
 	}
 }
 
+func addNetworkConditionFlags(cmd *cobra.Command) {
+	cmd.Flags().Float64("latency", 0, "Added round-trip latency in ms")
+	cmd.Flags().Float64("download", 0, "Download limit in kbit/s (0 = no limit)")
+	cmd.Flags().Float64("upload", 0, "Upload limit in kbit/s (0 = no limit)")
+	cmd.Flags().Float64("packet-loss", 0, "WebRTC packet loss in percent")
+}
+
+func addProxyFlags(cmd *cobra.Command) {
+	cmd.Flags().String("proxy", "", "Upstream proxy URL: scheme://[user:pass@]host:port (http, https, socks4, socks5)")
+	cmd.Flags().String("proxy-bypass", "", "Hosts that skip the proxy, e.g. \"localhost;*.internal\"")
+}
+
 func addPointFlags(cmd *cobra.Command, action string) {
 	cmd.Flags().Float64("x", 0, "X coordinate for "+action)
 	cmd.Flags().Float64("y", 0, "Y coordinate for "+action)
@@ -0,0 +1,73 @@
+package main
+
+import (
+	browseractions "github.com/pinchtab/pinchtab/internal/cli/actions"
+	"github.com/spf13/cobra"
+)
+
+var visualCmd = &cobra.Command{
+	Use:   "visual",
+	Short: "Visual regression checks against baseline screenshots",
+	Long:  "Commands for capturing named baseline screenshots and comparing a tab against them pixel by pixel.",
+}
+
+var visualBaselineCmd = &cobra.Command{
+	Use:   "baseline <name>",
+	Short: "Capture a named baseline screenshot",
+	Long:  "Capture the viewport, the full page or one element and store it as a baseline. Capturing again replaces it.",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		runCLI(func(rt cliRuntime) {
+			browseractions.VisualBaseline(rt.client, rt.base, rt.token, args[0], cmd)
+		})
+	},
+}
+
+var visualCompareCmd = &cobra.Command{
+	Use:   "compare <name>",
+	Short: "Compare a tab against a baseline",
+	Long:  "Capture the tab the way the baseline was captured and print the mismatch and changed regions. Exits 1 when the mismatch is above --max-mismatch.",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		runCLI(func(rt cliRuntime) {
+			browseractions.VisualCompare(rt.client, rt.base, rt.token, args[0], cmd)
+		})
+	},
+}
+
+var visualListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List baselines",
+	Run: func(cmd *cobra.Command, args []string) {
+		runCLI(func(rt cliRuntime) {
+			browseractions.VisualList(rt.client, rt.base, rt.token)
+		})
+	},
+}
+
+var visualDeleteCmd = &cobra.Command{
+	Use:   "delete <name>",
+	Short: "Delete a baseline",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		runCLI(func(rt cliRuntime) {
+			browseractions.VisualDelete(rt.client, rt.base, rt.token, args[0])
+		})
+	},
+}
+
+func init() {
+	visualCmd.AddCommand(visualBaselineCmd, visualCompareCmd, visualListCmd, visualDeleteCmd)
+
+	visualBaselineCmd.Flags().StringP("selector", "s", "", "Capture only this element")
+	visualBaselineCmd.Flags().Bool("full-page", false, "Capture the whole page")
+	for _, c := range []*cobra.Command{visualBaselineCmd, visualCompareCmd} {
+		c.Flags().StringArray("mask", nil, "Selector of an element to leave out (repeatable)")
+	}
+	visualCompareCmd.Flags().Float64("threshold", 0, "Per-pixel colour threshold from 0 to 1 (default 0.1)")
+	visualCompareCmd.Flags().Float64("max-mismatch", 0, "Largest changed-pixel percentage that still passes")
+	visualCompareCmd.Flags().Bool("include-aa", false, "Count anti-aliased pixels as changed")
+	visualCompareCmd.Flags().Bool("save-diff", false, "Save the diff image under the state directory")
+	visualCompareCmd.Flags().Bool("update", false, "Replace the baseline with the new capture")
+	addTabFlag(visualBaselineCmd, visualCompareCmd)
+}
@@ -18,3 +18,27 @@ var ScreencastRepaintStartJS string
 
 //go:embed screencast_repaint_stop.js
 var ScreencastRepaintStopJS string
+
+//go:embed extract.js
+var ExtractJS string
+
+//go:embed tables.js
+var TablesJS string
+
+//go:embed marks.js
+var MarksJS string
+
+//go:embed marks_clear.js
+var MarksClearJS string
+
+//go:embed geometry_tag.js
+var GeometryTagJS string
+
+//go:embed geometry.js
+var GeometryJS string
+
+//go:embed snapshot_observer.js
+var SnapshotObserverJS string
+
+//go:embed structured_data.js
+var StructuredDataJS string
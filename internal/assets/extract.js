@@ -0,0 +1,80 @@
+(spec) => {
+  const text = (s) => (s || '').replace(/\s+/g, ' ').trim();
+
+  // Password, one-time-code and payment card fields never give up their
+  // values, as the accessibility snapshot masks password values.
+  const secretTokens = ['current-password', 'new-password', 'one-time-code'];
+  const sensitive = (el) => {
+    if (!el.matches || !el.matches('input, textarea, select')) return false;
+    if (el.type === 'password') return true;
+    const tokens = (el.getAttribute('autocomplete') || '').toLowerCase().split(/\s+/);
+    return tokens.some((t) => t.startsWith('cc-') || secretTokens.includes(t));
+  };
+
+  const read = (el, attr) => {
+    if (!el) return null;
+    if (attr) return attr.toLowerCase() === 'value' && sensitive(el) ? null : el.getAttribute(attr);
+    if (el.nodeType === Node.ATTRIBUTE_NODE && el.name.toLowerCase() === 'value' && el.ownerElement && sensitive(el.ownerElement)) return null;
+    if (el.nodeType === Node.ATTRIBUTE_NODE || el.nodeType === Node.TEXT_NODE) return text(el.nodeValue);
+    if (el.matches('input, textarea, select')) {
+      if (el.type === 'checkbox' || el.type === 'radio') return String(el.checked);
+      if (sensitive(el)) return null;
+      return el.value;
+    }
+    return text(el.innerText !== undefined ? el.innerText : el.textContent);
+  };
+
+  const byText = (root, needle) => {
+    const out = [];
+    const walker = document.createTreeWalker(root, NodeFilter.SHOW_TEXT);
+    const lower = needle.toLowerCase();
+    for (let n = walker.nextNode(); n; n = walker.nextNode()) {
+      const el = n.parentElement;
+      if (el && !out.includes(el) && n.nodeValue.toLowerCase().includes(lower)) out.push(el);
+    }
+    return out;
+  };
+
+  const query = (root, f, all) => {
+    try {
+      switch (f.kind) {
+        case 'css':
+          return all ? Array.from(root.querySelectorAll(f.sel)) : [root.querySelector(f.sel)].filter(Boolean);
+        case 'xpath': {
+          const res = document.evaluate(f.sel, root, null, XPathResult.ORDERED_NODE_SNAPSHOT_TYPE, null);
+          const out = [];
+          for (let i = 0; i < res.snapshotLength && (all || i < 1); i++) out.push(res.snapshotItem(i));
+          return out;
+        }
+        case 'text': {
+          const found = byText(root, f.sel);
+          return all ? found : found.slice(0, 1);
+        }
+      }
+    } catch (e) {
+      return [];
+    }
+    return [];
+  };
+
+  const fill = (root, fields) => {
+    const out = {};
+    for (const f of fields) {
+      if (!f.kind) {
+        out[f.name] = fill(root, f.fields || []);
+        continue;
+      }
+      const found = query(root, f, f.many);
+      if (f.many) {
+        out[f.name] = f.fields ? found.map(el => fill(el, f.fields)) : found.map(el => read(el, f.attr));
+      } else if (found.length === 0) {
+        out[f.name] = null;
+      } else {
+        out[f.name] = f.fields ? fill(found[0], f.fields) : read(found[0], f.attr);
+      }
+    }
+    return out;
+  };
+
+  return fill(document, spec);
+}
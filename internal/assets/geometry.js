@@ -0,0 +1,68 @@
+function(token) {
+  const el = this.nodeType === Node.ELEMENT_NODE ? this : this.parentElement;
+  if (!el) return null;
+  const doc = el.ownerDocument;
+  const w = doc.defaultView;
+  const tags = w.__pinchtabRefs && w.__pinchtabRefs.token === token ? w.__pinchtabRefs.map : null;
+
+  // Text nodes are measured through a range so they get their own box.
+  let rect;
+  if (this.nodeType === Node.TEXT_NODE) {
+    const range = doc.createRange();
+    range.selectNodeContents(this);
+    rect = range.getBoundingClientRect();
+  } else {
+    rect = el.getBoundingClientRect();
+  }
+  if (!rect || (rect.width === 0 && rect.height === 0)) return null;
+
+  const parentOf = (n) => n.parentElement || (n.getRootNode() instanceof ShadowRoot ? n.getRootNode().host : null);
+  // describe names n by its ref, or by the ref of its nearest tagged
+  // ancestor that does not also contain el, falling back to tag.class.
+  const describe = (n, climb) => {
+    for (let p = n; p && !p.contains(el); p = climb ? parentOf(p) : null) {
+      if (tags && tags.has(p)) return tags.get(p);
+    }
+    if (tags && tags.has(n)) return tags.get(n);
+    let d = n.tagName.toLowerCase();
+    if (n.id) d += '#' + n.id;
+    else if (n.classList.length) d += '.' + n.classList[0];
+    return d;
+  };
+
+  // Occlusion: hit-test the centre of the part of the box inside the
+  // frame's viewport. Anything other than the element, a descendant or an
+  // ancestor (pointer-events: none) is drawn on top of it.
+  let occludedBy = '';
+  const left = Math.max(rect.left, 0);
+  const top = Math.max(rect.top, 0);
+  const right = Math.min(rect.right, w.innerWidth);
+  const bottom = Math.min(rect.bottom, w.innerHeight);
+  if (right > left && bottom > top) {
+    const root = el.getRootNode();
+    const hitTest = root.elementFromPoint ? root : doc;
+    const hit = hitTest.elementFromPoint((left + right) / 2, (top + bottom) / 2);
+    if (hit && hit !== el && !el.contains(hit) && !hit.contains(el)) {
+      occludedBy = describe(hit, true);
+    }
+  }
+
+  // Nearest scrollable ancestor below the document scroller.
+  let scrollContainer = '';
+  for (let p = parentOf(el); p && p !== doc.body && p !== doc.documentElement; p = parentOf(p)) {
+    const style = w.getComputedStyle(p);
+    const scrollsY = /(auto|scroll|overlay)/.test(style.overflowY) && p.scrollHeight > p.clientHeight;
+    const scrollsX = /(auto|scroll|overlay)/.test(style.overflowX) && p.scrollWidth > p.clientWidth;
+    if (scrollsY || scrollsX) {
+      scrollContainer = describe(p, false);
+      break;
+    }
+  }
+
+  return {
+    rect: { x: rect.left, y: rect.top, width: rect.width, height: rect.height },
+    occluded: occludedBy !== '',
+    occludedBy,
+    scrollContainer,
+  };
+}
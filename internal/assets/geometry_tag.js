@@ -0,0 +1,9 @@
+function(token, ref) {
+  const el = this.nodeType === Node.ELEMENT_NODE ? this : this.parentElement;
+  if (!el) return;
+  const w = el.ownerDocument.defaultView;
+  if (!w.__pinchtabRefs || w.__pinchtabRefs.token !== token) {
+    w.__pinchtabRefs = { token, map: new WeakMap() };
+  }
+  if (!w.__pinchtabRefs.map.has(el)) w.__pinchtabRefs.map.set(el, ref);
+}
@@ -0,0 +1,33 @@
+(marks) => {
+  const id = '__pinchtabMarks';
+  const old = document.getElementById(id);
+  if (old) old.remove();
+  const colors = ['#e6194b', '#3cb44b', '#4363d8', '#f58231', '#911eb4', '#008080', '#f032e6', '#9a6324'];
+  const vw = document.documentElement.clientWidth || window.innerWidth;
+  const vh = document.documentElement.clientHeight || window.innerHeight;
+  const layer = document.createElement('div');
+  layer.id = id;
+  layer.setAttribute('aria-hidden', 'true');
+  layer.style.cssText = 'position:fixed;inset:0;pointer-events:none;z-index:2147483647;';
+  const shown = [];
+  for (const m of marks) {
+    const x = Math.max(0, m.x);
+    const y = Math.max(0, m.y);
+    const width = Math.min(vw, m.x + m.width) - x;
+    const height = Math.min(vh, m.y + m.height) - y;
+    if (width < 1 || height < 1) continue;
+    const color = colors[shown.length % colors.length];
+    const box = document.createElement('div');
+    box.style.cssText = 'position:absolute;box-sizing:border-box;border:2px solid ' + color + ';' +
+      'left:' + x + 'px;top:' + y + 'px;width:' + width + 'px;height:' + height + 'px;';
+    const label = document.createElement('span');
+    label.textContent = m.ref;
+    label.style.cssText = 'position:absolute;left:-2px;top:' + (y >= 16 ? '-16px' : '-2px') + ';' +
+      'background:' + color + ';color:#fff;font:bold 11px/14px monospace;padding:0 3px;white-space:nowrap;';
+    box.appendChild(label);
+    layer.appendChild(box);
+    shown.push({ ref: m.ref, x, y, width, height });
+  }
+  (document.body || document.documentElement).appendChild(layer);
+  return { scale: window.devicePixelRatio || 1, marks: shown };
+}
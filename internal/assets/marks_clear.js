@@ -0,0 +1,7 @@
+(function() {
+  const layer = document.getElementById('__pinchtabMarks');
+  if (layer) {
+    layer.remove();
+  }
+  return !!layer;
+})()
@@ -0,0 +1,44 @@
+function(binding) {
+  const key = "__pinchtabObserver_" + binding;
+  if (window[key]) {
+    return false;
+  }
+
+  // Coalesce bursts into one call per task; the server debounces further.
+  let queued = false;
+  const notify = (kind) => {
+    if (queued) {
+      return;
+    }
+    queued = true;
+    setTimeout(() => {
+      queued = false;
+      const fn = window[binding];
+      if (typeof fn === "function") {
+        fn(kind);
+      }
+    }, 0);
+  };
+
+  const observer = new MutationObserver(() => notify("mutation"));
+  observer.observe(document, { subtree: true, childList: true, attributes: true, characterData: true });
+
+  // Form values and focus change the accessibility tree without mutating
+  // the DOM.
+  const events = ["input", "change", "focusin", "focusout", "toggle"];
+  const onEvent = (e) => notify(e.type);
+  for (const type of events) {
+    document.addEventListener(type, onEvent, true);
+  }
+
+  window[key] = {
+    disconnect() {
+      observer.disconnect();
+      for (const type of events) {
+        document.removeEventListener(type, onEvent, true);
+      }
+      delete window[key];
+    },
+  };
+  return true;
+}
@@ -0,0 +1,79 @@
+function() {
+  const OG = /^(og|article|book|profile|music|video|product):/i;
+  const clean = (s) => (s || '').replace(/\s+/g, ' ').trim();
+
+  const jsonLd = [];
+  for (const el of document.querySelectorAll('script[type="application/ld+json" i]')) {
+    try {
+      const data = JSON.parse(el.textContent);
+      for (const item of Array.isArray(data) ? data : [data]) {
+        if (item && typeof item === 'object') jsonLd.push(item);
+      }
+    } catch (e) {
+      // Pages ship broken JSON-LD often enough that one bad block must not
+      // hide the rest.
+    }
+  }
+
+  const openGraph = {};
+  for (const el of document.querySelectorAll('meta[property], meta[name]')) {
+    const key = (el.getAttribute('property') || el.getAttribute('name') || '').trim().toLowerCase();
+    const content = clean(el.getAttribute('content'));
+    if (!OG.test(key) || !content) continue;
+    if (key in openGraph) {
+      openGraph[key] = [].concat(openGraph[key], content);
+    } else {
+      openGraph[key] = content;
+    }
+  }
+
+  // Microdata values as defined by the HTML spec's itemprop value rules.
+  const value = (el) => {
+    if (el.hasAttribute('itemscope')) return item(el);
+    const tag = el.tagName.toLowerCase();
+    if (tag === 'meta') return el.getAttribute('content') || '';
+    if (['audio', 'embed', 'iframe', 'img', 'source', 'track', 'video'].includes(tag)) return el.src || '';
+    if (['a', 'area', 'link'].includes(tag)) return el.href || '';
+    if (tag === 'object') return el.data || '';
+    if (tag === 'data' || tag === 'meter') return el.getAttribute('value') || '';
+    if (tag === 'time') return el.getAttribute('datetime') || clean(el.textContent);
+    return clean(el.textContent);
+  };
+
+  // Properties of an item are the itemprop elements below it that do not
+  // belong to a nested item, plus those named by itemref.
+  const item = (scope, seen = new Set()) => {
+    seen.add(scope);
+    const out = {};
+    const type = clean(scope.getAttribute('itemtype'));
+    if (type) out['@type'] = type.split(' ');
+    if (scope.getAttribute('itemid')) out['@id'] = scope.getAttribute('itemid');
+    const roots = [scope];
+    for (const id of clean(scope.getAttribute('itemref')).split(' ')) {
+      const el = id && document.getElementById(id);
+      if (el) roots.push(el);
+    }
+    const visit = (el, top) => {
+      if (!top && el.hasAttribute('itemprop')) {
+        if (seen.has(el)) return;
+        const v = el.hasAttribute('itemscope') ? item(el, seen) : value(el);
+        for (const name of clean(el.getAttribute('itemprop')).split(' ')) {
+          (out[name] = out[name] || []).push(v);
+        }
+        if (el.hasAttribute('itemscope')) return;
+      } else if (!top && el.hasAttribute('itemscope')) {
+        return;
+      }
+      for (const child of el.children) visit(child, false);
+    };
+    for (const root of roots) visit(root, root === scope);
+    return out;
+  };
+
+  const microdata = [];
+  for (const el of document.querySelectorAll('[itemscope]:not([itemprop])')) {
+    microdata.push(item(el));
+  }
+
+  return { jsonLd, openGraph, microdata };
+}
@@ -0,0 +1,203 @@
+function(opts) {
+  const root = this.nodeType === Node.DOCUMENT_NODE ? this.body : this;
+  const kinds = opts.kinds || ['table', 'grid', 'list'];
+  const text = (el) => (el.innerText !== undefined ? el.innerText : el.textContent || '').replace(/\s+/g, ' ').trim();
+  const span = (el, attr, aria) => Math.max(1, parseInt(el.getAttribute(attr) || el.getAttribute(aria) || '1', 10) || 1);
+  const GRID = '[role="grid"], [role="table"], [role="treegrid"]';
+  const CHROME = 'nav, header, footer, [role="navigation"], [role="banner"], [role="contentinfo"], [role="menu"], [role="menubar"], [role="tablist"]';
+
+  const cssPath = (el) => {
+    const parts = [];
+    for (let n = el; n && n.nodeType === Node.ELEMENT_NODE && n !== document.body; n = n.parentElement) {
+      if (n.id) {
+        parts.unshift('#' + CSS.escape(n.id));
+        return parts.join(' > ');
+      }
+      let i = 1;
+      for (let s = n.previousElementSibling; s; s = s.previousElementSibling) {
+        if (s.tagName === n.tagName) i++;
+      }
+      parts.unshift(n.tagName.toLowerCase() + ':nth-of-type(' + i + ')');
+    }
+    parts.unshift('body');
+    return parts.join(' > ');
+  };
+
+  // Lays rows of cells out on a grid, repeating spanned cells in every
+  // position they cover.
+  const layout = (rows, cellsOf, spanOf) => {
+    const grid = [];
+    const header = [];
+    rows.forEach((row, r) => {
+      grid[r] = grid[r] || [];
+      let c = 0;
+      let allHeader = true;
+      let any = false;
+      for (const cell of cellsOf(row)) {
+        while (grid[r][c] !== undefined) c++;
+        const [cols, rowsSpanned] = spanOf(cell.el);
+        const value = text(cell.el);
+        for (let dr = 0; dr < rowsSpanned && r + dr < rows.length; dr++) {
+          grid[r + dr] = grid[r + dr] || [];
+          for (let dc = 0; dc < cols; dc++) grid[r + dr][c + dc] = value;
+        }
+        c += cols;
+        any = true;
+        if (!cell.header) allHeader = false;
+      }
+      header[r] = any && allHeader;
+    });
+    const width = grid.reduce((w, r) => Math.max(w, r.length), 0);
+    return {
+      grid: grid.map(r => Array.from({ length: width }, (_, i) => r[i] === undefined ? '' : r[i])),
+      header,
+    };
+  };
+
+  const build = (el, kind, rows, cellsOf, spanOf, forcedHeaders) => {
+    const { grid, header } = layout(rows, cellsOf, spanOf);
+    let h = 0;
+    while (h < grid.length && (header[h] || h < forcedHeaders)) h++;
+    const width = grid.length ? grid[0].length : 0;
+    const headers = Array.from({ length: width }, (_, c) => {
+      const parts = [];
+      for (let r = 0; r < h; r++) {
+        const v = grid[r][c];
+        if (v && parts[parts.length - 1] !== v) parts.push(v);
+      }
+      return parts.join(' / ');
+    });
+    const body = grid.slice(h).filter(r => r.some(v => v !== ''));
+    const caption = el.querySelector('caption');
+    return {
+      el,
+      kind,
+      selector: cssPath(el),
+      caption: caption ? text(caption) : (el.getAttribute('aria-label') || ''),
+      headers,
+      rows: body,
+    };
+  };
+
+  const htmlTable = (table) => {
+    const rows = Array.from(table.rows).filter(r => r.closest('table') === table);
+    const theadRows = table.tHead ? table.tHead.rows.length : 0;
+    return build(table, 'table', rows,
+      (row) => Array.from(row.cells).map(c => ({ el: c, header: c.tagName === 'TH' })),
+      (cell) => [span(cell, 'colspan', 'colspan'), cell.rowSpan === 0 ? rows.length : span(cell, 'rowspan', 'rowspan')],
+      theadRows);
+  };
+
+  const ariaGrid = (grid) => {
+    const owned = (n) => n.closest(GRID) === grid;
+    let rows = Array.from(grid.querySelectorAll('[role="row"]')).filter(owned);
+    if (rows.some(r => r.hasAttribute('aria-rowindex'))) {
+      rows = rows.slice().sort((a, b) => (+a.getAttribute('aria-rowindex') || 0) - (+b.getAttribute('aria-rowindex') || 0));
+    }
+    const headRows = Array.from(grid.querySelectorAll('[role="rowgroup"]')).filter(owned)
+      .slice(0, 1).filter(g => g.querySelector('[role="columnheader"]'))
+      .reduce((n, g) => n + g.querySelectorAll('[role="row"]').length, 0);
+    return build(grid, 'grid', rows,
+      (row) => Array.from(row.querySelectorAll('[role="cell"], [role="gridcell"], [role="columnheader"], [role="rowheader"]'))
+        .filter(c => c.closest('[role="row"]') === row)
+        .map(c => ({ el: c, header: c.getAttribute('role') === 'columnheader' })),
+      (cell) => [span(cell, 'aria-colspan', 'colspan'), span(cell, 'aria-rowspan', 'rowspan')],
+      headRows);
+  };
+
+  // Repeated lists: a parent whose children mostly share a tag and class,
+  // read as one row per child and one column per repeated leaf.
+  const signature = (el) => el.tagName + (el.classList.length ? '.' + el.classList[0] : '');
+  const leaves = (item) => {
+    const out = [];
+    const walk = (el, path) => {
+      const kids = Array.from(el.children).filter(k => !/^(SCRIPT|STYLE|NOSCRIPT|SVG|TEMPLATE)$/.test(k.tagName));
+      const own = Array.from(el.childNodes).some(n => n.nodeType === Node.TEXT_NODE && n.nodeValue.trim());
+      if (el !== item && (kids.length === 0 || own)) {
+        const v = text(el);
+        if (v) out.push({ key: path, name: el.classList[0] || el.tagName.toLowerCase(), value: v });
+        if (el.tagName === 'A' && el.getAttribute('href')) {
+          out.push({ key: path + '@href', name: (el.classList[0] || 'link') + ' href', value: el.href });
+        }
+        return;
+      }
+      const seen = {};
+      for (const k of kids) {
+        const sig = signature(k);
+        seen[sig] = (seen[sig] || 0) + 1;
+        walk(k, path + '>' + sig + (seen[sig] > 1 ? ':' + seen[sig] : ''));
+      }
+    };
+    walk(item, '');
+    return out;
+  };
+
+  const repeatedList = (parent, explicit) => {
+    const children = Array.from(parent.children);
+    if (children.length < 3) return null;
+    const counts = {};
+    for (const c of children) counts[signature(c)] = (counts[signature(c)] || 0) + 1;
+    const [sig, n] = Object.entries(counts).sort((a, b) => b[1] - a[1])[0];
+    if (n < 3 || n < children.length * 0.6) return null;
+    const items = children.filter(c => signature(c) === sig && text(c));
+    if (items.length < 3) return null;
+    const isList = parent.tagName === 'UL' || parent.tagName === 'OL' || parent.getAttribute('role') === 'list';
+    if (!isList && /^(P|BR|TR|TD|TH|OPTION|SCRIPT|STYLE)$/.test(items[0].tagName)) return null;
+
+    const perItem = items.map(leaves);
+    const columns = [];
+    const byKey = {};
+    perItem.forEach(ls => ls.forEach(l => {
+      if (!(l.key in byKey)) {
+        byKey[l.key] = { key: l.key, name: l.name, count: 0 };
+        columns.push(byKey[l.key]);
+      }
+      byKey[l.key].count++;
+    }));
+    let cols = columns.filter(c => c.count >= items.length / 2);
+    if (!isList && !explicit && cols.length < 2) return null;
+    if (cols.length === 0) cols = [{ key: '', name: 'item' }];
+    const names = {};
+    const headers = cols.map(c => {
+      names[c.name] = (names[c.name] || 0) + 1;
+      return names[c.name] > 1 ? c.name + ' ' + names[c.name] : c.name;
+    });
+    const rows = perItem.map((ls, i) => cols.map(c => {
+      if (c.key === '') return text(items[i]);
+      const hit = ls.find(l => l.key === c.key);
+      return hit ? hit.value : '';
+    }));
+    return { el: parent, kind: 'list', selector: cssPath(parent), caption: parent.getAttribute('aria-label') || '', headers, rows };
+  };
+
+  const found = [];
+  const taken = [];
+  const inside = (el) => taken.some(t => t.contains(el));
+  const isCandidate = (el) => (kinds.includes('table') && el.tagName === 'TABLE') || (kinds.includes('grid') && el.matches(GRID));
+
+  const candidates = [root, ...root.querySelectorAll('table, ' + GRID)].filter(isCandidate);
+  // Layout tables that wrap data tables are skipped in favour of the inner table.
+  const innermost = candidates.filter(el => !candidates.some(o => o !== el && el.contains(o)));
+  for (const el of innermost) {
+    const t = el.tagName === 'TABLE' ? htmlTable(el) : ariaGrid(el);
+    if (t.rows.length || t.headers.some(Boolean)) {
+      found.push(t);
+      taken.push(el);
+    }
+  }
+
+  if (kinds.includes('list')) {
+    const explicit = root !== document.body;
+    for (const el of [root, ...root.querySelectorAll('*')]) {
+      if (inside(el) || (!explicit && el.closest(CHROME))) continue;
+      const list = repeatedList(el, explicit && el === root);
+      if (list) {
+        found.push(list);
+        taken.push(el);
+      }
+    }
+  }
+
+  found.sort((a, b) => a.el.compareDocumentPosition(b.el) & Node.DOCUMENT_POSITION_FOLLOWING ? -1 : 1);
+  return found.map(({ el, ...t }) => t);
+}
@@ -267,6 +267,9 @@ func ResolveUnifiedSelector(ctx context.Context, sel selector.Selector, refCache
 			if nid, ok := refCache.Refs[sel.Value]; ok {
 				return nid, nil
 			}
+			if n, ok := refCache.Stale(sel.Value); ok {
+				return 0, &StaleRefError{Ref: sel.Value, Node: n}
+			}
 		}
 		return 0, fmt.Errorf("ref %s not found in snapshot cache", sel.Value)
 
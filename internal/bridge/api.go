@@ -20,6 +20,7 @@ type BridgeAPI interface {
 	TabContext(tabID string) (ctx context.Context, resolvedID string, err error)
 	ListTargets() ([]*target.Info, error)
 	CreateTab(url string) (tabID string, ctx context.Context, cancel context.CancelFunc, err error)
+	CreateTabWithProxy(url string, proxy config.ProxyConfig) (tabID string, ctx context.Context, cancel context.CancelFunc, err error)
 	CloseTab(tabID string) error
 	FocusTab(tabID string) error
 
@@ -50,8 +51,10 @@ type BridgeAPI interface {
 	// Crash monitoring
 	GetCrashLogs() []string
 
-	// Network monitoring
+	// Network monitoring and emulation
 	NetworkMonitor() *NetworkMonitor
+	NetworkEmulator() *NetworkEmulator
+	ProxyManager() *ProxyManager
 
 	// Dialog management
 	GetDialogManager() *DialogManager
@@ -116,6 +119,8 @@ type ProfileInfo struct {
 	HasAccount        bool      `json:"hasAccount,omitempty"`
 	UseWhen           string    `json:"useWhen,omitempty"`
 	Description       string    `json:"description,omitempty"`
+
+	Proxy *config.ProxyConfig `json:"proxy,omitempty"` // password masked
 }
 
 type ActionRecord struct {
@@ -29,9 +29,13 @@ type TabEntry struct {
 	ConsoleCaptureEnabled bool
 }
 
+// RefCache maps a tab's snapshot refs to backend node IDs. Build it with
+// NewRefCache so that refs stay stable from one snapshot to the next.
 type RefCache struct {
 	Refs  map[string]int64
 	Nodes []A11yNode
+
+	state *refState
 }
 
 type Bridge struct {
@@ -49,8 +53,13 @@ type Bridge struct {
 	Dialogs       *DialogManager
 	LogStore      *ConsoleLogStore
 
-	// Network monitoring
-	netMonitor *NetworkMonitor
+	// Network monitoring and emulation
+	netMonitor  *NetworkMonitor
+	netEmulator *NetworkEmulator
+	proxies     *ProxyManager
+
+	tabSetupMu    sync.RWMutex
+	tabSetupHooks []TabSetupHook
 
 	fingerprintMu        sync.RWMutex
 	fingerprintOverlays  map[string]bool
@@ -76,12 +85,20 @@ func New(allocCtx, browserCtx context.Context, cfg *config.RuntimeConfig) *Bridg
 		netBufSize = cfg.NetworkBufferSize
 	}
 	logStore := NewConsoleLogStore(1000)
+	var netConditions config.NetworkConditions
+	var proxy config.ProxyConfig
+	if cfg != nil {
+		netConditions = cfg.NetworkConditions
+		proxy = cfg.Proxy
+	}
 	b := &Bridge{
 		AllocCtx:            allocCtx,
 		BrowserCtx:          browserCtx,
 		Config:              cfg,
 		IdMgr:               idMgr,
 		netMonitor:          NewNetworkMonitor(netBufSize),
+		netEmulator:         NewNetworkEmulator(netConditions),
+		proxies:             NewProxyManager(proxy),
 		fingerprintOverlays: make(map[string]bool),
 		LogStore:            logStore,
 		stealthLaunchMode:   stealth.LaunchModeUninitialized,
@@ -162,6 +179,43 @@ func (b *Bridge) tabSetup(ctx context.Context) {
 			slog.Warn("no-animations injection failed", "err", err)
 		}
 	}
+	if b.netEmulator != nil {
+		if nc := b.netEmulator.Instance(); nc.Active() {
+			if err := EmulateNetworkConditions(ctx, nc); err != nil {
+				slog.Warn("network emulation failed", "profile", nc.Profile, "err", err)
+			}
+		}
+	}
+	b.runTabSetupHooks(ctx)
+}
+
+// TabSetupHook is called with every tab the bridge sets up, new or
+// restored, before it navigates anywhere.
+type TabSetupHook func(ctx context.Context, tabID string)
+
+// AddTabSetupHook registers a hook for the tabs set up from now on.
+func (b *Bridge) AddTabSetupHook(hook TabSetupHook) {
+	b.tabSetupMu.Lock()
+	defer b.tabSetupMu.Unlock()
+	b.tabSetupHooks = append(b.tabSetupHooks, hook)
+}
+
+func (b *Bridge) runTabSetupHooks(ctx context.Context) {
+	b.tabSetupMu.RLock()
+	hooks := b.tabSetupHooks
+	b.tabSetupMu.RUnlock()
+	if len(hooks) == 0 {
+		return
+	}
+	// The stealth setup above has attached the target by now.
+	c := chromedp.FromContext(ctx)
+	if c == nil || c.Target == nil {
+		return
+	}
+	tabID := b.IdMgr.TabIDFromCDPTarget(string(c.Target.TargetID))
+	for _, hook := range hooks {
+		hook(ctx, tabID)
+	}
 }
 
 // StartNetworkCapture enables network monitoring for a specific tab.
@@ -575,6 +629,15 @@ func (b *Bridge) NetworkMonitor() *NetworkMonitor {
 	return b.netMonitor
 }
 
+// NetworkEmulator returns the bridge's network conditions.
+func (b *Bridge) NetworkEmulator() *NetworkEmulator {
+	return b.netEmulator
+}
+
+func (b *Bridge) ProxyManager() *ProxyManager {
+	return b.proxies
+}
+
 func (b *Bridge) AvailableActions() []string {
 	keys := make([]string, 0, len(b.Actions))
 	for k := range b.Actions {
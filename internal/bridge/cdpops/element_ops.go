@@ -3,6 +3,7 @@ package cdpops
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 
 	"github.com/chromedp/chromedp"
 )
@@ -143,3 +144,62 @@ func ScrollByNodeID(ctx context.Context, nodeID int64) error {
 		return chromedp.FromContext(ctx).Target.Execute(ctx, "DOM.scrollIntoViewIfNeeded", map[string]any{"backendNodeId": nodeID}, nil)
 	}))
 }
+
+// CallFunctionOnNode calls a JavaScript function with the element as this,
+// passing args by value, and decodes the returned value into out.
+func CallFunctionOnNode(ctx context.Context, nodeID int64, fn string, out any, args ...any) error {
+	return chromedp.Run(ctx, chromedp.ActionFunc(func(ctx context.Context) error {
+		var result json.RawMessage
+		if err := chromedp.FromContext(ctx).Target.Execute(ctx, "DOM.resolveNode", map[string]any{
+			"backendNodeId": nodeID,
+		}, &result); err != nil {
+			return err
+		}
+		var resolved struct {
+			Object struct {
+				ObjectID string `json:"objectId"`
+			} `json:"object"`
+		}
+		if err := json.Unmarshal(result, &resolved); err != nil {
+			return err
+		}
+		callArgs := make([]map[string]any, len(args))
+		for i, a := range args {
+			callArgs[i] = map[string]any{"value": a}
+		}
+		var callResult json.RawMessage
+		if err := chromedp.FromContext(ctx).Target.Execute(ctx, "Runtime.callFunctionOn", map[string]any{
+			"functionDeclaration": fn,
+			"objectId":            resolved.Object.ObjectID,
+			"arguments":           callArgs,
+			"returnByValue":       true,
+		}, &callResult); err != nil {
+			return err
+		}
+		var cr struct {
+			Result struct {
+				Value json.RawMessage `json:"value"`
+			} `json:"result"`
+			ExceptionDetails *struct {
+				Text      string `json:"text"`
+				Exception struct {
+					Description string `json:"description"`
+				} `json:"exception"`
+			} `json:"exceptionDetails"`
+		}
+		if err := json.Unmarshal(callResult, &cr); err != nil {
+			return err
+		}
+		if cr.ExceptionDetails != nil {
+			msg := cr.ExceptionDetails.Exception.Description
+			if msg == "" {
+				msg = cr.ExceptionDetails.Text
+			}
+			return fmt.Errorf("script error: %s", msg)
+		}
+		if out == nil || len(cr.Result.Value) == 0 {
+			return nil
+		}
+		return json.Unmarshal(cr.Result.Value, out)
+	}))
+}
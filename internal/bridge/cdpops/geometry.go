@@ -167,3 +167,49 @@ func ScrollIntoViewAndGetBox(ctx context.Context, nodeID int64) (map[string]any,
 		},
 	}, nil
 }
+
+// Box is an element's bounding box in CSS pixels, relative to the viewport.
+type Box struct {
+	X      float64 `json:"x"`
+	Y      float64 `json:"y"`
+	Width  float64 `json:"width"`
+	Height float64 `json:"height"`
+}
+
+// GetElementBox returns the bounding box of an element's border quad using
+// DOM.getBoxModel. Elements that are not rendered return an error.
+func GetElementBox(ctx context.Context, backendNodeID int64) (Box, error) {
+	var result json.RawMessage
+	err := chromedp.Run(ctx, chromedp.ActionFunc(func(ctx context.Context) error {
+		return chromedp.FromContext(ctx).Target.Execute(ctx, "DOM.getBoxModel", map[string]any{
+			"backendNodeId": backendNodeID,
+		}, &result)
+	}))
+	if err != nil {
+		return Box{}, err
+	}
+
+	var box struct {
+		Model struct {
+			Border []float64 `json:"border"`
+		} `json:"model"`
+	}
+	if err := json.Unmarshal(result, &box); err != nil {
+		return Box{}, err
+	}
+	b, ok := BoxFromQuad(box.Model.Border)
+	if !ok {
+		return Box{}, fmt.Errorf("invalid box model: expected 8 coordinates")
+	}
+	return b, nil
+}
+
+// BoxFromQuad returns the axis-aligned bounding box of a CDP quad.
+func BoxFromQuad(q []float64) (Box, bool) {
+	if len(q) < 8 {
+		return Box{}, false
+	}
+	minX, maxX := min(q[0], q[2], q[4], q[6]), max(q[0], q[2], q[4], q[6])
+	minY, maxY := min(q[1], q[3], q[5], q[7]), max(q[1], q[3], q[5], q[7])
+	return Box{X: minX, Y: minY, Width: maxX - minX, Height: maxY - minY}, true
+}
@@ -39,6 +39,16 @@ func getElementCenterJS(ctx context.Context, backendNodeID int64) (float64, floa
 	return bridgecdpops.GetElementCenterJS(ctx, backendNodeID)
 }
 
+type Box = bridgecdpops.Box
+
+func GetElementBox(ctx context.Context, backendNodeID int64) (Box, error) {
+	return bridgecdpops.GetElementBox(ctx, backendNodeID)
+}
+
+func BoxFromQuad(q []float64) (Box, bool) {
+	return bridgecdpops.BoxFromQuad(q)
+}
+
 func ScrollIntoViewAndGetBox(ctx context.Context, nodeID int64) (map[string]any, error) {
 	return bridgecdpops.ScrollIntoViewAndGetBox(ctx, nodeID)
 }
@@ -90,3 +100,7 @@ func ReadInputValue(ctx context.Context, nodeID int64) (string, error) {
 func ScrollByNodeID(ctx context.Context, nodeID int64) error {
 	return bridgecdpops.ScrollByNodeID(ctx, nodeID)
 }
+
+func CallFunctionOnNode(ctx context.Context, nodeID int64, fn string, out any, args ...any) error {
+	return bridgecdpops.CallFunctionOnNode(ctx, nodeID, fn, out, args...)
+}
@@ -0,0 +1,263 @@
+package bridge
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/chromedp/cdproto/fetch"
+	"github.com/chromedp/cdproto/network"
+	bridgeobserve "github.com/pinchtab/pinchtab/internal/bridge/observe"
+)
+
+// Replay body matching modes.
+const (
+	ReplayBodyExact  = "exact"
+	ReplayBodyJSON   = "json"
+	ReplayBodyIgnore = "ignore"
+)
+
+// What replay does with requests the archive has no entry for.
+const (
+	ReplayUnmatchedFail        = "fail"
+	ReplayUnmatchedPassthrough = "passthrough"
+)
+
+// maxReplayUnmatched bounds the unmatched URLs a replay remembers.
+const maxReplayUnmatched = 50
+
+// ReplayRuleID marks network log entries served, or failed, by a replay in
+// place of a rule ID.
+const ReplayRuleID = "har"
+
+// replayUnmatchedReason fails unmatched requests as if the browser were
+// offline.
+const replayUnmatchedReason = network.ErrorReasonInternetDisconnected
+
+// replayDroppedHeaders describe the body as it came over the wire. An
+// archive holds it decoded, so they would no longer be true.
+var replayDroppedHeaders = map[string]bool{
+	"content-encoding":  true,
+	"content-length":    true,
+	"transfer-encoding": true,
+}
+
+// HARReplayOptions controls how requests are matched against an archive.
+// IgnoreQuery names query parameters left out of the comparison, or "*"
+// for the whole query. Body is exact, json or ignore; in json mode bodies
+// are compared as parsed JSON without the IgnoreBodyFields keys, at any
+// depth. Unmatched is fail or passthrough.
+type HARReplayOptions struct {
+	IgnoreQuery      []string `json:"ignoreQuery,omitempty"`
+	Body             string   `json:"body,omitempty"`
+	IgnoreBodyFields []string `json:"ignoreBodyFields,omitempty"`
+	Unmatched        string   `json:"unmatched,omitempty"`
+}
+
+// HARReplayStatus describes a replay and what it has served.
+type HARReplayStatus struct {
+	Source        string           `json:"source"`
+	Entries       int              `json:"entries"`
+	Options       HARReplayOptions `json:"options"`
+	Matched       int              `json:"matched"`
+	Unmatched     int              `json:"unmatched"`
+	UnmatchedURLs []string         `json:"unmatchedUrls,omitempty"`
+}
+
+type replayResponse struct {
+	status     int
+	statusText string
+	headers    []*fetch.HeaderEntry
+	body       string // base64
+}
+
+// HARReplay serves requests from a recorded network export. Requests are
+// matched by method, URL and body after normalization. When an archive
+// holds several responses for one request they are served in recorded
+// order, and the last one keeps being served after that.
+type HARReplay struct {
+	source string
+	opts   HARReplayOptions
+	ignore map[string]bool
+
+	mu        sync.Mutex
+	responses map[string][]replayResponse
+	served    map[string]int
+	total     int
+	matched   int
+	unmatched int
+	missed    []string
+}
+
+// NewHARReplay indexes export entries for replay. Entries without a status,
+// such as requests that failed when recorded, are replayed as failures.
+func NewHARReplay(source string, entries []bridgeobserve.ExportEntry, opts HARReplayOptions) (*HARReplay, error) {
+	opts.Body = strings.ToLower(opts.Body)
+	if opts.Body == "" {
+		opts.Body = ReplayBodyExact
+	}
+	if opts.Body != ReplayBodyExact && opts.Body != ReplayBodyJSON && opts.Body != ReplayBodyIgnore {
+		return nil, fmt.Errorf("unknown body mode %q (use exact, json or ignore)", opts.Body)
+	}
+	opts.Unmatched = strings.ToLower(opts.Unmatched)
+	if opts.Unmatched == "" {
+		opts.Unmatched = ReplayUnmatchedFail
+	}
+	if opts.Unmatched != ReplayUnmatchedFail && opts.Unmatched != ReplayUnmatchedPassthrough {
+		return nil, fmt.Errorf("unknown unmatched mode %q (use fail or passthrough)", opts.Unmatched)
+	}
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("archive has no entries")
+	}
+
+	r := &HARReplay{
+		source:    source,
+		opts:      opts,
+		ignore:    make(map[string]bool, len(opts.IgnoreQuery)),
+		responses: make(map[string][]replayResponse),
+		served:    make(map[string]int),
+	}
+	for _, q := range opts.IgnoreQuery {
+		r.ignore[q] = true
+	}
+	for n, e := range entries {
+		body := ""
+		if e.Request.PostData != nil {
+			body = e.Request.PostData.Text
+		}
+		resp, err := newReplayResponse(e.Response)
+		if err != nil {
+			return nil, fmt.Errorf("entry %d: %w", n+1, err)
+		}
+		key := r.key(e.Request.Method, e.Request.URL, body)
+		r.responses[key] = append(r.responses[key], resp)
+		r.total++
+	}
+	return r, nil
+}
+
+func newReplayResponse(resp bridgeobserve.ExportResponse) (replayResponse, error) {
+	out := replayResponse{status: resp.Status, statusText: resp.StatusText}
+	for _, h := range resp.Headers {
+		if !replayDroppedHeaders[strings.ToLower(h.Name)] {
+			out.headers = append(out.headers, &fetch.HeaderEntry{Name: h.Name, Value: h.Value})
+		}
+	}
+	switch {
+	case resp.Content.Text == "":
+	case resp.Content.Encoding == "base64":
+		if _, err := base64.StdEncoding.DecodeString(resp.Content.Text); err != nil {
+			return out, fmt.Errorf("response body: %w", err)
+		}
+		out.body = resp.Content.Text
+	default:
+		out.body = base64.StdEncoding.EncodeToString([]byte(resp.Content.Text))
+	}
+	return out, nil
+}
+
+// key is the normalized form of a request that replay compares.
+func (r *HARReplay) key(method, rawURL, body string) string {
+	return strings.ToUpper(method) + " " + r.normalizeURL(rawURL) + "\x00" + r.normalizeBody(body)
+}
+
+func (r *HARReplay) normalizeURL(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+	u.Fragment, u.RawFragment = "", ""
+	u.Host = strings.ToLower(u.Host)
+	if r.ignore["*"] {
+		u.RawQuery = ""
+		return u.String()
+	}
+	q := u.Query()
+	for name := range r.ignore {
+		q.Del(name)
+	}
+	// Encode sorts by name, so parameter order does not matter.
+	u.RawQuery = q.Encode()
+	return u.String()
+}
+
+func (r *HARReplay) normalizeBody(body string) string {
+	switch r.opts.Body {
+	case ReplayBodyIgnore:
+		return ""
+	case ReplayBodyJSON:
+		var v any
+		if err := json.Unmarshal([]byte(body), &v); err != nil {
+			return body
+		}
+		v = dropJSONFields(v, r.opts.IgnoreBodyFields)
+		out, err := json.Marshal(v)
+		if err != nil {
+			return body
+		}
+		return string(out)
+	default:
+		return body
+	}
+}
+
+func dropJSONFields(v any, fields []string) any {
+	switch t := v.(type) {
+	case map[string]any:
+		for k, child := range t {
+			if slices.Contains(fields, k) {
+				delete(t, k)
+				continue
+			}
+			t[k] = dropJSONFields(child, fields)
+		}
+	case []any:
+		for i, child := range t {
+			t[i] = dropJSONFields(child, fields)
+		}
+	}
+	return v
+}
+
+// lookup returns the recorded response for a request and counts the
+// outcome.
+func (r *HARReplay) lookup(method, rawURL, body string) (replayResponse, bool) {
+	key := r.key(method, rawURL, body)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	list, ok := r.responses[key]
+	if !ok {
+		r.unmatched++
+		if len(r.missed) < maxReplayUnmatched {
+			r.missed = append(r.missed, strings.ToUpper(method)+" "+rawURL)
+		}
+		return replayResponse{}, false
+	}
+	n := r.served[key]
+	r.served[key] = n + 1
+	r.matched++
+	return list[min(n, len(list)-1)], true
+}
+
+// Passthrough reports whether unmatched requests go to the network.
+func (r *HARReplay) Passthrough() bool {
+	return r.opts.Unmatched == ReplayUnmatchedPassthrough
+}
+
+// Status returns what the replay holds and has served so far.
+func (r *HARReplay) Status() HARReplayStatus {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return HARReplayStatus{
+		Source:        r.source,
+		Entries:       r.total,
+		Options:       r.opts,
+		Matched:       r.matched,
+		Unmatched:     r.unmatched,
+		UnmatchedURLs: slices.Clone(r.missed),
+	}
+}
@@ -0,0 +1,118 @@
+package bridge
+
+import (
+	"encoding/base64"
+	"testing"
+
+	bridgeobserve "github.com/pinchtab/pinchtab/internal/bridge/observe"
+)
+
+func replayEntry(method, url, reqBody string, status int, body string) bridgeobserve.ExportEntry {
+	e := bridgeobserve.ExportEntry{
+		Request: bridgeobserve.ExportRequest{Method: method, URL: url},
+		Response: bridgeobserve.ExportResponse{
+			Status: status,
+			Headers: []bridgeobserve.NameValuePair{
+				{Name: "Content-Type", Value: "application/json"},
+				{Name: "Content-Encoding", Value: "gzip"},
+			},
+			Content: bridgeobserve.ExportContent{Text: body},
+		},
+	}
+	if reqBody != "" {
+		e.Request.PostData = &bridgeobserve.ExportPostData{Text: reqBody}
+	}
+	return e
+}
+
+func replayBody(t *testing.T, resp replayResponse) string {
+	t.Helper()
+	b, err := base64.StdEncoding.DecodeString(resp.body)
+	if err != nil {
+		t.Fatalf("body is not base64: %v", err)
+	}
+	return string(b)
+}
+
+func TestHARReplayMatching(t *testing.T) {
+	r, err := NewHARReplay("test.har", []bridgeobserve.ExportEntry{
+		replayEntry("GET", "https://Example.com/api/items?b=2&a=1&ts=100#top", "", 200, "first"),
+		replayEntry("GET", "https://example.com/api/items?a=1&b=2&ts=200", "", 200, "second"),
+	}, HARReplayOptions{IgnoreQuery: []string{"ts"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, want := range []string{"first", "second", "second"} {
+		resp, ok := r.lookup("get", "https://example.com/api/items?a=1&b=2&ts=999", "")
+		if !ok {
+			t.Fatal("expected a match")
+		}
+		if got := replayBody(t, resp); got != want {
+			t.Errorf("expected %q, got %q", want, got)
+		}
+		if len(resp.headers) != 1 || resp.headers[0].Name != "Content-Type" {
+			t.Errorf("expected only Content-Type to be replayed, got %+v", resp.headers)
+		}
+	}
+
+	if _, ok := r.lookup("GET", "https://example.com/api/items?a=1&b=3", ""); ok {
+		t.Error("expected a different query to miss")
+	}
+	if _, ok := r.lookup("POST", "https://example.com/api/items?a=1&b=2", ""); ok {
+		t.Error("expected a different method to miss")
+	}
+
+	st := r.Status()
+	if st.Entries != 2 || st.Matched != 3 || st.Unmatched != 2 || len(st.UnmatchedURLs) != 2 {
+		t.Errorf("unexpected status %+v", st)
+	}
+	if st.Options.Body != ReplayBodyExact || st.Options.Unmatched != ReplayUnmatchedFail || r.Passthrough() {
+		t.Errorf("unexpected defaults %+v", st.Options)
+	}
+}
+
+func TestHARReplayBodyModes(t *testing.T) {
+	entries := []bridgeobserve.ExportEntry{
+		replayEntry("POST", "https://example.com/api/search", `{"q":"shoes","meta":{"nonce":"abc"},"page":1}`, 200, "ok"),
+	}
+
+	exact, _ := NewHARReplay("", entries, HARReplayOptions{})
+	if _, ok := exact.lookup("POST", "https://example.com/api/search", `{"page":1,"q":"shoes","meta":{"nonce":"abc"}}`); ok {
+		t.Error("exact mode should compare bodies byte for byte")
+	}
+
+	js, _ := NewHARReplay("", entries, HARReplayOptions{Body: "JSON", IgnoreBodyFields: []string{"nonce"}})
+	if _, ok := js.lookup("POST", "https://example.com/api/search", `{"page":1,"q":"shoes","meta":{"nonce":"xyz"}}`); !ok {
+		t.Error("json mode should ignore key order and ignored fields")
+	}
+	if _, ok := js.lookup("POST", "https://example.com/api/search", `{"page":2,"q":"shoes","meta":{}}`); ok {
+		t.Error("json mode should still compare the other fields")
+	}
+
+	ignore, _ := NewHARReplay("", entries, HARReplayOptions{Body: ReplayBodyIgnore, IgnoreQuery: []string{"*"}, Unmatched: ReplayUnmatchedPassthrough})
+	if _, ok := ignore.lookup("POST", "https://example.com/api/search?x=1", "anything"); !ok {
+		t.Error("ignore mode should match any body and query")
+	}
+	if !ignore.Passthrough() {
+		t.Error("expected passthrough")
+	}
+}
+
+func TestHARReplayInvalid(t *testing.T) {
+	entries := []bridgeobserve.ExportEntry{replayEntry("GET", "https://example.com/", "", 200, "")}
+	if _, err := NewHARReplay("", nil, HARReplayOptions{}); err == nil {
+		t.Error("expected an error for an empty archive")
+	}
+	if _, err := NewHARReplay("", entries, HARReplayOptions{Body: "fuzzy"}); err == nil {
+		t.Error("expected an error for an unknown body mode")
+	}
+	if _, err := NewHARReplay("", entries, HARReplayOptions{Unmatched: "retry"}); err == nil {
+		t.Error("expected an error for an unknown unmatched mode")
+	}
+	bad := replayEntry("GET", "https://example.com/", "", 200, "%%%")
+	bad.Response.Content.Encoding = "base64"
+	if _, err := NewHARReplay("", []bridgeobserve.ExportEntry{bad}, HARReplayOptions{}); err == nil {
+		t.Error("expected an error for an invalid base64 body")
+	}
+}
@@ -0,0 +1,690 @@
+package bridge
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/chromedp/cdproto/cdp"
+	"github.com/chromedp/cdproto/fetch"
+	"github.com/chromedp/cdproto/network"
+	"github.com/chromedp/chromedp"
+)
+
+// Interception actions.
+const (
+	InterceptFulfill = "fulfill"
+	InterceptModify  = "modify"
+	InterceptRewrite = "rewrite"
+	InterceptFail    = "fail"
+	InterceptDelay   = "delay"
+)
+
+const (
+	// MaxInterceptRules bounds the rules on one tab.
+	MaxInterceptRules = 100
+	// MaxInterceptDelay bounds the delay a rule may add to a request.
+	MaxInterceptDelay = 60 * time.Second
+	// maxInterceptBodyBytes bounds a canned response or replacement body.
+	maxInterceptBodyBytes = 1 << 20
+	// authOnlyURLPattern matches no URL Chrome loads, so a Fetch session
+	// that only answers auth challenges pauses no requests. An empty
+	// pattern list would pause all of them.
+	authOnlyURLPattern = "pinchtab-auth-only:"
+)
+
+// interceptErrorReasons are the net errors a fail rule may use, keyed by
+// lower-cased name.
+var interceptErrorReasons = map[string]network.ErrorReason{}
+
+func init() {
+	for _, r := range []network.ErrorReason{
+		network.ErrorReasonFailed,
+		network.ErrorReasonAborted,
+		network.ErrorReasonTimedOut,
+		network.ErrorReasonAccessDenied,
+		network.ErrorReasonConnectionClosed,
+		network.ErrorReasonConnectionReset,
+		network.ErrorReasonConnectionRefused,
+		network.ErrorReasonConnectionAborted,
+		network.ErrorReasonConnectionFailed,
+		network.ErrorReasonNameNotResolved,
+		network.ErrorReasonInternetDisconnected,
+		network.ErrorReasonAddressUnreachable,
+		network.ErrorReasonBlockedByClient,
+		network.ErrorReasonBlockedByResponse,
+	} {
+		interceptErrorReasons[strings.ToLower(string(r))] = r
+	}
+}
+
+// InterceptRule says what to do with the requests of a tab that match it.
+// URL is a glob where * matches any run of characters and ? any single
+// one; URLRegex is a regular expression searched anywhere in the URL.
+// Method and ResourceType are compared case-insensitively.
+//
+// Headers and Body belong to the response for fulfill and to the request
+// for modify, where an empty header value removes the header. RewriteURL
+// may refer to URLRegex groups as $1. DelayMs holds any action back, and
+// is the whole of a delay rule. A rule with Times stops matching after
+// that many hits.
+type InterceptRule struct {
+	ID           string            `json:"id"`
+	URL          string            `json:"url,omitempty"`
+	URLRegex     string            `json:"urlRegex,omitempty"`
+	Method       string            `json:"method,omitempty"`
+	ResourceType string            `json:"resourceType,omitempty"`
+	Action       string            `json:"action"`
+	Status       int               `json:"status,omitempty"`
+	Headers      map[string]string `json:"headers,omitempty"`
+	Body         string            `json:"body,omitempty"`
+	BodyBase64   string            `json:"bodyBase64,omitempty"`
+	RewriteURL   string            `json:"rewriteUrl,omitempty"`
+	ErrorReason  string            `json:"errorReason,omitempty"`
+	DelayMs      int               `json:"delayMs,omitempty"`
+	Times        int               `json:"times,omitempty"`
+	Hits         int               `json:"hits"`
+}
+
+type interceptRule struct {
+	InterceptRule
+	url    *regexp.Regexp
+	body   string // base64, as CDP wants it
+	reason network.ErrorReason
+}
+
+// compileInterceptRule validates a rule and prepares it for matching.
+func compileInterceptRule(r InterceptRule) (*interceptRule, error) {
+	r.Action = strings.ToLower(strings.TrimSpace(r.Action))
+	r.Method = strings.ToUpper(strings.TrimSpace(r.Method))
+	c := &interceptRule{}
+
+	switch {
+	case r.URL != "" && r.URLRegex != "":
+		return nil, fmt.Errorf("url and urlRegex are mutually exclusive")
+	case r.URL != "":
+		c.url = globRegexp(r.URL)
+	case r.URLRegex != "":
+		re, err := regexp.Compile(r.URLRegex)
+		if err != nil {
+			return nil, fmt.Errorf("invalid urlRegex: %w", err)
+		}
+		c.url = re
+	default:
+		return nil, fmt.Errorf("url or urlRegex required")
+	}
+	if r.DelayMs < 0 || time.Duration(r.DelayMs)*time.Millisecond > MaxInterceptDelay {
+		return nil, fmt.Errorf("delayMs must be between 0 and %d", MaxInterceptDelay.Milliseconds())
+	}
+	if r.Times < 0 {
+		return nil, fmt.Errorf("times must not be negative")
+	}
+	if r.Body != "" && r.BodyBase64 != "" {
+		return nil, fmt.Errorf("body and bodyBase64 are mutually exclusive")
+	}
+	if r.BodyBase64 != "" {
+		raw, err := base64.StdEncoding.DecodeString(r.BodyBase64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid bodyBase64: %w", err)
+		}
+		if len(raw) > maxInterceptBodyBytes {
+			return nil, fmt.Errorf("body exceeds %d bytes", maxInterceptBodyBytes)
+		}
+		c.body = r.BodyBase64
+	} else if r.Body != "" {
+		if len(r.Body) > maxInterceptBodyBytes {
+			return nil, fmt.Errorf("body exceeds %d bytes", maxInterceptBodyBytes)
+		}
+		c.body = base64.StdEncoding.EncodeToString([]byte(r.Body))
+	}
+
+	switch r.Action {
+	case InterceptFulfill:
+		if r.Status == 0 {
+			r.Status = http.StatusOK
+		}
+		if r.Status < 100 || r.Status > 599 {
+			return nil, fmt.Errorf("status must be between 100 and 599")
+		}
+	case InterceptModify:
+		if len(r.Headers) == 0 && c.body == "" {
+			return nil, fmt.Errorf("modify needs headers or a body")
+		}
+	case InterceptRewrite:
+		if r.RewriteURL == "" {
+			return nil, fmt.Errorf("rewrite needs rewriteUrl")
+		}
+	case InterceptFail:
+		name := strings.ToLower(r.ErrorReason)
+		if name == "" {
+			name = "failed"
+		}
+		reason, ok := interceptErrorReasons[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown errorReason %q", r.ErrorReason)
+		}
+		r.ErrorReason = string(reason)
+		c.reason = reason
+	case InterceptDelay:
+		if r.DelayMs == 0 {
+			return nil, fmt.Errorf("delay needs delayMs")
+		}
+	default:
+		return nil, fmt.Errorf("unknown action %q (use fulfill, modify, rewrite, fail or delay)", r.Action)
+	}
+	c.InterceptRule = r
+	return c, nil
+}
+
+// globRegexp turns a URL glob into an anchored regular expression.
+func globRegexp(glob string) *regexp.Regexp {
+	var b strings.Builder
+	b.WriteString("^")
+	for _, ch := range glob {
+		switch ch {
+		case '*':
+			b.WriteString(".*")
+		case '?':
+			b.WriteString(".")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(ch)))
+		}
+	}
+	b.WriteString("$")
+	return regexp.MustCompile(b.String())
+}
+
+func (r *interceptRule) matches(url, method, resourceType string) bool {
+	if r.Times > 0 && r.Hits >= r.Times {
+		return false
+	}
+	if r.Method != "" && r.Method != strings.ToUpper(method) {
+		return false
+	}
+	if r.ResourceType != "" && !strings.EqualFold(r.ResourceType, resourceType) {
+		return false
+	}
+	return r.url.MatchString(url)
+}
+
+// rewrite returns the URL a rewrite rule sends the request to.
+func (r *interceptRule) rewrite(url string) string {
+	if r.URLRegex == "" {
+		return r.RewriteURL
+	}
+	return r.url.ReplaceAllString(url, r.RewriteURL)
+}
+
+// mergeHeaders applies a modify rule's headers to the request's own.
+func mergeHeaders(orig network.Headers, set map[string]string) []*fetch.HeaderEntry {
+	merged := make(map[string]string, len(orig)+len(set))
+	names := make(map[string]string, len(orig)+len(set))
+	for k, v := range orig {
+		if s, ok := v.(string); ok {
+			merged[strings.ToLower(k)] = s
+			names[strings.ToLower(k)] = k
+		}
+	}
+	for k, v := range set {
+		key := strings.ToLower(k)
+		if v == "" {
+			delete(merged, key)
+			continue
+		}
+		merged[key] = v
+		names[key] = k
+	}
+	out := make([]*fetch.HeaderEntry, 0, len(merged))
+	for key, v := range merged {
+		out = append(out, &fetch.HeaderEntry{Name: names[key], Value: v})
+	}
+	slices.SortFunc(out, func(a, b *fetch.HeaderEntry) int { return strings.Compare(a.Name, b.Name) })
+	return out
+}
+
+func headerEntries(headers map[string]string) []*fetch.HeaderEntry {
+	out := make([]*fetch.HeaderEntry, 0, len(headers))
+	for k, v := range headers {
+		out = append(out, &fetch.HeaderEntry{Name: k, Value: v})
+	}
+	slices.SortFunc(out, func(a, b *fetch.HeaderEntry) int { return strings.Compare(a.Name, b.Name) })
+	return out
+}
+
+type redirectBudget struct {
+	max     int
+	count   int
+	blocked bool
+}
+
+// Interceptor applies interception rules to one tab's requests through the
+// CDP Fetch domain. While attached every request of the tab is paused and
+// either handled by the first matching rule or continued unchanged.
+type Interceptor struct {
+	// OnHit, when set, is called with the network request ID of every
+	// request a rule or the replay handles. ruleID is ReplayRuleID for the
+	// replay.
+	OnHit func(requestID, ruleID, action string)
+
+	mu        sync.Mutex
+	rules     []*interceptRule
+	nextID    int
+	listener  context.Context
+	cancel    context.CancelFunc
+	redirects *redirectBudget
+	replay    *HARReplay
+
+	proxyAuth *ProxyAuth
+
+	// enableMu serializes Fetch.enable calls; pausing is whether the last
+	// one asked Chrome to pause every request.
+	enableMu sync.Mutex
+	pausing  bool
+}
+
+// NewInterceptor returns an interceptor with no rules.
+func NewInterceptor() *Interceptor {
+	return &Interceptor{}
+}
+
+// Add validates a rule and appends it, assigning a fresh ID.
+func (i *Interceptor) Add(rule InterceptRule) (InterceptRule, error) {
+	c, err := compileInterceptRule(rule)
+	if err != nil {
+		return InterceptRule{}, err
+	}
+	defer i.syncPatterns()
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	if len(i.rules) >= MaxInterceptRules {
+		return InterceptRule{}, fmt.Errorf("a tab can have at most %d interception rules", MaxInterceptRules)
+	}
+	i.nextID++
+	c.ID = fmt.Sprintf("r%d", i.nextID)
+	c.Hits = 0
+	i.rules = append(i.rules, c)
+	return c.InterceptRule, nil
+}
+
+// Update replaces a rule in place, keeping its ID, position and hits.
+func (i *Interceptor) Update(id string, rule InterceptRule) (InterceptRule, bool, error) {
+	c, err := compileInterceptRule(rule)
+	if err != nil {
+		return InterceptRule{}, false, err
+	}
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	for n, old := range i.rules {
+		if old.ID == id {
+			c.ID, c.Hits = id, old.Hits
+			i.rules[n] = c
+			return c.InterceptRule, true, nil
+		}
+	}
+	return InterceptRule{}, false, nil
+}
+
+// Remove deletes a rule, reporting whether it existed.
+func (i *Interceptor) Remove(id string) bool {
+	defer i.syncPatterns()
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	for n, r := range i.rules {
+		if r.ID == id {
+			i.rules = slices.Delete(i.rules, n, n+1)
+			return true
+		}
+	}
+	return false
+}
+
+// Clear deletes every rule and returns how many there were.
+func (i *Interceptor) Clear() int {
+	defer i.syncPatterns()
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	n := len(i.rules)
+	i.rules = nil
+	return n
+}
+
+// Rules returns the rules in match order with their hit counts.
+func (i *Interceptor) Rules() []InterceptRule {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	out := make([]InterceptRule, len(i.rules))
+	for n, r := range i.rules {
+		out[n] = r.InterceptRule
+	}
+	return out
+}
+
+// Rule returns one rule by ID.
+func (i *Interceptor) Rule(id string) (InterceptRule, bool) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	for _, r := range i.rules {
+		if r.ID == id {
+			return r.InterceptRule, true
+		}
+	}
+	return InterceptRule{}, false
+}
+
+// SetReplay serves the requests no rule handles from an archive. nil
+// stops replaying.
+func (i *Interceptor) SetReplay(r *HARReplay) {
+	defer i.syncPatterns()
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.replay = r
+}
+
+// Replay returns the archive being replayed, if any.
+func (i *Interceptor) Replay() *HARReplay {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	return i.replay
+}
+
+// SetProxyAuth answers the upstream proxy's auth challenges with the given
+// credentials. It must be called before Attach, which is when Chrome is
+// asked to report challenges.
+func (i *Interceptor) SetProxyAuth(username, password string) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.proxyAuth = NewProxyAuth(username, password)
+}
+
+// ProxyAuth reports whether proxy credentials are set.
+func (i *Interceptor) ProxyAuth() bool {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	return i.proxyAuth != nil
+}
+
+// Empty reports whether the interceptor has nothing to do: no rules, no
+// replay and no proxy credentials.
+func (i *Interceptor) Empty() bool {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	return len(i.rules) == 0 && i.replay == nil && i.proxyAuth == nil
+}
+
+// Len returns the number of rules.
+func (i *Interceptor) Len() int {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	return len(i.rules)
+}
+
+// match returns the first rule for a request and counts the hit.
+func (i *Interceptor) match(url, method, resourceType string) (*interceptRule, bool) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	for _, r := range i.rules {
+		if r.matches(url, method, resourceType) {
+			r.Hits++
+			return r, true
+		}
+	}
+	return nil, false
+}
+
+// Attached reports whether the interceptor owns the tab's Fetch domain.
+func (i *Interceptor) Attached() bool {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	return i.listener != nil
+}
+
+// wantsPauseLocked reports whether requests must be paused: for rules, a
+// replay or a redirect limit. Proxy credentials alone only need auth
+// challenges reported.
+func (i *Interceptor) wantsPauseLocked() bool {
+	return len(i.rules) > 0 || i.replay != nil || i.redirects != nil
+}
+
+// enableFetch enables the Fetch domain, pausing every request or none.
+func enableFetch(pause, handleAuth bool) chromedp.Action {
+	pattern := &fetch.RequestPattern{URLPattern: authOnlyURLPattern, RequestStage: fetch.RequestStageRequest}
+	if pause {
+		pattern.URLPattern = "*"
+	}
+	return chromedp.ActionFunc(func(ctx context.Context) error {
+		return fetch.Enable().
+			WithPatterns([]*fetch.RequestPattern{pattern}).
+			WithHandleAuthRequests(handleAuth).
+			Do(ctx)
+	})
+}
+
+// syncPatterns re-enables the Fetch domain of an attached interceptor when
+// whether it needs to pause requests has changed.
+func (i *Interceptor) syncPatterns() {
+	i.enableMu.Lock()
+	defer i.enableMu.Unlock()
+	i.mu.Lock()
+	lctx, pause, handleAuth := i.listener, i.wantsPauseLocked(), i.proxyAuth != nil
+	changed := lctx != nil && pause != i.pausing
+	i.mu.Unlock()
+	if !changed {
+		return
+	}
+	if err := chromedp.Run(lctx, enableFetch(pause, handleAuth)); err != nil {
+		slog.Debug("intercept: fetch re-enable failed", "err", err)
+		return
+	}
+	i.mu.Lock()
+	if i.listener == lctx {
+		i.pausing = pause
+	}
+	i.mu.Unlock()
+}
+
+// Attach enables the Fetch domain on the tab and starts handling paused
+// requests. tabCtx must be the tab's own context, not a request-scoped
+// one: the listener lives as long as it does. Attaching twice is a no-op.
+// With only proxy credentials set, no request is paused until a rule, a
+// replay or a redirect limit needs it.
+func (i *Interceptor) Attach(tabCtx context.Context) error {
+	i.enableMu.Lock()
+	defer i.enableMu.Unlock()
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	if i.listener != nil {
+		return nil
+	}
+	pause := i.wantsPauseLocked()
+	if err := chromedp.Run(tabCtx, enableFetch(pause, i.proxyAuth != nil)); err != nil {
+		return fmt.Errorf("fetch enable: %w", err)
+	}
+
+	lctx, cancel := context.WithCancel(tabCtx)
+	i.listener, i.cancel, i.pausing = lctx, cancel, pause
+	chromedp.ListenTarget(lctx, func(ev any) {
+		// Handle in goroutine to avoid deadlocking the event dispatcher.
+		switch e := ev.(type) {
+		case *fetch.EventRequestPaused:
+			go i.handle(lctx, e)
+		case *fetch.EventAuthRequired:
+			go i.handleAuth(lctx, e)
+		}
+	})
+	context.AfterFunc(lctx, func() {
+		i.mu.Lock()
+		defer i.mu.Unlock()
+		if i.listener == lctx {
+			i.listener, i.cancel = nil, nil
+		}
+	})
+	return nil
+}
+
+// Detach stops handling requests and disables the Fetch domain. The rules
+// are kept.
+func (i *Interceptor) Detach(tabCtx context.Context) {
+	i.mu.Lock()
+	cancel := i.cancel
+	i.listener, i.cancel = nil, nil
+	i.mu.Unlock()
+	if cancel == nil {
+		return
+	}
+	cancel()
+	_ = chromedp.Run(tabCtx, chromedp.ActionFunc(func(ctx context.Context) error {
+		return fetch.Disable().Do(ctx)
+	}))
+}
+
+// LimitRedirects fails redirects past max until the returned function is
+// called, which reports how many redirects were seen and whether any was
+// blocked. Navigation uses it in place of its own Fetch session, which
+// would displace the interceptor's.
+func (i *Interceptor) LimitRedirects(max int) func() (int, bool) {
+	b := &redirectBudget{max: max}
+	i.mu.Lock()
+	i.redirects = b
+	i.mu.Unlock()
+	i.syncPatterns()
+	return func() (int, bool) {
+		defer i.syncPatterns()
+		i.mu.Lock()
+		defer i.mu.Unlock()
+		if i.redirects == b {
+			i.redirects = nil
+		}
+		return b.count, b.blocked
+	}
+}
+
+func (i *Interceptor) redirectAllowed() bool {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	b := i.redirects
+	if b == nil {
+		return true
+	}
+	b.count++
+	if b.count > b.max {
+		b.blocked = true
+		return false
+	}
+	return true
+}
+
+func (i *Interceptor) handle(ctx context.Context, e *fetch.EventRequestPaused) {
+	c := chromedp.FromContext(ctx)
+	if c == nil || c.Target == nil {
+		return
+	}
+	exec := cdp.WithExecutor(ctx, c.Target)
+	if e.RedirectedRequestID != "" && !i.redirectAllowed() {
+		_ = fetch.FailRequest(e.RequestID, network.ErrorReasonBlockedByClient).Do(exec)
+		return
+	}
+
+	rule, ok := i.match(e.Request.URL, e.Request.Method, e.ResourceType.String())
+	if !ok {
+		if replay := i.Replay(); replay != nil {
+			i.serveReplay(exec, e, replay)
+			return
+		}
+		_ = fetch.ContinueRequest(e.RequestID).Do(exec)
+		return
+	}
+	i.hit(e, rule.ID, rule.Action)
+	if rule.DelayMs > 0 {
+		t := time.NewTimer(time.Duration(rule.DelayMs) * time.Millisecond)
+		select {
+		case <-t.C:
+		case <-ctx.Done():
+			t.Stop()
+			return
+		}
+	}
+
+	var err error
+	switch rule.Action {
+	case InterceptFulfill:
+		p := fetch.FulfillRequest(e.RequestID, int64(rule.Status))
+		if len(rule.Headers) > 0 {
+			p = p.WithResponseHeaders(headerEntries(rule.Headers))
+		}
+		err = p.WithBody(rule.body).Do(exec)
+	case InterceptModify:
+		p := fetch.ContinueRequest(e.RequestID)
+		if len(rule.Headers) > 0 {
+			p = p.WithHeaders(mergeHeaders(e.Request.Headers, rule.Headers))
+		}
+		if rule.body != "" {
+			p = p.WithPostData(rule.body)
+		}
+		err = p.Do(exec)
+	case InterceptRewrite:
+		err = fetch.ContinueRequest(e.RequestID).WithURL(rule.rewrite(e.Request.URL)).Do(exec)
+	case InterceptFail:
+		err = fetch.FailRequest(e.RequestID, rule.reason).Do(exec)
+	default:
+		err = fetch.ContinueRequest(e.RequestID).Do(exec)
+	}
+	if err != nil {
+		slog.Debug("intercept: rule failed", "rule", rule.ID, "url", e.Request.URL, "err", err)
+		_ = fetch.ContinueRequest(e.RequestID).Do(exec)
+	}
+}
+
+func (i *Interceptor) handleAuth(ctx context.Context, e *fetch.EventAuthRequired) {
+	i.mu.Lock()
+	auth := i.proxyAuth
+	i.mu.Unlock()
+	if auth == nil {
+		auth = &ProxyAuth{}
+	}
+	auth.Answer(ctx, e)
+}
+
+func (i *Interceptor) hit(e *fetch.EventRequestPaused, ruleID, action string) {
+	if i.OnHit != nil {
+		i.OnHit(string(e.NetworkID), ruleID, action)
+	}
+}
+
+// serveReplay answers a request from the archive, or fails or continues it
+// when the archive has no entry for it.
+func (i *Interceptor) serveReplay(exec context.Context, e *fetch.EventRequestPaused, replay *HARReplay) {
+	resp, ok := replay.lookup(e.Request.Method, e.Request.URL, RequestBody(e.Request))
+	var err error
+	switch {
+	case !ok && replay.Passthrough():
+		i.hit(e, ReplayRuleID, "passthrough")
+		err = fetch.ContinueRequest(e.RequestID).Do(exec)
+	case !ok:
+		i.hit(e, ReplayRuleID, "unmatched")
+		err = fetch.FailRequest(e.RequestID, replayUnmatchedReason).Do(exec)
+	case resp.status == 0:
+		i.hit(e, ReplayRuleID, "replay")
+		err = fetch.FailRequest(e.RequestID, network.ErrorReasonFailed).Do(exec)
+	default:
+		i.hit(e, ReplayRuleID, "replay")
+		p := fetch.FulfillRequest(e.RequestID, int64(resp.status)).WithBody(resp.body)
+		if len(resp.headers) > 0 {
+			p = p.WithResponseHeaders(resp.headers)
+		}
+		if resp.statusText != "" {
+			p = p.WithResponsePhrase(resp.statusText)
+		}
+		err = p.Do(exec)
+	}
+	if err != nil {
+		slog.Debug("intercept: replay failed", "url", e.Request.URL, "err", err)
+		_ = fetch.FailRequest(e.RequestID, network.ErrorReasonFailed).Do(exec)
+	}
+}
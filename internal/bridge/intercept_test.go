@@ -0,0 +1,145 @@
+package bridge
+
+import (
+	"testing"
+
+	"github.com/chromedp/cdproto/fetch"
+	"github.com/chromedp/cdproto/network"
+)
+
+func TestInterceptRuleMatching(t *testing.T) {
+	ic := NewInterceptor()
+	api, err := ic.Add(InterceptRule{URL: "https://example.com/api/*", Method: "post", Action: "Fulfill", Times: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if api.Action != InterceptFulfill || api.Status != 200 {
+		t.Errorf("defaults not applied: %+v", api)
+	}
+	img, err := ic.Add(InterceptRule{URLRegex: `\.png$`, ResourceType: "image", Action: InterceptFail})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		url, method, typ string
+		want             string
+	}{
+		{"https://example.com/api/items?page=1", "GET", "XHR", ""},
+		{"https://example.com/api/items?page=1", "POST", "XHR", api.ID},
+		// times=1 is used up.
+		{"https://example.com/api/items?page=1", "POST", "XHR", ""},
+		{"https://cdn.example.com/logo.png", "GET", "Image", img.ID},
+		{"https://cdn.example.com/logo.png", "GET", "Script", ""},
+	}
+	for _, tc := range cases {
+		r, ok := ic.match(tc.url, tc.method, tc.typ)
+		got := ""
+		if ok {
+			got = r.ID
+		}
+		if got != tc.want {
+			t.Errorf("match(%s %s %s) = %q, want %q", tc.method, tc.url, tc.typ, got, tc.want)
+		}
+	}
+
+	rules := ic.Rules()
+	if rules[0].Hits != 1 || rules[1].Hits != 1 {
+		t.Errorf("hits = %d, %d, want 1, 1", rules[0].Hits, rules[1].Hits)
+	}
+	updated, found, err := ic.Update(api.ID, InterceptRule{URL: "*", Action: InterceptDelay, DelayMs: 10})
+	if err != nil || !found || updated.Hits != 1 || updated.ID != api.ID {
+		t.Errorf("Update = %+v, %v, %v; want hits and ID kept", updated, found, err)
+	}
+}
+
+func TestInterceptRuleRewriteAndHeaders(t *testing.T) {
+	c, err := compileInterceptRule(InterceptRule{URLRegex: `^https://api\.prod\.example\.com/(.*)$`, RewriteURL: "https://api.staging.example.com/$1", Action: InterceptRewrite})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := c.rewrite("https://api.prod.example.com/v1/users?id=3"); got != "https://api.staging.example.com/v1/users?id=3" {
+		t.Errorf("rewrite = %q", got)
+	}
+
+	headers := mergeHeaders(network.Headers{"Accept": "text/html", "Cookie": "a=1"}, map[string]string{"cookie": "", "X-Test": "1"})
+	got := map[string]string{}
+	for _, h := range headers {
+		got[h.Name] = h.Value
+	}
+	if len(got) != 2 || got["Accept"] != "text/html" || got["X-Test"] != "1" {
+		t.Errorf("mergeHeaders = %v", got)
+	}
+}
+
+func TestInterceptLimitRedirects(t *testing.T) {
+	ic := NewInterceptor()
+	done := ic.LimitRedirects(1)
+	if !ic.redirectAllowed() || ic.redirectAllowed() {
+		t.Error("expected the first redirect allowed and the second blocked")
+	}
+	if count, blocked := done(); count != 2 || !blocked {
+		t.Errorf("done() = %d, %v", count, blocked)
+	}
+	if !ic.redirectAllowed() {
+		t.Error("redirects should be unlimited once the navigation is done")
+	}
+}
+
+func TestInterceptProxyAuth(t *testing.T) {
+	var zero ProxyAuth
+	if got := zero.Response("1", fetch.AuthChallengeSourceProxy); got.Response != fetch.AuthChallengeResponseResponseDefault {
+		t.Errorf("without credentials: %s", got.Response)
+	}
+
+	ic := NewInterceptor()
+	ic.SetProxyAuth("user", "pass")
+	if ic.Empty() {
+		t.Error("an interceptor with proxy credentials is not empty")
+	}
+
+	auth := NewProxyAuth("user", "pass")
+	got := auth.Response("1", fetch.AuthChallengeSourceProxy)
+	if got.Response != fetch.AuthChallengeResponseResponseProvideCredentials || got.Username != "user" || got.Password != "pass" {
+		t.Errorf("first proxy challenge = %+v", got)
+	}
+	if got := auth.Response("1", fetch.AuthChallengeSourceProxy); got.Response != fetch.AuthChallengeResponseResponseCancelAuth {
+		t.Errorf("repeated proxy challenge = %s, want CancelAuth", got.Response)
+	}
+	if got := auth.Response("2", fetch.AuthChallengeSourceServer); got.Response != fetch.AuthChallengeResponseResponseDefault {
+		t.Errorf("server challenge = %s, want Default", got.Response)
+	}
+}
+
+func TestInterceptPausesOnlyWhenNeeded(t *testing.T) {
+	ic := NewInterceptor()
+	ic.SetProxyAuth("user", "pass")
+	wantsPause := func() bool {
+		ic.mu.Lock()
+		defer ic.mu.Unlock()
+		return ic.wantsPauseLocked()
+	}
+	if wantsPause() {
+		t.Error("proxy credentials alone should not pause requests")
+	}
+
+	if _, err := ic.Add(InterceptRule{URL: "*.png", Action: InterceptFail}); err != nil {
+		t.Fatal(err)
+	}
+	if !wantsPause() {
+		t.Error("a rule needs requests paused")
+	}
+	ic.Clear()
+	if wantsPause() {
+		t.Error("clearing the rules should stop pausing")
+	}
+
+	done := ic.LimitRedirects(3)
+	if !wantsPause() {
+		t.Error("a redirect limit needs requests paused")
+	}
+	done()
+	if wantsPause() {
+		t.Error("a finished redirect limit should stop pausing")
+	}
+}
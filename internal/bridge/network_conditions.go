@@ -0,0 +1,96 @@
+package bridge
+
+import (
+	"context"
+	"sync"
+
+	"github.com/chromedp/cdproto/network"
+	"github.com/chromedp/chromedp"
+	"github.com/pinchtab/pinchtab/internal/config"
+)
+
+// EmulateNetworkConditions applies resolved network conditions to a tab.
+// Conditions that are not active lift any throttling.
+func EmulateNetworkConditions(ctx context.Context, c config.NetworkConditions) error {
+	return chromedp.Run(ctx,
+		network.Enable(),
+		chromedp.ActionFunc(func(ctx context.Context) error {
+			return network.EmulateNetworkConditions(c.Offline, c.LatencyMs, kbpsToBytes(c.DownloadKbps), kbpsToBytes(c.UploadKbps)).
+				WithPacketLoss(c.PacketLoss).
+				Do(ctx)
+		}),
+	)
+}
+
+// kbpsToBytes converts kilobits per second to the bytes per second CDP
+// expects, where -1 means no limit.
+func kbpsToBytes(kbps float64) float64 {
+	if kbps <= 0 {
+		return -1
+	}
+	return kbps * 1000 / 8
+}
+
+// NetworkEmulator tracks the network conditions of an instance: the ones
+// every tab starts with, and the ones set on single tabs since.
+type NetworkEmulator struct {
+	mu       sync.Mutex
+	instance config.NetworkConditions
+	tabs     map[string]config.NetworkConditions
+}
+
+// NewNetworkEmulator returns an emulator whose tabs start with the given
+// resolved conditions.
+func NewNetworkEmulator(instance config.NetworkConditions) *NetworkEmulator {
+	if instance.Profile == "" {
+		instance.Profile = config.NetworkProfileNone
+	}
+	return &NetworkEmulator{instance: instance, tabs: make(map[string]config.NetworkConditions)}
+}
+
+// Instance returns the conditions tabs start with.
+func (e *NetworkEmulator) Instance() config.NetworkConditions {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.instance
+}
+
+// SetInstance changes the conditions tabs start with and forgets the ones
+// set on single tabs. Callers apply the conditions to open tabs.
+func (e *NetworkEmulator) SetInstance(c config.NetworkConditions) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.instance = c
+	clear(e.tabs)
+}
+
+// Tab returns a tab's conditions and whether they were set on the tab
+// rather than inherited from the instance.
+func (e *NetworkEmulator) Tab(tabID string) (config.NetworkConditions, bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if c, ok := e.tabs[tabID]; ok {
+		return c, true
+	}
+	return e.instance, false
+}
+
+// SetTab records conditions set on one tab until the tab context ends.
+func (e *NetworkEmulator) SetTab(tabCtx context.Context, tabID string, c config.NetworkConditions) {
+	e.mu.Lock()
+	_, tracked := e.tabs[tabID]
+	e.tabs[tabID] = c
+	e.mu.Unlock()
+	if !tracked {
+		context.AfterFunc(tabCtx, func() { e.ResetTab(tabID) })
+	}
+}
+
+// ResetTab makes a tab inherit the instance conditions again and returns
+// them.
+func (e *NetworkEmulator) ResetTab(tabID string) config.NetworkConditions {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	delete(e.tabs, tabID)
+	return e.instance
+}
@@ -0,0 +1,49 @@
+package bridge
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/pinchtab/pinchtab/internal/config"
+)
+
+func TestKbpsToBytes(t *testing.T) {
+	if got := kbpsToBytes(0); got != -1 {
+		t.Errorf("0 kbps: expected -1 (unlimited), got %v", got)
+	}
+	if got := kbpsToBytes(400); got != 50000 {
+		t.Errorf("400 kbps: expected 50000 B/s, got %v", got)
+	}
+}
+
+func TestNetworkEmulatorTabOverrides(t *testing.T) {
+	slow, _ := config.NetworkConditions{Profile: config.NetworkProfileSlow3G}.Resolve()
+	offline, _ := config.NetworkConditions{Profile: config.NetworkProfileOffline}.Resolve()
+	e := NewNetworkEmulator(config.NetworkConditions{})
+
+	if c, own := e.Tab("t1"); own || c.Profile != config.NetworkProfileNone {
+		t.Fatalf("expected t1 to inherit none, got %+v own=%v", c, own)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	e.SetTab(ctx, "t1", slow)
+	if c, own := e.Tab("t1"); !own || c.Profile != config.NetworkProfileSlow3G {
+		t.Fatalf("expected t1 slow-3g override, got %+v own=%v", c, own)
+	}
+
+	e.SetInstance(offline)
+	if c, own := e.Tab("t1"); own || !c.Offline {
+		t.Fatalf("expected SetInstance to drop the override, got %+v own=%v", c, own)
+	}
+
+	e.SetTab(ctx, "t1", slow)
+	cancel()
+	for i := 0; i < 100; i++ {
+		if _, own := e.Tab("t1"); !own {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatal("expected the override to be dropped when the tab context ends")
+}
@@ -77,6 +77,22 @@ func TestNetworkBuffer_Update(t *testing.T) {
 	}
 }
 
+func TestNetworkBuffer_Tag(t *testing.T) {
+	buf := NewNetworkBuffer(10)
+	buf.Add(NetworkEntry{RequestID: "r1", URL: "https://example.com/a"})
+	buf.Tag("r1", "rule1", "fulfill")
+	// A request can be paused before its entry is captured.
+	buf.Tag("r2", "rule2", "fail")
+	buf.Add(NetworkEntry{RequestID: "r2", URL: "https://example.com/b"})
+
+	for id, want := range map[string]string{"r1": "rule1", "r2": "rule2"} {
+		e, _ := buf.Get(id)
+		if e.InterceptRule != want {
+			t.Errorf("%s: InterceptRule = %q, want %q", id, e.InterceptRule, want)
+		}
+	}
+}
+
 func TestNetworkBuffer_TruncatesOversizedFields(t *testing.T) {
 	buf := NewNetworkBuffer(10)
 	buf.Add(NetworkEntry{
@@ -393,3 +409,84 @@ func TestNewNetworkMonitor_ClampsOversizedBuffer(t *testing.T) {
 		t.Errorf("expected bufSize %d, got %d", config.MaxNetworkBufferSize, nm.BufferSizeForTest())
 	}
 }
+
+func TestNetworkBuffer_AddMessage(t *testing.T) {
+	buf := NewNetworkBuffer(10)
+	buf.Add(NetworkEntry{RequestID: "ws1", URL: "wss://example.com/live", Method: "GET", ResourceType: "WebSocket"})
+	subID, ch := buf.SubscribeMessages()
+	defer buf.UnsubscribeMessages(subID)
+
+	buf.AddMessage("ws1", NetworkMessage{Direction: "send", Opcode: 1, Data: `{"op":"subscribe"}`})
+	buf.AddMessage("unknown", NetworkMessage{Direction: "receive", Data: "dropped"})
+
+	select {
+	case ev := <-ch:
+		if ev.RequestID != "ws1" || ev.URL != "wss://example.com/live" || ev.Message.Data != `{"op":"subscribe"}` {
+			t.Errorf("unexpected event %+v", ev)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for message notification")
+	}
+	select {
+	case ev := <-ch:
+		t.Errorf("expected no event for an unknown request, got %+v", ev)
+	default:
+	}
+
+	entry, _ := buf.Get("ws1")
+	if len(entry.Messages) != 1 || entry.Messages[0].Direction != "send" {
+		t.Errorf("expected the message on the entry, got %+v", entry.Messages)
+	}
+}
+
+func TestNetworkBuffer_AddMessageDropsOldest(t *testing.T) {
+	buf := NewNetworkBuffer(10)
+	buf.Add(NetworkEntry{RequestID: "ws1", URL: "wss://example.com", Method: "GET"})
+	before, _ := buf.Get("ws1")
+	for i := 0; i < 205; i++ {
+		buf.AddMessage("ws1", NetworkMessage{Direction: "receive", Data: fmt.Sprintf("m%d", i)})
+		if i == 199 {
+			before, _ = buf.Get("ws1")
+		}
+	}
+	entry, _ := buf.Get("ws1")
+	if len(entry.Messages) != 200 || entry.MessagesDropped != 5 {
+		t.Fatalf("expected 200 messages and 5 dropped, got %d and %d", len(entry.Messages), entry.MessagesDropped)
+	}
+	if entry.Messages[0].Data != "m5" || entry.Messages[199].Data != "m204" {
+		t.Errorf("expected m5..m204, got %s..%s", entry.Messages[0].Data, entry.Messages[199].Data)
+	}
+	if before.Messages[0].Data != "m0" || before.Messages[199].Data != "m199" {
+		t.Errorf("earlier copy changed: %s..%s", before.Messages[0].Data, before.Messages[199].Data)
+	}
+}
+
+func TestNetworkFilter_Messages(t *testing.T) {
+	buf := NewNetworkBuffer(10)
+	buf.Add(NetworkEntry{RequestID: "r1", URL: "https://example.com/api", Method: "GET", ResourceType: "XHR"})
+	buf.Add(NetworkEntry{RequestID: "ws1", URL: "wss://example.com/live", Method: "GET", ResourceType: "WebSocket"})
+	buf.AddMessage("ws1", NetworkMessage{Direction: "send", Data: `{"op":"ping"}`})
+	buf.AddMessage("ws1", NetworkMessage{Direction: "receive", Data: `{"op":"price","value":3}`})
+	buf.AddMessage("ws1", NetworkMessage{Direction: "receive", Data: `{"op":"pong"}`})
+
+	entries := buf.List(NetworkFilter{Direction: "receive", MessagePattern: "PRICE"})
+	if len(entries) != 1 || entries[0].RequestID != "ws1" {
+		t.Fatalf("expected only ws1, got %+v", entries)
+	}
+	if len(entries[0].Messages) != 1 || entries[0].Messages[0].Data != `{"op":"price","value":3}` {
+		t.Errorf("expected only the matching message, got %+v", entries[0].Messages)
+	}
+	if all := buf.List(NetworkFilter{}); len(all) != 2 || len(all[1].Messages) != 3 {
+		t.Errorf("expected unfiltered list to keep every message, got %+v", all)
+	}
+
+	f := NetworkFilter{ResourceType: "websocket", Direction: "send"}
+	ev := NetworkMessageEvent{RequestID: "ws1", URL: "wss://example.com/live", ResourceType: "WebSocket", Message: NetworkMessage{Direction: "send"}}
+	if !f.MatchMessageEvent(ev) {
+		t.Error("expected sent frame to match")
+	}
+	ev.Message.Direction = "receive"
+	if f.MatchMessageEvent(ev) {
+		t.Error("expected received frame not to match direction=send")
+	}
+}
@@ -4,6 +4,8 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"maps"
+	"slices"
 )
 
 func init() {
@@ -17,6 +19,7 @@ func init() {
 // harEncoder writes HAR 1.2 JSON incrementally.
 type harEncoder struct {
 	creator    harCreator
+	meta       map[string]any
 	w          io.Writer
 	entryCount int
 	started    bool
@@ -30,6 +33,11 @@ type harCreator struct {
 func (e *harEncoder) ContentType() string   { return "application/har+json" }
 func (e *harEncoder) FileExtension() string { return ".har" }
 
+// SetMetadata adds custom fields to the log object.
+func (e *harEncoder) SetMetadata(meta map[string]any) {
+	e.meta = meta
+}
+
 func (e *harEncoder) Start(w io.Writer) error {
 	e.w = w
 	e.started = true
@@ -44,6 +52,19 @@ func (e *harEncoder) Start(w io.Writer) error {
 	if _, err = e.w.Write(creatorJSON); err != nil {
 		return err
 	}
+	for _, key := range slices.Sorted(maps.Keys(e.meta)) {
+		keyJSON, err := json.Marshal(key)
+		if err != nil {
+			return err
+		}
+		valueJSON, err := json.Marshal(e.meta[key])
+		if err != nil {
+			return fmt.Errorf("marshal %s: %w", key, err)
+		}
+		if _, err := fmt.Fprintf(e.w, ",%s:%s", keyJSON, valueJSON); err != nil {
+			return err
+		}
+	}
 	_, err = io.WriteString(e.w, `,"entries":[`)
 	return err
 }
@@ -2,9 +2,11 @@ package observe
 
 import (
 	"context"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"log/slog"
+	"slices"
 	"strings"
 	"sync"
 	"time"
@@ -30,6 +32,18 @@ const (
 	maxNetworkHeaderKeyBytes    = 256
 	maxNetworkHeaderValueBytes  = 4 * 1024
 	maxNetworkHeaderTotalBytes  = 32 * 1024
+
+	// maxNetworkMessages bounds the WebSocket frames and EventSource
+	// messages kept per entry; older ones are dropped first.
+	maxNetworkMessages = 200
+	// maxNetworkMessagePreviewBytes bounds the payload kept per message.
+	maxNetworkMessagePreviewBytes = 1024
+)
+
+// Directions of a NetworkMessage.
+const (
+	MessageSend    = "send"
+	MessageReceive = "receive"
 )
 
 // NetworkEntry represents a single captured network request/response pair.
@@ -51,6 +65,48 @@ type NetworkEntry struct {
 	Error           string            `json:"error,omitempty"`
 	Finished        bool              `json:"finished"`
 	Failed          bool              `json:"failed"`
+	InterceptRule   string            `json:"interceptRule,omitempty"`
+	InterceptAction string            `json:"interceptAction,omitempty"`
+	// Messages holds the frames of a WebSocket and the messages of an
+	// EventSource, oldest first. MessagesDropped counts the ones dropped
+	// to keep at most maxNetworkMessages.
+	Messages        []NetworkMessage `json:"messages,omitempty"`
+	MessagesDropped int              `json:"messagesDropped,omitempty"`
+}
+
+// NetworkMessage is a WebSocket frame or an EventSource message. Data is a
+// preview of the payload, cut to maxNetworkMessagePreviewBytes; Size is the
+// length of the whole payload. Binary WebSocket frames (opcode 2) carry
+// base64 data.
+type NetworkMessage struct {
+	Direction string    `json:"direction"`
+	Opcode    int       `json:"opcode,omitempty"`
+	EventName string    `json:"eventName,omitempty"`
+	EventID   string    `json:"eventId,omitempty"`
+	Data      string    `json:"data"`
+	Size      int       `json:"size"`
+	Truncated bool      `json:"truncated,omitempty"`
+	Time      time.Time `json:"time"`
+}
+
+// NetworkMessageEvent is a message as delivered to message subscribers,
+// with the connection it belongs to.
+type NetworkMessageEvent struct {
+	RequestID    string         `json:"requestId"`
+	URL          string         `json:"url"`
+	Method       string         `json:"method"`
+	Status       int            `json:"status,omitempty"`
+	ResourceType string         `json:"resourceType"`
+	Message      NetworkMessage `json:"message"`
+}
+
+// newNetworkMessage builds a message, cutting its payload to the preview
+// size.
+func newNetworkMessage(direction, data string) NetworkMessage {
+	m := NetworkMessage{Direction: direction, Size: len(data), Time: time.Now()}
+	m.Data = sanitize.TruncateUTF8Bytes(data, maxNetworkMessagePreviewBytes)
+	m.Truncated = len(m.Data) < len(data)
+	return m
 }
 
 // NetworkBuffer is a thread-safe ring buffer of network entries for a single tab.
@@ -59,10 +115,13 @@ type NetworkBuffer struct {
 	entries []NetworkEntry
 	index   map[string]int
 	maxSize int
+	// tags holds interception marks for requests not yet in the buffer.
+	tags map[string][2]string
 
-	subMu       sync.Mutex
-	subscribers map[int]chan NetworkEntry
-	nextSubID   int
+	subMu          sync.Mutex
+	subscribers    map[int]chan NetworkEntry
+	msgSubscribers map[int]chan NetworkMessageEvent
+	nextSubID      int
 }
 
 // NewNetworkBuffer creates a ring buffer with the given capacity.
@@ -72,10 +131,11 @@ func NewNetworkBuffer(size int) *NetworkBuffer {
 		size = DefaultNetworkBufferSize
 	}
 	return &NetworkBuffer{
-		entries:     make([]NetworkEntry, 0, size),
-		index:       make(map[string]int),
-		maxSize:     size,
-		subscribers: make(map[int]chan NetworkEntry),
+		entries:        make([]NetworkEntry, 0, size),
+		index:          make(map[string]int),
+		maxSize:        size,
+		subscribers:    make(map[int]chan NetworkEntry),
+		msgSubscribers: make(map[int]chan NetworkMessageEvent),
 	}
 }
 
@@ -89,6 +149,10 @@ func (nb *NetworkBuffer) Add(entry NetworkEntry) {
 		nb.entries[idx] = entry
 	} else {
 		isNew = true
+		if tag, ok := nb.tags[entry.RequestID]; ok {
+			entry.InterceptRule, entry.InterceptAction = tag[0], tag[1]
+			delete(nb.tags, entry.RequestID)
+		}
 		if len(nb.entries) >= nb.maxSize {
 			oldest := nb.entries[0]
 			delete(nb.index, oldest.RequestID)
@@ -135,6 +199,66 @@ func (nb *NetworkBuffer) Unsubscribe(id int) {
 	}
 }
 
+// SubscribeMessages returns a channel that receives WebSocket frames and
+// EventSource messages as they are captured.
+func (nb *NetworkBuffer) SubscribeMessages() (int, <-chan NetworkMessageEvent) {
+	nb.subMu.Lock()
+	defer nb.subMu.Unlock()
+	id := nb.nextSubID
+	nb.nextSubID++
+	ch := make(chan NetworkMessageEvent, 64)
+	nb.msgSubscribers[id] = ch
+	return id, ch
+}
+
+// UnsubscribeMessages removes a message subscriber and closes its channel.
+func (nb *NetworkBuffer) UnsubscribeMessages(id int) {
+	nb.subMu.Lock()
+	defer nb.subMu.Unlock()
+	if ch, ok := nb.msgSubscribers[id]; ok {
+		close(ch)
+		delete(nb.msgSubscribers, id)
+	}
+}
+
+// AddMessage appends a message to an entry, dropping the oldest beyond
+// maxNetworkMessages, and hands it to message subscribers. Messages for
+// entries not in the buffer are ignored.
+func (nb *NetworkBuffer) AddMessage(requestID string, msg NetworkMessage) {
+	nb.mu.Lock()
+	idx, ok := nb.index[requestID]
+	if !ok {
+		nb.mu.Unlock()
+		return
+	}
+	entry := &nb.entries[idx]
+	if len(entry.Messages) >= maxNetworkMessages {
+		// Reslicing keeps earlier copies of the entry intact: appends only
+		// write past their end.
+		entry.Messages = entry.Messages[1:]
+		entry.MessagesDropped++
+	}
+	entry.Messages = append(entry.Messages, msg)
+	ev := NetworkMessageEvent{
+		RequestID:    entry.RequestID,
+		URL:          entry.URL,
+		Method:       entry.Method,
+		Status:       entry.Status,
+		ResourceType: entry.ResourceType,
+		Message:      msg,
+	}
+	nb.mu.Unlock()
+
+	nb.subMu.Lock()
+	for _, ch := range nb.msgSubscribers {
+		select {
+		case ch <- ev:
+		default:
+		}
+	}
+	nb.subMu.Unlock()
+}
+
 // Get returns a specific entry by request ID.
 func (nb *NetworkBuffer) Get(requestID string) (NetworkEntry, bool) {
 	nb.mu.RLock()
@@ -158,6 +282,26 @@ func (nb *NetworkBuffer) Update(requestID string, fn func(*NetworkEntry)) {
 	nb.entries[idx] = normalizeNetworkEntry(nb.entries[idx])
 }
 
+// Tag marks a request as handled by an interception rule. The request may
+// be paused before its entry is added, in which case the mark is applied
+// when it is.
+func (nb *NetworkBuffer) Tag(requestID, rule, action string) {
+	nb.mu.Lock()
+	defer nb.mu.Unlock()
+	if idx, ok := nb.index[requestID]; ok {
+		nb.entries[idx].InterceptRule = rule
+		nb.entries[idx].InterceptAction = action
+		return
+	}
+	if nb.tags == nil {
+		nb.tags = make(map[string][2]string)
+	}
+	if len(nb.tags) >= nb.maxSize {
+		clear(nb.tags)
+	}
+	nb.tags[requestID] = [2]string{rule, action}
+}
+
 // List returns all entries, optionally filtered.
 func (nb *NetworkBuffer) List(filter NetworkFilter) []NetworkEntry {
 	nb.mu.RLock()
@@ -166,6 +310,9 @@ func (nb *NetworkBuffer) List(filter NetworkFilter) []NetworkEntry {
 	result := make([]NetworkEntry, 0, len(nb.entries))
 	for _, e := range nb.entries {
 		if filter.Match(e) {
+			if filter.filtersMessages() {
+				e.Messages = filter.matchingMessages(e.Messages)
+			}
 			result = append(result, e)
 		}
 	}
@@ -178,6 +325,7 @@ func (nb *NetworkBuffer) Clear() {
 	defer nb.mu.Unlock()
 	nb.entries = nb.entries[:0]
 	nb.index = make(map[string]int)
+	nb.tags = nil
 }
 
 // Len returns the number of entries.
@@ -195,12 +343,54 @@ func (nb *NetworkBuffer) MaxSizeForTest() int {
 }
 
 // NetworkFilter defines criteria for filtering network entries.
+// MessagePattern and Direction select WebSocket and EventSource messages:
+// when either is set, only entries with a matching message match, and
+// List keeps only the matching messages.
 type NetworkFilter struct {
-	URLPattern   string
-	Method       string
-	StatusRange  string
-	ResourceType string
-	Limit        int
+	URLPattern     string
+	Method         string
+	StatusRange    string
+	ResourceType   string
+	MessagePattern string
+	Direction      string
+	Limit          int
+}
+
+func (f NetworkFilter) filtersMessages() bool {
+	return f.MessagePattern != "" || f.Direction != ""
+}
+
+// MatchMessage returns true if a message matches the message criteria.
+func (f NetworkFilter) MatchMessage(m NetworkMessage) bool {
+	if f.Direction != "" && !strings.EqualFold(m.Direction, f.Direction) {
+		return false
+	}
+	if f.MessagePattern != "" && !strings.Contains(strings.ToLower(m.Data), strings.ToLower(f.MessagePattern)) {
+		return false
+	}
+	return true
+}
+
+// MatchMessageEvent returns true if a streamed message and its connection
+// match the filter criteria.
+func (f NetworkFilter) MatchMessageEvent(ev NetworkMessageEvent) bool {
+	return f.Match(NetworkEntry{
+		URL:          ev.URL,
+		Method:       ev.Method,
+		Status:       ev.Status,
+		ResourceType: ev.ResourceType,
+		Messages:     []NetworkMessage{ev.Message},
+	})
+}
+
+func (f NetworkFilter) matchingMessages(msgs []NetworkMessage) []NetworkMessage {
+	var out []NetworkMessage
+	for _, m := range msgs {
+		if f.MatchMessage(m) {
+			out = append(out, m)
+		}
+	}
+	return out
 }
 
 // Match returns true if the entry matches the filter criteria.
@@ -217,6 +407,9 @@ func (f NetworkFilter) Match(e NetworkEntry) bool {
 	if f.StatusRange != "" && !MatchStatusRange(e.Status, f.StatusRange) {
 		return false
 	}
+	if f.filtersMessages() && !slices.ContainsFunc(e.Messages, f.MatchMessage) {
+		return false
+	}
 	return true
 }
 
@@ -328,10 +521,8 @@ func (nm *NetworkMonitor) StartCaptureWithSize(tabCtx context.Context, tabID str
 				}
 			}
 			var postData string
-			if e.Request.HasPostData && len(e.Request.PostDataEntries) > 0 {
-				for _, entry := range e.Request.PostDataEntries {
-					postData += entry.Bytes
-				}
+			if e.Request.HasPostData {
+				postData = RequestBody(e.Request)
 			}
 			entry := NetworkEntry{
 				RequestID:      string(e.RequestID),
@@ -385,6 +576,65 @@ func (nm *NetworkMonitor) StartCaptureWithSize(tabCtx context.Context, tabID str
 				}
 				entry.Error = e.ErrorText
 			})
+
+		case *network.EventWebSocketCreated:
+			buf.Add(NetworkEntry{
+				RequestID:    string(e.RequestID),
+				URL:          e.URL,
+				Method:       "GET",
+				ResourceType: network.ResourceTypeWebSocket.String(),
+				StartTime:    time.Now(),
+			})
+
+		case *network.EventWebSocketWillSendHandshakeRequest:
+			if e.Request != nil {
+				buf.Update(string(e.RequestID), func(entry *NetworkEntry) {
+					entry.RequestHeaders = headerStrings(e.Request.Headers)
+				})
+			}
+
+		case *network.EventWebSocketHandshakeResponseReceived:
+			if e.Response != nil {
+				buf.Update(string(e.RequestID), func(entry *NetworkEntry) {
+					entry.Status = int(e.Response.Status)
+					entry.StatusText = e.Response.StatusText
+					entry.ResponseHeaders = headerStrings(e.Response.Headers)
+				})
+			}
+
+		case *network.EventWebSocketFrameSent:
+			if e.Response != nil {
+				msg := newNetworkMessage(MessageSend, e.Response.PayloadData)
+				msg.Opcode = int(e.Response.Opcode)
+				buf.AddMessage(string(e.RequestID), msg)
+			}
+
+		case *network.EventWebSocketFrameReceived:
+			if e.Response != nil {
+				msg := newNetworkMessage(MessageReceive, e.Response.PayloadData)
+				msg.Opcode = int(e.Response.Opcode)
+				buf.AddMessage(string(e.RequestID), msg)
+			}
+
+		case *network.EventWebSocketFrameError:
+			buf.Update(string(e.RequestID), func(entry *NetworkEntry) {
+				entry.Error = e.ErrorMessage
+			})
+
+		case *network.EventWebSocketClosed:
+			buf.Update(string(e.RequestID), func(entry *NetworkEntry) {
+				entry.Finished = true
+				entry.EndTime = time.Now()
+				if !entry.StartTime.IsZero() {
+					entry.Duration = float64(entry.EndTime.Sub(entry.StartTime).Milliseconds())
+				}
+			})
+
+		case *network.EventEventSourceMessageReceived:
+			msg := newNetworkMessage(MessageReceive, e.Data)
+			msg.EventName = e.EventName
+			msg.EventID = e.EventID
+			buf.AddMessage(string(e.RequestID), msg)
 		}
 	})
 
@@ -392,6 +642,37 @@ func (nm *NetworkMonitor) StartCaptureWithSize(tabCtx context.Context, tabID str
 	return nil
 }
 
+// headerStrings keeps the string-valued headers of a CDP header map.
+func headerStrings(h network.Headers) map[string]string {
+	if h == nil {
+		return nil
+	}
+	out := make(map[string]string, len(h))
+	for k, v := range h {
+		if s, ok := v.(string); ok {
+			out[k] = s
+		}
+	}
+	return out
+}
+
+// RequestBody returns a request's body as sent. CDP delivers it as base64
+// chunks; a chunk that does not decode is kept as it came.
+func RequestBody(req *network.Request) string {
+	if req == nil {
+		return ""
+	}
+	var b strings.Builder
+	for _, entry := range req.PostDataEntries {
+		if raw, err := base64.StdEncoding.DecodeString(entry.Bytes); err == nil {
+			b.Write(raw)
+		} else {
+			b.WriteString(entry.Bytes)
+		}
+	}
+	return b.String()
+}
+
 // StopCapture removes the buffer and listener for a tab.
 func (nm *NetworkMonitor) StopCapture(tabID string) {
 	nm.mu.Lock()
@@ -33,6 +33,15 @@ type ExportEncoder interface {
 	Finish() error
 }
 
+// ExportMetadataEncoder is implemented by encoders whose format has room for
+// export-wide metadata, such as the network conditions the capture ran
+// under. SetMetadata is called before Start. Keys are written as given, so
+// for HAR they should start with an underscore to mark a custom field.
+type ExportMetadataEncoder interface {
+	ExportEncoder
+	SetMetadata(meta map[string]any)
+}
+
 // ExportEncoderFactory creates a new encoder for a single export session.
 type ExportEncoderFactory func(creatorName, creatorVersion string) ExportEncoder
 
@@ -84,6 +93,29 @@ type ExportEntry struct {
 	Request         ExportRequest  `json:"request"`
 	Response        ExportResponse `json:"response"`
 	Timings         ExportTimings  `json:"timings"`
+	// WebSocketMessages and EventSourceMessages carry the messages of a
+	// WebSocket or EventSource connection, as custom HAR fields. The
+	// WebSocket ones follow the Chrome DevTools convention.
+	WebSocketMessages   []ExportWebSocketMessage   `json:"_webSocketMessages,omitempty"`
+	EventSourceMessages []ExportEventSourceMessage `json:"_eventSourceMessages,omitempty"`
+}
+
+// ExportWebSocketMessage is a WebSocket frame. Time is in seconds since the
+// Unix epoch.
+type ExportWebSocketMessage struct {
+	Type   string  `json:"type"`
+	Time   float64 `json:"time"`
+	Opcode int     `json:"opcode"`
+	Data   string  `json:"data"`
+}
+
+// ExportEventSourceMessage is an EventSource message. Time is in seconds
+// since the Unix epoch.
+type ExportEventSourceMessage struct {
+	Time      float64 `json:"time"`
+	EventName string  `json:"eventName"`
+	EventID   string  `json:"eventId"`
+	Data      string  `json:"data"`
 }
 
 // ExportRequest holds the request portion of an entry.
@@ -178,6 +210,19 @@ func NetworkEntryToExport(entry NetworkEntry, body string, base64Encoded bool) E
 		}
 	}
 
+	for _, m := range entry.Messages {
+		t := float64(m.Time.UnixMicro()) / 1e6
+		if strings.EqualFold(entry.ResourceType, "EventSource") {
+			e.EventSourceMessages = append(e.EventSourceMessages, ExportEventSourceMessage{
+				Time: t, EventName: m.EventName, EventID: m.EventID, Data: m.Data,
+			})
+			continue
+		}
+		e.WebSocketMessages = append(e.WebSocketMessages, ExportWebSocketMessage{
+			Type: m.Direction, Time: t, Opcode: m.Opcode, Data: m.Data,
+		})
+	}
+
 	if body != "" {
 		e.Response.Content.Text = body
 		if base64Encoded {
@@ -188,3 +188,80 @@ func makeTestExportEntry() ExportEntry {
 		Timings: ExportTimings{Send: 1, Wait: 98, Receive: 1},
 	}
 }
+
+func TestHAREncoder_Metadata(t *testing.T) {
+	enc := GetFormat("har")("Test", "0")
+	me, ok := enc.(ExportMetadataEncoder)
+	if !ok {
+		t.Fatal("har encoder should accept metadata")
+	}
+	me.SetMetadata(map[string]any{"_networkConditions": map[string]any{"profile": "slow-3g"}})
+	var buf bytes.Buffer
+	_ = enc.Start(&buf)
+	_ = enc.Encode(makeTestExportEntry())
+	_ = enc.Finish()
+
+	var har struct {
+		Log struct {
+			NetworkConditions struct{ Profile string } `json:"_networkConditions"`
+			Entries           []json.RawMessage        `json:"entries"`
+		} `json:"log"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &har); err != nil {
+		t.Fatalf("invalid HAR JSON: %v", err)
+	}
+	if har.Log.NetworkConditions.Profile != "slow-3g" || len(har.Log.Entries) != 1 {
+		t.Errorf("unexpected HAR %s", buf.String())
+	}
+	if _, err := ReadExport(&buf); err != nil {
+		t.Errorf("HAR with metadata should read back: %v", err)
+	}
+}
+
+func TestNewNetworkMessage_Preview(t *testing.T) {
+	long := strings.Repeat("x", maxNetworkMessagePreviewBytes+10)
+	m := newNetworkMessage(MessageReceive, long)
+	if len(m.Data) != maxNetworkMessagePreviewBytes || m.Size != len(long) || !m.Truncated {
+		t.Errorf("expected a truncated preview of a %d byte payload, got %d bytes (size %d, truncated %v)", len(long), len(m.Data), m.Size, m.Truncated)
+	}
+	if m := newNetworkMessage(MessageSend, "hi"); m.Truncated || m.Data != "hi" || m.Size != 2 {
+		t.Errorf("unexpected short message %+v", m)
+	}
+}
+
+func TestNetworkEntryToExport_Messages(t *testing.T) {
+	at := time.Date(2026, 3, 1, 12, 0, 0, 500_000_000, time.UTC)
+	ws := NetworkEntry{
+		RequestID: "ws1", URL: "wss://example.com/live", Method: "GET", Status: 101, ResourceType: "WebSocket",
+		Messages: []NetworkMessage{
+			{Direction: MessageSend, Opcode: 1, Data: "hello", Time: at},
+			{Direction: MessageReceive, Opcode: 2, Data: "AAE=", Time: at},
+		},
+	}
+	e := NetworkEntryToExport(ws, "", false)
+	if len(e.WebSocketMessages) != 2 || e.EventSourceMessages != nil {
+		t.Fatalf("expected 2 websocket messages, got %+v / %+v", e.WebSocketMessages, e.EventSourceMessages)
+	}
+	want := ExportWebSocketMessage{Type: "send", Time: float64(at.UnixMicro()) / 1e6, Opcode: 1, Data: "hello"}
+	if e.WebSocketMessages[0] != want {
+		t.Errorf("expected %+v, got %+v", want, e.WebSocketMessages[0])
+	}
+	data, _ := json.Marshal(e)
+	if !strings.Contains(string(data), `"_webSocketMessages":[{"type":"send"`) {
+		t.Errorf("expected _webSocketMessages in HAR entry, got %s", data)
+	}
+
+	sse := NetworkEntry{
+		RequestID: "es1", URL: "https://example.com/events", Method: "GET", ResourceType: "EventSource",
+		Messages: []NetworkMessage{{Direction: MessageReceive, EventName: "tick", EventID: "7", Data: "1", Time: at}},
+	}
+	e = NetworkEntryToExport(sse, "", false)
+	if len(e.EventSourceMessages) != 1 || e.EventSourceMessages[0].EventName != "tick" || e.WebSocketMessages != nil {
+		t.Errorf("expected one eventsource message, got %+v / %+v", e.EventSourceMessages, e.WebSocketMessages)
+	}
+
+	data, _ = json.Marshal(NetworkEntryToExport(NetworkEntry{URL: "https://example.com"}, "", false))
+	if strings.Contains(string(data), "Messages") {
+		t.Errorf("expected no message fields on plain entries, got %s", data)
+	}
+}
@@ -0,0 +1,49 @@
+package observe
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// ReadExport parses an export back into entries. It accepts a HAR document
+// from any tool as well as the NDJSON format.
+func ReadExport(r io.Reader) ([]ExportEntry, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 {
+		return nil, fmt.Errorf("empty export")
+	}
+
+	var har struct {
+		Log *struct {
+			Entries []ExportEntry `json:"entries"`
+		} `json:"log"`
+	}
+	if err := json.Unmarshal(data, &har); err == nil && har.Log != nil {
+		return har.Log.Entries, nil
+	}
+
+	var entries []ExportEntry
+	dec := json.NewDecoder(bytes.NewReader(data))
+	for {
+		var e ExportEntry
+		err := dec.Decode(&e)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("entry %d: %w", len(entries)+1, err)
+		}
+		if e.Request.URL == "" {
+			return nil, fmt.Errorf("entry %d: not a HAR or NDJSON network export", len(entries)+1)
+		}
+		entries = append(entries, e)
+	}
+	return entries, nil
+}
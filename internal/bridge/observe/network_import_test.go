@@ -0,0 +1,39 @@
+package observe
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestReadExport(t *testing.T) {
+	entry := makeTestExportEntry()
+	for _, format := range []string{"har", "ndjson"} {
+		var buf bytes.Buffer
+		enc := GetFormat(format)("Test", "0")
+		_ = enc.Start(&buf)
+		_ = enc.Encode(entry)
+		_ = enc.Encode(entry)
+		_ = enc.Finish()
+
+		entries, err := ReadExport(&buf)
+		if err != nil {
+			t.Fatalf("%s: %v", format, err)
+		}
+		if len(entries) != 2 || entries[0].Request.URL != entry.Request.URL || entries[1].Response.Status != entry.Response.Status {
+			t.Errorf("%s: unexpected entries %+v", format, entries)
+		}
+	}
+}
+
+func TestReadExport_Invalid(t *testing.T) {
+	for name, input := range map[string]string{
+		"empty":   "  \n",
+		"garbage": "not json",
+		"no url":  `{"request":{"method":"GET"}}`,
+	} {
+		if _, err := ReadExport(strings.NewReader(input)); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"strconv"
 	"strings"
 
 	"github.com/chromedp/chromedp"
@@ -19,6 +20,29 @@ type A11yNode struct {
 	Focused  bool   `json:"focused,omitempty"`
 	Hidden   bool   `json:"hidden,omitempty"`
 	NodeID   int64  `json:"nodeId,omitempty"`
+	// Path is the roles of the node's ancestors in the full tree. It helps
+	// recognise an element that the page re-rendered as a new DOM node.
+	Path string `json:"-" yaml:"-"`
+	// Level and Checked feed the Markdown format: heading level, and
+	// "true", "false" or "mixed" for checkboxes, radios and switches.
+	Level   int    `json:"-" yaml:"-"`
+	Checked string `json:"-" yaml:"-"`
+
+	Geometry *Geometry `json:"geometry,omitempty"`
+}
+
+// Geometry is where a node is drawn, in CSS pixels relative to the
+// viewport. Occluders and scroll containers are given as refs when they are
+// part of the same snapshot, otherwise as a short tag.class description.
+type Geometry struct {
+	X               int    `json:"x"`
+	Y               int    `json:"y"`
+	Width           int    `json:"width"`
+	Height          int    `json:"height"`
+	InViewport      bool   `json:"inViewport"`
+	Occluded        bool   `json:"occluded,omitempty"`
+	OccludedBy      string `json:"occludedBy,omitempty"`
+	ScrollContainer string `json:"scrollContainer,omitempty"`
 }
 
 type RawAXNode struct {
@@ -216,6 +240,32 @@ func BuildSnapshot(nodes []RawAXNode, filter string, maxDepth int) ([]A11yNode,
 		return false
 	}
 
+	byID := make(map[string]RawAXNode, len(nodes))
+	for _, n := range nodes {
+		byID[n.NodeID] = n
+	}
+	paths := make(map[string]string, len(nodes))
+	var pathOf func(nodeID string, walk int) string
+	pathOf = func(nodeID string, walk int) string {
+		if p, ok := paths[nodeID]; ok {
+			return p
+		}
+		parentID, ok := parentMap[nodeID]
+		if !ok || walk > maxAncestorWalk {
+			return ""
+		}
+		p := pathOf(parentID, walk+1)
+		if parent := byID[parentID]; !parent.Ignored {
+			switch role := parent.Role.String(); role {
+			case "", "none", "generic":
+			default:
+				p += "/" + role
+			}
+		}
+		paths[nodeID] = p
+		return p
+	}
+
 	flat := make([]A11yNode, 0)
 	refs := make(map[string]int64)
 	refID := 0
@@ -249,6 +299,7 @@ func BuildSnapshot(nodes []RawAXNode, filter string, maxDepth int) ([]A11yNode,
 			Role:  role,
 			Name:  name,
 			Depth: depth,
+			Path:  pathOf(n.NodeID, 0),
 		}
 
 		if v := n.Value.String(); v != "" {
@@ -266,6 +317,12 @@ func BuildSnapshot(nodes []RawAXNode, filter string, maxDepth int) ([]A11yNode,
 			if prop.Name == "focused" && prop.Value.String() == "true" {
 				entry.Focused = true
 			}
+			if prop.Name == "level" {
+				entry.Level, _ = strconv.Atoi(prop.Value.String())
+			}
+			if prop.Name == "checked" {
+				entry.Checked = prop.Value.String()
+			}
 		}
 
 		// Tag nodes that are visually hidden but still present in the a11y tree
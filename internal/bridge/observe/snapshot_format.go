@@ -1,6 +1,9 @@
 package observe
 
-import "strings"
+import (
+	"strconv"
+	"strings"
+)
 
 func FormatSnapshotText(nodes []A11yNode) string {
 	var b strings.Builder
@@ -30,6 +33,7 @@ func FormatSnapshotText(nodes []A11yNode) string {
 		if n.Hidden {
 			b.WriteString(" [hidden]")
 		}
+		writeGeometry(&b, n.Geometry, false)
 		b.WriteByte('\n')
 	}
 	return b.String()
@@ -60,24 +64,95 @@ func FormatSnapshotCompact(nodes []A11yNode) string {
 		if n.Hidden {
 			b.WriteString(" [hidden]")
 		}
+		writeGeometry(&b, n.Geometry, true)
 		b.WriteByte('\n')
 	}
 	return b.String()
 }
 
+// writeGeometry appends a node's box as " @x,y wxh" followed by its
+// visibility markers. Text output spells the markers out; compact output
+// uses "!off" for off-screen, "!occ" for occluded and "^" for the scroll
+// container.
+func writeGeometry(b *strings.Builder, g *Geometry, compact bool) {
+	if g == nil {
+		return
+	}
+	b.WriteString(" @")
+	b.WriteString(strconv.Itoa(g.X))
+	b.WriteByte(',')
+	b.WriteString(strconv.Itoa(g.Y))
+	b.WriteByte(' ')
+	b.WriteString(strconv.Itoa(g.Width))
+	b.WriteByte('x')
+	b.WriteString(strconv.Itoa(g.Height))
+	if compact {
+		if !g.InViewport {
+			b.WriteString(" !off")
+		}
+		if g.Occluded {
+			b.WriteString(" !occ")
+			if g.OccludedBy != "" {
+				b.WriteByte('=')
+				b.WriteString(g.OccludedBy)
+			}
+		}
+		if g.ScrollContainer != "" {
+			b.WriteString(" ^")
+			b.WriteString(g.ScrollContainer)
+		}
+		return
+	}
+	if !g.InViewport {
+		b.WriteString(" [offscreen]")
+	}
+	if g.Occluded {
+		b.WriteString(" [occluded")
+		if g.OccludedBy != "" {
+			b.WriteString(" by ")
+			b.WriteString(g.OccludedBy)
+		}
+		b.WriteByte(']')
+	}
+	if g.ScrollContainer != "" {
+		b.WriteString(" [scroll ")
+		b.WriteString(g.ScrollContainer)
+		b.WriteByte(']')
+	}
+}
+
+// geometrySize is the number of bytes a node's geometry adds to the given
+// output format.
+func geometrySize(g *Geometry, format string) int {
+	if g == nil {
+		return 0
+	}
+	switch format {
+	case "compact", "text":
+		var b strings.Builder
+		writeGeometry(&b, g, format == "compact")
+		return b.Len()
+	default:
+		return 80 + len(g.OccludedBy) + len(g.ScrollContainer)
+	}
+}
+
 func TruncateToTokens(nodes []A11yNode, maxTokens int, format string) ([]A11yNode, bool) {
 	tokensUsed := 0
 	for i, n := range nodes {
 		var nodeTokens int
 		switch format {
 		case "compact":
-			size := len(n.Ref) + 1 + len(n.Role) + len(n.Name) + len(n.Value) + 8
+			size := len(n.Ref) + 1 + len(n.Role) + len(n.Name) + len(n.Value) + 8 + geometrySize(n.Geometry, format)
+			nodeTokens = size / 4
+		case "markdown":
+			size := len(n.Ref) + len(n.Role) + len(n.Name) + len(n.Value) + 10
 			nodeTokens = size / 4
 		case "text":
-			size := n.Depth*2 + len(n.Ref) + 1 + len(n.Role) + len(n.Name) + len(n.Value) + 8
+			size := n.Depth*2 + len(n.Ref) + 1 + len(n.Role) + len(n.Name) + len(n.Value) + 8 + geometrySize(n.Geometry, format)
 			nodeTokens = size / 4
 		default:
-			size := len(n.Ref) + len(n.Role) + len(n.Name) + len(n.Value) + 60
+			size := len(n.Ref) + len(n.Role) + len(n.Name) + len(n.Value) + 60 + geometrySize(n.Geometry, format)
 			nodeTokens = size / 3
 		}
 		if nodeTokens < 1 {
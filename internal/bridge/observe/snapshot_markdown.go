@@ -0,0 +1,251 @@
+package observe
+
+import (
+	"strings"
+)
+
+// markdownLeaves are roles whose accessible name already covers their
+// descendants, so the descendants are not rendered again.
+var markdownLeaves = map[string]bool{
+	"heading": true, "link": true, "button": true, "img": true, "image": true,
+	"checkbox": true, "radio": true, "switch": true, "option": true,
+	"menuitem": true, "menuitemcheckbox": true, "menuitemradio": true,
+	"tab": true, "textbox": true, "searchbox": true, "slider": true,
+	"spinbutton": true, "StaticText": true,
+}
+
+// markdownBlocks are set off from their surroundings by blank lines unless
+// they are nested in a list item or table.
+var markdownBlocks = map[string]bool{
+	"list": true, "table": true, "grid": true, "treegrid": true, "paragraph": true,
+}
+
+var markdownCells = map[string]bool{
+	"cell": true, "gridcell": true, "columnheader": true, "rowheader": true,
+}
+
+type mdFrame struct {
+	depth  int
+	role   string
+	absorb bool
+	bullet bool     // list item whose first line has not been written
+	cells  []string // table row
+	rows   int      // table
+}
+
+type mdWriter struct {
+	out   strings.Builder
+	stack []mdFrame
+	line  strings.Builder
+	owner int // stack index of the frame the pending line belongs to
+	// gap asks for a blank line before the next line.
+	gap bool
+}
+
+// FormatSnapshotMarkdown renders a snapshot as Markdown: headings, nested
+// lists, pipe tables, inline links and one line per form control. Headings
+// and controls carry their ref as a {#e5} suffix, links and images as the
+// link target.
+func FormatSnapshotMarkdown(nodes []A11yNode) string {
+	w := &mdWriter{owner: -1}
+	for _, n := range nodes {
+		w.node(n)
+	}
+	w.popTo(-1)
+	return w.out.String()
+}
+
+func (w *mdWriter) node(n A11yNode) {
+	w.popTo(n.Depth)
+	for _, f := range w.stack {
+		if f.absorb {
+			return
+		}
+	}
+	parent := len(w.stack) - 1
+	frame := mdFrame{depth: n.Depth, role: n.Role, absorb: markdownLeaves[n.Role] && n.Name != ""}
+	name := strings.Join(strings.Fields(n.Name), " ")
+
+	if markdownBlocks[n.Role] {
+		w.flush()
+		w.gap = w.gap || w.find("listitem", "row") == nil
+	}
+	switch {
+	case markdownBlocks[n.Role]:
+	case n.Role == "listitem":
+		w.flush()
+		frame.bullet = true
+	case n.Role == "row":
+		w.flush()
+		frame.cells = []string{}
+	case markdownCells[n.Role]:
+		if row := w.find("row"); row != nil {
+			row.cells = append(row.cells, "")
+		}
+	case n.Role == "heading" && name != "":
+		level := n.Level
+		if level < 1 || level > 6 {
+			level = 2
+		}
+		w.block(strings.Repeat("#", level)+" "+name+refSuffix(n), false, true)
+	case n.Role == "link" && name != "":
+		w.inline(parent, "["+name+"](#"+n.Ref+")")
+	case (n.Role == "img" || n.Role == "image") && name != "":
+		w.inline(parent, "!["+name+"](#"+n.Ref+")")
+	case n.Role == "StaticText":
+		w.inline(parent, name)
+	case n.Checked != "" || n.Role == "checkbox" || n.Role == "radio" || n.Role == "switch":
+		mark := "[ ]"
+		switch n.Checked {
+		case "true":
+			mark = "[x]"
+		case "mixed":
+			mark = "[-]"
+		}
+		w.block(mark+" "+name+controlSuffix(n), true, false)
+	case InteractiveRoles[n.Role]:
+		text := "[" + n.Role + "]"
+		if name != "" {
+			text += " " + name
+		}
+		if n.Value != "" {
+			text += ": " + strings.Join(strings.Fields(n.Value), " ")
+		}
+		w.block(text+controlSuffix(n), false, false)
+	}
+	w.stack = append(w.stack, frame)
+}
+
+// popTo closes every open frame at depth or deeper, writing finished table
+// rows. depth -1 closes everything.
+func (w *mdWriter) popTo(depth int) {
+	for len(w.stack) > 0 {
+		top := len(w.stack) - 1
+		f := w.stack[top]
+		if f.depth < depth {
+			return
+		}
+		if w.owner >= top {
+			w.flush()
+		}
+		w.stack = w.stack[:top]
+		if markdownBlocks[f.role] && w.find("listitem", "row") == nil {
+			w.gap = true
+		}
+		if len(f.cells) > 0 {
+			w.writeLine("| "+strings.Join(f.cells, " | ")+" |", false, false)
+			if table := w.find("table", "grid", "treegrid"); table != nil {
+				if table.rows == 0 {
+					w.writeLine("|"+strings.Repeat(" --- |", len(f.cells)), false, false)
+				}
+				table.rows++
+			}
+		}
+	}
+}
+
+// find returns the innermost open frame with one of the roles.
+func (w *mdWriter) find(roles ...string) *mdFrame {
+	for i := len(w.stack) - 1; i >= 0; i-- {
+		for _, r := range roles {
+			if w.stack[i].role == r {
+				return &w.stack[i]
+			}
+		}
+	}
+	return nil
+}
+
+// inline adds text to the line of the frame at owner, or to the current
+// table cell.
+func (w *mdWriter) inline(owner int, text string) {
+	if text == "" {
+		return
+	}
+	if row := w.find("row"); row != nil && len(row.cells) > 0 {
+		cell := &row.cells[len(row.cells)-1]
+		*cell = strings.TrimSpace(*cell + " " + strings.ReplaceAll(text, "|", `\|`))
+		return
+	}
+	if w.owner != owner {
+		w.flush()
+		w.owner = owner
+	}
+	if w.line.Len() > 0 {
+		w.line.WriteByte(' ')
+	}
+	w.line.WriteString(text)
+}
+
+func (w *mdWriter) block(text string, bullet, heading bool) {
+	if row := w.find("row"); row != nil && len(row.cells) > 0 {
+		w.inline(-1, text)
+		return
+	}
+	w.flush()
+	w.writeLine(text, bullet, heading)
+}
+
+func (w *mdWriter) flush() {
+	if w.line.Len() > 0 {
+		text := w.line.String()
+		w.line.Reset()
+		w.writeLine(text, false, false)
+	}
+	w.owner = -1
+}
+
+// writeLine writes one line, indented and bulleted for the lists it is in.
+// Headings outside lists are followed by a blank line.
+func (w *mdWriter) writeLine(text string, bullet, heading bool) {
+	lists := 0
+	var item *mdFrame
+	for i := range w.stack {
+		switch w.stack[i].role {
+		case "list":
+			lists++
+		case "listitem":
+			item = &w.stack[i]
+		}
+	}
+	indent := strings.Repeat("  ", max(lists-1, 0))
+	prefix := ""
+	switch {
+	case item != nil && item.bullet:
+		prefix = indent + "- "
+		item.bullet = false
+	case item != nil:
+		prefix = indent + "  "
+	case bullet:
+		prefix = indent + "- "
+	}
+	heading = heading && item == nil
+	if (heading || w.gap) && w.out.Len() > 0 {
+		w.out.WriteByte('\n')
+	}
+	w.out.WriteString(prefix)
+	w.out.WriteString(text)
+	w.out.WriteByte('\n')
+	w.gap = heading
+}
+
+func refSuffix(n A11yNode) string {
+	if n.Ref == "" {
+		return ""
+	}
+	return " {#" + n.Ref + "}"
+}
+
+func controlSuffix(n A11yNode) string {
+	var s string
+	if n.Focused {
+		s += " (focused)"
+	}
+	if n.Disabled {
+		s += " (disabled)"
+	}
+	if n.Hidden {
+		s += " (hidden)"
+	}
+	return s + refSuffix(n)
+}
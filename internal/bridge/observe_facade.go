@@ -3,6 +3,7 @@ package bridge
 import (
 	"context"
 
+	"github.com/chromedp/cdproto/network"
 	bridgeobserve "github.com/pinchtab/pinchtab/internal/bridge/observe"
 )
 
@@ -14,6 +15,7 @@ const (
 var InteractiveRoles = bridgeobserve.InteractiveRoles
 
 type A11yNode = bridgeobserve.A11yNode
+type Geometry = bridgeobserve.Geometry
 type RawAXNode = bridgeobserve.RawAXNode
 type RawAXValue = bridgeobserve.RawAXValue
 type RawAXProp = bridgeobserve.RawAXProp
@@ -22,6 +24,8 @@ type rawFrameTree = bridgeobserve.RawFrameTree
 type NetworkEntry = bridgeobserve.NetworkEntry
 type NetworkBuffer = bridgeobserve.NetworkBuffer
 type NetworkFilter = bridgeobserve.NetworkFilter
+type NetworkMessage = bridgeobserve.NetworkMessage
+type NetworkMessageEvent = bridgeobserve.NetworkMessageEvent
 type NetworkMonitor = bridgeobserve.NetworkMonitor
 type MemoryMetrics = bridgeobserve.MemoryMetrics
 
@@ -53,6 +57,10 @@ func FormatSnapshotCompact(nodes []A11yNode) string {
 	return bridgeobserve.FormatSnapshotCompact(nodes)
 }
 
+func FormatSnapshotMarkdown(nodes []A11yNode) string {
+	return bridgeobserve.FormatSnapshotMarkdown(nodes)
+}
+
 func TruncateToTokens(nodes []A11yNode, maxTokens int, format string) ([]A11yNode, bool) {
 	return bridgeobserve.TruncateToTokens(nodes, maxTokens, format)
 }
@@ -69,6 +77,10 @@ func matchStatusRange(status int, pattern string) bool {
 	return bridgeobserve.MatchStatusRange(status, pattern)
 }
 
+func RequestBody(req *network.Request) string {
+	return bridgeobserve.RequestBody(req)
+}
+
 func GetResponseBodyDirect(ctx context.Context, requestID string) (string, bool, error) {
 	return bridgeobserve.GetResponseBodyDirect(ctx, requestID)
 }
@@ -0,0 +1,189 @@
+package bridge
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/chromedp/cdproto/cdp"
+	"github.com/chromedp/cdproto/fetch"
+	"github.com/chromedp/cdproto/target"
+	"github.com/chromedp/chromedp"
+	"github.com/pinchtab/pinchtab/internal/config"
+)
+
+// ProxyManager tracks the upstream proxies of an instance: the one Chrome
+// was launched with, and the ones of tabs opened in their own browser
+// context with a proxy of their own.
+type ProxyManager struct {
+	mu       sync.Mutex
+	instance config.ProxyConfig
+	tabs     map[string]config.ProxyConfig
+}
+
+// NewProxyManager returns a manager for an instance launched with the
+// given resolved proxy.
+func NewProxyManager(instance config.ProxyConfig) *ProxyManager {
+	return &ProxyManager{instance: instance, tabs: make(map[string]config.ProxyConfig)}
+}
+
+// Instance returns the proxy Chrome was launched with.
+func (m *ProxyManager) Instance() config.ProxyConfig {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.instance
+}
+
+// Tab returns the proxy a tab's traffic goes through and whether it is the
+// tab's own rather than the instance's.
+func (m *ProxyManager) Tab(tabID string) (config.ProxyConfig, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if p, ok := m.tabs[tabID]; ok {
+		return p, true
+	}
+	return m.instance, false
+}
+
+// SetTab records a tab's own proxy until the tab context ends.
+func (m *ProxyManager) SetTab(tabCtx context.Context, tabID string, p config.ProxyConfig) {
+	m.mu.Lock()
+	_, tracked := m.tabs[tabID]
+	m.tabs[tabID] = p
+	m.mu.Unlock()
+	if !tracked {
+		context.AfterFunc(tabCtx, func() {
+			m.mu.Lock()
+			defer m.mu.Unlock()
+			delete(m.tabs, tabID)
+		})
+	}
+}
+
+// CreateTabWithProxy opens a tab in a fresh browser context whose traffic
+// goes through proxy. The context has its own cookies and storage, and is
+// disposed of when the tab closes. Tab setup hooks see the tab's proxy, so
+// its credentials are in place before the first request.
+func (b *Bridge) CreateTabWithProxy(url string, proxy config.ProxyConfig) (string, context.Context, context.CancelFunc, error) {
+	if b.TabManager == nil {
+		return "", nil, nil, fmt.Errorf("no browser context available")
+	}
+	server := proxy.Server()
+	if server == "" {
+		return "", nil, nil, fmt.Errorf("proxy url required")
+	}
+
+	var browserContextID cdp.BrowserContextID
+	createCtx, createCancel := context.WithTimeout(b.TabManager.browserCtx, 10*time.Second)
+	err := chromedp.Run(createCtx, chromedp.ActionFunc(func(ctx context.Context) error {
+		p := target.CreateBrowserContext().WithDisposeOnDetach(true).WithProxyServer(server)
+		if proxy.Bypass != "" {
+			p = p.WithProxyBypassList(proxy.Bypass)
+		}
+		var err error
+		browserContextID, err = p.Do(ctx)
+		return err
+	}))
+	createCancel()
+	if err != nil {
+		return "", nil, nil, fmt.Errorf("create browser context: %w", err)
+	}
+
+	tabID, ctx, cancel, err := b.TabManager.createTab(url, browserContextID, func(tabCtx context.Context, tabID string) {
+		if b.proxies != nil {
+			b.proxies.SetTab(tabCtx, tabID, proxy)
+		}
+	})
+	if err != nil {
+		b.disposeBrowserContext(browserContextID)
+		return "", nil, nil, err
+	}
+	context.AfterFunc(ctx, func() { b.disposeBrowserContext(browserContextID) })
+	return tabID, ctx, cancel, nil
+}
+
+func (b *Bridge) disposeBrowserContext(id cdp.BrowserContextID) {
+	c := chromedp.FromContext(b.TabManager.browserCtx)
+	if c == nil || c.Browser == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := target.DisposeBrowserContext(id).Do(cdp.WithExecutor(ctx, c.Browser)); err != nil {
+		slog.Debug("dispose browser context failed", "id", id, "err", err)
+	}
+}
+
+// maxProxyAuthAttempts bounds the requests whose proxy challenge is
+// remembered so a rejected password is not retried forever.
+const maxProxyAuthAttempts = 1024
+
+// ProxyAuth answers an upstream proxy's auth challenges for a tab whose
+// Fetch domain was enabled with auth handling. The zero value leaves every
+// challenge to the browser.
+type ProxyAuth struct {
+	mu       sync.Mutex
+	user     string
+	pass     string
+	set      bool
+	attempts map[fetch.RequestID]struct{}
+}
+
+// NewProxyAuth returns a ProxyAuth answering with the given credentials.
+func NewProxyAuth(username, password string) *ProxyAuth {
+	return &ProxyAuth{user: username, pass: password, set: true}
+}
+
+// Response decides how to answer an auth challenge. Proxy challenges get
+// the credentials once per request; a second challenge for the same
+// request means they were rejected, and retrying would loop. Server
+// challenges are left to the browser.
+func (a *ProxyAuth) Response(requestID fetch.RequestID, source fetch.AuthChallengeSource) *fetch.AuthChallengeResponse {
+	if source != fetch.AuthChallengeSourceProxy {
+		return &fetch.AuthChallengeResponse{Response: fetch.AuthChallengeResponseResponseDefault}
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if !a.set {
+		return &fetch.AuthChallengeResponse{Response: fetch.AuthChallengeResponseResponseDefault}
+	}
+	if _, tried := a.attempts[requestID]; tried {
+		delete(a.attempts, requestID)
+		return &fetch.AuthChallengeResponse{Response: fetch.AuthChallengeResponseResponseCancelAuth}
+	}
+	if a.attempts == nil || len(a.attempts) >= maxProxyAuthAttempts {
+		a.attempts = make(map[fetch.RequestID]struct{})
+	}
+	a.attempts[requestID] = struct{}{}
+	return &fetch.AuthChallengeResponse{
+		Response: fetch.AuthChallengeResponseResponseProvideCredentials,
+		Username: a.user,
+		Password: a.pass,
+	}
+}
+
+// Answer continues the request behind a Fetch.authRequired event with the
+// response it calls for. ctx must carry the tab's chromedp target.
+func (a *ProxyAuth) Answer(ctx context.Context, e *fetch.EventAuthRequired) {
+	c := chromedp.FromContext(ctx)
+	if c == nil || c.Target == nil {
+		return
+	}
+	var source fetch.AuthChallengeSource
+	if e.AuthChallenge != nil {
+		source = e.AuthChallenge.Source
+	}
+	var url string
+	if e.Request != nil {
+		url = e.Request.URL
+	}
+	resp := a.Response(e.RequestID, source)
+	if resp.Response == fetch.AuthChallengeResponseResponseCancelAuth {
+		slog.Warn("proxy rejected credentials", "url", url)
+	}
+	if err := fetch.ContinueWithAuth(e.RequestID, resp).Do(cdp.WithExecutor(ctx, c.Target)); err != nil {
+		slog.Debug("continue with auth failed", "url", url, "err", err)
+	}
+}
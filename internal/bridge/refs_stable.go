@@ -0,0 +1,315 @@
+package bridge
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// maxStaleRefs bounds how many removed refs a tab remembers.
+const maxStaleRefs = 1000
+
+// Refs to elements missing from the latest snapshots are forgotten once
+// they have gone unseen for refGenerations snapshots, or, oldest first, when
+// a tab tracks more than maxTrackedRefs. Forgotten refs answer as stale.
+const (
+	refGenerations = 16
+	maxTrackedRefs = 5000
+)
+
+// StaleRefError is returned when a ref points at an element that has been
+// removed from the page since the ref was handed out.
+type StaleRefError struct {
+	Ref  string
+	Node A11yNode // the element as last seen
+}
+
+func (e *StaleRefError) Error() string {
+	return fmt.Sprintf("ref %s is stale: %s %q was removed from the page", e.Ref, e.Node.Role, e.Node.Name)
+}
+
+// refState is the identity bookkeeping behind stable refs. It lives for as
+// long as the tab's document: each RefCache built from the previous one takes
+// over its state and updates it in place, under mu, since earlier caches may
+// still be answering Stale.
+type refState struct {
+	mu         sync.Mutex
+	next       int
+	byNode     map[int64]string    // backend node ID -> ref
+	byPrint    map[string][]string // fingerprint -> refs, oldest first
+	prints     map[string]string   // ref -> fingerprint
+	last       map[string]A11yNode // ref -> node as last seen
+	seen       map[string]int      // ref -> generation it was last seen in
+	gen        int
+	stale      map[string]A11yNode
+	staleOrder []string
+}
+
+func newRefState() *refState {
+	return &refState{
+		byNode:  make(map[int64]string),
+		byPrint: make(map[string][]string),
+		prints:  make(map[string]string),
+		last:    make(map[string]A11yNode),
+		seen:    make(map[string]int),
+		stale:   make(map[string]A11yNode),
+	}
+}
+
+// NewRefCache builds the ref cache for a new snapshot of a tab, rewriting
+// the Ref of each node in place so that elements keep the refs they had in
+// prev. An element is recognised by its backend node ID, or, when the page
+// re-rendered it as a new DOM node, by its role, name and ancestor roles.
+// Elements seen for the first time get refs that have never been used in
+// this tab.
+//
+// covered reports which elements the snapshot would have included if they
+// still existed; refs to such elements that are missing are marked stale.
+// Pass nil when the snapshot was scoped or depth-limited and absence proves
+// nothing.
+func NewRefCache(prev *RefCache, nodes []A11yNode, covered func(A11yNode) bool) *RefCache {
+	var st *refState
+	var prevRefs map[string]int64
+	switch {
+	case prev == nil:
+		st = newRefState()
+	case prev.state != nil:
+		st = prev.state
+		prevRefs = prev.Refs
+	default:
+		st = seedRefState(prev)
+		prevRefs = prev.Refs
+	}
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	st.gen++
+	prints := fingerprints(nodes)
+	assigned := make([]string, len(nodes))
+	taken := make(map[string]bool, len(nodes))
+	live := make(map[int64]bool, len(nodes))
+	for _, n := range nodes {
+		if n.NodeID != 0 {
+			live[n.NodeID] = true
+		}
+	}
+
+	// Same DOM node: keep its ref.
+	for i, n := range nodes {
+		if n.NodeID == 0 {
+			continue
+		}
+		if ref, ok := st.byNode[n.NodeID]; ok && !taken[ref] {
+			assigned[i], taken[ref] = ref, true
+		}
+	}
+	// Same element re-rendered: reuse the ref of an element with the same
+	// fingerprint whose DOM node is gone.
+	for i := range nodes {
+		if assigned[i] != "" {
+			continue
+		}
+		for _, ref := range st.byPrint[prints[i]] {
+			if taken[ref] {
+				continue
+			}
+			if old, ok := st.last[ref]; ok && old.NodeID != 0 && live[old.NodeID] {
+				continue
+			}
+			assigned[i], taken[ref] = ref, true
+			break
+		}
+	}
+
+	refs := make(map[string]int64, len(nodes))
+	for i := range nodes {
+		ref := assigned[i]
+		if ref == "" {
+			ref = fmt.Sprintf("e%d", st.next)
+			st.next++
+		}
+		n := &nodes[i]
+		if old, ok := st.last[ref]; ok && old.NodeID != 0 && old.NodeID != n.NodeID && st.byNode[old.NodeID] == ref {
+			delete(st.byNode, old.NodeID)
+		}
+		n.Ref = ref
+		if n.NodeID != 0 {
+			refs[ref] = n.NodeID
+			st.byNode[n.NodeID] = ref
+		}
+		if st.prints[ref] != prints[i] {
+			if oldPrint, ok := st.prints[ref]; ok {
+				st.byPrint[oldPrint] = remove(st.byPrint[oldPrint], ref)
+			}
+			st.prints[ref] = prints[i]
+			st.byPrint[prints[i]] = append(st.byPrint[prints[i]], ref)
+		}
+		st.last[ref] = *n
+		st.seen[ref] = st.gen
+		delete(st.stale, ref)
+	}
+	st.forgetUnseen(taken)
+
+	// Refs from earlier snapshots stay resolvable unless this snapshot shows
+	// their element is gone.
+	for ref, nid := range prevRefs {
+		if taken[ref] {
+			continue
+		}
+		old, known := st.last[ref]
+		if !known {
+			continue
+		}
+		if covered != nil && covered(old) {
+			st.markStale(ref, old)
+			if st.byNode[nid] == ref {
+				delete(st.byNode, nid)
+			}
+			continue
+		}
+		if _, ok := refs[ref]; !ok && !live[nid] {
+			refs[ref] = nid
+		}
+	}
+
+	return &RefCache{Refs: refs, Nodes: nodes, state: st}
+}
+
+// seedRefState takes over the refs of a cache that was built without
+// NewRefCache, so that its elements keep their refs too.
+func seedRefState(prev *RefCache) *refState {
+	st := newRefState()
+	prints := fingerprints(prev.Nodes)
+	for i, n := range prev.Nodes {
+		st.last[n.Ref] = n
+		st.seen[n.Ref] = st.gen
+		st.prints[n.Ref] = prints[i]
+		st.byPrint[prints[i]] = append(st.byPrint[prints[i]], n.Ref)
+		st.next = max(st.next, refNumber(n.Ref)+1)
+	}
+	for ref, nid := range prev.Refs {
+		if _, ok := st.byNode[nid]; !ok {
+			st.byNode[nid] = ref
+		}
+		if _, ok := st.last[ref]; !ok {
+			st.last[ref] = A11yNode{Ref: ref, NodeID: nid}
+			st.seen[ref] = st.gen
+		}
+		st.next = max(st.next, refNumber(ref)+1)
+	}
+	return st
+}
+
+// forgetUnseen drops the bookkeeping of refs that have not been seen for
+// refGenerations snapshots, then of the longest unseen ones while the tab
+// tracks more than maxTrackedRefs. Refs in keep were just assigned.
+func (s *refState) forgetUnseen(keep map[string]bool) {
+	var unseen []string
+	for ref := range s.last {
+		if keep[ref] {
+			continue
+		}
+		if s.gen-s.seen[ref] >= refGenerations {
+			s.forget(ref)
+			continue
+		}
+		unseen = append(unseen, ref)
+	}
+	excess := len(s.last) - maxTrackedRefs
+	if excess <= 0 {
+		return
+	}
+	sort.Slice(unseen, func(i, j int) bool { return s.seen[unseen[i]] < s.seen[unseen[j]] })
+	for _, ref := range unseen[:min(excess, len(unseen))] {
+		s.forget(ref)
+	}
+}
+
+// forget drops a ref's bookkeeping, so it is no longer carried into new
+// caches nor matched by fingerprint, and marks it stale.
+func (s *refState) forget(ref string) {
+	n := s.last[ref]
+	if n.NodeID != 0 && s.byNode[n.NodeID] == ref {
+		delete(s.byNode, n.NodeID)
+	}
+	if fp, ok := s.prints[ref]; ok {
+		if refs := remove(s.byPrint[fp], ref); len(refs) > 0 {
+			s.byPrint[fp] = refs
+		} else {
+			delete(s.byPrint, fp)
+		}
+		delete(s.prints, ref)
+	}
+	delete(s.last, ref)
+	delete(s.seen, ref)
+	s.markStale(ref, n)
+}
+
+func refNumber(ref string) int {
+	n, err := strconv.Atoi(strings.TrimPrefix(ref, "e"))
+	if err != nil {
+		return -1
+	}
+	return n
+}
+
+func (s *refState) markStale(ref string, n A11yNode) {
+	if _, ok := s.stale[ref]; !ok {
+		s.staleOrder = append(s.staleOrder, ref)
+	}
+	s.stale[ref] = n
+	for len(s.staleOrder) > maxStaleRefs {
+		oldest := s.staleOrder[0]
+		s.staleOrder = s.staleOrder[1:]
+		delete(s.stale, oldest)
+	}
+}
+
+// Stale reports whether ref belonged to an element that has since been
+// removed, and returns the element as last seen.
+func (c *RefCache) Stale(ref string) (A11yNode, bool) {
+	if c == nil || c.state == nil {
+		return A11yNode{}, false
+	}
+	if _, live := c.Refs[ref]; live {
+		return A11yNode{}, false
+	}
+	c.state.mu.Lock()
+	defer c.state.mu.Unlock()
+	n, ok := c.state.stale[ref]
+	return n, ok
+}
+
+// SnapshotCoverage returns the covered predicate for NewRefCache: which
+// elements a snapshot taken with these options would include. It returns
+// nil for scoped or depth-limited snapshots.
+func SnapshotCoverage(filter string, maxDepth int, scoped bool) func(A11yNode) bool {
+	if scoped || maxDepth >= 0 {
+		return nil
+	}
+	if filter == FilterInteractive {
+		return func(n A11yNode) bool { return InteractiveRoles[n.Role] }
+	}
+	return func(A11yNode) bool { return true }
+}
+
+// fingerprints returns, for each node, its role and name together with the
+// roles of its ancestors.
+func fingerprints(nodes []A11yNode) []string {
+	out := make([]string, len(nodes))
+	for i, n := range nodes {
+		out[i] = n.Role + "\x00" + n.Name + "\x00" + n.Path
+	}
+	return out
+}
+
+func remove(list []string, s string) []string {
+	for i, v := range list {
+		if v == s {
+			return append(list[:i:i], list[i+1:]...)
+		}
+	}
+	return list
+}
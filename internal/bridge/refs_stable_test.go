@@ -0,0 +1,234 @@
+package bridge
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/chromedp/cdproto/cdp"
+	"github.com/chromedp/cdproto/page"
+	"github.com/pinchtab/pinchtab/internal/config"
+	"github.com/pinchtab/pinchtab/internal/selector"
+)
+
+func refsOf(nodes []A11yNode) map[string]string {
+	out := make(map[string]string, len(nodes))
+	for _, n := range nodes {
+		out[n.Name] = n.Ref
+	}
+	return out
+}
+
+func TestNewRefCache_KeepsRefsByNodeID(t *testing.T) {
+	first := NewRefCache(nil, []A11yNode{
+		{Role: "button", Name: "Save", NodeID: 10},
+		{Role: "button", Name: "Cancel", NodeID: 11},
+	}, SnapshotCoverage("", -1, false))
+	before := refsOf(first.Nodes)
+
+	// A new element appears before the existing ones.
+	second := NewRefCache(first, []A11yNode{
+		{Role: "link", Name: "Help", NodeID: 12},
+		{Role: "button", Name: "Save", NodeID: 10},
+		{Role: "button", Name: "Cancel", NodeID: 11},
+	}, SnapshotCoverage("", -1, false))
+	after := refsOf(second.Nodes)
+
+	if after["Save"] != before["Save"] || after["Cancel"] != before["Cancel"] {
+		t.Fatalf("refs changed: before %v, after %v", before, after)
+	}
+	if after["Help"] != "e2" {
+		t.Errorf("new element ref = %q, want e2", after["Help"])
+	}
+	if second.Refs[after["Save"]] != 10 {
+		t.Errorf("Refs[%s] = %d, want 10", after["Save"], second.Refs[after["Save"]])
+	}
+}
+
+func TestNewRefCache_MatchesReRenderedElements(t *testing.T) {
+	first := NewRefCache(nil, []A11yNode{
+		{Role: "textbox", Name: "Email", NodeID: 20, Path: "form"},
+	}, nil)
+	second := NewRefCache(first, []A11yNode{
+		{Role: "textbox", Name: "Email", NodeID: 99, Path: "form"},
+	}, nil)
+	if got := second.Nodes[0].Ref; got != "e0" {
+		t.Fatalf("re-rendered element ref = %q, want e0", got)
+	}
+	if second.Refs["e0"] != 99 {
+		t.Errorf("Refs[e0] = %d, want 99", second.Refs["e0"])
+	}
+
+	// Same role and name elsewhere in the page is a different element.
+	third := NewRefCache(second, []A11yNode{
+		{Role: "textbox", Name: "Email", NodeID: 99, Path: "form"},
+		{Role: "textbox", Name: "Email", NodeID: 100, Path: "dialog form"},
+	}, nil)
+	if got := third.Nodes[1].Ref; got != "e1" {
+		t.Errorf("second textbox ref = %q, want e1", got)
+	}
+}
+
+func TestNewRefCache_StaleRefs(t *testing.T) {
+	first := NewRefCache(nil, []A11yNode{
+		{Role: "button", Name: "Delete", NodeID: 30},
+		{Role: "heading", Name: "Title", NodeID: 31},
+	}, SnapshotCoverage("", -1, false))
+
+	// An interactive snapshot says nothing about the heading, but does show
+	// the button is gone.
+	second := NewRefCache(first, []A11yNode{
+		{Role: "button", Name: "Undo", NodeID: 32},
+	}, SnapshotCoverage(FilterInteractive, -1, false))
+
+	if _, ok := second.Refs["e0"]; ok {
+		t.Error("removed element should not resolve")
+	}
+	if n, ok := second.Stale("e0"); !ok || n.Name != "Delete" {
+		t.Errorf("Stale(e0) = %+v, %v", n, ok)
+	}
+	if second.Refs["e1"] != 31 {
+		t.Errorf("uncovered ref e1 should still resolve, got %d", second.Refs["e1"])
+	}
+	if _, ok := second.Stale("e1"); ok {
+		t.Error("uncovered ref e1 should not be stale")
+	}
+	if second.Nodes[0].Ref != "e2" {
+		t.Errorf("new ref = %q, want e2 (refs are never reused)", second.Nodes[0].Ref)
+	}
+
+	_, err := ResolveUnifiedSelector(t.Context(), selector.Selector{Kind: selector.KindRef, Value: "e0"}, second)
+	var stale *StaleRefError
+	if !errors.As(err, &stale) || stale.Node.Name != "Delete" {
+		t.Errorf("ResolveUnifiedSelector error = %v, want StaleRefError", err)
+	}
+
+	// A scoped snapshot proves nothing about what is missing.
+	third := NewRefCache(second, nil, nil)
+	if third.Refs["e2"] != 32 {
+		t.Errorf("ref e2 should survive a scoped snapshot, got %d", third.Refs["e2"])
+	}
+}
+
+func TestNewRefCache_SeedsFromPlainCache(t *testing.T) {
+	prev := &RefCache{
+		Refs:  map[string]int64{"e0": 40, "e1": 41},
+		Nodes: []A11yNode{{Ref: "e0", Role: "button", Name: "A", NodeID: 40}, {Ref: "e1", Role: "button", Name: "B", NodeID: 41}},
+	}
+	next := NewRefCache(prev, []A11yNode{
+		{Role: "button", Name: "B", NodeID: 41},
+		{Role: "button", Name: "C", NodeID: 42},
+	}, nil)
+	got := refsOf(next.Nodes)
+	if got["B"] != "e1" || got["C"] != "e2" {
+		t.Errorf("refs = %v, want B=e1 C=e2", got)
+	}
+}
+
+func TestNewRefCache_ForgetsLongUnseenRefs(t *testing.T) {
+	cache := NewRefCache(nil, []A11yNode{
+		{Role: "heading", Name: "Title", NodeID: 50},
+		{Role: "button", Name: "Save", NodeID: 51},
+	}, SnapshotCoverage("", -1, false))
+
+	// Interactive snapshots say nothing about the heading, so its ref is
+	// carried forward, but only for so long.
+	for i := 0; i < refGenerations-1; i++ {
+		cache = NewRefCache(cache, []A11yNode{{Role: "button", Name: "Save", NodeID: 51}}, SnapshotCoverage(FilterInteractive, -1, false))
+	}
+	if cache.Refs["e0"] != 50 {
+		t.Fatalf("ref e0 should still resolve, got %d", cache.Refs["e0"])
+	}
+	cache = NewRefCache(cache, []A11yNode{{Role: "button", Name: "Save", NodeID: 51}}, SnapshotCoverage(FilterInteractive, -1, false))
+	if _, ok := cache.Refs["e0"]; ok {
+		t.Error("long unseen ref should no longer resolve")
+	}
+	if n, ok := cache.Stale("e0"); !ok || n.Name != "Title" {
+		t.Errorf("Stale(e0) = %+v, %v", n, ok)
+	}
+	if len(cache.state.last) != 1 || len(cache.state.byPrint) != 1 {
+		t.Errorf("forgotten ref left bookkeeping behind: last=%d byPrint=%d", len(cache.state.last), len(cache.state.byPrint))
+	}
+
+	// A re-rendered heading with the same fingerprint gets a new ref.
+	cache = NewRefCache(cache, []A11yNode{{Role: "heading", Name: "Title", NodeID: 60}}, nil)
+	if got := cache.Nodes[0].Ref; got == "e0" {
+		t.Error("forgotten ref should not be reused")
+	}
+}
+
+func TestNewRefCache_CapsTrackedRefs(t *testing.T) {
+	var cache *RefCache
+	for i := 0; i < 3; i++ {
+		nodes := make([]A11yNode, maxTrackedRefs/2)
+		for j := range nodes {
+			id := int64(i*maxTrackedRefs + j + 1)
+			nodes[j] = A11yNode{Role: "button", Name: fmt.Sprint(id), NodeID: id}
+		}
+		cache = NewRefCache(cache, nodes, nil)
+	}
+	if n := len(cache.state.last); n > maxTrackedRefs {
+		t.Errorf("tracked refs = %d, want at most %d", n, maxTrackedRefs)
+	}
+	if n := len(cache.Refs); n > maxTrackedRefs {
+		t.Errorf("resolvable refs = %d, want at most %d", n, maxTrackedRefs)
+	}
+}
+
+func TestNewRefCache_UpdatesStateInPlace(t *testing.T) {
+	first := NewRefCache(nil, []A11yNode{{Role: "button", Name: "Save", NodeID: 70}}, SnapshotCoverage("", -1, false))
+
+	// Earlier caches keep answering Stale while newer ones are built.
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 100; i++ {
+			first.Stale("e0")
+		}
+	}()
+	cache := first
+	for i := 0; i < 100; i++ {
+		cache = NewRefCache(cache, []A11yNode{{Role: "button", Name: "Save", NodeID: 70}}, SnapshotCoverage("", -1, false))
+	}
+	<-done
+
+	if cache.state != first.state {
+		t.Error("new cache should take over the previous cache's state")
+	}
+	if got := cache.Nodes[0].Ref; got != "e0" {
+		t.Errorf("ref = %q, want e0", got)
+	}
+}
+
+func TestNewRefCache_ResetsOnNewDocument(t *testing.T) {
+	tm := NewTabManager(context.Background(), &config.RuntimeConfig{}, nil, nil, nil)
+	tm.SetRefCache("tab1", NewRefCache(nil, []A11yNode{
+		{Role: "button", Name: "Save", NodeID: 80},
+		{Role: "button", Name: "Cancel", NodeID: 81},
+	}, SnapshotCoverage("", -1, false)))
+
+	// A subframe loading a new document leaves the page's refs alone.
+	tm.frameNavigated("tab1", &page.EventFrameNavigated{Frame: &cdp.Frame{ID: "child", ParentID: "main"}})
+	if tm.GetRefCache("tab1") == nil {
+		t.Fatal("subframe navigation should keep the tab's refs")
+	}
+
+	tm.frameNavigated("tab1", &page.EventFrameNavigated{Frame: &cdp.Frame{ID: "main"}})
+	prev := tm.GetRefCache("tab1")
+	if prev != nil {
+		t.Fatal("top-level navigation should drop the tab's refs")
+	}
+
+	// The new document happens to reuse backend node ID 81.
+	next := NewRefCache(prev, []A11yNode{{Role: "link", Name: "Home", NodeID: 81}}, SnapshotCoverage("", -1, false))
+	if got := next.Nodes[0].Ref; got != "e0" {
+		t.Errorf("ref on new document = %q, want e0", got)
+	}
+	if _, ok := next.Refs["e1"]; ok {
+		t.Error("old document's ref e1 should not resolve")
+	}
+	if _, ok := next.Stale("e1"); ok {
+		t.Error("old document's ref e1 should not be reported stale")
+	}
+}
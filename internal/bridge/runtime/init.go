@@ -92,6 +92,24 @@ func appendExecAllocatorFlag(opts []chromedp.ExecAllocatorOption, flag string) [
 	return append(opts, chromedp.Flag(name, true))
 }
 
+// proxyFlagArgs returns the flags routing the browser through proxy. They
+// come after the extra flags so the first-class setting wins over a raw
+// --proxy-server. Credentials are never passed on the command line; they
+// answer auth challenges through the Fetch domain instead.
+func proxyFlagArgs(proxy config.ProxyConfig) []string {
+	server := proxy.Server()
+	if server == "" {
+		return nil
+	}
+	// Keep WebRTC from sending UDP around the proxy and leaking the real
+	// address.
+	args := []string{"--proxy-server=" + server, "--force-webrtc-ip-handling-policy=disable_non_proxied_udp"}
+	if proxy.Bypass != "" {
+		args = append(args, "--proxy-bypass-list="+proxy.Bypass)
+	}
+	return args
+}
+
 func ensureStealthBundle(cfg *config.RuntimeConfig, bundle *stealth.Bundle) *stealth.Bundle {
 	if bundle != nil {
 		return bundle
@@ -163,6 +181,7 @@ func setupAllocator(cfg *config.RuntimeConfig, bundle *stealth.Bundle, hooks Hoo
 	}
 
 	opts = appendExecAllocatorFlags(opts, config.AllowedChromeExtraFlags(cfg.ChromeExtraFlags))
+	opts = appendExecAllocatorFlags(opts, proxyFlagArgs(cfg.Proxy))
 	for _, flag := range appendChromeCompatibilityFlags(nil) {
 		opts = appendExecAllocatorFlag(opts, flag)
 	}
@@ -429,6 +448,7 @@ func buildChromeArgsWithBundle(cfg *config.RuntimeConfig, bundle *stealth.Bundle
 	}
 
 	args = append(args, config.AllowedChromeExtraFlags(cfg.ChromeExtraFlags)...)
+	args = append(args, proxyFlagArgs(cfg.Proxy)...)
 
 	return appendChromeCompatibilityFlags(args)
 }
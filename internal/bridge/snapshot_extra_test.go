@@ -91,6 +91,47 @@ func TestTruncateToTokens_Formats(t *testing.T) {
 	}
 }
 
+func TestFormatSnapshot_Geometry(t *testing.T) {
+	nodes := []A11yNode{
+		{Ref: "e0", Role: "button", Name: "Save", Geometry: &Geometry{X: 10, Y: 20, Width: 80, Height: 24, InViewport: true}},
+		{Ref: "e1", Role: "button", Name: "Save", Geometry: &Geometry{X: 10, Y: 900, Width: 80, Height: 24, ScrollContainer: "e3"}},
+		{Ref: "e2", Role: "link", Name: "Help", Geometry: &Geometry{X: 0, Y: 0, Width: 40, Height: 16, InViewport: true, Occluded: true, OccludedBy: "div.modal"}},
+	}
+
+	compact := FormatSnapshotCompact(nodes)
+	want := "e0:button \"Save\" @10,20 80x24\n" +
+		"e1:button \"Save\" @10,900 80x24 !off ^e3\n" +
+		"e2:link \"Help\" @0,0 40x16 !occ=div.modal\n"
+	if compact != want {
+		t.Errorf("compact got:\n%s\nwant:\n%s", compact, want)
+	}
+
+	text := FormatSnapshotText(nodes)
+	if !contains(text, "e1 button \"Save\" @10,900 80x24 [offscreen] [scroll e3]\n") {
+		t.Errorf("expected offscreen and scroll markers, got:\n%s", text)
+	}
+	if !contains(text, "e2 link \"Help\" @0,0 40x16 [occluded by div.modal]\n") {
+		t.Errorf("expected occluded marker, got:\n%s", text)
+	}
+}
+
+func TestTruncateToTokens_CountsGeometry(t *testing.T) {
+	plain := make([]A11yNode, 50)
+	withBoxes := make([]A11yNode, 50)
+	for i := range plain {
+		plain[i] = A11yNode{Ref: "e0", Role: "button", Name: "Click me"}
+		withBoxes[i] = plain[i]
+		withBoxes[i].Geometry = &Geometry{X: 100, Y: 200, Width: 80, Height: 24, InViewport: true}
+	}
+	for _, format := range []string{"compact", "text", "json"} {
+		a, _ := TruncateToTokens(plain, 100, format)
+		b, _ := TruncateToTokens(withBoxes, 100, format)
+		if len(b) >= len(a) {
+			t.Errorf("%s: geometry should use up the budget sooner: plain=%d geometry=%d", format, len(a), len(b))
+		}
+	}
+}
+
 func TestTruncateToTokens_Empty(t *testing.T) {
 	result, truncated := TruncateToTokens(nil, 100, "compact")
 	if truncated {
@@ -263,6 +263,58 @@ func TestFormatSnapshotText(t *testing.T) {
 	}
 }
 
+func TestFormatSnapshotMarkdown(t *testing.T) {
+	nodes := []A11yNode{
+		{Ref: "e0", Role: "RootWebArea", Name: "Shop", Depth: 0},
+		{Ref: "e1", Role: "heading", Name: "Cart", Level: 1, Depth: 1},
+		{Ref: "e2", Role: "StaticText", Name: "Cart", Depth: 2},
+		{Ref: "e3", Role: "paragraph", Depth: 1},
+		{Ref: "e4", Role: "StaticText", Name: "Read the", Depth: 2},
+		{Ref: "e5", Role: "link", Name: "returns policy", Depth: 2},
+		{Ref: "e6", Role: "StaticText", Name: "returns policy", Depth: 3},
+		{Ref: "e7", Role: "StaticText", Name: "first.", Depth: 2},
+		{Ref: "e8", Role: "list", Depth: 1},
+		{Ref: "e9", Role: "listitem", Depth: 2},
+		{Ref: "e10", Role: "StaticText", Name: "Socks", Depth: 3},
+		{Ref: "e11", Role: "list", Depth: 3},
+		{Ref: "e12", Role: "listitem", Depth: 4},
+		{Ref: "e13", Role: "link", Name: "Size guide", Depth: 5},
+		{Ref: "e14", Role: "table", Depth: 1},
+		{Ref: "e15", Role: "row", Depth: 2},
+		{Ref: "e16", Role: "columnheader", Name: "Item", Depth: 3},
+		{Ref: "e17", Role: "StaticText", Name: "Item", Depth: 4},
+		{Ref: "e18", Role: "columnheader", Name: "Price", Depth: 3},
+		{Ref: "e19", Role: "StaticText", Name: "Price", Depth: 4},
+		{Ref: "e20", Role: "row", Depth: 2},
+		{Ref: "e21", Role: "cell", Depth: 3},
+		{Ref: "e22", Role: "StaticText", Name: "Socks", Depth: 4},
+		{Ref: "e23", Role: "cell", Depth: 3},
+		{Ref: "e24", Role: "StaticText", Name: "$5", Depth: 4},
+		{Ref: "e25", Role: "textbox", Name: "Coupon", Value: "SAVE10", Focused: true, Depth: 1},
+		{Ref: "e26", Role: "checkbox", Name: "Gift wrap", Checked: "true", Depth: 1},
+		{Ref: "e27", Role: "button", Name: "Checkout", Disabled: true, Depth: 1},
+	}
+
+	want := `# Cart {#e1}
+
+Read the [returns policy](#e5) first.
+
+- Socks
+  - [Size guide](#e13)
+
+| Item | Price |
+| --- | --- |
+| Socks | $5 |
+
+[textbox] Coupon: SAVE10 (focused) {#e25}
+- [x] Gift wrap {#e26}
+[button] Checkout (disabled) {#e27}
+`
+	if got := FormatSnapshotMarkdown(nodes); got != want {
+		t.Errorf("markdown:\n%s\nwant:\n%s", got, want)
+	}
+}
+
 func TestDiffSnapshot(t *testing.T) {
 	prev := []A11yNode{
 		{Ref: "e0", Role: "button", Name: "Submit", NodeID: 10},
@@ -279,6 +279,12 @@ func (tm *TabManager) closeLRUTab() error {
 }
 
 func (tm *TabManager) CreateTab(url string) (string, context.Context, context.CancelFunc, error) {
+	return tm.createTab(url, "", nil)
+}
+
+// createTab opens a tab, in browserContextID when it is set. onCreated, when
+// set, sees the tab before the setup hooks run.
+func (tm *TabManager) createTab(url string, browserContextID cdp.BrowserContextID, onCreated func(ctx context.Context, tabID string)) (string, context.Context, context.CancelFunc, error) {
 	if tm.browserCtx == nil {
 		return "", nil, nil, fmt.Errorf("no browser context available")
 	}
@@ -313,8 +319,12 @@ func (tm *TabManager) CreateTab(url string) (string, context.Context, context.Ca
 	createCtx, createCancel := context.WithTimeout(tm.browserCtx, 10*time.Second)
 	if err := chromedp.Run(createCtx,
 		chromedp.ActionFunc(func(ctx context.Context) error {
+			p := target.CreateTarget("about:blank")
+			if browserContextID != "" {
+				p = p.WithBrowserContextID(browserContextID)
+			}
 			var err error
-			targetID, err = target.CreateTarget("about:blank").Do(ctx)
+			targetID, err = p.Do(ctx)
 			return err
 		}),
 	); err != nil {
@@ -328,6 +338,12 @@ func (tm *TabManager) CreateTab(url string) (string, context.Context, context.Ca
 		chromedp.WithTargetID(targetID),
 	)
 
+	rawCDPID := string(targetID)
+	tabID := tm.idMgr.TabIDFromCDPTarget(rawCDPID)
+
+	if onCreated != nil {
+		onCreated(ctx, tabID)
+	}
 	if tm.onTabSetup != nil {
 		tm.onTabSetup(ctx)
 	}
@@ -348,9 +364,6 @@ func (tm *TabManager) CreateTab(url string) (string, context.Context, context.Ca
 		_ = SetResourceBlocking(ctx, blockPatterns)
 	}
 
-	rawCDPID := string(targetID)
-	tabID := tm.idMgr.TabIDFromCDPTarget(rawCDPID)
-
 	// Start network capture before navigation so CDP events are captured.
 	if tm.netMonitor != nil {
 		if err := tm.netMonitor.StartCapture(ctx, tabID); err != nil {
@@ -395,6 +408,7 @@ func (tm *TabManager) CreateTab(url string) (string, context.Context, context.Ca
 	tm.mu.Unlock()
 
 	tm.startTabPolicyWatcher(tabID, ctx)
+	tm.watchDocument(tabID, ctx)
 
 	return tabID, ctx, cancel, nil
 }
@@ -551,6 +565,27 @@ func (tm *TabManager) DeleteRefCache(tabID string) {
 	delete(tm.snapshots, tabID)
 }
 
+// watchDocument drops a tab's refs whenever its top frame loads a new
+// document: backend node IDs are only meaningful within one document, so a
+// new page must not inherit the refs of the old one.
+func (tm *TabManager) watchDocument(tabID string, ctx context.Context) {
+	if ctx == nil || chromedp.FromContext(ctx) == nil {
+		return
+	}
+	chromedp.ListenTarget(ctx, func(ev any) {
+		if e, ok := ev.(*page.EventFrameNavigated); ok {
+			tm.frameNavigated(tabID, e)
+		}
+	})
+}
+
+func (tm *TabManager) frameNavigated(tabID string, e *page.EventFrameNavigated) {
+	if e.Frame == nil || e.Frame.ParentID != "" {
+		return
+	}
+	tm.DeleteRefCache(tabID)
+}
+
 func (tm *TabManager) RegisterTab(tabID string, ctx context.Context) {
 	now := time.Now()
 	tm.mu.Lock()
@@ -559,6 +594,7 @@ func (tm *TabManager) RegisterTab(tabID string, ctx context.Context) {
 	tm.mu.Unlock()
 
 	tm.startTabPolicyWatcher(tabID, ctx)
+	tm.watchDocument(tabID, ctx)
 }
 
 // RegisterTabWithCancel registers a tab ID with its context and cancel function.
@@ -570,6 +606,7 @@ func (tm *TabManager) RegisterTabWithCancel(tabID, rawCDPID string, ctx context.
 	tm.mu.Unlock()
 
 	tm.startTabPolicyWatcher(tabID, ctx)
+	tm.watchDocument(tabID, ctx)
 }
 
 // Execute runs a task for a tab through the TabExecutor, ensuring per-tab
@@ -22,6 +22,13 @@ func InstanceStart(client *http.Client, base, token string, cmd *cobra.Command)
 	if exts, _ := cmd.Flags().GetStringArray("extension"); len(exts) > 0 {
 		body["extensionPaths"] = exts
 	}
+	network, _ := cmd.Flags().GetString("network")
+	if nc := NetworkConditionsFromFlags(cmd, network); nc != nil {
+		body["network"] = nc
+	}
+	if p := ProxyFromFlags(cmd); p != nil {
+		body["proxy"] = p
+	}
 	apiclient.DoPost(client, base, token, "/instances/start", body)
 }
 
@@ -48,6 +48,12 @@ func Network(client *http.Client, base, token string, cmd *cobra.Command, args [
 	if v, _ := cmd.Flags().GetString("type"); v != "" {
 		params.Set("type", v)
 	}
+	if v, _ := cmd.Flags().GetString("message"); v != "" {
+		params.Set("message", v)
+	}
+	if v, _ := cmd.Flags().GetString("direction"); v != "" {
+		params.Set("direction", v)
+	}
 	if v, _ := cmd.Flags().GetString("limit"); v != "" {
 		params.Set("limit", v)
 	}
@@ -100,11 +106,23 @@ func NetworkStream(client *http.Client, base, token string, cmd *cobra.Command)
 	if v, _ := cmd.Flags().GetString("type"); v != "" {
 		params.Set("type", v)
 	}
+	if v, _ := cmd.Flags().GetString("message"); v != "" {
+		params.Set("message", v)
+	}
+	if v, _ := cmd.Flags().GetString("direction"); v != "" {
+		params.Set("direction", v)
+	}
 	if v, _ := cmd.Flags().GetString("buffer-size"); v != "" {
 		params.Set("bufferSize", v)
 	}
 
-	u := base + "/network/stream"
+	streamSSE(base, token, "/network/stream", params)
+}
+
+// streamSSE connects to a Server-Sent Events endpoint and prints each event's
+// data line as it arrives.
+func streamSSE(base, token, path string, params url.Values) {
+	u := base + path
 	if len(params) > 0 {
 		u += "?" + params.Encode()
 	}
@@ -134,6 +152,8 @@ func NetworkStream(client *http.Client, base, token string, cmd *cobra.Command)
 	}
 
 	scanner := bufio.NewScanner(resp.Body)
+	// Snapshot events carry a whole tree on one data line.
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
 	for scanner.Scan() {
 		line := scanner.Text()
 		if strings.HasPrefix(line, "data: ") {
@@ -0,0 +1,55 @@
+package actions
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/pinchtab/pinchtab/internal/cli"
+	"github.com/pinchtab/pinchtab/internal/cli/apiclient"
+	"github.com/spf13/cobra"
+)
+
+// ProxyFromFlags reads the --proxy and --proxy-bypass flags into a request
+// body. It returns nil when --proxy is not set.
+func ProxyFromFlags(cmd *cobra.Command) map[string]any {
+	if !cmd.Flags().Changed("proxy") {
+		return nil
+	}
+	proxyURL, _ := cmd.Flags().GetString("proxy")
+	body := map[string]any{"url": proxyURL}
+	if bypass, _ := cmd.Flags().GetString("proxy-bypass"); bypass != "" {
+		body["bypass"] = bypass
+	}
+	return body
+}
+
+// Proxy shows a tab's upstream proxy, or with "check" health-checks it, or
+// the proxy given with --proxy.
+func Proxy(client *http.Client, base, token string, cmd *cobra.Command, args []string) {
+	tabID, _ := cmd.Flags().GetString("tab")
+	path := "/network/proxy"
+	if tabID != "" {
+		path = fmt.Sprintf("/tabs/%s/network/proxy", url.PathEscape(tabID))
+	}
+
+	if len(args) == 0 {
+		apiclient.DoGet(client, base, token, path, nil)
+		return
+	}
+	if args[0] != "check" {
+		cli.Fatal("Usage: pinchtab proxy [check]")
+	}
+
+	body := map[string]any{}
+	if p := ProxyFromFlags(cmd); p != nil {
+		body["proxy"] = p
+	}
+	if v, _ := cmd.Flags().GetString("target"); v != "" {
+		body["target"] = v
+	}
+	if v, _ := cmd.Flags().GetInt("timeout"); v > 0 {
+		body["timeoutMs"] = v
+	}
+	apiclient.DoPost(client, base, token, path+"/check", body)
+}
@@ -0,0 +1,66 @@
+package actions
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+func newProxyCmd() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Flags().String("tab", "", "")
+	cmd.Flags().String("proxy", "", "")
+	cmd.Flags().String("proxy-bypass", "", "")
+	cmd.Flags().String("target", "", "")
+	cmd.Flags().Int("timeout", 0, "")
+	return cmd
+}
+
+func TestProxyShow(t *testing.T) {
+	m := newMockServer()
+	defer m.close()
+
+	cmd := newProxyCmd()
+	_ = cmd.Flags().Set("tab", "tab-abc")
+	Proxy(m.server.Client(), m.base(), "", cmd, nil)
+	if m.lastMethod != "GET" || m.lastPath != "/tabs/tab-abc/network/proxy" {
+		t.Errorf("expected GET /tabs/tab-abc/network/proxy, got %s %s", m.lastMethod, m.lastPath)
+	}
+}
+
+func TestProxyCheckGivenProxy(t *testing.T) {
+	m := newMockServer()
+	defer m.close()
+
+	cmd := newProxyCmd()
+	_ = cmd.Flags().Set("proxy", "http://u:p@proxy.example:3128")
+	_ = cmd.Flags().Set("target", "https://example.com")
+	_ = cmd.Flags().Set("timeout", "5000")
+	Proxy(m.server.Client(), m.base(), "", cmd, []string{"check"})
+	if m.lastMethod != "POST" || m.lastPath != "/network/proxy/check" {
+		t.Fatalf("expected POST /network/proxy/check, got %s %s", m.lastMethod, m.lastPath)
+	}
+	var body struct {
+		Proxy     map[string]any `json:"proxy"`
+		Target    string         `json:"target"`
+		TimeoutMs int            `json:"timeoutMs"`
+	}
+	_ = json.Unmarshal([]byte(m.lastBody), &body)
+	if body.Proxy["url"] != "http://u:p@proxy.example:3128" || body.Target != "https://example.com" || body.TimeoutMs != 5000 {
+		t.Errorf("unexpected body %s", m.lastBody)
+	}
+}
+
+func TestProxyFromFlags(t *testing.T) {
+	cmd := newProxyCmd()
+	if ProxyFromFlags(cmd) != nil {
+		t.Error("expected nil without --proxy")
+	}
+	_ = cmd.Flags().Set("proxy", "socks5://proxy:1080")
+	_ = cmd.Flags().Set("proxy-bypass", "localhost")
+	p := ProxyFromFlags(cmd)
+	if p["url"] != "socks5://proxy:1080" || p["bypass"] != "localhost" {
+		t.Errorf("unexpected proxy %v", p)
+	}
+}
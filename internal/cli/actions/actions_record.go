@@ -0,0 +1,76 @@
+package actions
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+
+	"github.com/pinchtab/pinchtab/internal/cli"
+	"github.com/pinchtab/pinchtab/internal/cli/apiclient"
+	"github.com/spf13/cobra"
+)
+
+// recordPath returns the recording endpoint, tab-scoped when --tab is set.
+func recordPath(cmd *cobra.Command, suffix string) string {
+	if tabID, _ := cmd.Flags().GetString("tab"); tabID != "" {
+		return "/tabs/" + url.PathEscape(tabID) + "/record" + suffix
+	}
+	return "/record" + suffix
+}
+
+// RecordStart starts recording the actions sent for a tab.
+func RecordStart(client *http.Client, base, token string, cmd *cobra.Command) {
+	apiclient.DoPost(client, base, token, recordPath(cmd, "/start"), map[string]any{})
+}
+
+// RecordStop stops a tab's recording and prints the script, saving it to
+// --output when set.
+func RecordStop(client *http.Client, base, token string, cmd *cobra.Command) {
+	script := apiclient.DoPost(client, base, token, recordPath(cmd, "/stop"), map[string]any{})
+	saveScript(cmd, script)
+}
+
+// RecordShow prints a tab's recording so far without stopping it.
+func RecordShow(client *http.Client, base, token string, cmd *cobra.Command) {
+	script := apiclient.DoGet(client, base, token, recordPath(cmd, ""), nil)
+	saveScript(cmd, script)
+}
+
+func saveScript(cmd *cobra.Command, script map[string]any) {
+	outFile, _ := cmd.Flags().GetString("output")
+	if outFile == "" || script == nil {
+		return
+	}
+	data, err := json.MarshalIndent(script, "", "  ")
+	if err != nil {
+		cli.Fatal("Encode failed: %v", err)
+	}
+	if err := os.WriteFile(outFile, append(data, '\n'), 0600); err != nil {
+		cli.Fatal("Write failed: %v", err)
+	}
+	fmt.Fprintln(os.Stderr, cli.StyleStderr(cli.SuccessStyle, fmt.Sprintf("Saved %s", outFile)))
+}
+
+// Replay replays a recorded script file against a tab.
+func Replay(client *http.Client, base, token, file string, cmd *cobra.Command) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		cli.Fatal("Read failed: %v", err)
+	}
+	var script map[string]any
+	if err := json.Unmarshal(data, &script); err != nil {
+		cli.Fatal("Invalid script %s: %v", file, err)
+	}
+
+	body := map[string]any{"script": script}
+	if v, _ := cmd.Flags().GetBool("continue-on-error"); v {
+		body["continueOnError"] = true
+	}
+	path := "/replay"
+	if tabID, _ := cmd.Flags().GetString("tab"); tabID != "" {
+		path = "/tabs/" + url.PathEscape(tabID) + "/replay"
+	}
+	apiclient.DoPost(client, base, token, path, body)
+}
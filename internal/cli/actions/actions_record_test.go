@@ -0,0 +1,58 @@
+package actions
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+func newRecordCmd() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Flags().String("tab", "", "")
+	cmd.Flags().String("output", "", "")
+	cmd.Flags().Bool("continue-on-error", false, "")
+	return cmd
+}
+
+func TestRecordStopSavesScript(t *testing.T) {
+	m := newMockServer()
+	m.response = `{"version":1,"steps":[{"type":"navigate","url":"https://pinchtab.com"}]}`
+	defer m.close()
+
+	out := filepath.Join(t.TempDir(), "script.json")
+	cmd := newRecordCmd()
+	_ = cmd.Flags().Set("tab", "TAB1")
+	_ = cmd.Flags().Set("output", out)
+	RecordStop(m.server.Client(), m.base(), "", cmd)
+
+	if m.lastPath != "/tabs/TAB1/record/stop" {
+		t.Errorf("expected /tabs/TAB1/record/stop, got %s", m.lastPath)
+	}
+	data, err := os.ReadFile(out)
+	if err != nil || !strings.Contains(string(data), `"https://pinchtab.com"`) {
+		t.Errorf("expected the script to be saved, got %q (%v)", data, err)
+	}
+}
+
+func TestReplaySendsScript(t *testing.T) {
+	m := newMockServer()
+	defer m.close()
+
+	file := filepath.Join(t.TempDir(), "script.json")
+	if err := os.WriteFile(file, []byte(`{"version":1,"steps":[{"type":"wait","wait":{"ms":100}}]}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	cmd := newRecordCmd()
+	_ = cmd.Flags().Set("continue-on-error", "true")
+	Replay(m.server.Client(), m.base(), "", file, cmd)
+
+	if m.lastPath != "/replay" {
+		t.Errorf("expected /replay, got %s", m.lastPath)
+	}
+	if !strings.Contains(m.lastBody, `"script":{`) || !strings.Contains(m.lastBody, `"continueOnError":true`) {
+		t.Errorf("unexpected body: %s", m.lastBody)
+	}
+}
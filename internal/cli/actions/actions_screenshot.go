@@ -1,6 +1,9 @@
 package actions
 
 import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
 	"fmt"
 	"github.com/pinchtab/pinchtab/internal/cli"
 	"github.com/pinchtab/pinchtab/internal/cli/apiclient"
@@ -27,12 +30,39 @@ func Screenshot(client *http.Client, base, token string, cmd *cobra.Command) {
 		outFile = fmt.Sprintf("screenshot-%s.jpg", time.Now().Format("20060102-150405"))
 	}
 
+	marks, _ := cmd.Flags().GetBool("marks")
+	if marks {
+		params.Del("raw")
+		params.Set("marks", "true")
+	}
+
 	data := apiclient.DoGetRaw(client, base, token, "/screenshot", params)
 	if data == nil {
 		return
 	}
+	var marksJSON []byte
+	if marks {
+		var resp struct {
+			Base64 string          `json:"base64"`
+			Marks  json.RawMessage `json:"marks"`
+		}
+		if err := json.Unmarshal(data, &resp); err != nil {
+			cli.Fatal("Decode failed: %v", err)
+		}
+		img, err := base64.StdEncoding.DecodeString(resp.Base64)
+		if err != nil {
+			cli.Fatal("Decode failed: %v", err)
+		}
+		data, marksJSON = img, resp.Marks
+	}
 	if err := os.WriteFile(outFile, data, 0600); err != nil {
 		cli.Fatal("Write failed: %v", err)
 	}
 	fmt.Println(cli.StyleStdout(cli.SuccessStyle, fmt.Sprintf("Saved %s (%d bytes)", outFile, len(data))))
+	if marksJSON != nil {
+		var buf bytes.Buffer
+		if json.Indent(&buf, marksJSON, "", "  ") == nil {
+			fmt.Println(buf.String())
+		}
+	}
 }
@@ -35,3 +35,24 @@ func TestScreenshot(t *testing.T) {
 		t.Errorf("unexpected content: %s", string(data))
 	}
 }
+
+func TestScreenshotMarks(t *testing.T) {
+	m := newMockServer()
+	m.response = `{"format":"jpeg","base64":"RkFLRQ==","marks":{"e1":{"role":"button","box":{"x":10,"y":20,"width":30,"height":40}}}}`
+	defer m.close()
+
+	outFile := filepath.Join(t.TempDir(), "marks.jpg")
+	cmd := &cobra.Command{}
+	cmd.Flags().String("output", outFile, "")
+	cmd.Flags().String("quality", "", "")
+	cmd.Flags().String("tab", "", "")
+	cmd.Flags().Bool("marks", true, "")
+	Screenshot(m.server.Client(), m.base(), "", cmd)
+
+	if !strings.Contains(m.lastQuery, "marks=true") || strings.Contains(m.lastQuery, "raw=true") {
+		t.Errorf("expected a JSON marks request, got %s", m.lastQuery)
+	}
+	if data, err := os.ReadFile(outFile); err != nil || string(data) != "FAKE" {
+		t.Errorf("expected the decoded image to be saved, got %q (%v)", data, err)
+	}
+}
@@ -8,10 +8,17 @@ import (
 )
 
 func Snapshot(client *http.Client, base, token string, cmd *cobra.Command) {
+	if v, _ := cmd.Flags().GetBool("watch"); v {
+		SnapshotWatch(base, token, cmd)
+		return
+	}
 	params := url.Values{}
 	if v, _ := cmd.Flags().GetBool("interactive"); v {
 		params.Set("filter", "interactive")
 	}
+	if v, _ := cmd.Flags().GetString("format"); v != "" {
+		params.Set("format", v)
+	}
 	if v, _ := cmd.Flags().GetBool("compact"); v {
 		params.Set("format", "compact")
 	}
@@ -24,15 +31,41 @@ func Snapshot(client *http.Client, base, token string, cmd *cobra.Command) {
 	if v, _ := cmd.Flags().GetString("selector"); v != "" {
 		params.Set("selector", v)
 	}
+	regions, _ := cmd.Flags().GetStringArray("region")
+	for _, v := range regions {
+		params.Add("region", v)
+	}
 	if v, _ := cmd.Flags().GetString("max-tokens"); v != "" {
 		params.Set("maxTokens", v)
 	}
 	if v, _ := cmd.Flags().GetString("depth"); v != "" {
 		params.Set("depth", v)
 	}
+	if v, _ := cmd.Flags().GetBool("geometry"); v {
+		params.Set("geometry", "true")
+	}
 	if v, _ := cmd.Flags().GetString("tab"); v != "" {
 		params.Set("tabId", v)
 	}
 	result := apiclient.DoGet(client, base, token, "/snapshot", params)
 	apiclient.SuggestNextAction("snapshot", result)
 }
+
+// SnapshotWatch prints a full snapshot, then one delta per line each time the
+// page changes.
+func SnapshotWatch(base, token string, cmd *cobra.Command) {
+	params := url.Values{}
+	if v, _ := cmd.Flags().GetBool("interactive"); v {
+		params.Set("filter", "interactive")
+	}
+	if v, _ := cmd.Flags().GetString("depth"); v != "" {
+		params.Set("depth", v)
+	}
+	if v, _ := cmd.Flags().GetString("debounce"); v != "" {
+		params.Set("debounce", v)
+	}
+	if v, _ := cmd.Flags().GetString("tab"); v != "" {
+		params.Set("tabId", v)
+	}
+	streamSSE(base, token, "/snapshot/stream", params)
+}
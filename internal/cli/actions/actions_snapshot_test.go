@@ -12,10 +12,15 @@ func newSnapshotCmd() *cobra.Command {
 	cmd.Flags().Bool("interactive", false, "")
 	cmd.Flags().Bool("compact", false, "")
 	cmd.Flags().Bool("text", false, "")
+	cmd.Flags().String("format", "", "")
 	cmd.Flags().Bool("diff", false, "")
 	cmd.Flags().String("selector", "", "")
+	cmd.Flags().StringArray("region", nil, "")
 	cmd.Flags().String("max-tokens", "", "")
 	cmd.Flags().String("depth", "", "")
+	cmd.Flags().Bool("geometry", false, "")
+	cmd.Flags().Bool("watch", false, "")
+	cmd.Flags().String("debounce", "", "")
 	cmd.Flags().String("tab", "", "")
 	return cmd
 }
@@ -44,6 +49,34 @@ func TestSnapshot(t *testing.T) {
 	}
 }
 
+func TestSnapshotFormat(t *testing.T) {
+	m := newMockServer()
+	defer m.close()
+	client := m.server.Client()
+
+	cmd := newSnapshotCmd()
+	_ = cmd.Flags().Set("format", "markdown")
+	Snapshot(client, m.base(), "", cmd)
+	if !strings.Contains(m.lastQuery, "format=markdown") {
+		t.Errorf("expected format=markdown in query, got %s", m.lastQuery)
+	}
+}
+
+func TestSnapshotRegions(t *testing.T) {
+	m := newMockServer()
+	defer m.close()
+	client := m.server.Client()
+
+	cmd := newSnapshotCmd()
+	_ = cmd.Flags().Set("region", "main")
+	_ = cmd.Flags().Set("region", "#errors")
+	_ = cmd.Flags().Set("max-tokens", "500")
+	Snapshot(client, m.base(), "", cmd)
+	if !strings.Contains(m.lastQuery, "region=main&region=%23errors") {
+		t.Errorf("expected both regions in query, got %s", m.lastQuery)
+	}
+}
+
 func TestSnapshotDiff(t *testing.T) {
 	m := newMockServer()
 	defer m.close()
@@ -54,6 +87,7 @@ func TestSnapshotDiff(t *testing.T) {
 	_ = cmd.Flags().Set("selector", "main")
 	_ = cmd.Flags().Set("max-tokens", "2000")
 	_ = cmd.Flags().Set("depth", "5")
+	_ = cmd.Flags().Set("geometry", "true")
 	Snapshot(client, m.base(), "", cmd)
 	if !strings.Contains(m.lastQuery, "diff=true") {
 		t.Errorf("expected diff=true, got %s", m.lastQuery)
@@ -67,6 +101,9 @@ func TestSnapshotDiff(t *testing.T) {
 	if !strings.Contains(m.lastQuery, "depth=5") {
 		t.Errorf("expected depth=5, got %s", m.lastQuery)
 	}
+	if !strings.Contains(m.lastQuery, "geometry=true") {
+		t.Errorf("expected geometry=true, got %s", m.lastQuery)
+	}
 }
 
 func TestSnapshotTabId(t *testing.T) {
@@ -81,3 +118,22 @@ func TestSnapshotTabId(t *testing.T) {
 		t.Errorf("expected tabId=ABC123, got %s", m.lastQuery)
 	}
 }
+
+func TestSnapshotWatch(t *testing.T) {
+	m := newMockServer()
+	m.response = "event: snapshot\ndata: {\"seq\":0}\n\n"
+	defer m.close()
+	client := m.server.Client()
+
+	cmd := newSnapshotCmd()
+	_ = cmd.Flags().Set("watch", "true")
+	_ = cmd.Flags().Set("interactive", "true")
+	_ = cmd.Flags().Set("debounce", "500")
+	Snapshot(client, m.base(), "", cmd)
+	if m.lastPath != "/snapshot/stream" {
+		t.Errorf("expected /snapshot/stream, got %s", m.lastPath)
+	}
+	if !strings.Contains(m.lastQuery, "filter=interactive") || !strings.Contains(m.lastQuery, "debounce=500") {
+		t.Errorf("unexpected query: %s", m.lastQuery)
+	}
+}
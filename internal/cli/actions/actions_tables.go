@@ -0,0 +1,54 @@
+package actions
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+
+	"github.com/pinchtab/pinchtab/internal/cli"
+	"github.com/pinchtab/pinchtab/internal/cli/apiclient"
+	"github.com/spf13/cobra"
+)
+
+// Tables prints the tables found on the page, or in the region named by
+// args[0] (a ref or selector), as JSON or CSV. --output saves the response
+// instead of printing it.
+func Tables(client *http.Client, base, token string, args []string, cmd *cobra.Command) {
+	params := url.Values{}
+	if len(args) > 0 && args[0] != "" {
+		params.Set("selector", args[0])
+	}
+	format, _ := cmd.Flags().GetString("format")
+	if format != "" {
+		params.Set("format", format)
+	}
+	if v, _ := cmd.Flags().GetString("index"); v != "" {
+		params.Set("index", v)
+	}
+	if v, _ := cmd.Flags().GetString("kind"); v != "" {
+		params.Set("kind", v)
+	}
+	if v, _ := cmd.Flags().GetString("max-rows"); v != "" {
+		params.Set("maxRows", v)
+	}
+	path := "/tables"
+	if tabID, _ := cmd.Flags().GetString("tab"); tabID != "" {
+		path = "/tabs/" + url.PathEscape(tabID) + "/tables"
+	}
+
+	outFile, _ := cmd.Flags().GetString("output")
+	if outFile == "" && format != "csv" {
+		apiclient.DoGet(client, base, token, path, params)
+		return
+	}
+	data := apiclient.DoGetRaw(client, base, token, path, params)
+	if outFile == "" {
+		fmt.Print(string(data))
+		return
+	}
+	if err := os.WriteFile(outFile, data, 0600); err != nil {
+		cli.Fatal("Write failed: %v", err)
+	}
+	fmt.Fprintln(os.Stderr, cli.StyleStderr(cli.SuccessStyle, fmt.Sprintf("Saved %s", outFile)))
+}
@@ -0,0 +1,62 @@
+package actions
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+func newTablesCmd() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Flags().String("tab", "", "")
+	cmd.Flags().String("format", "", "")
+	cmd.Flags().String("index", "", "")
+	cmd.Flags().String("kind", "", "")
+	cmd.Flags().String("max-rows", "", "")
+	cmd.Flags().String("output", "", "")
+	return cmd
+}
+
+func TestTables(t *testing.T) {
+	m := newMockServer()
+	m.response = `{"tables":[],"count":0}`
+	defer m.close()
+
+	cmd := newTablesCmd()
+	_ = cmd.Flags().Set("kind", "table,grid")
+	_ = cmd.Flags().Set("max-rows", "50")
+	Tables(m.server.Client(), m.base(), "", []string{"e12"}, cmd)
+
+	if m.lastPath != "/tables" {
+		t.Errorf("expected /tables, got %s", m.lastPath)
+	}
+	for _, want := range []string{"selector=e12", "kind=table%2Cgrid", "maxRows=50"} {
+		if !strings.Contains(m.lastQuery, want) {
+			t.Errorf("expected %s in query, got %s", want, m.lastQuery)
+		}
+	}
+}
+
+func TestTablesSavesCSV(t *testing.T) {
+	m := newMockServer()
+	m.response = "name,price\nLamp,39.50\n"
+	defer m.close()
+
+	out := filepath.Join(t.TempDir(), "table.csv")
+	cmd := newTablesCmd()
+	_ = cmd.Flags().Set("tab", "TAB1")
+	_ = cmd.Flags().Set("format", "csv")
+	_ = cmd.Flags().Set("index", "1")
+	_ = cmd.Flags().Set("output", out)
+	Tables(m.server.Client(), m.base(), "", nil, cmd)
+
+	if m.lastPath != "/tabs/TAB1/tables" || !strings.Contains(m.lastQuery, "format=csv") || !strings.Contains(m.lastQuery, "index=1") {
+		t.Errorf("unexpected request %s?%s", m.lastPath, m.lastQuery)
+	}
+	if data, err := os.ReadFile(out); err != nil || string(data) != m.response {
+		t.Errorf("expected the CSV to be saved, got %q (%v)", data, err)
+	}
+}
@@ -13,6 +13,9 @@ func Text(client *http.Client, base, token string, cmd *cobra.Command) {
 		params.Set("mode", "raw")
 		params.Set("format", "text")
 	}
+	if v, _ := cmd.Flags().GetBool("readability"); v {
+		params.Set("mode", "readability")
+	}
 	if v, _ := cmd.Flags().GetString("tab"); v != "" {
 		params.Set("tabId", v)
 	}
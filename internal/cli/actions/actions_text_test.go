@@ -10,6 +10,7 @@ import (
 func newTextCmd() *cobra.Command {
 	cmd := &cobra.Command{}
 	cmd.Flags().Bool("raw", false, "")
+	cmd.Flags().Bool("readability", false, "")
 	cmd.Flags().String("tab", "", "")
 	return cmd
 }
@@ -43,6 +44,22 @@ func TestTextRaw(t *testing.T) {
 	}
 }
 
+func TestTextReadability(t *testing.T) {
+	m := newMockServer()
+	defer m.close()
+	client := m.server.Client()
+
+	cmd := newTextCmd()
+	_ = cmd.Flags().Set("readability", "true")
+	Text(client, m.base(), "", cmd)
+	if !strings.Contains(m.lastQuery, "mode=readability") {
+		t.Errorf("expected mode=readability, got %s", m.lastQuery)
+	}
+	if strings.Contains(m.lastQuery, "format=text") {
+		t.Errorf("readability should keep the JSON response, got %s", m.lastQuery)
+	}
+}
+
 func TestTextTab(t *testing.T) {
 	m := newMockServer()
 	defer m.close()
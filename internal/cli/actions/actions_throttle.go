@@ -0,0 +1,69 @@
+package actions
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/pinchtab/pinchtab/internal/cli/apiclient"
+	"github.com/spf13/cobra"
+)
+
+// NetworkConditionsFromFlags reads a network emulation profile and the
+// --latency, --download, --upload and --packet-loss flags into a request
+// body. It returns nil when none of them is set.
+func NetworkConditionsFromFlags(cmd *cobra.Command, profile string) map[string]any {
+	body := map[string]any{}
+	if profile != "" {
+		body["profile"] = profile
+	}
+	for flag, field := range map[string]string{
+		"latency":     "latencyMs",
+		"download":    "downloadKbps",
+		"upload":      "uploadKbps",
+		"packet-loss": "packetLoss",
+	} {
+		if cmd.Flags().Changed(flag) {
+			v, _ := cmd.Flags().GetFloat64(flag)
+			body[field] = v
+		}
+	}
+	if len(body) == 0 {
+		return nil
+	}
+	return body
+}
+
+// Throttle shows, sets or resets a tab's network conditions. With no
+// profile and no flags it shows them; "off" resets them.
+func Throttle(client *http.Client, base, token string, cmd *cobra.Command, args []string) {
+	tabID, _ := cmd.Flags().GetString("tab")
+	scope, _ := cmd.Flags().GetString("scope")
+	path := "/network/conditions"
+	if tabID != "" {
+		path = fmt.Sprintf("/tabs/%s/network/conditions", url.PathEscape(tabID))
+	}
+
+	profile := ""
+	if len(args) > 0 {
+		profile = args[0]
+	}
+	if profile == "off" {
+		params := url.Values{}
+		if scope != "" {
+			params.Set("scope", scope)
+		}
+		apiclient.DoDelete(client, base, token, path, params)
+		return
+	}
+
+	body := NetworkConditionsFromFlags(cmd, profile)
+	if body == nil {
+		apiclient.DoGet(client, base, token, path, nil)
+		return
+	}
+	if scope != "" {
+		body["scope"] = scope
+	}
+	apiclient.DoPost(client, base, token, path, body)
+}
@@ -0,0 +1,62 @@
+package actions
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+func newThrottleCmd() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Flags().String("tab", "", "")
+	cmd.Flags().String("scope", "", "")
+	cmd.Flags().Float64("latency", 0, "")
+	cmd.Flags().Float64("download", 0, "")
+	cmd.Flags().Float64("upload", 0, "")
+	cmd.Flags().Float64("packet-loss", 0, "")
+	return cmd
+}
+
+func TestThrottleShow(t *testing.T) {
+	m := newMockServer()
+	defer m.close()
+
+	Throttle(m.server.Client(), m.base(), "", newThrottleCmd(), nil)
+	if m.lastMethod != "GET" || m.lastPath != "/network/conditions" {
+		t.Errorf("expected GET /network/conditions, got %s %s", m.lastMethod, m.lastPath)
+	}
+}
+
+func TestThrottleProfileWithOverrides(t *testing.T) {
+	m := newMockServer()
+	defer m.close()
+
+	cmd := newThrottleCmd()
+	_ = cmd.Flags().Set("tab", "tab-abc")
+	_ = cmd.Flags().Set("latency", "150")
+	Throttle(m.server.Client(), m.base(), "", cmd, []string{"fast-3g"})
+	if m.lastMethod != "POST" || m.lastPath != "/tabs/tab-abc/network/conditions" {
+		t.Fatalf("expected POST /tabs/tab-abc/network/conditions, got %s %s", m.lastMethod, m.lastPath)
+	}
+	var body map[string]any
+	_ = json.Unmarshal([]byte(m.lastBody), &body)
+	if body["profile"] != "fast-3g" || body["latencyMs"] != 150.0 {
+		t.Errorf("unexpected body %s", m.lastBody)
+	}
+	if _, ok := body["downloadKbps"]; ok {
+		t.Errorf("unset flags should not be sent, got %s", m.lastBody)
+	}
+}
+
+func TestThrottleOffInstance(t *testing.T) {
+	m := newMockServer()
+	defer m.close()
+
+	cmd := newThrottleCmd()
+	_ = cmd.Flags().Set("scope", "instance")
+	Throttle(m.server.Client(), m.base(), "", cmd, []string{"off"})
+	if m.lastMethod != "DELETE" || m.lastPath != "/network/conditions" || m.lastQuery != "scope=instance" {
+		t.Errorf("expected DELETE /network/conditions?scope=instance, got %s %s?%s", m.lastMethod, m.lastPath, m.lastQuery)
+	}
+}
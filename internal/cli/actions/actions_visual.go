@@ -0,0 +1,81 @@
+package actions
+
+import (
+	"net/http"
+	"net/url"
+
+	"github.com/pinchtab/pinchtab/internal/cli"
+	"github.com/pinchtab/pinchtab/internal/cli/apiclient"
+	"github.com/spf13/cobra"
+)
+
+// visualPath returns a visual endpoint, tab-scoped when --tab is set.
+func visualPath(cmd *cobra.Command, suffix string) string {
+	if tabID, _ := cmd.Flags().GetString("tab"); tabID != "" {
+		return "/tabs/" + url.PathEscape(tabID) + "/visual" + suffix
+	}
+	return "/visual" + suffix
+}
+
+// visualMasks turns --mask selectors into request masks.
+func visualMasks(cmd *cobra.Command) []map[string]any {
+	sels, _ := cmd.Flags().GetStringArray("mask")
+	masks := make([]map[string]any, 0, len(sels))
+	for _, s := range sels {
+		masks = append(masks, map[string]any{"selector": s})
+	}
+	return masks
+}
+
+// VisualBaseline captures a named baseline screenshot.
+func VisualBaseline(client *http.Client, base, token, name string, cmd *cobra.Command) {
+	body := map[string]any{"name": name}
+	if v, _ := cmd.Flags().GetString("selector"); v != "" {
+		body["selector"] = v
+	}
+	if v, _ := cmd.Flags().GetBool("full-page"); v {
+		body["fullPage"] = true
+	}
+	if masks := visualMasks(cmd); len(masks) > 0 {
+		body["masks"] = masks
+	}
+	apiclient.DoPost(client, base, token, visualPath(cmd, "/baseline"), body)
+}
+
+// VisualCompare compares a tab against a baseline and exits non-zero when
+// the mismatch is above --max-mismatch.
+func VisualCompare(client *http.Client, base, token, name string, cmd *cobra.Command) {
+	body := map[string]any{"name": name, "diff": "none"}
+	if v, _ := cmd.Flags().GetFloat64("threshold"); v > 0 {
+		body["threshold"] = v
+	}
+	if v, _ := cmd.Flags().GetFloat64("max-mismatch"); v > 0 {
+		body["maxMismatch"] = v
+	}
+	if v, _ := cmd.Flags().GetBool("include-aa"); v {
+		body["includeAntialiasing"] = true
+	}
+	if v, _ := cmd.Flags().GetBool("save-diff"); v {
+		body["diff"] = "file"
+	}
+	if v, _ := cmd.Flags().GetBool("update"); v {
+		body["update"] = true
+	}
+	if masks := visualMasks(cmd); len(masks) > 0 {
+		body["masks"] = masks
+	}
+	result := apiclient.DoPost(client, base, token, visualPath(cmd, "/compare"), body)
+	if passed, ok := result["passed"].(bool); ok && !passed {
+		cli.Fatal("Visual mismatch: %v%% of pixels changed", result["mismatchPercent"])
+	}
+}
+
+// VisualList lists the stored baselines.
+func VisualList(client *http.Client, base, token string) {
+	apiclient.DoGet(client, base, token, "/visual/baselines", nil)
+}
+
+// VisualDelete deletes a baseline.
+func VisualDelete(client *http.Client, base, token, name string) {
+	apiclient.DoDelete(client, base, token, "/visual/baseline", url.Values{"name": {name}})
+}
@@ -0,0 +1,62 @@
+package actions
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+func newVisualCmd() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Flags().String("tab", "", "")
+	cmd.Flags().String("selector", "", "")
+	cmd.Flags().Bool("full-page", false, "")
+	cmd.Flags().StringArray("mask", nil, "")
+	cmd.Flags().Float64("threshold", 0, "")
+	cmd.Flags().Float64("max-mismatch", 0, "")
+	cmd.Flags().Bool("include-aa", false, "")
+	cmd.Flags().Bool("save-diff", false, "")
+	cmd.Flags().Bool("update", false, "")
+	return cmd
+}
+
+func TestVisualBaseline(t *testing.T) {
+	m := newMockServer()
+	defer m.close()
+
+	cmd := newVisualCmd()
+	_ = cmd.Flags().Set("tab", "TAB1")
+	_ = cmd.Flags().Set("selector", "header")
+	_ = cmd.Flags().Set("mask", ".clock")
+	VisualBaseline(m.server.Client(), m.base(), "", "home", cmd)
+
+	if m.lastPath != "/tabs/TAB1/visual/baseline" {
+		t.Errorf("expected /tabs/TAB1/visual/baseline, got %s", m.lastPath)
+	}
+	for _, want := range []string{`"name":"home"`, `"selector":"header"`, `"masks":[{"selector":".clock"}]`} {
+		if !strings.Contains(m.lastBody, want) {
+			t.Errorf("expected %s in body, got %s", want, m.lastBody)
+		}
+	}
+}
+
+func TestVisualCompare(t *testing.T) {
+	m := newMockServer()
+	m.response = `{"passed":true,"mismatchPercent":0.2}`
+	defer m.close()
+
+	cmd := newVisualCmd()
+	_ = cmd.Flags().Set("max-mismatch", "0.5")
+	_ = cmd.Flags().Set("save-diff", "true")
+	VisualCompare(m.server.Client(), m.base(), "", "home", cmd)
+
+	if m.lastPath != "/visual/compare" {
+		t.Errorf("expected /visual/compare, got %s", m.lastPath)
+	}
+	for _, want := range []string{`"maxMismatch":0.5`, `"diff":"file"`} {
+		if !strings.Contains(m.lastBody, want) {
+			t.Errorf("expected %s in body, got %s", want, m.lastBody)
+		}
+	}
+}
@@ -195,6 +195,9 @@ type instanceDefaultsConfigJSON struct {
 	NoAnimations      *bool  `json:"noAnimations"`
 	StealthLevel      string `json:"stealthLevel"`
 	TabEvictionPolicy string `json:"tabEvictionPolicy"`
+
+	Network *NetworkConditions `json:"network,omitempty"`
+	Proxy   *ProxyConfig       `json:"proxy,omitempty"`
 }
 
 type profilesConfigJSON struct {
@@ -263,14 +266,26 @@ type timeoutsConfigJSON struct {
 }
 
 type schedulerFileConfigJSON struct {
-	Enabled           *bool  `json:"enabled"`
-	Strategy          string `json:"strategy"`
-	MaxQueueSize      *int   `json:"maxQueueSize"`
-	MaxPerAgent       *int   `json:"maxPerAgent"`
-	MaxInflight       *int   `json:"maxInflight"`
-	MaxPerAgentFlight *int   `json:"maxPerAgentInflight"`
-	ResultTTLSec      *int   `json:"resultTTLSec"`
-	WorkerCount       *int   `json:"workerCount"`
+	Enabled           *bool    `json:"enabled"`
+	Strategy          string   `json:"strategy"`
+	MaxQueueSize      *int     `json:"maxQueueSize"`
+	MaxPerAgent       *int     `json:"maxPerAgent"`
+	MaxInflight       *int     `json:"maxInflight"`
+	MaxPerAgentFlight *int     `json:"maxPerAgentInflight"`
+	ResultTTLSec      *int     `json:"resultTTLSec"`
+	WorkerCount       *int     `json:"workerCount"`
+	Persist           *bool    `json:"persist"`
+	RecoverRunning    string   `json:"recoverRunning,omitempty"`
+	RetryMaxAttempts  *int     `json:"retryMaxAttempts,omitempty"`
+	RetryBackoffMs    *int     `json:"retryBackoffMs,omitempty"`
+	RetryMaxBackoffMs *int     `json:"retryMaxBackoffMs,omitempty"`
+	RetryOn           []string `json:"retryOn,omitempty"`
+
+	WebhookSecret      string `json:"webhookSecret,omitempty"`
+	WebhookMaxAttempts *int   `json:"webhookMaxAttempts,omitempty"`
+	WebhookBackoffMs   *int   `json:"webhookBackoffMs,omitempty"`
+
+	AgentGroups map[string]SchedulerAgentGroup `json:"agentGroups,omitempty"`
 }
 
 type observabilityFileConfigJSON struct {
@@ -365,6 +380,8 @@ func (fc FileConfig) MarshalJSON() ([]byte, error) {
 			NoAnimations:      fc.InstanceDefaults.NoAnimations,
 			StealthLevel:      fc.InstanceDefaults.StealthLevel,
 			TabEvictionPolicy: fc.InstanceDefaults.TabEvictionPolicy,
+			Network:           fc.InstanceDefaults.Network,
+			Proxy:             fc.InstanceDefaults.Proxy,
 		},
 		Security: securityConfigJSON{
 			AllowEvaluate:          fc.Security.AllowEvaluate,
@@ -430,6 +447,18 @@ func (fc FileConfig) MarshalJSON() ([]byte, error) {
 			MaxPerAgentFlight: fc.Scheduler.MaxPerAgentFlight,
 			ResultTTLSec:      fc.Scheduler.ResultTTLSec,
 			WorkerCount:       fc.Scheduler.WorkerCount,
+			Persist:           fc.Scheduler.Persist,
+			RecoverRunning:    fc.Scheduler.RecoverRunning,
+			RetryMaxAttempts:  fc.Scheduler.RetryMaxAttempts,
+			RetryBackoffMs:    fc.Scheduler.RetryBackoffMs,
+			RetryMaxBackoffMs: fc.Scheduler.RetryMaxBackoffMs,
+			RetryOn:           fc.Scheduler.RetryOn,
+
+			WebhookSecret:      fc.Scheduler.WebhookSecret,
+			WebhookMaxAttempts: fc.Scheduler.WebhookMaxAttempts,
+			WebhookBackoffMs:   fc.Scheduler.WebhookBackoffMs,
+
+			AgentGroups: fc.Scheduler.AgentGroups,
 		},
 		Observability: observabilityFileConfigJSON{
 			Activity: activityConfigJSON{
@@ -530,6 +559,18 @@ func FileConfigFromRuntime(cfg *RuntimeConfig) FileConfig {
 		mode = "headed"
 	}
 
+	var network *NetworkConditions
+	if cfg.NetworkConditions.Active() {
+		nc := cfg.NetworkConditions
+		network = &nc
+	}
+
+	var proxy *ProxyConfig
+	if cfg.Proxy.Active() {
+		p := cfg.Proxy
+		proxy = &p
+	}
+
 	var netBufSize *int
 	if cfg.NetworkBufferSize > 0 {
 		v := cfg.NetworkBufferSize
@@ -567,6 +608,8 @@ func FileConfigFromRuntime(cfg *RuntimeConfig) FileConfig {
 			NoAnimations:      &noAnimations,
 			StealthLevel:      cfg.StealthLevel,
 			TabEvictionPolicy: cfg.TabEvictionPolicy,
+			Network:           network,
+			Proxy:             proxy,
 		},
 		Security: SecurityConfig{
 			AllowEvaluate:          &allowEvaluate,
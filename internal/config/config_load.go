@@ -394,6 +394,16 @@ func applyFileConfig(cfg *RuntimeConfig, fc *FileConfig) {
 	if fc.InstanceDefaults.DialogAutoAccept != nil {
 		cfg.DialogAutoAccept = *fc.InstanceDefaults.DialogAutoAccept
 	}
+	if fc.InstanceDefaults.Network != nil {
+		if nc, err := fc.InstanceDefaults.Network.Resolve(); err == nil {
+			cfg.NetworkConditions = nc
+		}
+	}
+	if fc.InstanceDefaults.Proxy != nil {
+		if p, err := fc.InstanceDefaults.Proxy.Resolve(); err == nil {
+			cfg.Proxy = p
+		}
+	}
 
 	// Profiles
 	if fc.Profiles.BaseDir != "" {
@@ -481,6 +491,36 @@ func applyFileConfig(cfg *RuntimeConfig, fc *FileConfig) {
 	if fc.Scheduler.WorkerCount != nil {
 		cfg.Scheduler.WorkerCount = *fc.Scheduler.WorkerCount
 	}
+	if fc.Scheduler.Persist != nil {
+		cfg.Scheduler.Persist = *fc.Scheduler.Persist
+	}
+	if fc.Scheduler.RecoverRunning != "" {
+		cfg.Scheduler.RecoverRunning = fc.Scheduler.RecoverRunning
+	}
+	if fc.Scheduler.RetryMaxAttempts != nil {
+		cfg.Scheduler.RetryMaxAttempts = *fc.Scheduler.RetryMaxAttempts
+	}
+	if fc.Scheduler.RetryBackoffMs != nil {
+		cfg.Scheduler.RetryBackoffMs = *fc.Scheduler.RetryBackoffMs
+	}
+	if fc.Scheduler.RetryMaxBackoffMs != nil {
+		cfg.Scheduler.RetryMaxBackoffMs = *fc.Scheduler.RetryMaxBackoffMs
+	}
+	if len(fc.Scheduler.RetryOn) > 0 {
+		cfg.Scheduler.RetryOn = fc.Scheduler.RetryOn
+	}
+	if fc.Scheduler.WebhookSecret != "" {
+		cfg.Scheduler.WebhookSecret = fc.Scheduler.WebhookSecret
+	}
+	if fc.Scheduler.WebhookMaxAttempts != nil {
+		cfg.Scheduler.WebhookMaxAttempts = *fc.Scheduler.WebhookMaxAttempts
+	}
+	if fc.Scheduler.WebhookBackoffMs != nil {
+		cfg.Scheduler.WebhookBackoffMs = *fc.Scheduler.WebhookBackoffMs
+	}
+	if len(fc.Scheduler.AgentGroups) > 0 {
+		cfg.Scheduler.AgentGroups = fc.Scheduler.AgentGroups
+	}
 
 	// AutoSolver
 	if fc.AutoSolver.Enabled != nil {
@@ -56,7 +56,9 @@ type RuntimeConfig struct {
 	UserAgent         string
 	NoAnimations      bool
 	StealthLevel      string
-	TabEvictionPolicy string // "close_lru" (default), "reject", "close_oldest"
+	TabEvictionPolicy string            // "close_lru" (default), "reject", "close_oldest"
+	NetworkConditions NetworkConditions // Resolved network emulation new tabs start with
+	Proxy             ProxyConfig       // Resolved upstream proxy for the browser
 
 	// Timeout settings
 	ActionTimeout   time.Duration
@@ -141,14 +143,36 @@ type IDPIConfig struct {
 
 // SchedulerConfig holds task scheduler settings.
 type SchedulerConfig struct {
-	Enabled           bool   `json:"enabled,omitempty"`
-	Strategy          string `json:"strategy,omitempty"`
-	MaxQueueSize      int    `json:"maxQueueSize,omitempty"`
-	MaxPerAgent       int    `json:"maxPerAgent,omitempty"`
-	MaxInflight       int    `json:"maxInflight,omitempty"`
-	MaxPerAgentFlight int    `json:"maxPerAgentInflight,omitempty"`
-	ResultTTLSec      int    `json:"resultTTLSec,omitempty"`
-	WorkerCount       int    `json:"workerCount,omitempty"`
+	Enabled           bool     `json:"enabled,omitempty"`
+	Strategy          string   `json:"strategy,omitempty"`
+	MaxQueueSize      int      `json:"maxQueueSize,omitempty"`
+	MaxPerAgent       int      `json:"maxPerAgent,omitempty"`
+	MaxInflight       int      `json:"maxInflight,omitempty"`
+	MaxPerAgentFlight int      `json:"maxPerAgentInflight,omitempty"`
+	ResultTTLSec      int      `json:"resultTTLSec,omitempty"`
+	WorkerCount       int      `json:"workerCount,omitempty"`
+	Persist           bool     `json:"persist,omitempty"`
+	RecoverRunning    string   `json:"recoverRunning,omitempty"`
+	RetryMaxAttempts  int      `json:"retryMaxAttempts,omitempty"`
+	RetryBackoffMs    int      `json:"retryBackoffMs,omitempty"`
+	RetryMaxBackoffMs int      `json:"retryMaxBackoffMs,omitempty"`
+	RetryOn           []string `json:"retryOn,omitempty"`
+
+	WebhookSecret      string `json:"webhookSecret,omitempty"`
+	WebhookMaxAttempts int    `json:"webhookMaxAttempts,omitempty"`
+	WebhookBackoffMs   int    `json:"webhookBackoffMs,omitempty"`
+
+	AgentGroups map[string]SchedulerAgentGroup `json:"agentGroups,omitempty"`
+}
+
+// SchedulerAgentGroup is the fair-share weight and submission limits for a
+// set of agents. Agent entries ending in "*" match by prefix.
+type SchedulerAgentGroup struct {
+	Agents     []string `json:"agents"`
+	Weight     int      `json:"weight,omitempty"`
+	RatePerSec float64  `json:"ratePerSec,omitempty"`
+	Burst      int      `json:"burst,omitempty"`
+	DailyQuota int      `json:"dailyQuota,omitempty"`
 }
 
 // AutoSolverConfig holds autosolver runtime settings.
@@ -254,6 +278,9 @@ type InstanceDefaultsConfig struct {
 	StealthLevel      string `json:"stealthLevel,omitempty"`
 	TabEvictionPolicy string `json:"tabEvictionPolicy,omitempty"`
 	DialogAutoAccept  *bool  `json:"dialogAutoAccept,omitempty"`
+
+	Network *NetworkConditions `json:"network,omitempty"`
+	Proxy   *ProxyConfig       `json:"proxy,omitempty"`
 }
 
 type ProfilesConfig struct {
@@ -312,14 +339,26 @@ type TimeoutsConfig struct {
 }
 
 type SchedulerFileConfig struct {
-	Enabled           *bool  `json:"enabled,omitempty"`
-	Strategy          string `json:"strategy,omitempty"`
-	MaxQueueSize      *int   `json:"maxQueueSize,omitempty"`
-	MaxPerAgent       *int   `json:"maxPerAgent,omitempty"`
-	MaxInflight       *int   `json:"maxInflight,omitempty"`
-	MaxPerAgentFlight *int   `json:"maxPerAgentInflight,omitempty"`
-	ResultTTLSec      *int   `json:"resultTTLSec,omitempty"`
-	WorkerCount       *int   `json:"workerCount,omitempty"`
+	Enabled           *bool    `json:"enabled,omitempty"`
+	Strategy          string   `json:"strategy,omitempty"`
+	MaxQueueSize      *int     `json:"maxQueueSize,omitempty"`
+	MaxPerAgent       *int     `json:"maxPerAgent,omitempty"`
+	MaxInflight       *int     `json:"maxInflight,omitempty"`
+	MaxPerAgentFlight *int     `json:"maxPerAgentInflight,omitempty"`
+	ResultTTLSec      *int     `json:"resultTTLSec,omitempty"`
+	WorkerCount       *int     `json:"workerCount,omitempty"`
+	Persist           *bool    `json:"persist,omitempty"`
+	RecoverRunning    string   `json:"recoverRunning,omitempty"`
+	RetryMaxAttempts  *int     `json:"retryMaxAttempts,omitempty"`
+	RetryBackoffMs    *int     `json:"retryBackoffMs,omitempty"`
+	RetryMaxBackoffMs *int     `json:"retryMaxBackoffMs,omitempty"`
+	RetryOn           []string `json:"retryOn,omitempty"`
+
+	WebhookSecret      string `json:"webhookSecret,omitempty"`
+	WebhookMaxAttempts *int   `json:"webhookMaxAttempts,omitempty"`
+	WebhookBackoffMs   *int   `json:"webhookBackoffMs,omitempty"`
+
+	AgentGroups map[string]SchedulerAgentGroup `json:"agentGroups,omitempty"`
 }
 
 type ObservabilityFileConfig struct {
@@ -0,0 +1,95 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Network emulation profiles.
+const (
+	NetworkProfileNone    = "none"
+	NetworkProfileOffline = "offline"
+	NetworkProfileSlow3G  = "slow-3g"
+	NetworkProfileFast3G  = "fast-3g"
+	NetworkProfileCustom  = "custom"
+)
+
+// NetworkConditions describes emulated network conditions. Profile names a
+// preset; LatencyMs, DownloadKbps, UploadKbps and PacketLoss override its
+// values when non-zero, or describe the conditions on their own with the
+// custom profile. Throughput is in kilobits per second, 0 meaning no limit,
+// and PacketLoss is a percentage.
+type NetworkConditions struct {
+	Profile      string  `json:"profile,omitempty"`
+	Offline      bool    `json:"offline,omitempty"`
+	LatencyMs    float64 `json:"latencyMs,omitempty"`
+	DownloadKbps float64 `json:"downloadKbps,omitempty"`
+	UploadKbps   float64 `json:"uploadKbps,omitempty"`
+	PacketLoss   float64 `json:"packetLoss,omitempty"`
+}
+
+// networkPresets follow the DevTools throttling presets.
+var networkPresets = map[string]NetworkConditions{
+	NetworkProfileNone:    {},
+	NetworkProfileOffline: {Offline: true},
+	NetworkProfileSlow3G:  {LatencyMs: 2000, DownloadKbps: 400, UploadKbps: 400},
+	NetworkProfileFast3G:  {LatencyMs: 562.5, DownloadKbps: 1440, UploadKbps: 675},
+	NetworkProfileCustom:  {},
+}
+
+// ValidNetworkProfiles returns the network emulation profile names.
+func ValidNetworkProfiles() []string {
+	return []string{NetworkProfileNone, NetworkProfileOffline, NetworkProfileSlow3G, NetworkProfileFast3G, NetworkProfileCustom}
+}
+
+// Resolve returns the conditions with the profile's values filled in. An
+// empty profile is custom when any value is set, offline when Offline is,
+// and none otherwise.
+func (c NetworkConditions) Resolve() (NetworkConditions, error) {
+	profile := strings.ToLower(strings.TrimSpace(c.Profile))
+	if profile == "" {
+		switch {
+		case c.Offline:
+			profile = NetworkProfileOffline
+		case c.LatencyMs != 0 || c.DownloadKbps != 0 || c.UploadKbps != 0 || c.PacketLoss != 0:
+			profile = NetworkProfileCustom
+		default:
+			profile = NetworkProfileNone
+		}
+	}
+	preset, ok := networkPresets[profile]
+	if !ok {
+		return NetworkConditions{}, fmt.Errorf("unknown network profile %q (must be %s)", c.Profile, strings.Join(ValidNetworkProfiles(), ", "))
+	}
+	if c.LatencyMs < 0 || c.DownloadKbps < 0 || c.UploadKbps < 0 {
+		return NetworkConditions{}, fmt.Errorf("latencyMs, downloadKbps and uploadKbps must be >= 0")
+	}
+	if c.PacketLoss < 0 || c.PacketLoss > 100 {
+		return NetworkConditions{}, fmt.Errorf("packetLoss must be between 0 and 100")
+	}
+
+	out := preset
+	out.Profile = profile
+	if profile == NetworkProfileNone || profile == NetworkProfileOffline {
+		return out, nil
+	}
+	if c.LatencyMs != 0 {
+		out.LatencyMs = c.LatencyMs
+	}
+	if c.DownloadKbps != 0 {
+		out.DownloadKbps = c.DownloadKbps
+	}
+	if c.UploadKbps != 0 {
+		out.UploadKbps = c.UploadKbps
+	}
+	if c.PacketLoss != 0 {
+		out.PacketLoss = c.PacketLoss
+	}
+	return out, nil
+}
+
+// Active reports whether the conditions change anything. Only meaningful
+// on resolved conditions.
+func (c NetworkConditions) Active() bool {
+	return c.Profile != "" && c.Profile != NetworkProfileNone
+}
@@ -0,0 +1,53 @@
+package config
+
+import "testing"
+
+func TestNetworkConditionsResolve(t *testing.T) {
+	tests := []struct {
+		name string
+		in   NetworkConditions
+		want NetworkConditions
+	}{
+		{"empty", NetworkConditions{}, NetworkConditions{Profile: "none"}},
+		{"preset", NetworkConditions{Profile: "Slow-3G"}, NetworkConditions{Profile: "slow-3g", LatencyMs: 2000, DownloadKbps: 400, UploadKbps: 400}},
+		{"preset override", NetworkConditions{Profile: "fast-3g", LatencyMs: 100}, NetworkConditions{Profile: "fast-3g", LatencyMs: 100, DownloadKbps: 1440, UploadKbps: 675}},
+		{"implicit custom", NetworkConditions{LatencyMs: 300, PacketLoss: 5}, NetworkConditions{Profile: "custom", LatencyMs: 300, PacketLoss: 5}},
+		{"implicit offline", NetworkConditions{Offline: true}, NetworkConditions{Profile: "offline", Offline: true}},
+		{"offline ignores values", NetworkConditions{Profile: "offline", LatencyMs: 300}, NetworkConditions{Profile: "offline", Offline: true}},
+	}
+	for _, tt := range tests {
+		got, err := tt.in.Resolve()
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%s: got %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+
+	for _, bad := range []NetworkConditions{
+		{Profile: "edge"},
+		{LatencyMs: -1},
+		{Profile: "custom", PacketLoss: 101},
+	} {
+		if _, err := bad.Resolve(); err == nil {
+			t.Errorf("expected an error for %+v", bad)
+		}
+	}
+}
+
+func TestValidateFileConfig_Network(t *testing.T) {
+	fc := DefaultFileConfig()
+	fc.InstanceDefaults.Network = &NetworkConditions{Profile: "dialup"}
+	errs := ValidateFileConfig(&fc)
+	found := false
+	for _, err := range errs {
+		if ve, ok := err.(ValidationError); ok && ve.Field == "instanceDefaults.network" {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("expected an instanceDefaults.network error, got %v", errs)
+	}
+}
@@ -0,0 +1,113 @@
+package config
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+)
+
+// ProxyConfig routes browser traffic through an upstream proxy. URL is
+// scheme://[user:pass@]host:port with an http, https, socks4 or socks5
+// scheme; credentials are answered when the proxy issues an auth
+// challenge. Bypass is a Chrome proxy bypass list such as
+// "localhost;*.internal".
+type ProxyConfig struct {
+	URL    string `json:"url,omitempty"`
+	Bypass string `json:"bypass,omitempty"`
+}
+
+// ValidProxySchemes returns the supported proxy URL schemes.
+func ValidProxySchemes() []string {
+	return []string{"http", "https", "socks4", "socks5"}
+}
+
+// Resolve validates the proxy and returns it with the URL normalized. An
+// empty URL resolves to an inactive proxy.
+func (p ProxyConfig) Resolve() (ProxyConfig, error) {
+	raw := strings.TrimSpace(p.URL)
+	out := ProxyConfig{Bypass: strings.TrimSpace(p.Bypass)}
+	if raw == "" {
+		if out.Bypass != "" {
+			return ProxyConfig{}, fmt.Errorf("proxy bypass requires a proxy url")
+		}
+		return out, nil
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return ProxyConfig{}, fmt.Errorf("invalid proxy url: %s", redactProxyError(err, raw))
+	}
+	u.Scheme = strings.ToLower(u.Scheme)
+	switch u.Scheme {
+	case "http", "https", "socks4", "socks5":
+	default:
+		return ProxyConfig{}, fmt.Errorf("unsupported proxy scheme %q (must be %s)", u.Scheme, strings.Join(ValidProxySchemes(), ", "))
+	}
+	if u.Hostname() == "" {
+		return ProxyConfig{}, fmt.Errorf("proxy url must include a host")
+	}
+	if port := u.Port(); port != "" {
+		if _, err := net.LookupPort("tcp", port); err != nil {
+			return ProxyConfig{}, fmt.Errorf("invalid proxy port %q", port)
+		}
+	}
+	if (u.Path != "" && u.Path != "/") || u.RawQuery != "" || u.Fragment != "" {
+		return ProxyConfig{}, fmt.Errorf("proxy url must not include a path or query")
+	}
+	// Chrome cannot authenticate to SOCKS proxies, so credentials would be
+	// silently ignored.
+	if u.User != nil && strings.HasPrefix(u.Scheme, "socks") {
+		return ProxyConfig{}, fmt.Errorf("socks proxies do not support credentials")
+	}
+	u.Path = ""
+	out.URL = u.String()
+	return out, nil
+}
+
+// Active reports whether a proxy is configured.
+func (p ProxyConfig) Active() bool {
+	return strings.TrimSpace(p.URL) != ""
+}
+
+// Server returns the proxy URL without credentials, as passed to Chrome.
+func (p ProxyConfig) Server() string {
+	u, err := url.Parse(strings.TrimSpace(p.URL))
+	if err != nil || u.Host == "" {
+		return ""
+	}
+	return u.Scheme + "://" + u.Host
+}
+
+// Credentials returns the username and password embedded in the URL.
+func (p ProxyConfig) Credentials() (username, password string, ok bool) {
+	u, err := url.Parse(strings.TrimSpace(p.URL))
+	if err != nil || u.User == nil {
+		return "", "", false
+	}
+	password, _ = u.User.Password()
+	return u.User.Username(), password, true
+}
+
+// Redacted returns the proxy with any password masked, safe to log or
+// return from the API.
+func (p ProxyConfig) Redacted() ProxyConfig {
+	u, err := url.Parse(strings.TrimSpace(p.URL))
+	if err != nil {
+		return ProxyConfig{Bypass: p.Bypass}
+	}
+	if u.User != nil {
+		if _, ok := u.User.Password(); ok {
+			u.User = url.UserPassword(u.User.Username(), "xxxxx")
+		}
+	}
+	return ProxyConfig{URL: u.String(), Bypass: p.Bypass}
+}
+
+// redactProxyError keeps url.Parse errors from echoing credentials.
+func redactProxyError(err error, raw string) string {
+	msg := err.Error()
+	if at := strings.LastIndex(raw, "@"); at >= 0 {
+		msg = strings.ReplaceAll(msg, raw, "<redacted>")
+	}
+	return msg
+}
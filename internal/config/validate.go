@@ -2,6 +2,8 @@ package config
 
 import (
 	"fmt"
+	"maps"
+	"slices"
 	"strconv"
 	"strings"
 )
@@ -104,6 +106,22 @@ func ValidateFileConfig(fc *FileConfig) []error {
 			})
 		}
 	}
+	if fc.InstanceDefaults.Network != nil {
+		if _, err := fc.InstanceDefaults.Network.Resolve(); err != nil {
+			errs = append(errs, ValidationError{
+				Field:   "instanceDefaults.network",
+				Message: err.Error(),
+			})
+		}
+	}
+	if fc.InstanceDefaults.Proxy != nil {
+		if _, err := fc.InstanceDefaults.Proxy.Resolve(); err != nil {
+			errs = append(errs, ValidationError{
+				Field:   "instanceDefaults.proxy",
+				Message: err.Error(),
+			})
+		}
+	}
 	if fc.InstanceDefaults.MaxTabs != nil && *fc.InstanceDefaults.MaxTabs < 1 {
 		errs = append(errs, ValidationError{
 			Field:   "instanceDefaults.maxTabs",
@@ -191,6 +209,43 @@ func ValidateFileConfig(fc *FileConfig) []error {
 		})
 	}
 
+	// Scheduler validation
+	if fc.Scheduler.RecoverRunning != "" && !isValidSchedulerRecoverPolicy(fc.Scheduler.RecoverRunning) {
+		errs = append(errs, ValidationError{
+			Field:   "scheduler.recoverRunning",
+			Message: fmt.Sprintf("invalid value %q (must be fail or requeue)", fc.Scheduler.RecoverRunning),
+		})
+	}
+	errs = append(errs, validatePositiveIntLimit("scheduler.retryMaxAttempts", fc.Scheduler.RetryMaxAttempts, 10)...)
+	if fc.Scheduler.RetryBackoffMs != nil && *fc.Scheduler.RetryBackoffMs < 0 {
+		errs = append(errs, ValidationError{
+			Field:   "scheduler.retryBackoffMs",
+			Message: fmt.Sprintf("must be >= 0 (got %d)", *fc.Scheduler.RetryBackoffMs),
+		})
+	}
+	if fc.Scheduler.RetryMaxBackoffMs != nil && *fc.Scheduler.RetryMaxBackoffMs < 0 {
+		errs = append(errs, ValidationError{
+			Field:   "scheduler.retryMaxBackoffMs",
+			Message: fmt.Sprintf("must be >= 0 (got %d)", *fc.Scheduler.RetryMaxBackoffMs),
+		})
+	}
+	for _, class := range fc.Scheduler.RetryOn {
+		if !isValidSchedulerRetryClass(class) {
+			errs = append(errs, ValidationError{
+				Field:   "scheduler.retryOn",
+				Message: fmt.Sprintf("invalid value %q (must be server_error, tab_locked, browser_draining or network)", class),
+			})
+		}
+	}
+	errs = append(errs, validatePositiveIntLimit("scheduler.webhookMaxAttempts", fc.Scheduler.WebhookMaxAttempts, 10)...)
+	if fc.Scheduler.WebhookBackoffMs != nil && *fc.Scheduler.WebhookBackoffMs < 0 {
+		errs = append(errs, ValidationError{
+			Field:   "scheduler.webhookBackoffMs",
+			Message: fmt.Sprintf("must be >= 0 (got %d)", *fc.Scheduler.WebhookBackoffMs),
+		})
+	}
+	errs = append(errs, validateSchedulerAgentGroups(fc.Scheduler.AgentGroups)...)
+
 	if fc.Observability.Activity.SessionIdleSec != nil && *fc.Observability.Activity.SessionIdleSec < 0 {
 		errs = append(errs, ValidationError{
 			Field:   "observability.activity.sessionIdleSec",
@@ -286,6 +341,78 @@ func isValidEvictionPolicy(policy string) bool {
 	}
 }
 
+func isValidSchedulerRecoverPolicy(policy string) bool {
+	switch policy {
+	case "fail", "requeue":
+		return true
+	default:
+		return false
+	}
+}
+
+func validateSchedulerAgentGroups(groups map[string]SchedulerAgentGroup) []error {
+	var errs []error
+	owner := map[string]string{}
+	for _, name := range slices.Sorted(maps.Keys(groups)) {
+		g := groups[name]
+		field := "scheduler.agentGroups." + name
+		if len(g.Agents) == 0 {
+			errs = append(errs, ValidationError{Field: field + ".agents", Message: "must list at least one agent"})
+		}
+		for _, agent := range g.Agents {
+			if agent == "" || strings.Contains(strings.TrimSuffix(agent, "*"), "*") {
+				errs = append(errs, ValidationError{
+					Field:   field + ".agents",
+					Message: fmt.Sprintf("invalid agent %q (\"*\" is only allowed at the end)", agent),
+				})
+				continue
+			}
+			if other, dup := owner[agent]; dup {
+				errs = append(errs, ValidationError{
+					Field:   field + ".agents",
+					Message: fmt.Sprintf("agent %q is already in group %q", agent, other),
+				})
+				continue
+			}
+			owner[agent] = name
+		}
+		if g.Weight < 0 || g.Weight > 100 {
+			errs = append(errs, ValidationError{
+				Field:   field + ".weight",
+				Message: fmt.Sprintf("must be between 1 and 100 (got %d)", g.Weight),
+			})
+		}
+		if g.RatePerSec < 0 {
+			errs = append(errs, ValidationError{
+				Field:   field + ".ratePerSec",
+				Message: fmt.Sprintf("must be >= 0 (got %g)", g.RatePerSec),
+			})
+		}
+		if g.Burst < 0 {
+			errs = append(errs, ValidationError{
+				Field:   field + ".burst",
+				Message: fmt.Sprintf("must be >= 0 (got %d)", g.Burst),
+			})
+		}
+		if g.DailyQuota < 0 {
+			errs = append(errs, ValidationError{
+				Field:   field + ".dailyQuota",
+				Message: fmt.Sprintf("must be >= 0 (got %d)", g.DailyQuota),
+			})
+		}
+	}
+	return errs
+}
+
+func isValidSchedulerRetryClass(class string) bool {
+	switch class {
+	case "server_error", "tab_locked", "browser_draining", "network":
+		return true
+	default:
+		return false
+	}
+}
+
 func isValidStrategy(strategy string) bool {
 	switch strategy {
 	case "simple", "explicit", "simple-autorestart", "always-on", "no-instance":
@@ -242,6 +242,83 @@ func TestValidateFileConfig_InvalidStrategy(t *testing.T) {
 	}
 }
 
+func TestValidateFileConfig_SchedulerRecoverRunning(t *testing.T) {
+	tests := []struct {
+		policy  string
+		wantErr bool
+	}{
+		{"", false},
+		{"fail", false},
+		{"requeue", false},
+		{"retry", true},
+	}
+
+	for _, tt := range tests {
+		fc := &FileConfig{
+			Scheduler: SchedulerFileConfig{RecoverRunning: tt.policy},
+		}
+		errs := ValidateFileConfig(fc)
+		hasErr := len(errs) > 0
+		if hasErr != tt.wantErr {
+			t.Errorf("recoverRunning=%q: got error=%v, want error=%v", tt.policy, hasErr, tt.wantErr)
+		}
+	}
+}
+
+func TestValidateFileConfig_SchedulerRetry(t *testing.T) {
+	zero, eleven, negative := 0, 11, -1
+	tests := []struct {
+		name    string
+		cfg     SchedulerFileConfig
+		wantErr bool
+	}{
+		{"defaults", SchedulerFileConfig{}, false},
+		{"valid classes", SchedulerFileConfig{RetryOn: []string{"tab_locked", "network"}}, false},
+		{"unknown class", SchedulerFileConfig{RetryOn: []string{"timeout"}}, true},
+		{"zero attempts", SchedulerFileConfig{RetryMaxAttempts: &zero}, true},
+		{"too many attempts", SchedulerFileConfig{RetryMaxAttempts: &eleven}, true},
+		{"negative backoff", SchedulerFileConfig{RetryBackoffMs: &negative}, true},
+		{"too many webhook attempts", SchedulerFileConfig{WebhookMaxAttempts: &eleven}, true},
+		{"negative webhook backoff", SchedulerFileConfig{WebhookBackoffMs: &negative}, true},
+	}
+
+	for _, tt := range tests {
+		errs := ValidateFileConfig(&FileConfig{Scheduler: tt.cfg})
+		if hasErr := len(errs) > 0; hasErr != tt.wantErr {
+			t.Errorf("%s: got errors=%v, want error=%v", tt.name, errs, tt.wantErr)
+		}
+	}
+}
+
+func TestValidateFileConfig_SchedulerAgentGroups(t *testing.T) {
+	tests := []struct {
+		name    string
+		groups  map[string]SchedulerAgentGroup
+		wantErr bool
+	}{
+		{"valid", map[string]SchedulerAgentGroup{
+			"search":  {Agents: []string{"search-*"}, Weight: 3, RatePerSec: 5, Burst: 10, DailyQuota: 1000},
+			"billing": {Agents: []string{"billing-bot"}},
+		}, false},
+		{"no agents", map[string]SchedulerAgentGroup{"empty": {}}, true},
+		{"inner wildcard", map[string]SchedulerAgentGroup{"g": {Agents: []string{"a*b"}}}, true},
+		{"agent in two groups", map[string]SchedulerAgentGroup{
+			"a": {Agents: []string{"bot"}},
+			"b": {Agents: []string{"bot"}},
+		}, true},
+		{"weight too high", map[string]SchedulerAgentGroup{"g": {Agents: []string{"a"}, Weight: 101}}, true},
+		{"negative rate", map[string]SchedulerAgentGroup{"g": {Agents: []string{"a"}, RatePerSec: -1}}, true},
+		{"negative quota", map[string]SchedulerAgentGroup{"g": {Agents: []string{"a"}, DailyQuota: -1}}, true},
+	}
+
+	for _, tt := range tests {
+		errs := ValidateFileConfig(&FileConfig{Scheduler: SchedulerFileConfig{AgentGroups: tt.groups}})
+		if hasErr := len(errs) > 0; hasErr != tt.wantErr {
+			t.Errorf("%s: got errors=%v, want error=%v", tt.name, errs, tt.wantErr)
+		}
+	}
+}
+
 func TestValidateFileConfig_InvalidAllocationPolicy(t *testing.T) {
 	tests := []struct {
 		policy  string
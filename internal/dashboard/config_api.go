@@ -155,10 +155,16 @@ func (c *ConfigAPI) HandlePutConfig(w http.ResponseWriter, r *http.Request) {
 	}
 
 	normalized := *current
+	if current.InstanceDefaults.Proxy != nil {
+		// The decoder writes through pointers; keep current's proxy intact.
+		p := *current.InstanceDefaults.Proxy
+		normalized.InstanceDefaults.Proxy = &p
+	}
 	if err := json.NewDecoder(bytes.NewReader(body)).Decode(&normalized); err != nil {
 		httpx.ErrorCode(w, 400, "bad_config_json", "invalid config payload", false, nil)
 		return
 	}
+	restoreProxyPassword(&normalized, current.InstanceDefaults.Proxy)
 
 	if errs := config.ValidateFileConfig(&normalized); len(errs) > 0 {
 		messages := make([]string, 0, len(errs))
@@ -251,7 +257,7 @@ func (c *ConfigAPI) currentConfig() (config.FileConfig, string, []string, error)
 
 func (c *ConfigAPI) configEnvelopeFor(cfg config.FileConfig, path string, restartReasons []string) configEnvelope {
 	return configEnvelope{
-		Config:          redactToken(cfg),
+		Config:          redactSecrets(cfg),
 		ConfigPath:      path,
 		TokenConfigured: c.tokenConfigured(cfg),
 		RestartRequired: len(restartReasons) > 0,
@@ -266,11 +272,28 @@ func (c *ConfigAPI) tokenConfigured(cfg config.FileConfig) bool {
 	return strings.TrimSpace(cfg.Server.Token) != ""
 }
 
-func redactToken(cfg config.FileConfig) config.FileConfig {
+// redactSecrets blanks the token and masks the proxy password.
+func redactSecrets(cfg config.FileConfig) config.FileConfig {
 	cfg.Server.Token = ""
+	if cfg.InstanceDefaults.Proxy != nil {
+		p := cfg.InstanceDefaults.Proxy.Redacted()
+		cfg.InstanceDefaults.Proxy = &p
+	}
 	return cfg
 }
 
+// restoreProxyPassword puts back the proxy password when the config sent
+// back still holds the masked URL it was given.
+func restoreProxyPassword(cfg *config.FileConfig, current *config.ProxyConfig) {
+	next := cfg.InstanceDefaults.Proxy
+	if next == nil || current == nil || current.URL == "" {
+		return
+	}
+	if next.URL == current.Redacted().URL {
+		next.URL = current.URL
+	}
+}
+
 func (c *ConfigAPI) restartReasonsFor(next config.FileConfig) []string {
 	reasons := make([]string, 0, 5)
 
@@ -286,6 +309,9 @@ func (c *ConfigAPI) restartReasonsFor(next config.FileConfig) []string {
 	if c.boot.InstanceDefaults.StealthLevel != next.InstanceDefaults.StealthLevel {
 		reasons = append(reasons, "Stealth level")
 	}
+	if !sameProxy(c.boot.InstanceDefaults.Proxy, next.InstanceDefaults.Proxy) {
+		reasons = append(reasons, "Upstream proxy")
+	}
 	if !sameIntPtr(c.boot.MultiInstance.Restart.MaxRestarts, next.MultiInstance.Restart.MaxRestarts) ||
 		!sameIntPtr(c.boot.MultiInstance.Restart.InitBackoffSec, next.MultiInstance.Restart.InitBackoffSec) ||
 		!sameIntPtr(c.boot.MultiInstance.Restart.MaxBackoffSec, next.MultiInstance.Restart.MaxBackoffSec) ||
@@ -296,6 +322,13 @@ func (c *ConfigAPI) restartReasonsFor(next config.FileConfig) []string {
 	return reasons
 }
 
+func sameProxy(a, b *config.ProxyConfig) bool {
+	if a == nil || b == nil {
+		return (a == nil || !a.Active()) && (b == nil || !b.Active())
+	}
+	return *a == *b
+}
+
 func sameIntPtr(a, b *int) bool {
 	if a == nil || b == nil {
 		return a == b
@@ -10,6 +10,8 @@ import (
 	"context"
 	"errors"
 	"fmt"
+
+	"github.com/pinchtab/pinchtab/internal/readability"
 )
 
 // IDPIBlockedError is returned when IDPI security checks block a request.
@@ -97,6 +99,15 @@ type TextResult struct {
 	Engine    string `json:"engine,omitempty"`
 }
 
+// ArticleResult is the main article of a page, as found by readability
+// extraction.
+type ArticleResult struct {
+	readability.Article
+	URL         string `json:"url,omitempty"`
+	Engine      string `json:"engine,omitempty"`
+	IDPIWarning string `json:"idpiWarning,omitempty"`
+}
+
 // ActionResult is the response from a click/type/other action.
 type ActionResult struct {
 	Data   map[string]any `json:"data,omitempty"`
@@ -114,3 +125,9 @@ type Engine interface {
 	Capabilities() []Capability
 	Close() error
 }
+
+// ArticleEngine is implemented by engines that can extract the main article
+// of a page themselves.
+type ArticleEngine interface {
+	Article(ctx context.Context, tabID string) (*ArticleResult, error)
+}
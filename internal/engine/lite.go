@@ -14,6 +14,7 @@ import (
 	"github.com/gost-dom/browser/dom"
 	"github.com/gost-dom/browser/html"
 	gosturl "github.com/gost-dom/browser/url"
+	"github.com/pinchtab/pinchtab/internal/readability"
 	"github.com/pinchtab/pinchtab/internal/urls"
 	nethtml "golang.org/x/net/html"
 )
@@ -181,6 +182,37 @@ func (l *LiteEngine) Text(_ context.Context, tabID string) (*TextResult, error)
 	}, nil
 }
 
+// Article extracts the main article of the page. Script tags are stripped
+// on load, so JSON-LD metadata is not available here; meta tags are.
+func (l *LiteEngine) Article(_ context.Context, tabID string) (*ArticleResult, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	tab, err := l.resolveTab(tabID)
+	if err != nil {
+		return nil, err
+	}
+
+	doc := tab.window.Document()
+	if doc == nil || doc.DocumentElement() == nil {
+		return nil, errors.New("no document")
+	}
+
+	article, err := readability.Extract(doc.DocumentElement().OuterHTML(), tab.url)
+	if err != nil {
+		return nil, err
+	}
+	if article.Title == "" {
+		article.Title = l.getTitle(tab.window)
+	}
+
+	return &ArticleResult{
+		Article: *article,
+		URL:     tab.url,
+		Engine:  "lite",
+	}, nil
+}
+
 // Click clicks an element identified by ref.
 func (l *LiteEngine) Click(ctx context.Context, tabID, ref string) (retErr error) {
 	l.mu.Lock()
@@ -129,6 +129,38 @@ func TestLiteEngine_Text(t *testing.T) {
 	}
 }
 
+func TestLiteEngine_Article(t *testing.T) {
+	ts := newTestServer(`<html><head><title>Notes | Site</title>
+<meta name="author" content="Jane Doe"><meta property="og:image" content="/lead.png"></head>
+<body><nav><a href="/">Home</a></nav>
+<article><h1>Notes</h1>
+<p>The first paragraph is long enough to count as content, with commas, clauses, and a <a href="/more">link</a>.</p>
+<ul><li>one</li><li>two</li></ul></article>
+<footer>Footer text</footer></body></html>`)
+	defer ts.Close()
+
+	lite := NewLiteEngine()
+	defer func() { _ = lite.Close() }()
+
+	_, _ = lite.Navigate(context.Background(), ts.URL)
+
+	result, err := lite.Article(context.Background(), "")
+	if err != nil {
+		t.Fatalf("Article: %v", err)
+	}
+	if result.Title != "Notes" || result.Byline != "Jane Doe" || result.LeadImage != ts.URL+"/lead.png" {
+		t.Errorf("unexpected metadata: %+v", result.Article)
+	}
+	for _, want := range []string{"[link](" + ts.URL + "/more)", "- one\n- two"} {
+		if !strings.Contains(result.Markdown, want) {
+			t.Errorf("markdown should contain %q, got: %s", want, result.Markdown)
+		}
+	}
+	if strings.Contains(result.Markdown, "Home") || strings.Contains(result.Markdown, "Footer") {
+		t.Errorf("markdown should not contain page chrome, got: %s", result.Markdown)
+	}
+}
+
 func TestLiteEngine_Click(t *testing.T) {
 	ts := newTestServer(testPage)
 	defer ts.Close()
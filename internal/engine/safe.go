@@ -107,6 +107,31 @@ func (s *SafeEngine) Text(ctx context.Context, tabID string) (*TextResult, error
 	return result, nil
 }
 
+// Article forwards to the inner engine when it supports article extraction.
+// Like Snapshot, the result is scanned but not wrapped, so the caller can
+// truncate the Markdown before wrapping it.
+func (s *SafeEngine) Article(ctx context.Context, tabID string) (*ArticleResult, error) {
+	inner, ok := s.inner.(ArticleEngine)
+	if !ok {
+		return nil, ErrLiteNotSupported
+	}
+	result, err := inner.Article(ctx, tabID)
+	if err != nil {
+		return nil, err
+	}
+
+	scanResult := s.guard.ScanContent(result.Title + "\n" + result.Markdown)
+	if scanResult.Blocked {
+		return nil, &IDPIBlockedError{Reason: scanResult.Reason}
+	}
+	if scanResult.Threat {
+		result.IDPIWarning = scanResult.Reason
+		slog.Warn("IDPI content warning on article", "engine", result.Engine, "reason", scanResult.Reason)
+	}
+
+	return result, nil
+}
+
 func (s *SafeEngine) Click(ctx context.Context, tabID, ref string) error {
 	return s.inner.Click(ctx, tabID, ref)
 }
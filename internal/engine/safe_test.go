@@ -169,6 +169,18 @@ func TestSafeEngine_Text_WrapContent(t *testing.T) {
 	}
 }
 
+func TestSafeEngine_Article_Unsupported(t *testing.T) {
+	safe := NewSafeEngine(&mockEngine{}, &stubGuard{enabled: true}, true)
+
+	ae, ok := safe.(ArticleEngine)
+	if !ok {
+		t.Fatal("SafeEngine should implement ArticleEngine")
+	}
+	if _, err := ae.Article(context.Background(), ""); err != ErrLiteNotSupported {
+		t.Errorf("expected ErrLiteNotSupported, got: %v", err)
+	}
+}
+
 func TestSafeEngine_Text_NoWrap(t *testing.T) {
 	inner := &mockEngine{textResult: &TextResult{Text: "hello world"}}
 	guard := &stubGuard{enabled: true}
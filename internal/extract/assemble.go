@@ -0,0 +1,189 @@
+package extract
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// numberToken matches the first number in a string such as "$1,299.00".
+var numberToken = regexp.MustCompile(`[-+]?(?:\d[\d,]*(?:\.\d+)?|\.\d+)`)
+
+// Unfilled reports a field that has no value in the result.
+type Unfilled struct {
+	Path     string `json:"path"`
+	Reason   string `json:"reason"`
+	Required bool   `json:"required,omitempty"`
+	Raw      string `json:"raw,omitempty"` // text that was found but failed validation
+}
+
+// Result is the typed record built from the raw page values.
+type Result struct {
+	Data     map[string]any `json:"data"`
+	Unfilled []Unfilled     `json:"unfilled"`
+	Valid    bool           `json:"valid"`
+}
+
+// Assemble coerces raw values to their schema types and validates them.
+// raw holds strings, nested objects and arrays keyed by field name, with
+// nil or missing keys for values that were not found. A result is valid
+// when every required field has a value and no value failed validation.
+func Assemble(fields []Field, raw map[string]any) Result {
+	a := assembler{}
+	data := a.object(fields, raw, "")
+	res := Result{Data: data, Unfilled: a.unfilled, Valid: true}
+	if res.Unfilled == nil {
+		res.Unfilled = []Unfilled{}
+	}
+	for _, u := range res.Unfilled {
+		if u.Required || u.Raw != "" {
+			res.Valid = false
+			break
+		}
+	}
+	return res
+}
+
+type assembler struct {
+	unfilled []Unfilled
+}
+
+func (a *assembler) miss(path string, f Field, reason string) {
+	a.unfilled = append(a.unfilled, Unfilled{Path: path, Reason: reason, Required: f.Required})
+}
+
+func (a *assembler) object(fields []Field, raw map[string]any, prefix string) map[string]any {
+	out := make(map[string]any, len(fields))
+	for _, f := range fields {
+		path := joinPath(prefix, f.Name)
+		v := raw[f.Name]
+		switch f.Schema.Type {
+		case TypeObject:
+			sub, _ := v.(map[string]any)
+			if sub == nil && f.DOM() {
+				a.miss(path, f, f.missingReason())
+				continue
+			}
+			obj := a.object(f.Fields, sub, path)
+			if len(obj) == 0 {
+				if f.Required && !f.DOM() {
+					a.miss(path, f, "none of its properties could be filled")
+				}
+				continue
+			}
+			out[f.Name] = obj
+		case TypeArray:
+			items, ok := v.([]any)
+			if !ok {
+				a.miss(path, f, f.missingReason())
+				continue
+			}
+			out[f.Name] = a.array(f, items, path)
+			if len(items) == 0 && f.Required {
+				a.miss(path, f, f.missingReason())
+			}
+		default:
+			text, _ := v.(string)
+			if strings.TrimSpace(text) == "" {
+				a.miss(path, f, f.missingReason())
+				continue
+			}
+			val, err := coerce(f.Schema, text)
+			if err != nil {
+				a.unfilled = append(a.unfilled, Unfilled{Path: path, Reason: err.Error(), Required: f.Required, Raw: text})
+				continue
+			}
+			out[f.Name] = val
+		}
+	}
+	return out
+}
+
+func (a *assembler) array(f Field, items []any, path string) []any {
+	out := make([]any, 0, len(items))
+	for i, item := range items {
+		at := fmt.Sprintf("%s[%d]", path, i)
+		if f.Schema.Items.Type == TypeObject {
+			sub, _ := item.(map[string]any)
+			out = append(out, a.object(f.Fields, sub, at))
+			continue
+		}
+		text, _ := item.(string)
+		val, err := coerce(f.Schema.Items, text)
+		if err != nil {
+			a.unfilled = append(a.unfilled, Unfilled{Path: at, Reason: err.Error(), Raw: text})
+			continue
+		}
+		out = append(out, val)
+	}
+	return out
+}
+
+// coerce converts page text to the schema type and checks the schema's
+// enum, pattern and range constraints.
+func coerce(s *Schema, text string) (any, error) {
+	text = strings.Join(strings.Fields(text), " ")
+	var v any
+	switch s.Type {
+	case TypeString:
+		v = text
+	case TypeNumber, TypeInteger:
+		tok := numberToken.FindString(text)
+		if tok == "" {
+			return nil, fmt.Errorf("not a number")
+		}
+		n, err := strconv.ParseFloat(strings.ReplaceAll(tok, ",", ""), 64)
+		if err != nil {
+			return nil, fmt.Errorf("not a number")
+		}
+		if s.Type == TypeInteger && n != math.Trunc(n) {
+			return nil, fmt.Errorf("not an integer")
+		}
+		if s.Minimum != nil && n < *s.Minimum {
+			return nil, fmt.Errorf("below minimum %v", *s.Minimum)
+		}
+		if s.Maximum != nil && n > *s.Maximum {
+			return nil, fmt.Errorf("above maximum %v", *s.Maximum)
+		}
+		v = n
+	case TypeBoolean:
+		switch strings.ToLower(text) {
+		case "true", "yes", "on", "1", "checked":
+			v = true
+		case "false", "no", "off", "0":
+			v = false
+		default:
+			return nil, fmt.Errorf("not a boolean")
+		}
+	default:
+		return nil, fmt.Errorf("cannot read %s from text", s.Type)
+	}
+	if s.pattern != nil && !s.pattern.MatchString(text) {
+		return nil, fmt.Errorf("does not match pattern %q", s.Pattern)
+	}
+	if len(s.Enum) > 0 && !slices.ContainsFunc(s.Enum, func(e any) bool { return e == v }) {
+		return nil, fmt.Errorf("not one of the allowed values")
+	}
+	return v, nil
+}
+
+// Strings calls fn for every string in data and replaces it with the
+// result, returning the rewritten value.
+func Strings(data any, fn func(string) string) any {
+	switch v := data.(type) {
+	case string:
+		return fn(v)
+	case map[string]any:
+		for k, item := range v {
+			v[k] = Strings(item, fn)
+		}
+	case []any:
+		for i, item := range v {
+			v[i] = Strings(item, fn)
+		}
+	}
+	return data
+}
@@ -0,0 +1,238 @@
+package extract
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/pinchtab/pinchtab/internal/selector"
+)
+
+const productSchema = `{
+	"type": "object",
+	"required": ["title", "products"],
+	"properties": {
+		"title": {"type": "string"},
+		"seller": {"type": "object", "properties": {"name": {"type": "string", "description": "seller name"}}},
+		"products": {"type": "array", "items": {
+			"type": "object",
+			"required": ["name"],
+			"properties": {
+				"name": {"type": "string"},
+				"price": {"type": "number", "minimum": 0},
+				"inStock": {"type": "boolean"},
+				"sku": {"type": "string", "pattern": "^[A-Z]{3}-\\d+$"}
+			}
+		}}
+	}
+}`
+
+func mustPlan(t *testing.T, schema string, hints map[string]string) []Field {
+	t.Helper()
+	s, err := ParseSchema(json.RawMessage(schema))
+	if err != nil {
+		t.Fatalf("ParseSchema: %v", err)
+	}
+	fields, err := Plan(s, hints)
+	if err != nil {
+		t.Fatalf("Plan: %v", err)
+	}
+	return fields
+}
+
+func TestParseSchemaRejects(t *testing.T) {
+	for name, raw := range map[string]string{
+		"empty":            ``,
+		"non-object root":  `{"type":"string"}`,
+		"no properties":    `{"type":"object"}`,
+		"unknown required": `{"type":"object","properties":{"a":{"type":"string"}},"required":["b"]}`,
+		"array of arrays":  `{"type":"object","properties":{"a":{"type":"array","items":{"type":"array","items":{"type":"string"}}}}}`,
+		"array no items":   `{"type":"object","properties":{"a":{"type":"array"}}}`,
+		"bad type":         `{"type":"object","properties":{"a":{"type":"null"}}}`,
+		"bad pattern":      `{"type":"object","properties":{"a":{"type":"string","pattern":"("}}}`,
+	} {
+		if _, err := ParseSchema(json.RawMessage(raw)); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestPlanHints(t *testing.T) {
+	fields := mustPlan(t, productSchema, map[string]string{
+		"title":          "h1",
+		"products":       "css:.product",
+		"products.name":  "xpath:.//h2",
+		"products.price": ".price@data-value",
+	})
+
+	spec, ok := DOMSpec(fields)
+	if !ok {
+		t.Fatal("expected a DOM spec")
+	}
+	want := `[{"name":"products","kind":"css","sel":".product","many":true,"fields":[{"name":"name","kind":"xpath","sel":".//h2"},{"name":"price","kind":"css","sel":".price","attr":"data-value"}]},{"name":"title","kind":"css","sel":"h1"}]`
+	if string(spec) != want {
+		t.Errorf("spec:\n got %s\nwant %s", spec, want)
+	}
+
+	snap := SnapshotFields(fields)
+	if len(snap) != 1 || snap[0].Path != "seller.name" || snap[0].Query() != "seller name" {
+		t.Errorf("expected seller.name to come from the snapshot, got %+v", snap)
+	}
+}
+
+func TestPlanRejectsBadHints(t *testing.T) {
+	s, err := ParseSchema(json.RawMessage(productSchema))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, hints := range []map[string]string{
+		{"missing": "h1"},
+		{"products": "find:product list"},
+		{"products": ".product", "products.name": "find:name"},
+		{"title": "   "},
+	} {
+		if _, err := Plan(s, hints); err == nil {
+			t.Errorf("expected an error for %v", hints)
+		}
+	}
+}
+
+func TestAssemble(t *testing.T) {
+	fields := mustPlan(t, productSchema, map[string]string{
+		"title":            "h1",
+		"products":         ".product",
+		"products.name":    ".name",
+		"products.price":   ".price",
+		"products.inStock": ".stock",
+		"products.sku":     ".sku",
+	})
+	raw := map[string]any{
+		"title": "  Catalogue\n page ",
+		"products": []any{
+			map[string]any{"name": "Lamp", "price": "$1,299.50", "inStock": "Yes", "sku": "LMP-12"},
+			map[string]any{"name": "Desk", "price": "call us", "inStock": nil, "sku": "desk"},
+		},
+	}
+
+	res := Assemble(fields, raw)
+	if res.Data["title"] != "Catalogue page" {
+		t.Errorf("title = %q", res.Data["title"])
+	}
+	products := res.Data["products"].([]any)
+	first := products[0].(map[string]any)
+	if first["price"] != 1299.5 || first["inStock"] != true || first["sku"] != "LMP-12" {
+		t.Errorf("unexpected first product: %v", first)
+	}
+	if second := products[1].(map[string]any); len(second) != 1 || second["name"] != "Desk" {
+		t.Errorf("expected only the name of the second product, got %v", second)
+	}
+
+	got := map[string]Unfilled{}
+	for _, u := range res.Unfilled {
+		got[u.Path] = u
+	}
+	if u := got["products[1].price"]; u.Raw != "call us" || u.Reason != "not a number" {
+		t.Errorf("price: %+v", u)
+	}
+	if u := got["products[1].sku"]; !strings.Contains(u.Reason, "pattern") {
+		t.Errorf("sku: %+v", u)
+	}
+	if u := got["products[1].inStock"]; u.Reason != "no element matched css:.stock" {
+		t.Errorf("inStock: %+v", u)
+	}
+	if u := got["seller.name"]; u.Required || !strings.Contains(u.Reason, `"seller name"`) {
+		t.Errorf("seller.name: %+v", u)
+	}
+	if res.Valid {
+		t.Error("expected invalid values to make the result invalid")
+	}
+}
+
+func TestAssembleMissingRequired(t *testing.T) {
+	fields := mustPlan(t, productSchema, nil)
+	res := Assemble(fields, map[string]any{"title": "Shop"})
+	if res.Valid {
+		t.Fatal("expected a missing required list to make the result invalid")
+	}
+	for _, u := range res.Unfilled {
+		if u.Path == "products" {
+			if !u.Required || u.Reason != "needs a hint selecting the list elements" {
+				t.Errorf("products: %+v", u)
+			}
+			return
+		}
+	}
+	t.Errorf("products not reported: %+v", res.Unfilled)
+}
+
+func TestCoerce(t *testing.T) {
+	minimum := 1.0
+	tests := []struct {
+		schema Schema
+		in     string
+		want   any
+		err    bool
+	}{
+		{Schema{Type: TypeNumber}, "-3.5 kg", -3.5, false},
+		{Schema{Type: TypeInteger}, "4.0", 4.0, false},
+		{Schema{Type: TypeInteger}, "4.2", nil, true},
+		{Schema{Type: TypeNumber, Minimum: &minimum}, "0", nil, true},
+		{Schema{Type: TypeBoolean}, "Checked", true, false},
+		{Schema{Type: TypeBoolean}, "maybe", nil, true},
+		{Schema{Type: TypeString, Enum: []any{"new", "used"}}, "used", "used", false},
+		{Schema{Type: TypeString, Enum: []any{"new", "used"}}, "broken", nil, true},
+		{Schema{Type: TypeNumber, Enum: []any{1.0, 2.0}}, "2 items", 2.0, false},
+	}
+	for _, tt := range tests {
+		got, err := coerce(&tt.schema, tt.in)
+		if (err != nil) != tt.err || (!tt.err && got != tt.want) {
+			t.Errorf("coerce(%s, %q) = %v, %v", tt.schema.Type, tt.in, got, err)
+		}
+	}
+}
+
+func TestParseHint(t *testing.T) {
+	for in, want := range map[string][2]string{
+		"a.link@href":         {"a.link", "href"},
+		`a[href$="@x"]`:       {`a[href$="@x"]`, ""},
+		"css:img @src":        {"img", "src"},
+		"xpath://a/@href":     {"//a/@href", ""},
+		"find:checkout total": {"checkout total", ""},
+	} {
+		sel, attr := parseHint(in)
+		if sel.Value != want[0] || attr != want[1] {
+			t.Errorf("parseHint(%q) = %q, %q", in, sel.Value, attr)
+		}
+	}
+	if sel, _ := parseHint("e12"); sel.Kind != selector.KindRef {
+		t.Errorf("expected e12 to parse as a ref, got %s", sel.Kind)
+	}
+}
+
+func TestTableNormalize(t *testing.T) {
+	tbl := Table{
+		Kind:    TableHTML,
+		Headers: []string{"Name", "", "Score", "Score"},
+		Rows:    [][]string{{"Ada", "x", "1", "2"}, {"Linus"}, {"Grace", "", "3", "4"}},
+	}
+	tbl.Normalize(2)
+
+	want := []string{"Name", "column 2", "Score", "Score (2)"}
+	if strings.Join(tbl.Headers, "|") != strings.Join(want, "|") {
+		t.Errorf("headers = %q", tbl.Headers)
+	}
+	if tbl.RowCount != 3 || !tbl.Truncated || len(tbl.Rows) != 2 || len(tbl.Rows[1]) != 4 {
+		t.Errorf("unexpected rows: %+v", tbl)
+	}
+	if rec := tbl.Records()[0]; rec["Score (2)"] != "2" || rec["column 2"] != "x" {
+		t.Errorf("unexpected record: %v", rec)
+	}
+
+	var b strings.Builder
+	if err := tbl.WriteCSV(&b); err != nil {
+		t.Fatal(err)
+	}
+	if want := "Name,column 2,Score,Score (2)\nAda,x,1,2\nLinus,,,\n"; b.String() != want {
+		t.Errorf("csv:\n%s", b.String())
+	}
+}
@@ -0,0 +1,244 @@
+package extract
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/pinchtab/pinchtab/internal/selector"
+)
+
+// attrSuffix matches the "@attr" suffix of a CSS hint.
+var attrSuffix = regexp.MustCompile(`@([A-Za-z_:][-A-Za-z0-9_:.]*)$`)
+
+// Field is a schema property together with the hint that locates it.
+type Field struct {
+	Name     string
+	Path     string // dotted path, as used for hints
+	Schema   *Schema
+	Required bool
+	Hint     selector.Selector // zero when the field has no hint
+	Attr     string            // attribute read instead of the element text
+	Scoped   bool              // read relative to an element an ancestor hint selected
+	Fields   []Field           // object properties, or the properties of array items
+}
+
+// DOM reports whether the page script reads the field.
+func (f Field) DOM() bool {
+	switch f.Hint.Kind {
+	case selector.KindCSS, selector.KindXPath, selector.KindText:
+		return true
+	}
+	return false
+}
+
+// Snapshot reports whether the field is read from the accessibility
+// snapshot: a primitive with a find: or ref hint, or an unscoped primitive
+// with no hint, which is matched by its name and description.
+func (f Field) Snapshot() bool {
+	if f.Scoped || f.Schema.Type == TypeObject || f.Schema.Type == TypeArray {
+		return false
+	}
+	return f.Hint.Kind == selector.KindSemantic || f.Hint.Kind == selector.KindRef || f.Hint.IsEmpty()
+}
+
+// Query is the semantic query used for a snapshot field: the find: hint,
+// else the description, title or name of the field.
+func (f Field) Query() string {
+	switch {
+	case f.Hint.Kind == selector.KindSemantic:
+		return f.Hint.Value
+	case f.Schema.Description != "":
+		return f.Schema.Description
+	case f.Schema.Title != "":
+		return f.Schema.Title
+	}
+	return humanize(f.Name)
+}
+
+// missingReason explains why the field has no value.
+func (f Field) missingReason() string {
+	switch {
+	case f.Schema.Type == TypeArray && f.Hint.IsEmpty():
+		return "needs a hint selecting the list elements"
+	case f.Scoped && f.Hint.IsEmpty():
+		return "needs a hint: fields inside a list or hinted object are read relative to its element"
+	case f.DOM():
+		return "no element matched " + f.Hint.String()
+	case f.Hint.Kind == selector.KindRef:
+		return fmt.Sprintf("ref %s is not in the snapshot", f.Hint.Value)
+	case f.Hint.Kind == selector.KindSemantic:
+		return fmt.Sprintf("no snapshot element matched %q", f.Hint.Value)
+	case f.Snapshot():
+		return fmt.Sprintf("no hint given and no snapshot element matched %q", f.Query())
+	}
+	return "no value found"
+}
+
+// Plan pairs each property of the root schema with its hint. Hint keys are
+// dotted property paths; array item properties are addressed through the
+// array, as in "products.price". Every hint must name a property.
+func Plan(root *Schema, hints map[string]string) ([]Field, error) {
+	used := make(map[string]bool, len(hints))
+	fields, err := planObject(root, "", false, hints, used)
+	if err != nil {
+		return nil, err
+	}
+	for path := range hints {
+		if !used[path] {
+			return nil, fmt.Errorf("hint %q does not name a schema property", path)
+		}
+	}
+	return fields, nil
+}
+
+func planObject(s *Schema, parent string, scoped bool, hints map[string]string, used map[string]bool) ([]Field, error) {
+	names := make([]string, 0, len(s.Properties))
+	for name := range s.Properties {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	fields := make([]Field, 0, len(names))
+	for _, name := range names {
+		f := Field{
+			Name:     name,
+			Path:     joinPath(parent, name),
+			Schema:   s.Properties[name],
+			Required: s.required(name),
+			Scoped:   scoped,
+		}
+		if raw, ok := hints[f.Path]; ok {
+			used[f.Path] = true
+			f.Hint, f.Attr = parseHint(raw)
+			if f.Hint.IsEmpty() {
+				return nil, fmt.Errorf("hint %q is empty", f.Path)
+			}
+		}
+		if !f.Hint.IsEmpty() && !f.DOM() && (scoped || f.Schema.Type == TypeObject || f.Schema.Type == TypeArray) {
+			return nil, fmt.Errorf("hint %q: find: and ref hints only apply to unscoped single values", f.Path)
+		}
+
+		var err error
+		switch f.Schema.Type {
+		case TypeObject:
+			f.Fields, err = planObject(f.Schema, f.Path, scoped || f.DOM(), hints, used)
+		case TypeArray:
+			if f.Schema.Items.Type == TypeObject {
+				f.Fields, err = planObject(f.Schema.Items, f.Path, true, hints, used)
+			}
+		}
+		if err != nil {
+			return nil, err
+		}
+		fields = append(fields, f)
+	}
+	return fields, nil
+}
+
+// parseHint parses a hint through the selector package. CSS hints may end
+// in "@attr" to read that attribute instead of the element text.
+func parseHint(raw string) (selector.Selector, string) {
+	sel := selector.Parse(raw)
+	if sel.Kind != selector.KindCSS {
+		return sel, ""
+	}
+	if m := attrSuffix.FindStringSubmatchIndex(sel.Value); m != nil && m[0] > 0 {
+		return selector.Selector{Kind: selector.KindCSS, Value: strings.TrimSpace(sel.Value[:m[0]])}, sel.Value[m[2]:m[3]]
+	}
+	return sel, ""
+}
+
+// specField is one node of the page script input.
+type specField struct {
+	Name   string      `json:"name"`
+	Kind   string      `json:"kind,omitempty"`
+	Sel    string      `json:"sel,omitempty"`
+	Attr   string      `json:"attr,omitempty"`
+	Many   bool        `json:"many,omitempty"`
+	Fields []specField `json:"fields,omitempty"`
+}
+
+// DOMSpec encodes the fields the page script reads. It reports false when
+// no field needs the DOM.
+func DOMSpec(fields []Field) (json.RawMessage, bool) {
+	spec := domSpec(fields)
+	if len(spec) == 0 {
+		return nil, false
+	}
+	data, err := json.Marshal(spec)
+	if err != nil {
+		return nil, false
+	}
+	return data, true
+}
+
+func domSpec(fields []Field) []specField {
+	var out []specField
+	for _, f := range fields {
+		sf := specField{Name: f.Name}
+		if f.DOM() {
+			sf.Kind, sf.Sel, sf.Attr = string(f.Hint.Kind), f.Hint.Value, f.Attr
+			sf.Many = f.Schema.Type == TypeArray
+		}
+		if len(f.Fields) > 0 {
+			sf.Fields = domSpec(f.Fields)
+		}
+		// Unhinted objects are kept only to reach hinted descendants.
+		if sf.Kind == "" && len(sf.Fields) == 0 {
+			continue
+		}
+		out = append(out, sf)
+	}
+	return out
+}
+
+// SnapshotFields lists the fields read from the accessibility snapshot.
+func SnapshotFields(fields []Field) []Field {
+	var out []Field
+	for _, f := range fields {
+		if f.Snapshot() {
+			out = append(out, f)
+		}
+		if f.Schema.Type == TypeObject && !f.Scoped && !f.DOM() {
+			out = append(out, SnapshotFields(f.Fields)...)
+		}
+	}
+	return out
+}
+
+// SetValue stores a value read outside the page script into raw at a
+// dotted path, creating intermediate objects.
+func SetValue(raw map[string]any, path string, v any) {
+	parts := strings.Split(path, ".")
+	for _, p := range parts[:len(parts)-1] {
+		next, ok := raw[p].(map[string]any)
+		if !ok {
+			next = make(map[string]any)
+			raw[p] = next
+		}
+		raw = next
+	}
+	raw[parts[len(parts)-1]] = v
+}
+
+// humanize turns a property name such as "unitPrice" or "unit_price" into
+// "unit price".
+func humanize(name string) string {
+	var b strings.Builder
+	for i, r := range name {
+		switch {
+		case r == '_' || r == '-':
+			b.WriteByte(' ')
+		case unicode.IsUpper(r) && i > 0:
+			b.WriteByte(' ')
+			b.WriteRune(unicode.ToLower(r))
+		default:
+			b.WriteRune(unicode.ToLower(r))
+		}
+	}
+	return b.String()
+}
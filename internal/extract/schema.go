@@ -0,0 +1,135 @@
+// Package extract turns page content into structured data: typed records
+// described by a subset of JSON Schema, and tables of rows.
+//
+// A schema describes the record; hints map field paths to selectors that
+// locate each value on the page:
+//
+//	schema: {"type":"object","properties":{
+//	          "title":    {"type":"string"},
+//	          "products": {"type":"array","items":{"type":"object","properties":{
+//	                         "name":  {"type":"string"},
+//	                         "price": {"type":"number"}}}}}}
+//	hints:  {"title": "h1",
+//	         "products": "css:.product",
+//	         "products.name": ".name",
+//	         "products.price": ".price@data-value"}
+//
+// Hints inside an array or a hinted object are evaluated relative to the
+// element that hint selected.
+package extract
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"slices"
+)
+
+// Schema types supported by the extractor.
+const (
+	TypeString  = "string"
+	TypeNumber  = "number"
+	TypeInteger = "integer"
+	TypeBoolean = "boolean"
+	TypeObject  = "object"
+	TypeArray   = "array"
+)
+
+// maxSchemaDepth bounds how deeply objects and arrays may nest.
+const maxSchemaDepth = 8
+
+// Schema is the JSON Schema subset understood by the extractor. Other
+// keywords are ignored.
+type Schema struct {
+	Type        string             `json:"type"`
+	Title       string             `json:"title,omitempty"`
+	Description string             `json:"description,omitempty"`
+	Properties  map[string]*Schema `json:"properties,omitempty"`
+	Required    []string           `json:"required,omitempty"`
+	Items       *Schema            `json:"items,omitempty"`
+	Enum        []any              `json:"enum,omitempty"`
+	Pattern     string             `json:"pattern,omitempty"`
+	Minimum     *float64           `json:"minimum,omitempty"`
+	Maximum     *float64           `json:"maximum,omitempty"`
+
+	pattern *regexp.Regexp
+}
+
+// ParseSchema decodes and checks a schema. The root must be an object.
+func ParseSchema(raw json.RawMessage) (*Schema, error) {
+	if len(raw) == 0 {
+		return nil, fmt.Errorf("schema is required")
+	}
+	var s Schema
+	if err := json.Unmarshal(raw, &s); err != nil {
+		return nil, fmt.Errorf("schema: %w", err)
+	}
+	if s.Type != TypeObject {
+		return nil, fmt.Errorf("schema: root type must be %q", TypeObject)
+	}
+	if err := s.check("", 0); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+func (s *Schema) check(path string, depth int) error {
+	at := path
+	if at == "" {
+		at = "root"
+	}
+	if depth > maxSchemaDepth {
+		return fmt.Errorf("schema: %s nests deeper than %d levels", at, maxSchemaDepth)
+	}
+	switch s.Type {
+	case TypeString, TypeNumber, TypeInteger, TypeBoolean:
+	case TypeObject:
+		if len(s.Properties) == 0 {
+			return fmt.Errorf("schema: object %s has no properties", at)
+		}
+		for _, name := range s.Required {
+			if _, ok := s.Properties[name]; !ok {
+				return fmt.Errorf("schema: %s requires unknown property %q", at, name)
+			}
+		}
+		for name, p := range s.Properties {
+			if p == nil {
+				return fmt.Errorf("schema: property %q is empty", joinPath(path, name))
+			}
+			if err := p.check(joinPath(path, name), depth+1); err != nil {
+				return err
+			}
+		}
+	case TypeArray:
+		if s.Items == nil {
+			return fmt.Errorf("schema: array %s has no items", at)
+		}
+		if s.Items.Type == TypeArray {
+			return fmt.Errorf("schema: array %s has array items, which are not supported", at)
+		}
+		if err := s.Items.check(path, depth+1); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("schema: %s has unsupported type %q", at, s.Type)
+	}
+	if s.Pattern != "" {
+		re, err := regexp.Compile(s.Pattern)
+		if err != nil {
+			return fmt.Errorf("schema: %s pattern: %w", at, err)
+		}
+		s.pattern = re
+	}
+	return nil
+}
+
+func (s *Schema) required(name string) bool {
+	return slices.Contains(s.Required, name)
+}
+
+func joinPath(parent, name string) string {
+	if parent == "" {
+		return name
+	}
+	return parent + "." + name
+}
@@ -0,0 +1,97 @@
+package extract
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Table kinds found by the page script.
+const (
+	TableHTML = "table" // <table> element
+	TableGrid = "grid"  // ARIA grid, table or treegrid
+	TableList = "list"  // repeated sibling elements
+)
+
+// TableKinds lists the kinds in the order they are reported.
+var TableKinds = []string{TableHTML, TableGrid, TableList}
+
+// Table is a tabular region of the page. Rows are laid out on a grid, so
+// a cell spanning several columns or rows repeats its text in each.
+type Table struct {
+	Kind      string     `json:"kind"`
+	Selector  string     `json:"selector"`
+	Caption   string     `json:"caption,omitempty"`
+	Headers   []string   `json:"headers"`
+	Rows      [][]string `json:"rows"`
+	RowCount  int        `json:"rowCount"`
+	Truncated bool       `json:"truncated,omitempty"`
+}
+
+// Normalize names unnamed and duplicate columns, pads short rows, and
+// keeps at most maxRows rows when maxRows is positive.
+func (t *Table) Normalize(maxRows int) {
+	width := len(t.Headers)
+	for _, r := range t.Rows {
+		width = max(width, len(r))
+	}
+	seen := make(map[string]int, width)
+	headers := make([]string, width)
+	for i := range headers {
+		name := ""
+		if i < len(t.Headers) {
+			name = strings.TrimSpace(t.Headers[i])
+		}
+		if name == "" {
+			name = fmt.Sprintf("column %d", i+1)
+		}
+		seen[name]++
+		if n := seen[name]; n > 1 {
+			name = fmt.Sprintf("%s (%d)", name, n)
+		}
+		headers[i] = name
+	}
+	t.Headers = headers
+
+	for i, r := range t.Rows {
+		if len(r) < width {
+			t.Rows[i] = append(r, make([]string, width-len(r))...)
+		}
+	}
+	t.RowCount = len(t.Rows)
+	if maxRows > 0 && len(t.Rows) > maxRows {
+		t.Rows = t.Rows[:maxRows]
+		t.Truncated = true
+	}
+	if t.Rows == nil {
+		t.Rows = [][]string{}
+	}
+}
+
+// Records returns the rows as objects keyed by column header.
+func (t *Table) Records() []map[string]string {
+	out := make([]map[string]string, len(t.Rows))
+	for i, r := range t.Rows {
+		rec := make(map[string]string, len(t.Headers))
+		for c, h := range t.Headers {
+			if c < len(r) {
+				rec[h] = r[c]
+			}
+		}
+		out[i] = rec
+	}
+	return out
+}
+
+// WriteCSV writes the header row followed by the rows.
+func (t *Table) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(t.Headers); err != nil {
+		return err
+	}
+	if err := cw.WriteAll(t.Rows); err != nil {
+		return err
+	}
+	return cw.Error()
+}
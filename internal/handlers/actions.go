@@ -160,6 +160,7 @@ func (h *Handlers) HandleAction(w http.ResponseWriter, r *http.Request) {
 	// Unified selector resolution: normalize legacy ref/selector fields
 	// into the unified Selector, then resolve to a nodeID when possible.
 	req.NormalizeSelector()
+	givenSelector := req.Selector
 	refMissing := false
 	if !useLiteAction && req.NodeID == 0 && req.Selector != "" {
 		sel := selector.Parse(req.Selector)
@@ -257,6 +258,10 @@ func (h *Handlers) HandleAction(w http.ResponseWriter, r *http.Request) {
 	if !useLiteAction && req.Ref != "" && h.Recovery != nil && !refMissing {
 		h.cacheActionIntent(resolvedTabID, req)
 	}
+	var recordTarget *recordedTarget
+	if !useLiteAction {
+		recordTarget = h.actionTarget(tCtx, resolvedTabID, givenSelector, req)
+	}
 
 	// If ref was not in snapshot cache, attempt semantic recovery before
 	// returning 404. This handles the common case where a page reload
@@ -266,6 +271,12 @@ func (h *Handlers) HandleAction(w http.ResponseWriter, r *http.Request) {
 	var actionErr error
 	var recoveryResult *recovery.RecoveryResult
 
+	if refMissing && req.Ref != "" {
+		if stale, details := h.staleRef(tCtx, resolvedTabID, req.Ref); stale != nil {
+			writeStaleRef(w, stale, details)
+			return
+		}
+	}
 	if refMissing && req.Ref != "" && h.Recovery != nil {
 		rr, actionRes, recoveryErr := h.Recovery.Attempt(
 			tCtx, resolvedTabID, req.Ref, req.Kind,
@@ -293,6 +304,9 @@ func (h *Handlers) HandleAction(w http.ResponseWriter, r *http.Request) {
 				if nid, ok := cache.Refs[req.Ref]; ok {
 					req.NodeID = nid
 					result, engineName, actionErr = h.executeAction(tCtx, req)
+				} else if stale, details := h.staleRef(tCtx, resolvedTabID, req.Ref); stale != nil {
+					writeStaleRef(w, stale, details)
+					return
 				}
 			}
 		}
@@ -339,6 +353,9 @@ func (h *Handlers) HandleAction(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if !useLiteAction {
+		h.recordAction(resolvedTabID, req, recordTarget)
+	}
 	if engineName == "" {
 		engineName = "chrome"
 	}
@@ -398,6 +415,8 @@ type actionResult struct {
 	Success bool           `json:"success"`
 	Result  map[string]any `json:"result,omitempty"`
 	Error   string         `json:"error,omitempty"`
+	Code    string         `json:"code,omitempty"`
+	Details map[string]any `json:"details,omitempty"`
 }
 
 func (h *Handlers) HandleActions(w http.ResponseWriter, r *http.Request) {
@@ -510,6 +529,7 @@ func (h *Handlers) handleActionsBatch(w http.ResponseWriter, r *http.Request, re
 
 		// Unified selector resolution for batch actions.
 		action.NormalizeSelector()
+		givenSelector := action.Selector
 		refMissing := false
 		if !useLiteAction && action.NodeID == 0 && action.Selector != "" {
 			sel := selector.Parse(action.Selector)
@@ -635,10 +655,24 @@ func (h *Handlers) handleActionsBatch(w http.ResponseWriter, r *http.Request, re
 		if !useLiteAction && action.Ref != "" && h.Recovery != nil && !refMissing {
 			h.cacheActionIntent(resolvedTabID, action)
 		}
+		var recordTarget *recordedTarget
+		if !useLiteAction {
+			recordTarget = h.actionTarget(tCtx, resolvedTabID, givenSelector, action)
+		}
 
 		var actionRes map[string]any
 		var err error
 
+		if refMissing {
+			if stale, details := h.staleRef(tCtx, resolvedTabID, action.Ref); stale != nil {
+				tCancel()
+				results = append(results, staleRefResult(i, stale, details))
+				if req.StopOnError {
+					break
+				}
+				continue
+			}
+		}
 		if refMissing && h.Recovery != nil {
 			// Ref not in snapshot cache but we may have a cached intent —
 			// attempt semantic recovery (refresh snapshot + re-match).
@@ -675,6 +709,13 @@ func (h *Handlers) handleActionsBatch(w http.ResponseWriter, r *http.Request, re
 					if nid, ok := cache.Refs[action.Ref]; ok {
 						action.NodeID = nid
 						actionRes, _, err = h.executeAction(tCtx, action)
+					} else if stale, details := h.staleRef(tCtx, resolvedTabID, action.Ref); stale != nil {
+						tCancel()
+						results = append(results, staleRefResult(i, stale, details))
+						if req.StopOnError {
+							break
+						}
+						continue
 					}
 				}
 			}
@@ -707,6 +748,9 @@ func (h *Handlers) handleActionsBatch(w http.ResponseWriter, r *http.Request, re
 				break
 			}
 		} else {
+			if !useLiteAction {
+				h.recordAction(resolvedTabID, action, recordTarget)
+			}
 			results = append(results, actionResult{
 				Index: i, Success: true, Result: actionRes,
 			})
@@ -903,6 +947,16 @@ func (h *Handlers) HandleMacro(w http.ResponseWriter, r *http.Request) {
 		var res map[string]any
 		var err error
 
+		if stepRefMissing {
+			if stale, details := h.staleRef(tCtx, resolvedTabID, step.Ref); stale != nil {
+				cancel()
+				results = append(results, staleRefResult(i, stale, details))
+				if req.StopOnError {
+					break
+				}
+				continue
+			}
+		}
 		if stepRefMissing && h.Recovery != nil {
 			// Ref not in snapshot cache — attempt semantic recovery.
 			rr, recRes, recErr := h.Recovery.Attempt(
@@ -938,6 +992,13 @@ func (h *Handlers) HandleMacro(w http.ResponseWriter, r *http.Request) {
 					if nid, ok := cache.Refs[step.Ref]; ok {
 						step.NodeID = nid
 						res, _, err = h.executeAction(tCtx, step)
+					} else if stale, details := h.staleRef(tCtx, resolvedTabID, step.Ref); stale != nil {
+						cancel()
+						results = append(results, staleRefResult(i, stale, details))
+						if req.StopOnError {
+							break
+						}
+						continue
 					}
 				}
 			}
@@ -1102,6 +1163,7 @@ func (h *Handlers) refreshRefCache(ctx context.Context, tabID string) {
 	if err != nil {
 		return
 	}
-	flat, refs := bridge.BuildSnapshot(nodes, bridge.FilterInteractive, -1)
-	h.Bridge.SetRefCache(tabID, &bridge.RefCache{Refs: refs, Nodes: flat})
+	flat, _ := bridge.BuildSnapshot(nodes, bridge.FilterInteractive, -1)
+	prev := h.Bridge.GetRefCache(tabID)
+	h.Bridge.SetRefCache(tabID, bridge.NewRefCache(prev, flat, bridge.SnapshotCoverage(bridge.FilterInteractive, -1, false)))
 }
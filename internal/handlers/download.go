@@ -236,6 +236,20 @@ func writeDownloadGuardError(w http.ResponseWriter, err error, maxBytes int) boo
 	return true
 }
 
+// instanceProxyAuth returns what answers the instance proxy's auth
+// challenges, or nil when it has no credentials.
+func (h *Handlers) instanceProxyAuth() *bridge.ProxyAuth {
+	pm := h.Bridge.ProxyManager()
+	if pm == nil {
+		return nil
+	}
+	user, pass, ok := pm.Instance().Credentials()
+	if !ok {
+		return nil
+	}
+	return bridge.NewProxyAuth(user, pass)
+}
+
 // HandleDownload fetches a URL using the browser's session (cookies, stealth)
 // and returns the content. This preserves authentication and fingerprint.
 //
@@ -325,9 +339,12 @@ func (h *Handlers) HandleDownload(w http.ResponseWriter, r *http.Request) {
 	var receivedBytes atomic.Int64
 
 	// Intercept every browser-side request so redirects and follow-on navigations
-	// cannot escape the public-only URL policy enforced for /download.
+	// cannot escape the public-only URL policy enforced for /download. The
+	// temporary tab goes through the instance proxy, so it answers that
+	// proxy's auth challenges itself.
+	proxyAuth := h.instanceProxyAuth()
 	if err := chromedp.Run(tCtx, chromedp.ActionFunc(func(ctx context.Context) error {
-		return fetch.Enable().Do(ctx)
+		return fetch.Enable().WithHandleAuthRequests(proxyAuth != nil).Do(ctx)
 	})); err != nil {
 		httpx.Error(w, 500, fmt.Errorf("fetch enable: %w", err))
 		return
@@ -355,6 +372,10 @@ func (h *Handlers) HandleDownload(w http.ResponseWriter, r *http.Request) {
 				}
 				_ = fetch.ContinueRequest(reqID).Do(cdp.WithExecutor(tCtx, chromedp.FromContext(tCtx).Target))
 			}()
+		case *fetch.EventAuthRequired:
+			if proxyAuth != nil {
+				go proxyAuth.Answer(tCtx, e)
+			}
 		case *network.EventRequestWillBeSent:
 			if e.Type != network.ResourceTypeDocument {
 				return
@@ -0,0 +1,221 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/chromedp/chromedp"
+	"github.com/pinchtab/pinchtab/internal/assets"
+	"github.com/pinchtab/pinchtab/internal/bridge"
+	"github.com/pinchtab/pinchtab/internal/extract"
+	"github.com/pinchtab/pinchtab/internal/httpx"
+	"github.com/pinchtab/pinchtab/internal/selector"
+	"github.com/pinchtab/semantic"
+)
+
+type extractRequest struct {
+	TabID     string            `json:"tabId"`
+	Schema    json.RawMessage   `json:"schema"`
+	Hints     map[string]string `json:"hints"`
+	Threshold float64           `json:"threshold"`
+}
+
+// extractInference records which snapshot element filled a field.
+type extractInference struct {
+	Path  string  `json:"path"`
+	Ref   string  `json:"ref"`
+	Role  string  `json:"role,omitempty"`
+	Name  string  `json:"name,omitempty"`
+	Score float64 `json:"score,omitempty"`
+}
+
+// HandleExtract fills a JSON Schema from the current page. CSS, XPath and
+// text: hints are read by a page script; find: and ref hints, and unhinted
+// top-level values, come from the accessibility snapshot. Values are
+// coerced to their schema types and validated, and fields that could not
+// be filled are listed with the reason.
+//
+// @Endpoint POST /extract
+// @Endpoint POST /tabs/{id}/extract
+func (h *Handlers) HandleExtract(w http.ResponseWriter, r *http.Request) {
+	if err := h.ensureChrome(); err != nil {
+		if h.writeBridgeUnavailable(w, err) {
+			return
+		}
+		httpx.Error(w, 500, fmt.Errorf("chrome initialization: %w", err))
+		return
+	}
+
+	var req extractRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBodySize)).Decode(&req); err != nil {
+		httpx.Error(w, 400, fmt.Errorf("decode: %w", err))
+		return
+	}
+	if pathID := r.PathValue("id"); pathID != "" {
+		req.TabID = pathID
+	}
+	if req.Threshold <= 0 {
+		req.Threshold = 0.3
+	}
+
+	schema, err := extract.ParseSchema(req.Schema)
+	if err != nil {
+		httpx.ErrorCode(w, 400, "bad_schema", err.Error(), false, nil)
+		return
+	}
+	fields, err := extract.Plan(schema, req.Hints)
+	if err != nil {
+		httpx.ErrorCode(w, 400, "bad_schema", err.Error(), false, nil)
+		return
+	}
+
+	h.recordReadRequest(r, "extract", req.TabID)
+	ctx, resolvedTabID, err := h.tabContextWithHeader(w, r, req.TabID)
+	if err != nil {
+		httpx.Error(w, 404, err)
+		return
+	}
+	if _, ok := h.enforceCurrentTabDomainPolicy(w, r, ctx, resolvedTabID); !ok {
+		return
+	}
+
+	tCtx, tCancel := context.WithTimeout(ctx, h.Config.ActionTimeout)
+	defer tCancel()
+	go httpx.CancelOnClientDone(r.Context(), tCancel)
+
+	raw := map[string]any{}
+	if spec, ok := extract.DOMSpec(fields); ok {
+		js := fmt.Sprintf("(%s)(%s)", strings.TrimSpace(assets.ExtractJS), spec)
+		if err := chromedp.Run(tCtx, chromedp.Evaluate(js, &raw)); err != nil {
+			httpx.Error(w, 500, fmt.Errorf("extract: %w", err))
+			return
+		}
+	}
+	inferred := h.extractFromSnapshot(tCtx, resolvedTabID, extract.SnapshotFields(fields), req.Threshold, raw)
+
+	var url, title string
+	_ = chromedp.Run(tCtx,
+		chromedp.Location(&url),
+		chromedp.Title(&title),
+	)
+	h.recordResolvedURL(r, url)
+
+	result := extract.Assemble(fields, raw)
+
+	// IDPI: scan every extracted string, as SafeEngine does for page text.
+	var scanned strings.Builder
+	extract.Strings(result.Data, func(s string) string {
+		scanned.WriteString(s)
+		scanned.WriteByte('\n')
+		return s
+	})
+	idpiResult := h.IDPIGuard.ScanContent(scanned.String())
+	if idpiResult.Blocked {
+		httpx.Error(w, http.StatusForbidden,
+			fmt.Errorf("content blocked by IDPI scanner: %s", idpiResult.Reason))
+		return
+	}
+	if idpiResult.Threat {
+		w.Header().Set("X-IDPI-Warning", idpiResult.Reason)
+		if idpiResult.Pattern != "" {
+			w.Header().Set("X-IDPI-Pattern", idpiResult.Pattern)
+		}
+	}
+	// Wrapping happens after validation so patterns and enums see page text.
+	if h.Config.IDPI.Enabled && h.Config.IDPI.WrapContent {
+		extract.Strings(result.Data, func(s string) string {
+			return h.IDPIGuard.WrapContent(s, url)
+		})
+	}
+
+	resp := map[string]any{
+		"tabId":    resolvedTabID,
+		"url":      url,
+		"title":    title,
+		"data":     result.Data,
+		"valid":    result.Valid,
+		"unfilled": result.Unfilled,
+	}
+	if len(inferred) > 0 {
+		resp["inferred"] = inferred
+	}
+	if idpiResult.Threat {
+		resp["idpiWarning"] = idpiResult.Reason
+	}
+	httpx.JSON(w, 200, resp)
+}
+
+// extractFromSnapshot fills snapshot fields into raw. Ref hints use the
+// tab's cached snapshot, so they match the refs the caller last saw; find:
+// hints and unhinted fields are matched against the full accessibility
+// tree. Fields filled without a hint are reported as inferred.
+func (h *Handlers) extractFromSnapshot(ctx context.Context, tabID string, fields []extract.Field, threshold float64, raw map[string]any) []extractInference {
+	if len(fields) == 0 {
+		return nil
+	}
+	var full []bridge.A11yNode
+	var descs []semantic.ElementDescriptor
+	var inferred []extractInference
+
+	for _, f := range fields {
+		if f.Hint.Kind == selector.KindRef {
+			for _, n := range h.resolveSnapshotNodes(tabID) {
+				if n.Ref == f.Hint.Value {
+					extract.SetValue(raw, f.Path, nodeText(n))
+					break
+				}
+			}
+			continue
+		}
+
+		if descs == nil {
+			full = h.fullSnapshotNodes(ctx, tabID)
+			descs = make([]semantic.ElementDescriptor, len(full))
+			for i, n := range full {
+				descs[i] = semantic.ElementDescriptor{Ref: n.Ref, Role: n.Role, Name: n.Name, Value: n.Value}
+			}
+		}
+		if h.Matcher == nil || len(full) == 0 {
+			continue
+		}
+		result, err := h.Matcher.Find(ctx, f.Query(), descs, semantic.FindOptions{Threshold: threshold, TopK: 1})
+		if err != nil || result.BestRef == "" {
+			continue
+		}
+		for _, n := range full {
+			if n.Ref != result.BestRef {
+				continue
+			}
+			extract.SetValue(raw, f.Path, nodeText(n))
+			if f.Hint.IsEmpty() {
+				inferred = append(inferred, extractInference{Path: f.Path, Ref: n.Ref, Role: n.Role, Name: n.Name, Score: result.BestScore})
+			}
+			break
+		}
+	}
+	return inferred
+}
+
+// fullSnapshotNodes returns every node of the tab's accessibility tree,
+// falling back to the cached snapshot when the tree cannot be fetched.
+// The ref cache is left alone so the caller's refs stay valid.
+func (h *Handlers) fullSnapshotNodes(ctx context.Context, tabID string) []bridge.A11yNode {
+	if raw, err := bridge.FetchAXTree(ctx); err == nil {
+		if flat, _ := bridge.BuildSnapshot(raw, "", -1); len(flat) > 0 {
+			return flat
+		}
+	}
+	return h.resolveSnapshotNodes(tabID)
+}
+
+// nodeText is the text a snapshot node contributes to an extracted field:
+// its value for form controls, otherwise its accessible name.
+func nodeText(n bridge.A11yNode) string {
+	if n.Value != "" {
+		return n.Value
+	}
+	return n.Name
+}
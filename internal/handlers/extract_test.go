@@ -0,0 +1,61 @@
+package handlers
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/pinchtab/pinchtab/internal/bridge"
+)
+
+func TestHandleExtractFromSnapshot(t *testing.T) {
+	_, _, mux := newRecordTestHandler(
+		bridge.A11yNode{Ref: "e1", Role: "textbox", Name: "Email", Value: "user@pinchtab.com"},
+		bridge.A11yNode{Ref: "e2", Role: "heading", Name: "Order total 42.50"},
+	)
+	w := doJSON(t, mux, "POST", "/tabs/tab1/extract", map[string]any{
+		"schema": map[string]any{
+			"type":     "object",
+			"required": []string{"email", "missing"},
+			"properties": map[string]any{
+				"email":   map[string]any{"type": "string"},
+				"total":   map[string]any{"type": "number"},
+				"missing": map[string]any{"type": "string"},
+			},
+		},
+		"hints": map[string]string{"email": "e1", "total": "find:order total", "missing": "e9"},
+	})
+	if w.Code != 200 {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp struct {
+		Data     map[string]any `json:"data"`
+		Valid    bool           `json:"valid"`
+		Unfilled []struct {
+			Path     string `json:"path"`
+			Required bool   `json:"required"`
+		} `json:"unfilled"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if resp.Data["email"] != "user@pinchtab.com" || resp.Data["total"] != 42.5 {
+		t.Errorf("unexpected data: %v", resp.Data)
+	}
+	if resp.Valid || len(resp.Unfilled) != 1 || resp.Unfilled[0].Path != "missing" || !resp.Unfilled[0].Required {
+		t.Errorf("expected only the missing required field to be reported, got %+v", resp)
+	}
+}
+
+func TestHandleExtractRejectsBadSchemas(t *testing.T) {
+	_, _, mux := newRecordTestHandler()
+	for _, body := range []map[string]any{
+		{},
+		{"schema": map[string]any{"type": "array", "items": map[string]any{"type": "string"}}},
+		{"schema": map[string]any{"type": "object", "properties": map[string]any{"a": map[string]any{"type": "string"}}},
+			"hints": map[string]string{"b": "h1"}},
+	} {
+		if w := doJSON(t, mux, "POST", "/extract", body); w.Code != 400 {
+			t.Errorf("expected 400 for %v, got %d", body, w.Code)
+		}
+	}
+}
@@ -48,6 +48,8 @@ func (m *findMockBridge) AvailableActions() []string                       { ret
 func (m *findMockBridge) TabLockInfo(tabID string) *bridge.LockInfo        { return nil }
 func (m *findMockBridge) GetCrashLogs() []string                           { return nil }
 func (m *findMockBridge) NetworkMonitor() *bridge.NetworkMonitor           { return nil }
+func (m *findMockBridge) NetworkEmulator() *bridge.NetworkEmulator         { return nil }
+func (m *findMockBridge) ProxyManager() *bridge.ProxyManager               { return nil }
 
 func (m *findMockBridge) ExecuteAction(ctx context.Context, kind string, req bridge.ActionRequest) (map[string]any, error) {
 	return nil, nil
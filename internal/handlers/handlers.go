@@ -34,9 +34,13 @@ type Handlers struct {
 	IDPIGuard    idpi.Guard
 	Version      string // build version injected at startup
 	clipboard    clipboardStore
+	recordings   recordingStore
+	intercepts   interceptStore
 
 	// Optional dependency injection (for unit testing)
-	evalJS func(ctx context.Context, expression string, out *string) error
+	evalJS          func(ctx context.Context, expression string, out *string) error
+	interceptAttach func(ctx context.Context, tabID string, ic *bridge.Interceptor) error
+	networkEmulate  func(ctx context.Context, c config.NetworkConditions) error
 }
 
 func New(b bridge.BridgeAPI, cfg *config.RuntimeConfig, p bridge.ProfileService, d *dashboard.Dashboard, o bridge.OrchestratorService) *Handlers {
@@ -96,12 +100,20 @@ func New(b bridge.BridgeAPI, cfg *config.RuntimeConfig, p bridge.ProfileService,
 		return chromedp.Run(ctx, chromedp.Evaluate(expression, out))
 	}
 
+	if hooks, ok := b.(tabSetupHookRegistrar); ok {
+		hooks.AddTabSetupHook(h.setupProxyAuth)
+	}
+
 	// Clean up .tmp export files orphaned by a previous crash.
 	go CleanupStaleTmpExports(cfg.StateDir)
 
 	return h
 }
 
+type tabSetupHookRegistrar interface {
+	AddTabSetupHook(bridge.TabSetupHook)
+}
+
 type restartStatusProvider interface {
 	RestartStatus() (bool, time.Duration)
 }
@@ -151,6 +163,7 @@ func (h *Handlers) RegisterRoutes(mux *http.ServeMux, doShutdown func()) {
 	mux.HandleFunc("POST /tabs/{id}/forward", h.HandleTabForward)
 	mux.HandleFunc("POST /tabs/{id}/reload", h.HandleTabReload)
 	mux.HandleFunc("GET /tabs/{id}/snapshot", h.HandleTabSnapshot)
+	mux.HandleFunc("GET /tabs/{id}/snapshot/stream", h.HandleTabSnapshotStream)
 	mux.HandleFunc("GET /tabs/{id}/screenshot", h.HandleTabScreenshot)
 	mux.HandleFunc("POST /tabs/{id}/action", h.HandleTabAction)
 	mux.HandleFunc("POST /tabs/{id}/actions", h.HandleTabActions)
@@ -158,6 +171,7 @@ func (h *Handlers) RegisterRoutes(mux *http.ServeMux, doShutdown func()) {
 	mux.HandleFunc("GET /tabs/{id}/metrics", h.HandleTabMetrics)
 	mux.HandleFunc("GET /metrics", h.HandleMetrics)
 	mux.HandleFunc("GET /snapshot", h.HandleSnapshot)
+	mux.HandleFunc("GET /snapshot/stream", h.HandleSnapshotStream)
 	mux.HandleFunc("GET /screenshot", h.HandleScreenshot)
 	mux.HandleFunc("GET /tabs/{id}/pdf", h.HandleTabPDF)
 	mux.HandleFunc("POST /tabs/{id}/pdf", h.HandleTabPDF)
@@ -198,6 +212,17 @@ func (h *Handlers) RegisterRoutes(mux *http.ServeMux, doShutdown func()) {
 	mux.HandleFunc("POST /upload", h.HandleUpload)
 	mux.HandleFunc("POST /tabs/{id}/find", h.HandleFind)
 	mux.HandleFunc("POST /find", h.HandleFind)
+	mux.HandleFunc("POST /tabs/{id}/extract", h.HandleExtract)
+	mux.HandleFunc("POST /extract", h.HandleExtract)
+	mux.HandleFunc("GET /tabs/{id}/tables", h.HandleTabTables)
+	mux.HandleFunc("GET /tables", h.HandleTables)
+	mux.HandleFunc("POST /tabs/{id}/visual/baseline", h.HandleVisualBaseline)
+	mux.HandleFunc("POST /visual/baseline", h.HandleVisualBaseline)
+	mux.HandleFunc("POST /tabs/{id}/visual/compare", h.HandleVisualCompare)
+	mux.HandleFunc("POST /visual/compare", h.HandleVisualCompare)
+	mux.HandleFunc("GET /visual/baselines", h.HandleVisualBaselines)
+	mux.HandleFunc("GET /visual/baseline", h.HandleVisualBaselineImage)
+	mux.HandleFunc("DELETE /visual/baseline", h.HandleVisualBaselineDelete)
 	mux.HandleFunc("GET /screencast", h.HandleScreencast)
 	mux.HandleFunc("GET /screencast/tabs", h.HandleScreencastAll)
 	mux.HandleFunc("POST /tabs/{id}/evaluate", h.HandleTabEvaluate)
@@ -221,6 +246,43 @@ func (h *Handlers) RegisterRoutes(mux *http.ServeMux, doShutdown func()) {
 	mux.HandleFunc("POST /tabs/{id}/dialog", h.HandleTabDialog)
 	mux.HandleFunc("POST /wait", h.HandleWait)
 	mux.HandleFunc("POST /tabs/{id}/wait", h.HandleTabWait)
+	mux.HandleFunc("GET /network/conditions", h.HandleNetworkConditions)
+	mux.HandleFunc("GET /tabs/{id}/network/conditions", h.HandleNetworkConditions)
+	mux.HandleFunc("POST /network/conditions", h.HandleNetworkConditionsSet)
+	mux.HandleFunc("POST /tabs/{id}/network/conditions", h.HandleNetworkConditionsSet)
+	mux.HandleFunc("DELETE /network/conditions", h.HandleNetworkConditionsReset)
+	mux.HandleFunc("DELETE /tabs/{id}/network/conditions", h.HandleNetworkConditionsReset)
+	mux.HandleFunc("GET /network/proxy", h.HandleProxy)
+	mux.HandleFunc("GET /tabs/{id}/network/proxy", h.HandleProxy)
+	mux.HandleFunc("POST /network/proxy/check", h.HandleProxyCheck)
+	mux.HandleFunc("POST /tabs/{id}/network/proxy/check", h.HandleProxyCheck)
+	mux.HandleFunc("GET /network/replay", h.HandleNetworkReplayStatus)
+	mux.HandleFunc("GET /tabs/{id}/network/replay", h.HandleNetworkReplayStatus)
+	mux.HandleFunc("POST /network/replay", h.HandleNetworkReplay)
+	mux.HandleFunc("POST /tabs/{id}/network/replay", h.HandleNetworkReplay)
+	mux.HandleFunc("DELETE /network/replay", h.HandleNetworkReplayStop)
+	mux.HandleFunc("DELETE /tabs/{id}/network/replay", h.HandleNetworkReplayStop)
+	mux.HandleFunc("GET /intercept", h.HandleIntercepts)
+	mux.HandleFunc("GET /tabs/{id}/intercept", h.HandleIntercepts)
+	mux.HandleFunc("POST /intercept", h.HandleInterceptAdd)
+	mux.HandleFunc("POST /tabs/{id}/intercept", h.HandleInterceptAdd)
+	mux.HandleFunc("DELETE /intercept", h.HandleInterceptClear)
+	mux.HandleFunc("DELETE /tabs/{id}/intercept", h.HandleInterceptClear)
+	mux.HandleFunc("GET /intercept/{ruleId}", h.HandleInterceptRule)
+	mux.HandleFunc("GET /tabs/{id}/intercept/{ruleId}", h.HandleInterceptRule)
+	mux.HandleFunc("PUT /intercept/{ruleId}", h.HandleInterceptUpdate)
+	mux.HandleFunc("PUT /tabs/{id}/intercept/{ruleId}", h.HandleInterceptUpdate)
+	mux.HandleFunc("DELETE /intercept/{ruleId}", h.HandleInterceptDelete)
+	mux.HandleFunc("DELETE /tabs/{id}/intercept/{ruleId}", h.HandleInterceptDelete)
+
+	mux.HandleFunc("POST /record/start", h.HandleRecordStart)
+	mux.HandleFunc("POST /tabs/{id}/record/start", h.HandleRecordStart)
+	mux.HandleFunc("POST /record/stop", h.HandleRecordStop)
+	mux.HandleFunc("POST /tabs/{id}/record/stop", h.HandleRecordStop)
+	mux.HandleFunc("GET /record", h.HandleRecord)
+	mux.HandleFunc("GET /tabs/{id}/record", h.HandleRecord)
+	mux.HandleFunc("POST /replay", h.HandleReplay)
+	mux.HandleFunc("POST /tabs/{id}/replay", h.HandleReplay)
 	mux.HandleFunc("GET /console", h.HandleGetConsoleLogs)
 	mux.HandleFunc("POST /console/clear", h.HandleClearConsoleLogs)
 	mux.HandleFunc("GET /errors", h.HandleGetErrorLogs)
@@ -102,6 +102,14 @@ func (m *mockBridge) NetworkMonitor() *bridge.NetworkMonitor {
 	return nil
 }
 
+func (m *mockBridge) NetworkEmulator() *bridge.NetworkEmulator {
+	return nil
+}
+
+func (m *mockBridge) ProxyManager() *bridge.ProxyManager {
+	return nil
+}
+
 func (m *mockBridge) GetDialogManager() *bridge.DialogManager {
 	return bridge.NewDialogManager()
 }
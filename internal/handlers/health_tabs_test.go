@@ -378,6 +378,10 @@ func (m *MockBridge) CreateTab(url string) (string, context.Context, context.Can
 	return "", context.Background(), func() {}, nil
 }
 
+func (m *MockBridge) CreateTabWithProxy(url string, proxy config.ProxyConfig) (string, context.Context, context.CancelFunc, error) {
+	return "", context.Background(), func() {}, nil
+}
+
 func (m *MockBridge) CloseTab(tabID string) error {
 	return nil
 }
@@ -466,6 +470,14 @@ func (m *MockBridge) NetworkMonitor() *bridge.NetworkMonitor {
 	return nil
 }
 
+func (m *MockBridge) NetworkEmulator() *bridge.NetworkEmulator {
+	return nil
+}
+
+func (m *MockBridge) ProxyManager() *bridge.ProxyManager {
+	return nil
+}
+
 func (m *MockBridge) GetDialogManager() *bridge.DialogManager {
 	return bridge.NewDialogManager()
 }
@@ -0,0 +1,318 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+
+	"github.com/pinchtab/pinchtab/internal/bridge"
+	"github.com/pinchtab/pinchtab/internal/httpx"
+)
+
+// interceptStore holds the request interceptors, keyed by tab ID, and the
+// archive new tabs replay when one is loaded for the whole instance.
+type interceptStore struct {
+	mu     sync.Mutex
+	tabs   map[string]*bridge.Interceptor
+	replay *bridge.HARReplay
+}
+
+func (s *interceptStore) get(tabID string) *bridge.Interceptor {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.tabs[tabID]
+}
+
+// getOrCreate returns the tab's interceptor, calling init on a new one
+// before it is stored.
+func (s *interceptStore) getOrCreate(tabID string, init func(*bridge.Interceptor)) *bridge.Interceptor {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if ic, ok := s.tabs[tabID]; ok {
+		return ic
+	}
+	if s.tabs == nil {
+		s.tabs = make(map[string]*bridge.Interceptor)
+	}
+	ic := bridge.NewInterceptor()
+	init(ic)
+	s.tabs[tabID] = ic
+	return ic
+}
+
+// drop forgets the tab's interceptor if it is still ic.
+func (s *interceptStore) drop(tabID string, ic *bridge.Interceptor) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.tabs[tabID] == ic {
+		delete(s.tabs, tabID)
+	}
+}
+
+// interceptRequest is the body of a rule create or update.
+type interceptRequest struct {
+	TabID string `json:"tabId"`
+	bridge.InterceptRule
+}
+
+// interceptor returns the tab's interceptor, creating one whose hits are
+// marked in the tab's network log.
+func (h *Handlers) interceptor(tabID string) *bridge.Interceptor {
+	return h.intercepts.getOrCreate(tabID, func(ic *bridge.Interceptor) {
+		ic.OnHit = func(requestID, ruleID, action string) {
+			nm := h.Bridge.NetworkMonitor()
+			if nm == nil {
+				return
+			}
+			if buf := nm.GetBuffer(tabID); buf != nil {
+				buf.Tag(requestID, ruleID, action)
+			}
+		}
+	})
+}
+
+// attachInterceptor starts network capture for the tab, so rule hits have
+// a log to show up in, and starts the interceptor.
+func (h *Handlers) attachInterceptor(tabCtx context.Context, tabID string, ic *bridge.Interceptor) error {
+	if h.interceptAttach != nil {
+		return h.interceptAttach(tabCtx, tabID, ic)
+	}
+	if ic.Attached() {
+		return nil
+	}
+	if nm := h.Bridge.NetworkMonitor(); nm != nil && nm.GetBuffer(tabID) == nil {
+		if err := nm.StartCapture(tabCtx, tabID); err != nil {
+			return fmt.Errorf("start network capture: %w", err)
+		}
+	}
+	if err := ic.Attach(tabCtx); err != nil {
+		return err
+	}
+	context.AfterFunc(tabCtx, func() { h.intercepts.drop(tabID, ic) })
+	return nil
+}
+
+// navigatePage navigates an existing tab. A tab with interception rules
+// owns the Fetch domain, so its interceptor enforces the redirect limit in
+// place of the Fetch session navigation would otherwise open.
+func (h *Handlers) navigatePage(ctx context.Context, tabID, url string) error {
+	maxRedirects := h.Config.MaxRedirects
+	ic := h.intercepts.get(tabID)
+	if ic == nil || !ic.Attached() || maxRedirects < 0 {
+		return bridge.NavigatePageWithRedirectLimit(ctx, url, maxRedirects)
+	}
+	done := ic.LimitRedirects(maxRedirects)
+	err := bridge.NavigatePageWithRedirectLimit(ctx, url, -1)
+	if count, blocked := done(); blocked {
+		return fmt.Errorf("%w: got %d, max %d", bridge.ErrTooManyRedirects, count, maxRedirects)
+	}
+	return err
+}
+
+// requestTab resolves the tab an interception, replay or network emulation
+// request refers to: the path ID, then the tabId query parameter, then the
+// body's tabId.
+func (h *Handlers) requestTab(w http.ResponseWriter, r *http.Request, bodyTabID string) (context.Context, string, bool) {
+	tabID := r.PathValue("id")
+	if tabID == "" {
+		tabID = r.URL.Query().Get("tabId")
+	}
+	if tabID == "" {
+		tabID = bodyTabID
+	}
+	ctx, resolvedTabID, err := h.tabContext(r, tabID)
+	if err != nil {
+		httpx.Error(w, 404, err)
+		return nil, "", false
+	}
+	return ctx, resolvedTabID, true
+}
+
+func decodeInterceptRequest(w http.ResponseWriter, r *http.Request) (interceptRequest, bool) {
+	var req interceptRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBodySize)).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		httpx.Error(w, 400, fmt.Errorf("decode: %w", err))
+		return req, false
+	}
+	return req, true
+}
+
+func writeRuleNotFound(w http.ResponseWriter, tabID, ruleID string) {
+	httpx.ErrorCode(w, 404, "rule_not_found", fmt.Sprintf("tab %s has no interception rule %s", tabID, ruleID), false, nil)
+}
+
+// HandleIntercepts lists a tab's interception rules with their hit counts.
+//
+// @Endpoint GET /intercept
+// @Endpoint GET /tabs/{id}/intercept
+// @Description Lists the tab's request interception rules in match order
+//
+// @Param tabId string query Tab ID (optional, uses current tab if empty)
+//
+// @Response 200 application/json Rules with hit counts
+// @Response 404 application/json Tab not found
+func (h *Handlers) HandleIntercepts(w http.ResponseWriter, r *http.Request) {
+	_, tabID, ok := h.requestTab(w, r, "")
+	if !ok {
+		return
+	}
+	rules := []bridge.InterceptRule{}
+	active := false
+	if ic := h.intercepts.get(tabID); ic != nil {
+		rules = ic.Rules()
+		active = ic.Attached()
+	}
+	httpx.JSON(w, 200, map[string]any{"tabId": tabID, "rules": rules, "count": len(rules), "active": active})
+}
+
+// HandleInterceptAdd adds an interception rule to a tab and starts
+// intercepting its requests.
+//
+// @Endpoint POST /intercept
+// @Endpoint POST /tabs/{id}/intercept
+// @Description Adds a rule that fulfills, modifies, rewrites, fails or delays matching requests
+//
+// @Param tabId string body Tab ID (optional, uses current tab if empty)
+// @Param url string body URL glob, * and ? wildcards (url or urlRegex required)
+// @Param urlRegex string body URL regular expression
+// @Param method string body HTTP method to match (optional)
+// @Param resourceType string body Resource type to match e.g. "XHR", "Fetch", "Document" (optional)
+// @Param action string body fulfill, modify, rewrite, fail or delay (required)
+// @Param status int body Status for fulfill (default: 200)
+// @Param headers object body Response headers for fulfill, request headers for modify
+// @Param body string body Response body for fulfill, request body for modify
+// @Param bodyBase64 string body Base64 body, instead of body
+// @Param rewriteUrl string body Target URL for rewrite; may use $1 groups from urlRegex
+// @Param errorReason string body Net error for fail e.g. "ConnectionRefused" (default: "Failed")
+// @Param delayMs int body Delay before the action in milliseconds
+// @Param times int body Stop matching after this many hits (optional)
+//
+// @Response 201 application/json The created rule
+// @Response 400 application/json Invalid rule
+// @Response 404 application/json Tab not found
+func (h *Handlers) HandleInterceptAdd(w http.ResponseWriter, r *http.Request) {
+	req, ok := decodeInterceptRequest(w, r)
+	if !ok {
+		return
+	}
+	if err := h.ensureChrome(); err != nil {
+		if h.writeBridgeUnavailable(w, err) {
+			return
+		}
+		httpx.Error(w, 500, fmt.Errorf("chrome initialization: %w", err))
+		return
+	}
+	tabCtx, tabID, ok := h.requestTab(w, r, req.TabID)
+	if !ok {
+		return
+	}
+
+	ic := h.interceptor(tabID)
+	rule, err := ic.Add(req.InterceptRule)
+	if err != nil {
+		httpx.ErrorCode(w, 400, "invalid_rule", err.Error(), false, nil)
+		return
+	}
+	if err := h.attachInterceptor(tabCtx, tabID, ic); err != nil {
+		ic.Remove(rule.ID)
+		httpx.Error(w, 500, fmt.Errorf("intercept: %w", err))
+		return
+	}
+	httpx.JSON(w, 201, map[string]any{"tabId": tabID, "rule": rule})
+}
+
+// HandleInterceptRule returns one interception rule.
+//
+// @Endpoint GET /intercept/{ruleId}
+// @Endpoint GET /tabs/{id}/intercept/{ruleId}
+func (h *Handlers) HandleInterceptRule(w http.ResponseWriter, r *http.Request) {
+	_, tabID, ok := h.requestTab(w, r, "")
+	if !ok {
+		return
+	}
+	ruleID := r.PathValue("ruleId")
+	if ic := h.intercepts.get(tabID); ic != nil {
+		if rule, ok := ic.Rule(ruleID); ok {
+			httpx.JSON(w, 200, map[string]any{"tabId": tabID, "rule": rule})
+			return
+		}
+	}
+	writeRuleNotFound(w, tabID, ruleID)
+}
+
+// HandleInterceptUpdate replaces an interception rule. The rule keeps its
+// ID, its place in the match order and its hit count.
+//
+// @Endpoint PUT /intercept/{ruleId}
+// @Endpoint PUT /tabs/{id}/intercept/{ruleId}
+func (h *Handlers) HandleInterceptUpdate(w http.ResponseWriter, r *http.Request) {
+	req, ok := decodeInterceptRequest(w, r)
+	if !ok {
+		return
+	}
+	_, tabID, ok := h.requestTab(w, r, req.TabID)
+	if !ok {
+		return
+	}
+	ruleID := r.PathValue("ruleId")
+	ic := h.intercepts.get(tabID)
+	if ic == nil {
+		writeRuleNotFound(w, tabID, ruleID)
+		return
+	}
+	rule, found, err := ic.Update(ruleID, req.InterceptRule)
+	switch {
+	case err != nil:
+		httpx.ErrorCode(w, 400, "invalid_rule", err.Error(), false, nil)
+	case !found:
+		writeRuleNotFound(w, tabID, ruleID)
+	default:
+		httpx.JSON(w, 200, map[string]any{"tabId": tabID, "rule": rule})
+	}
+}
+
+// HandleInterceptDelete removes an interception rule. Removing the last
+// rule stops intercepting the tab's requests unless a replay is loaded.
+//
+// @Endpoint DELETE /intercept/{ruleId}
+// @Endpoint DELETE /tabs/{id}/intercept/{ruleId}
+func (h *Handlers) HandleInterceptDelete(w http.ResponseWriter, r *http.Request) {
+	tabCtx, tabID, ok := h.requestTab(w, r, "")
+	if !ok {
+		return
+	}
+	ruleID := r.PathValue("ruleId")
+	ic := h.intercepts.get(tabID)
+	if ic == nil || !ic.Remove(ruleID) {
+		writeRuleNotFound(w, tabID, ruleID)
+		return
+	}
+	if ic.Empty() {
+		ic.Detach(tabCtx)
+	}
+	httpx.JSON(w, 200, map[string]any{"tabId": tabID, "removed": ruleID, "count": ic.Len()})
+}
+
+// HandleInterceptClear removes all of a tab's interception rules and stops
+// intercepting its requests unless a replay is loaded.
+//
+// @Endpoint DELETE /intercept
+// @Endpoint DELETE /tabs/{id}/intercept
+func (h *Handlers) HandleInterceptClear(w http.ResponseWriter, r *http.Request) {
+	tabCtx, tabID, ok := h.requestTab(w, r, "")
+	if !ok {
+		return
+	}
+	cleared := 0
+	if ic := h.intercepts.get(tabID); ic != nil {
+		cleared = ic.Clear()
+		if ic.Empty() {
+			ic.Detach(tabCtx)
+		}
+	}
+	httpx.JSON(w, 200, map[string]any{"tabId": tabID, "cleared": cleared})
+}
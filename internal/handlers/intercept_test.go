@@ -0,0 +1,107 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/pinchtab/pinchtab/internal/bridge"
+	"github.com/pinchtab/pinchtab/internal/config"
+)
+
+func newInterceptTestHandler() (*Handlers, *http.ServeMux, *int) {
+	h := New(&findMockBridge{}, &config.RuntimeConfig{ActionTimeout: 10 * time.Second}, nil, nil, nil)
+	attached := 0
+	h.interceptAttach = func(ctx context.Context, tabID string, ic *bridge.Interceptor) error {
+		attached++
+		return nil
+	}
+	mux := http.NewServeMux()
+	h.RegisterRoutes(mux, nil)
+	return h, mux, &attached
+}
+
+func decodeRule(t *testing.T, w *httptest.ResponseRecorder) bridge.InterceptRule {
+	t.Helper()
+	var resp struct {
+		Rule bridge.InterceptRule `json:"rule"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	return resp.Rule
+}
+
+func TestInterceptRuleCRUD(t *testing.T) {
+	h, mux, attached := newInterceptTestHandler()
+
+	w := doJSON(t, mux, "POST", "/tabs/tab1/intercept", map[string]any{
+		"url": "*/api/items*", "action": "fulfill", "status": 503, "body": "down",
+	})
+	if w.Code != 201 {
+		t.Fatalf("add: expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+	rule := decodeRule(t, w)
+	if rule.ID == "" || rule.Status != 503 || *attached != 1 {
+		t.Fatalf("unexpected rule %+v (attached %d)", rule, *attached)
+	}
+
+	w = doJSON(t, mux, "PUT", "/intercept/"+rule.ID+"?tabId=tab1", map[string]any{
+		"url": "*/api/items*", "action": "fail", "errorReason": "connectionrefused",
+	})
+	if w.Code != 200 {
+		t.Fatalf("update: expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if got := decodeRule(t, w); got.ID != rule.ID || got.Action != "fail" || got.ErrorReason != "ConnectionRefused" {
+		t.Errorf("unexpected updated rule %+v", got)
+	}
+
+	w = doJSON(t, mux, "GET", "/intercept?tabId=tab1", nil)
+	var list struct {
+		Rules []bridge.InterceptRule `json:"rules"`
+		Count int                    `json:"count"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &list); err != nil || list.Count != 1 || list.Rules[0].ID != rule.ID {
+		t.Fatalf("list: %s", w.Body.String())
+	}
+
+	if w := doJSON(t, mux, "DELETE", "/tabs/tab1/intercept/"+rule.ID, nil); w.Code != 200 {
+		t.Fatalf("delete: expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if w := doJSON(t, mux, "GET", "/tabs/tab1/intercept/"+rule.ID, nil); w.Code != 404 {
+		t.Errorf("get after delete: expected 404, got %d", w.Code)
+	}
+	if h.intercepts.get("tab1").Len() != 0 {
+		t.Error("rule should be gone")
+	}
+}
+
+func TestInterceptRuleValidation(t *testing.T) {
+	h, mux, attached := newInterceptTestHandler()
+
+	for _, body := range []map[string]any{
+		{"action": "fulfill"},
+		{"url": "*", "action": "explode"},
+		{"urlRegex": "(", "action": "fail"},
+		{"url": "*", "action": "fail", "errorReason": "Nope"},
+		{"url": "*", "action": "rewrite"},
+		{"url": "*", "action": "delay", "delayMs": 120000},
+	} {
+		w := doJSON(t, mux, "POST", "/intercept", body)
+		if w.Code != 400 {
+			t.Errorf("%v: expected 400, got %d: %s", body, w.Code, w.Body.String())
+		}
+	}
+	if *attached != 0 {
+		t.Errorf("invalid rules should not start interception, attached %d times", *attached)
+	}
+	if w := doJSON(t, mux, "PUT", "/intercept/r9", map[string]any{"url": "*", "action": "fail"}); w.Code != 404 {
+		t.Errorf("update unknown rule: expected 404, got %d", w.Code)
+	}
+	if ic := h.intercepts.get("tab1"); ic != nil && ic.Len() != 0 {
+		t.Errorf("expected no rules, got %d", ic.Len())
+	}
+}
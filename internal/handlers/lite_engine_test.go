@@ -135,3 +135,39 @@ func TestHandleText_LiteRespectsTabID(t *testing.T) {
 		t.Fatalf("expected first page text, got %q", w.Body.String())
 	}
 }
+
+func TestHandleText_LiteReadability(t *testing.T) {
+	page := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`<html><head><title>Guide</title><meta name="author" content="Sam"></head><body>
+<nav><a href="/">Home</a></nav>
+<article><h1>Guide</h1><p>This paragraph is the body of the article, long enough, with commas, to be scored as content.</p>
+<h2>Steps</h2><ol><li>Open</li><li>Close</li></ol></article>
+<footer>Footer links</footer></body></html>`))
+	}))
+	defer page.Close()
+
+	lite := engine.NewLiteEngine()
+	defer func() { _ = lite.Close() }()
+	h := New(&mockBridge{}, &config.RuntimeConfig{Engine: "lite"}, nil, nil, nil)
+	h.Router = engine.NewRouter(engine.ModeLite, lite)
+	if _, err := lite.Navigate(context.Background(), page.URL); err != nil {
+		t.Fatal(err)
+	}
+
+	w := httptest.NewRecorder()
+	h.HandleText(w, httptest.NewRequest("GET", "/text?mode=readability", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d body=%s", w.Code, w.Body.String())
+	}
+	var resp map[string]any
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	text, _ := resp["text"].(string)
+	if resp["title"] != "Guide" || resp["byline"] != "Sam" || resp["mode"] != "readability" {
+		t.Errorf("unexpected response: %v", resp)
+	}
+	if !strings.Contains(text, "## Steps\n\n1. Open\n2. Close") || strings.Contains(text, "Footer") || strings.Contains(text, "Home") {
+		t.Errorf("unexpected markdown: %q", text)
+	}
+}
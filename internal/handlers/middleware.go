@@ -294,16 +294,20 @@ func sessionBrowseGrantAllows(method, path string) bool {
 			path == "/navigate",
 			path == "/action",
 			path == "/snapshot",
+			path == "/snapshot/stream",
 			path == "/screenshot",
 			path == "/text",
+			path == "/tables",
 			path == "/openapi.json",
 			path == "/help",
 			path == "/health",
 			path == "/sessions/me":
 			return true
 		case tabRouteHasSuffix(path, "/snapshot"),
+			tabRouteHasSuffix(path, "/snapshot/stream"),
 			tabRouteHasSuffix(path, "/screenshot"),
 			tabRouteHasSuffix(path, "/text"),
+			tabRouteHasSuffix(path, "/tables"),
 			tabRouteHasSuffix(path, "/metrics"):
 			return true
 		}
@@ -318,6 +322,7 @@ func sessionBrowseGrantAllows(method, path string) bool {
 			path == "/actions",
 			path == "/macro",
 			path == "/find",
+			path == "/extract",
 			path == "/wait",
 			path == "/dialog",
 			path == "/lock",
@@ -330,6 +335,7 @@ func sessionBrowseGrantAllows(method, path string) bool {
 			tabRouteHasSuffix(path, "/action"),
 			tabRouteHasSuffix(path, "/actions"),
 			tabRouteHasSuffix(path, "/find"),
+			tabRouteHasSuffix(path, "/extract"),
 			tabRouteHasSuffix(path, "/wait"),
 			tabRouteHasSuffix(path, "/dialog"),
 			tabRouteHasSuffix(path, "/lock"),
@@ -462,7 +468,15 @@ func sessionTasksGrantAllows(method, path string) bool {
 	case http.MethodGet:
 		return path == "/tasks" || path == "/scheduler/stats" || strings.HasPrefix(path, "/tasks/")
 	case http.MethodPost:
-		return path == "/tasks" || path == "/tasks/batch" || (strings.HasPrefix(path, "/tasks/") && strings.HasSuffix(path, "/cancel"))
+		switch {
+		case path == "/tasks", path == "/tasks/batch", path == "/tasks/recurring":
+			return true
+		case strings.HasPrefix(path, "/tasks/recurring/"):
+			return strings.HasSuffix(path, "/pause") || strings.HasSuffix(path, "/resume")
+		}
+		return strings.HasPrefix(path, "/tasks/") && strings.HasSuffix(path, "/cancel")
+	case http.MethodDelete:
+		return strings.HasPrefix(path, "/tasks/recurring/")
 	default:
 		return false
 	}
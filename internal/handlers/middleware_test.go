@@ -1268,3 +1268,52 @@ func TestAuthMiddleware_SessionAuthDisabled(t *testing.T) {
 		t.Fatalf("expected 401, got %d", rr.Code)
 	}
 }
+
+func TestSessionTasksGrantAllowsRecurring(t *testing.T) {
+	allowed := []struct{ method, path string }{
+		{http.MethodPost, "/tasks/recurring"},
+		{http.MethodGet, "/tasks/recurring"},
+		{http.MethodGet, "/tasks/recurring/rec_1"},
+		{http.MethodPost, "/tasks/recurring/rec_1/pause"},
+		{http.MethodPost, "/tasks/recurring/rec_1/resume"},
+		{http.MethodDelete, "/tasks/recurring/rec_1"},
+	}
+	for _, tt := range allowed {
+		if !sessionGrantAllows("tasks", tt.method, tt.path) {
+			t.Errorf("tasks grant should allow %s %s", tt.method, tt.path)
+		}
+	}
+	denied := []struct{ method, path string }{
+		{http.MethodDelete, "/tasks/task_1"},
+		{http.MethodPost, "/tasks/recurring/rec_1/run"},
+	}
+	for _, tt := range denied {
+		if sessionGrantAllows("tasks", tt.method, tt.path) {
+			t.Errorf("tasks grant should not allow %s %s", tt.method, tt.path)
+		}
+	}
+}
+
+func TestSessionBrowseGrantAllowsTables(t *testing.T) {
+	for _, path := range []string{"/tables", "/tabs/tab1/tables"} {
+		if !sessionGrantAllows("browse", http.MethodGet, path) {
+			t.Errorf("browse grant should allow GET %s", path)
+		}
+	}
+}
+
+func TestSessionBrowseGrantAllowsSnapshotStream(t *testing.T) {
+	for _, path := range []string{"/snapshot/stream", "/tabs/tab1/snapshot/stream"} {
+		if !sessionGrantAllows("browse", http.MethodGet, path) {
+			t.Errorf("browse grant should allow GET %s", path)
+		}
+	}
+}
+
+func TestSessionBrowseGrantAllowsExtract(t *testing.T) {
+	for _, path := range []string{"/extract", "/tabs/tab1/extract"} {
+		if !sessionGrantAllows("browse", http.MethodPost, path) {
+			t.Errorf("browse grant should allow POST %s", path)
+		}
+	}
+}
@@ -15,6 +15,7 @@ import (
 	"github.com/chromedp/cdproto/page"
 	"github.com/chromedp/chromedp"
 	"github.com/pinchtab/pinchtab/internal/bridge"
+	"github.com/pinchtab/pinchtab/internal/config"
 	"github.com/pinchtab/pinchtab/internal/engine"
 	"github.com/pinchtab/pinchtab/internal/httpx"
 )
@@ -27,6 +28,7 @@ import (
 // @Param tabId string body Tab ID to navigate in (optional - creates new if omitted)
 // @Param url string body URL to navigate to (required)
 // @Param newTab bool body Force create new tab (optional, default: false)
+// @Param proxy object body Upstream proxy {url, bypass} for the new tab, in a browser context of its own (optional)
 // @Param waitTitle float64 body Wait for title change (ms) (optional, default: 0)
 // @Param timeout float64 body Timeout for navigation (ms) (optional, default: 30000)
 //
@@ -61,6 +63,8 @@ func (h *Handlers) HandleNavigate(w http.ResponseWriter, r *http.Request) {
 		BlockAds     *bool   `json:"blockAds"`
 		WaitFor      string  `json:"waitFor"`
 		WaitSelector string  `json:"waitSelector"`
+
+		Proxy *config.ProxyConfig `json:"proxy"`
 	}
 
 	if r.Method == http.MethodGet {
@@ -91,6 +95,15 @@ func (h *Handlers) HandleNavigate(w http.ResponseWriter, r *http.Request) {
 		httpx.Error(w, 400, err)
 		return
 	}
+	proxy, err := h.resolveTabProxy(r.Context(), req.Proxy)
+	if err != nil {
+		httpx.ErrorCode(w, 400, "invalid_proxy", err.Error(), false, nil)
+		return
+	}
+	if proxy.Active() && req.TabID != "" && !req.NewTab {
+		httpx.Error(w, 400, fmt.Errorf("proxy only applies to new tabs"))
+		return
+	}
 
 	domainResult := h.IDPIGuard.CheckDomain(req.URL)
 	if domainResult.Blocked {
@@ -111,7 +124,8 @@ func (h *Handlers) HandleNavigate(w http.ResponseWriter, r *http.Request) {
 	h.recordNavigateRequest(r, req.TabID, req.URL)
 
 	// --- Lite engine fast path ---
-	if h.useLite(engine.CapNavigate, req.URL) {
+	// A tab proxy needs a browser context, which only Chrome has.
+	if !proxy.Active() && h.useLite(engine.CapNavigate, req.URL) {
 		h.recordEngine(r, "lite")
 		result, err := h.Router.Lite().Navigate(r.Context(), req.URL)
 		if err != nil {
@@ -184,11 +198,16 @@ func (h *Handlers) HandleNavigate(w http.ResponseWriter, r *http.Request) {
 	if req.NewTab {
 		// Create a blank tab first so the requested URL becomes the first
 		// real history entry.
-		newTabID, newCtx, _, err := h.Bridge.CreateTab("")
+		newTabID, newCtx, err := h.createTab(proxy)
 		if err != nil {
 			httpx.Error(w, 500, fmt.Errorf("new tab: %w", err))
 			return
 		}
+		if err := h.prepareNewTab(newCtx, newTabID); err != nil {
+			_ = h.Bridge.CloseTab(newTabID)
+			httpx.Error(w, 500, fmt.Errorf("new tab: %w", err))
+			return
+		}
 
 		tCtx, tCancel := context.WithTimeout(newCtx, navTimeout)
 		defer tCancel()
@@ -203,7 +222,7 @@ func (h *Handlers) HandleNavigate(w http.ResponseWriter, r *http.Request) {
 			_ = bridge.SetResourceBlocking(tCtx, blockPatterns)
 		}
 
-		if err := bridge.NavigatePageWithRedirectLimit(tCtx, req.URL, h.Config.MaxRedirects); err != nil {
+		if err := h.navigatePage(tCtx, newTabID, req.URL); err != nil {
 			if navGuard != nil {
 				if blockedErr := navGuard.blocked(); blockedErr != nil {
 					httpx.Error(w, http.StatusForbidden, blockedErr)
@@ -257,7 +276,7 @@ func (h *Handlers) HandleNavigate(w http.ResponseWriter, r *http.Request) {
 		_ = bridge.SetResourceBlocking(tCtx, nil)
 	}
 
-	if err := bridge.NavigatePageWithRedirectLimit(tCtx, req.URL, h.Config.MaxRedirects); err != nil {
+	if err := h.navigatePage(tCtx, resolvedTabID, req.URL); err != nil {
 		if navGuard != nil {
 			if blockedErr := navGuard.blocked(); blockedErr != nil {
 				httpx.Error(w, http.StatusForbidden, blockedErr)
@@ -286,6 +305,7 @@ func (h *Handlers) HandleNavigate(w http.ResponseWriter, r *http.Request) {
 	_ = chromedp.Run(tCtx, chromedp.Location(&navURL))
 	title, _ := bridge.WaitForTitle(tCtx, titleWait)
 	h.recordResolvedURL(r, navURL)
+	h.recordNavigate(resolvedTabID, req.URL, req.WaitFor, req.WaitSelector)
 
 	httpx.JSON(w, 200, map[string]any{"tabId": resolvedTabID, "url": navURL, "title": title})
 }
@@ -344,9 +364,10 @@ const (
 
 func (h *Handlers) HandleTab(w http.ResponseWriter, r *http.Request) {
 	var req struct {
-		Action string `json:"action"`
-		TabID  string `json:"tabId"`
-		URL    string `json:"url"`
+		Action string              `json:"action"`
+		TabID  string              `json:"tabId"`
+		URL    string              `json:"url"`
+		Proxy  *config.ProxyConfig `json:"proxy"`
 	}
 	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBodySize)).Decode(&req); err != nil {
 		httpx.Error(w, 400, fmt.Errorf("decode: %w", err))
@@ -355,6 +376,11 @@ func (h *Handlers) HandleTab(w http.ResponseWriter, r *http.Request) {
 
 	switch req.Action {
 	case tabActionNew:
+		proxy, err := h.resolveTabProxy(r.Context(), req.Proxy)
+		if err != nil {
+			httpx.ErrorCode(w, 400, "invalid_proxy", err.Error(), false, nil)
+			return
+		}
 		var target *validatedNavigateTarget
 		trustedCIDRs := parseCIDRs(h.Config.TrustedProxyCIDRs)
 		if req.URL != "" && req.URL != "about:blank" {
@@ -370,7 +396,6 @@ func (h *Handlers) HandleTab(w http.ResponseWriter, r *http.Request) {
 			if domainResult.Threat {
 				w.Header().Set("X-IDPI-Warning", domainResult.Reason)
 			}
-			var err error
 			target, err = validateNavigateTarget(req.URL, h.IDPIGuard.DomainAllowed(req.URL))
 			if err != nil {
 				httpx.Error(w, http.StatusForbidden, err)
@@ -380,11 +405,16 @@ func (h *Handlers) HandleTab(w http.ResponseWriter, r *http.Request) {
 
 		// Create a blank tab first so the requested URL becomes the first
 		// real history entry.
-		newTabID, ctx, _, err := h.Bridge.CreateTab("")
+		newTabID, ctx, err := h.createTab(proxy)
 		if err != nil {
 			httpx.Error(w, 500, err)
 			return
 		}
+		if err := h.prepareNewTab(ctx, newTabID); err != nil {
+			_ = h.Bridge.CloseTab(newTabID)
+			httpx.Error(w, 500, err)
+			return
+		}
 
 		if req.URL != "" && req.URL != "about:blank" {
 			tCtx, tCancel := context.WithTimeout(ctx, h.Config.NavigateTimeout)
@@ -396,7 +426,7 @@ func (h *Handlers) HandleTab(w http.ResponseWriter, r *http.Request) {
 				httpx.Error(w, 500, fmt.Errorf("navigation guard: %w", err))
 				return
 			}
-			if err := bridge.NavigatePageWithRedirectLimit(tCtx, req.URL, h.Config.MaxRedirects); err != nil {
+			if err := h.navigatePage(tCtx, newTabID, req.URL); err != nil {
 				if navGuard != nil {
 					if blockedErr := navGuard.blocked(); blockedErr != nil {
 						_ = h.Bridge.CloseTab(newTabID)
@@ -158,12 +158,8 @@ func extractNavigateHost(raw string) (string, bool) {
 func validateNavigateRemoteIPAddress(raw string, trustedCIDRs []*net.IPNet) error {
 	normalized := netguard.NormalizeRemoteIP(raw)
 	if err := netguard.ValidateRemoteIPAddress(raw); err != nil {
-		if ip := net.ParseIP(normalized); ip != nil {
-			for _, cidr := range trustedCIDRs {
-				if cidr.Contains(ip) {
-					return nil
-				}
-			}
+		if ip := net.ParseIP(normalized); ip != nil && inCIDRs(ip, trustedCIDRs) {
+			return nil
 		}
 		return fmt.Errorf("navigation connected to blocked remote IP %s", normalized)
 	}
@@ -187,6 +183,15 @@ func parseCIDRs(raw []string) []*net.IPNet {
 	return nets
 }
 
+func inCIDRs(ip net.IP, cidrs []*net.IPNet) bool {
+	for _, cidr := range cidrs {
+		if cidr.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
 func installNavigateRuntimeGuard(tCtx context.Context, tCancel context.CancelFunc, target *validatedNavigateTarget, trustedCIDRs []*net.IPNet) (*navigateRuntimeGuard, error) {
 	if target == nil || target.allowInternal {
 		return nil, nil
@@ -30,11 +30,13 @@ func parseBufferSize(r *http.Request) int {
 // @Param filter string query URL pattern filter (optional)
 // @Param method string query HTTP method filter (optional)
 // @Param status string query Status code range filter e.g. "4xx", "5xx", "200" (optional)
-// @Param type string query Resource type filter e.g. "xhr", "fetch", "document" (optional)
+// @Param type string query Resource type filter e.g. "xhr", "fetch", "document", "websocket" (optional)
+// @Param message string query WebSocket/EventSource message payload filter (optional)
+// @Param direction string query WebSocket message direction filter: "send" or "receive" (optional)
 // @Param limit int query Maximum entries to return (optional)
 // @Param bufferSize int query Buffer size for new capture (optional, default from config)
 //
-// @Response 200 application/json List of network entries
+// @Response 200 application/json List of network entries, with interception rule hits when the tab has rules
 // @Response 404 application/json Tab not found
 func (h *Handlers) HandleNetwork(w http.ResponseWriter, r *http.Request) {
 	if err := h.ensureChrome(); err != nil {
@@ -73,17 +75,7 @@ func (h *Handlers) HandleNetwork(w http.ResponseWriter, r *http.Request) {
 		buf = nm.GetBuffer(resolvedTabID)
 	}
 
-	filter := bridge.NetworkFilter{
-		URLPattern:   r.URL.Query().Get("filter"),
-		Method:       r.URL.Query().Get("method"),
-		StatusRange:  r.URL.Query().Get("status"),
-		ResourceType: r.URL.Query().Get("type"),
-	}
-	if v := r.URL.Query().Get("limit"); v != "" {
-		if n, err := strconv.Atoi(v); err == nil && n > 0 {
-			filter.Limit = n
-		}
-	}
+	filter := parseNetworkFilter(r)
 
 	entries := buf.List(filter)
 
@@ -91,11 +83,15 @@ func (h *Handlers) HandleNetwork(w http.ResponseWriter, r *http.Request) {
 		entries = entries[len(entries)-filter.Limit:]
 	}
 
-	httpx.JSON(w, 200, map[string]any{
+	resp := map[string]any{
 		"entries": entries,
 		"count":   len(entries),
 		"tabId":   resolvedTabID,
-	})
+	}
+	if ic := h.intercepts.get(resolvedTabID); ic != nil && ic.Len() > 0 {
+		resp["interceptRules"] = ic.Rules()
+	}
+	httpx.JSON(w, 200, resp)
 }
 
 // HandleNetworkByID returns details for a specific network request.
@@ -211,9 +207,11 @@ func (h *Handlers) HandleNetworkClear(w http.ResponseWriter, r *http.Request) {
 // @Param method string query HTTP method filter (optional)
 // @Param status string query Status code range filter (optional)
 // @Param type string query Resource type filter (optional)
+// @Param message string query WebSocket/EventSource message payload filter (optional)
+// @Param direction string query WebSocket message direction filter (optional)
 // @Param bufferSize int query Buffer size for new capture (optional)
 //
-// @Response 200 text/event-stream SSE stream of network entries
+// @Response 200 text/event-stream SSE stream of network entries and, as "frame" events, WebSocket frames and EventSource messages
 func (h *Handlers) HandleNetworkStream(w http.ResponseWriter, r *http.Request) {
 	flusher, ok := w.(http.Flusher)
 	if !ok {
@@ -261,15 +259,12 @@ func (h *Handlers) HandleNetworkStream(w http.ResponseWriter, r *http.Request) {
 		buf = nm.GetBuffer(resolvedTabID)
 	}
 
-	filter := bridge.NetworkFilter{
-		URLPattern:   r.URL.Query().Get("filter"),
-		Method:       r.URL.Query().Get("method"),
-		StatusRange:  r.URL.Query().Get("status"),
-		ResourceType: r.URL.Query().Get("type"),
-	}
+	filter := parseNetworkFilter(r)
 
 	subID, ch := buf.Subscribe()
 	defer buf.Unsubscribe(subID)
+	msgSubID, msgCh := buf.SubscribeMessages()
+	defer buf.UnsubscribeMessages(msgSubID)
 
 	w.Header().Set("Content-Type", "text/event-stream")
 	w.Header().Set("Cache-Control", "no-cache")
@@ -298,6 +293,22 @@ func (h *Handlers) HandleNetworkStream(w http.ResponseWriter, r *http.Request) {
 			}
 			flusher.Flush()
 
+		case ev, ok := <-msgCh:
+			if !ok {
+				return
+			}
+			if !filter.MatchMessageEvent(ev) {
+				continue
+			}
+			data, err := json.Marshal(ev)
+			if err != nil {
+				continue
+			}
+			if _, err := fmt.Fprintf(w, "event: frame\ndata: %s\n\n", data); err != nil {
+				return
+			}
+			flusher.Flush()
+
 		case <-keepalive.C:
 			if _, err := fmt.Fprintf(w, ": keepalive\n\n"); err != nil {
 				return
@@ -0,0 +1,216 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/pinchtab/pinchtab/internal/bridge"
+	"github.com/pinchtab/pinchtab/internal/config"
+	"github.com/pinchtab/pinchtab/internal/httpx"
+)
+
+// networkConditionsRequest is the body of POST /network/conditions.
+type networkConditionsRequest struct {
+	TabID string `json:"tabId"`
+	Scope string `json:"scope"`
+	config.NetworkConditions
+}
+
+// emulateNetwork applies network conditions to a tab.
+func (h *Handlers) emulateNetwork(tabCtx context.Context, c config.NetworkConditions) error {
+	if h.networkEmulate != nil {
+		return h.networkEmulate(tabCtx, c)
+	}
+	return bridge.EmulateNetworkConditions(tabCtx, c)
+}
+
+// networkEmulator returns the bridge's emulator, writing a 503 if there is
+// none.
+func (h *Handlers) networkEmulator(w http.ResponseWriter) (*bridge.NetworkEmulator, bool) {
+	em := h.Bridge.NetworkEmulator()
+	if em == nil {
+		httpx.ErrorCode(w, 503, "network_emulation_unavailable", "network emulation is not available", false, nil)
+		return nil, false
+	}
+	return em, true
+}
+
+// emulateOpenTabs applies instance conditions to every open tab.
+func (h *Handlers) emulateOpenTabs(c config.NetworkConditions) ([]string, error) {
+	targets, err := h.Bridge.ListTargets()
+	if err != nil {
+		return nil, err
+	}
+	tabs := []string{}
+	for _, t := range targets {
+		tabCtx, tabID, err := h.Bridge.TabContext(string(t.TargetID))
+		if err != nil {
+			continue
+		}
+		if err := h.emulateNetwork(tabCtx, c); err != nil {
+			return tabs, fmt.Errorf("tab %s: %w", tabID, err)
+		}
+		tabs = append(tabs, tabID)
+	}
+	return tabs, nil
+}
+
+// exportMetadata returns the export-wide metadata for a tab's network
+// export: the network conditions it runs under, when throttled.
+func (h *Handlers) exportMetadata(tabID string) map[string]any {
+	em := h.Bridge.NetworkEmulator()
+	if em == nil {
+		return nil
+	}
+	c, _ := em.Tab(tabID)
+	if !c.Active() {
+		return nil
+	}
+	return map[string]any{"_networkConditions": c}
+}
+
+// HandleNetworkConditions reports the network conditions a tab runs under.
+//
+// @Endpoint GET /network/conditions
+// @Endpoint GET /tabs/{id}/network/conditions
+// @Description Shows the tab's network emulation and the instance default
+//
+// @Param tabId string query Tab ID (optional, uses current tab if empty)
+//
+// @Response 200 application/json Tab and instance conditions
+// @Response 404 application/json Tab not found
+func (h *Handlers) HandleNetworkConditions(w http.ResponseWriter, r *http.Request) {
+	em, ok := h.networkEmulator(w)
+	if !ok {
+		return
+	}
+	_, tabID, ok := h.requestTab(w, r, "")
+	if !ok {
+		return
+	}
+	c, own := em.Tab(tabID)
+	scope := scopeInstance
+	if own {
+		scope = scopeTab
+	}
+	httpx.JSON(w, 200, map[string]any{
+		"tabId":      tabID,
+		"conditions": c,
+		"scope":      scope,
+		"instance":   em.Instance(),
+		"profiles":   config.ValidNetworkProfiles(),
+	})
+}
+
+// HandleNetworkConditionsSet emulates network conditions in a tab, or in
+// every open and new tab.
+//
+// @Endpoint POST /network/conditions
+// @Endpoint POST /tabs/{id}/network/conditions
+// @Description Throttles or takes offline the tab's network via Network.emulateNetworkConditions
+//
+// @Param tabId string body Tab ID (optional, uses current tab if empty)
+// @Param scope string body "tab" (default) or "instance" for all open and new tabs
+// @Param profile string body none, offline, slow-3g, fast-3g or custom
+// @Param latencyMs number body Added round-trip latency in milliseconds
+// @Param downloadKbps number body Download limit in kilobits per second (0 = no limit)
+// @Param uploadKbps number body Upload limit in kilobits per second (0 = no limit)
+// @Param packetLoss number body WebRTC packet loss in percent
+//
+// @Response 200 application/json The applied conditions
+// @Response 400 application/json Invalid conditions
+// @Response 404 application/json Tab not found
+func (h *Handlers) HandleNetworkConditionsSet(w http.ResponseWriter, r *http.Request) {
+	var req networkConditionsRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBodySize)).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		httpx.Error(w, 400, fmt.Errorf("decode: %w", err))
+		return
+	}
+	if req.Scope == "" {
+		req.Scope = scopeTab
+	}
+	if req.Scope != scopeTab && req.Scope != scopeInstance {
+		httpx.Error(w, 400, fmt.Errorf("scope must be tab or instance"))
+		return
+	}
+	if req.Scope == scopeInstance && r.PathValue("id") != "" {
+		httpx.Error(w, 400, fmt.Errorf("scope=instance cannot be used on a tab route"))
+		return
+	}
+	c, err := req.NetworkConditions.Resolve()
+	if err != nil {
+		httpx.ErrorCode(w, 400, "invalid_network_conditions", err.Error(), false, nil)
+		return
+	}
+	if err := h.ensureChrome(); err != nil {
+		if h.writeBridgeUnavailable(w, err) {
+			return
+		}
+		httpx.Error(w, 500, fmt.Errorf("chrome initialization: %w", err))
+		return
+	}
+	em, ok := h.networkEmulator(w)
+	if !ok {
+		return
+	}
+
+	if req.Scope == scopeInstance {
+		em.SetInstance(c)
+		tabs, err := h.emulateOpenTabs(c)
+		if err != nil {
+			httpx.Error(w, 500, fmt.Errorf("network emulation: %w", err))
+			return
+		}
+		httpx.JSON(w, 200, map[string]any{"scope": scopeInstance, "tabs": tabs, "conditions": c})
+		return
+	}
+
+	tabCtx, tabID, ok := h.requestTab(w, r, req.TabID)
+	if !ok {
+		return
+	}
+	if err := h.emulateNetwork(tabCtx, c); err != nil {
+		httpx.Error(w, 500, fmt.Errorf("network emulation: %w", err))
+		return
+	}
+	em.SetTab(tabCtx, tabID, c)
+	httpx.JSON(w, 200, map[string]any{"scope": scopeTab, "tabId": tabID, "conditions": c})
+}
+
+// HandleNetworkConditionsReset returns a tab to the instance conditions,
+// or with scope=instance lifts the instance conditions from every tab.
+//
+// @Endpoint DELETE /network/conditions
+// @Endpoint DELETE /tabs/{id}/network/conditions
+func (h *Handlers) HandleNetworkConditionsReset(w http.ResponseWriter, r *http.Request) {
+	em, ok := h.networkEmulator(w)
+	if !ok {
+		return
+	}
+	if r.URL.Query().Get("scope") == scopeInstance {
+		none, _ := config.NetworkConditions{}.Resolve()
+		em.SetInstance(none)
+		tabs, err := h.emulateOpenTabs(none)
+		if err != nil {
+			httpx.Error(w, 500, fmt.Errorf("network emulation: %w", err))
+			return
+		}
+		httpx.JSON(w, 200, map[string]any{"scope": scopeInstance, "tabs": tabs, "conditions": none})
+		return
+	}
+
+	tabCtx, tabID, ok := h.requestTab(w, r, "")
+	if !ok {
+		return
+	}
+	c := em.ResetTab(tabID)
+	if err := h.emulateNetwork(tabCtx, c); err != nil {
+		httpx.Error(w, 500, fmt.Errorf("network emulation: %w", err))
+		return
+	}
+	httpx.JSON(w, 200, map[string]any{"scope": scopeInstance, "tabId": tabID, "conditions": c})
+}
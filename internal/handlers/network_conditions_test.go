@@ -0,0 +1,102 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/pinchtab/pinchtab/internal/bridge"
+	"github.com/pinchtab/pinchtab/internal/config"
+)
+
+type emulatorMockBridge struct {
+	findMockBridge
+	emulator *bridge.NetworkEmulator
+}
+
+func (m *emulatorMockBridge) NetworkEmulator() *bridge.NetworkEmulator { return m.emulator }
+
+func newNetworkConditionsTestHandler() (*Handlers, *http.ServeMux, *[]config.NetworkConditions) {
+	b := &emulatorMockBridge{emulator: bridge.NewNetworkEmulator(config.NetworkConditions{})}
+	h := New(b, &config.RuntimeConfig{ActionTimeout: 10 * time.Second}, nil, nil, nil)
+	applied := []config.NetworkConditions{}
+	h.networkEmulate = func(ctx context.Context, c config.NetworkConditions) error {
+		applied = append(applied, c)
+		return nil
+	}
+	mux := http.NewServeMux()
+	h.RegisterRoutes(mux, nil)
+	return h, mux, &applied
+}
+
+func TestNetworkConditionsTabProfile(t *testing.T) {
+	h, mux, applied := newNetworkConditionsTestHandler()
+
+	w := doJSON(t, mux, "POST", "/tabs/tab1/network/conditions", map[string]any{"profile": "slow-3g", "latencyMs": 300})
+	if w.Code != 200 {
+		t.Fatalf("set: expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if len(*applied) != 1 || (*applied)[0].Profile != "slow-3g" || (*applied)[0].LatencyMs != 300 || (*applied)[0].DownloadKbps != 400 {
+		t.Fatalf("unexpected applied conditions %+v", *applied)
+	}
+
+	w = doJSON(t, mux, "GET", "/tabs/tab1/network/conditions", nil)
+	var resp struct {
+		Scope      string                   `json:"scope"`
+		Conditions config.NetworkConditions `json:"conditions"`
+	}
+	_ = json.Unmarshal(w.Body.Bytes(), &resp)
+	if resp.Scope != "tab" || resp.Conditions.Profile != "slow-3g" {
+		t.Errorf("unexpected status %s", w.Body.String())
+	}
+	if md := h.exportMetadata("tab1"); md["_networkConditions"] == nil {
+		t.Error("expected network conditions in export metadata")
+	}
+
+	w = doJSON(t, mux, "DELETE", "/tabs/tab1/network/conditions", nil)
+	if w.Code != 200 || len(*applied) != 2 || (*applied)[1].Active() {
+		t.Fatalf("reset: got %d %+v", w.Code, *applied)
+	}
+	if md := h.exportMetadata("tab1"); md != nil {
+		t.Errorf("expected no export metadata after reset, got %v", md)
+	}
+}
+
+func TestNetworkConditionsInstanceScope(t *testing.T) {
+	h, mux, applied := newNetworkConditionsTestHandler()
+
+	w := doJSON(t, mux, "POST", "/network/conditions", map[string]any{"scope": "instance", "offline": true})
+	if w.Code != 200 {
+		t.Fatalf("set: expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if len(*applied) != 1 || !(*applied)[0].Offline {
+		t.Fatalf("expected offline applied to the open tab, got %+v", *applied)
+	}
+	if c := h.Bridge.NetworkEmulator().Instance(); c.Profile != "offline" {
+		t.Errorf("expected instance profile offline, got %q", c.Profile)
+	}
+
+	w = doJSON(t, mux, "DELETE", "/network/conditions?scope=instance", nil)
+	if w.Code != 200 || h.Bridge.NetworkEmulator().Instance().Active() {
+		t.Fatalf("reset: got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestNetworkConditionsRejectsInvalid(t *testing.T) {
+	_, mux, applied := newNetworkConditionsTestHandler()
+
+	for _, body := range []map[string]any{
+		{"profile": "dialup"},
+		{"profile": "custom", "packetLoss": 150},
+		{"scope": "browser"},
+	} {
+		if w := doJSON(t, mux, "POST", "/tabs/tab1/network/conditions", body); w.Code != 400 {
+			t.Errorf("%v: expected 400, got %d", body, w.Code)
+		}
+	}
+	if len(*applied) != 0 {
+		t.Errorf("expected nothing applied, got %+v", *applied)
+	}
+}
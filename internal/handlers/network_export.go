@@ -120,7 +120,7 @@ func (h *Handlers) HandleNetworkExport(w http.ResponseWriter, r *http.Request) {
 	// Get network entries
 	nm := h.Bridge.NetworkMonitor()
 	if nm == nil {
-		enc := factory("PinchTab", h.version())
+		enc := h.newExportEncoder(factory, resolvedTabID)
 		w.Header().Set("Content-Type", enc.ContentType())
 		if err := enc.Start(w); err != nil {
 			return
@@ -190,7 +190,7 @@ func (h *Handlers) HandleNetworkExport(w http.ResponseWriter, r *http.Request) {
 	}
 	wg.Wait()
 
-	enc := factory("PinchTab", h.version())
+	enc := h.newExportEncoder(factory, resolvedTabID)
 
 	if output == "file" {
 		if err := h.writeExportFile(w, r, enc, exportEntries, formatName); err != nil {
@@ -393,7 +393,7 @@ func (h *Handlers) HandleNetworkExportStream(w http.ResponseWriter, r *http.Requ
 		return
 	}
 
-	enc := factory("PinchTab", h.version())
+	enc := h.newExportEncoder(factory, resolvedTabID)
 	if err := enc.Start(f); err != nil {
 		_ = f.Close()
 		_ = os.Remove(tmpPath)
@@ -587,10 +587,12 @@ func (h *Handlers) HandleTabNetworkExportStream(w http.ResponseWriter, r *http.R
 
 func parseNetworkFilter(r *http.Request) bridge.NetworkFilter {
 	f := bridge.NetworkFilter{
-		URLPattern:   r.URL.Query().Get("filter"),
-		Method:       r.URL.Query().Get("method"),
-		StatusRange:  r.URL.Query().Get("status"),
-		ResourceType: r.URL.Query().Get("type"),
+		URLPattern:     r.URL.Query().Get("filter"),
+		Method:         r.URL.Query().Get("method"),
+		StatusRange:    r.URL.Query().Get("status"),
+		ResourceType:   r.URL.Query().Get("type"),
+		MessagePattern: r.URL.Query().Get("message"),
+		Direction:      r.URL.Query().Get("direction"),
 	}
 	if v := r.URL.Query().Get("limit"); v != "" {
 		if n, err := strconv.Atoi(v); err == nil && n > 0 {
@@ -600,6 +602,18 @@ func parseNetworkFilter(r *http.Request) bridge.NetworkFilter {
 	return f
 }
 
+// newExportEncoder creates an encoder for a tab's export, handing it the
+// export metadata when its format has room for it.
+func (h *Handlers) newExportEncoder(factory observe.ExportEncoderFactory, tabID string) observe.ExportEncoder {
+	enc := factory("PinchTab", h.version())
+	if me, ok := enc.(observe.ExportMetadataEncoder); ok {
+		if meta := h.exportMetadata(tabID); meta != nil {
+			me.SetMetadata(meta)
+		}
+	}
+	return enc
+}
+
 func (h *Handlers) version() string {
 	if h.Version != "" {
 		return h.Version
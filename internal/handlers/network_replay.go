@@ -0,0 +1,271 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"github.com/pinchtab/pinchtab/internal/bridge"
+	"github.com/pinchtab/pinchtab/internal/bridge/observe"
+	"github.com/pinchtab/pinchtab/internal/httpx"
+)
+
+// maxReplayArchiveBytes bounds an archive sent inline or read from disk.
+const maxReplayArchiveBytes = 64 << 20
+
+// Scopes of replay and network emulation requests.
+const (
+	scopeTab      = "tab"
+	scopeInstance = "instance"
+)
+
+// harReplayRequest is the body of POST /network/replay. The archive is either
+// inline in har, as a HAR object or as a string holding HAR or NDJSON, or
+// a file written by /network/export with output=file.
+type harReplayRequest struct {
+	TabID string          `json:"tabId"`
+	Scope string          `json:"scope"`
+	HAR   json.RawMessage `json:"har"`
+	Path  string          `json:"path"`
+	bridge.HARReplayOptions
+}
+
+// instanceReplay returns the archive new tabs start replaying, if any.
+func (s *interceptStore) instanceReplay() *bridge.HARReplay {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.replay
+}
+
+func (s *interceptStore) setInstanceReplay(r *bridge.HARReplay) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.replay = r
+}
+
+// loadReplay reads and indexes the archive a replay request names.
+func (h *Handlers) loadReplay(req harReplayRequest) (*bridge.HARReplay, error) {
+	var src io.Reader
+	var source string
+	switch {
+	case len(req.HAR) > 0 && req.Path != "":
+		return nil, fmt.Errorf("har and path are mutually exclusive")
+	case len(req.HAR) > 0:
+		doc := []byte(req.HAR)
+		if doc[0] == '"' {
+			var s string
+			if err := json.Unmarshal(doc, &s); err != nil {
+				return nil, fmt.Errorf("har: %w", err)
+			}
+			doc = []byte(s)
+		}
+		src, source = bytes.NewReader(doc), "inline"
+	case req.Path != "":
+		name := filepath.Base(req.Path)
+		f, err := os.Open(filepath.Join(h.Config.StateDir, "exports", name))
+		if err != nil {
+			return nil, fmt.Errorf("open export %s: %w", name, err)
+		}
+		defer func() { _ = f.Close() }()
+		src, source = io.LimitReader(f, maxReplayArchiveBytes), name
+	default:
+		return nil, fmt.Errorf("har or path required")
+	}
+
+	entries, err := observe.ReadExport(src)
+	if err != nil {
+		return nil, fmt.Errorf("read archive: %w", err)
+	}
+	return bridge.NewHARReplay(source, entries, req.HARReplayOptions)
+}
+
+// startReplay makes a tab serve its requests from the archive.
+func (h *Handlers) startReplay(tabCtx context.Context, tabID string, replay *bridge.HARReplay) error {
+	ic := h.interceptor(tabID)
+	prev := ic.Replay()
+	ic.SetReplay(replay)
+	if err := h.attachInterceptor(tabCtx, tabID, ic); err != nil {
+		ic.SetReplay(prev)
+		return err
+	}
+	return nil
+}
+
+// prepareNewTab applies instance-wide state to a tab the handlers just
+// created, before anything is loaded in it.
+func (h *Handlers) prepareNewTab(tabCtx context.Context, tabID string) error {
+	if replay := h.intercepts.instanceReplay(); replay != nil {
+		if err := h.startReplay(tabCtx, tabID, replay); err != nil {
+			return fmt.Errorf("har replay: %w", err)
+		}
+	}
+	return nil
+}
+
+// HandleNetworkReplay loads a HAR or NDJSON network export and serves the
+// matching requests of a tab, or of every tab, from it.
+//
+// @Endpoint POST /network/replay
+// @Endpoint POST /tabs/{id}/network/replay
+// @Description Serves requests from a recorded archive instead of the network
+//
+// @Param tabId string body Tab ID (optional, uses current tab if empty)
+// @Param scope string body "tab" (default) or "instance" for all open and new tabs
+// @Param har object body Inline HAR, or a string holding HAR or NDJSON
+// @Param path string body File name of an export written with output=file
+// @Param ignoreQuery []string body Query parameters to ignore when matching, "*" for all
+// @Param body string body Body matching: "exact" (default), "json" or "ignore"
+// @Param ignoreBodyFields []string body JSON keys to ignore when body is "json"
+// @Param unmatched string body "fail" (default) or "passthrough"
+//
+// @Response 200 application/json Replay status
+// @Response 400 application/json Invalid archive or options
+// @Response 404 application/json Tab not found
+func (h *Handlers) HandleNetworkReplay(w http.ResponseWriter, r *http.Request) {
+	var req harReplayRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxReplayArchiveBytes)).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		httpx.Error(w, 400, fmt.Errorf("decode: %w", err))
+		return
+	}
+	if req.Scope == "" {
+		req.Scope = scopeTab
+	}
+	if req.Scope != scopeTab && req.Scope != scopeInstance {
+		httpx.Error(w, 400, fmt.Errorf("scope must be tab or instance"))
+		return
+	}
+	if req.Scope == scopeInstance && r.PathValue("id") != "" {
+		httpx.Error(w, 400, fmt.Errorf("scope=instance cannot be used on a tab route"))
+		return
+	}
+	replay, err := h.loadReplay(req)
+	if err != nil {
+		httpx.ErrorCode(w, 400, "invalid_archive", err.Error(), false, nil)
+		return
+	}
+	if err := h.ensureChrome(); err != nil {
+		if h.writeBridgeUnavailable(w, err) {
+			return
+		}
+		httpx.Error(w, 500, fmt.Errorf("chrome initialization: %w", err))
+		return
+	}
+
+	if req.Scope == scopeInstance {
+		targets, err := h.Bridge.ListTargets()
+		if err != nil {
+			httpx.Error(w, 503, err)
+			return
+		}
+		h.intercepts.setInstanceReplay(replay)
+		tabs := []string{}
+		for _, t := range targets {
+			tabCtx, tabID, err := h.Bridge.TabContext(string(t.TargetID))
+			if err != nil {
+				continue
+			}
+			if err := h.startReplay(tabCtx, tabID, replay); err != nil {
+				httpx.Error(w, 500, fmt.Errorf("har replay in tab %s: %w", tabID, err))
+				return
+			}
+			tabs = append(tabs, tabID)
+		}
+		httpx.JSON(w, 200, map[string]any{"scope": scopeInstance, "tabs": tabs, "replay": replay.Status()})
+		return
+	}
+
+	tabCtx, tabID, ok := h.requestTab(w, r, req.TabID)
+	if !ok {
+		return
+	}
+	if err := h.startReplay(tabCtx, tabID, replay); err != nil {
+		httpx.Error(w, 500, fmt.Errorf("har replay: %w", err))
+		return
+	}
+	httpx.JSON(w, 200, map[string]any{"scope": scopeTab, "tabId": tabID, "replay": replay.Status()})
+}
+
+// HandleNetworkReplayStatus reports the archive a tab replays and what it
+// has matched so far.
+//
+// @Endpoint GET /network/replay
+// @Endpoint GET /tabs/{id}/network/replay
+func (h *Handlers) HandleNetworkReplayStatus(w http.ResponseWriter, r *http.Request) {
+	_, tabID, ok := h.requestTab(w, r, "")
+	if !ok {
+		return
+	}
+	resp := map[string]any{"tabId": tabID, "active": false}
+	if ic := h.intercepts.get(tabID); ic != nil {
+		if replay := ic.Replay(); replay != nil {
+			resp["active"] = true
+			resp["replay"] = replay.Status()
+			if replay == h.intercepts.instanceReplay() {
+				resp["scope"] = scopeInstance
+			} else {
+				resp["scope"] = scopeTab
+			}
+		}
+	}
+	httpx.JSON(w, 200, resp)
+}
+
+// HandleNetworkReplayStop stops replaying in a tab, or with scope=instance
+// in every tab that replays the instance-wide archive, and in new tabs.
+//
+// @Endpoint DELETE /network/replay
+// @Endpoint DELETE /tabs/{id}/network/replay
+func (h *Handlers) HandleNetworkReplayStop(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Query().Get("scope") == scopeInstance {
+		replay := h.intercepts.instanceReplay()
+		h.intercepts.setInstanceReplay(nil)
+		tabs := []string{}
+		if replay != nil {
+			h.intercepts.mu.Lock()
+			ids := make([]string, 0, len(h.intercepts.tabs))
+			for id := range h.intercepts.tabs {
+				ids = append(ids, id)
+			}
+			h.intercepts.mu.Unlock()
+			for _, id := range ids {
+				if h.stopReplay(id, replay) {
+					tabs = append(tabs, id)
+				}
+			}
+		}
+		httpx.JSON(w, 200, map[string]any{"scope": scopeInstance, "stopped": tabs})
+		return
+	}
+
+	_, tabID, ok := h.requestTab(w, r, "")
+	if !ok {
+		return
+	}
+	httpx.JSON(w, 200, map[string]any{"tabId": tabID, "stopped": h.stopReplay(tabID, nil)})
+}
+
+// stopReplay stops a tab's replay, if it is only or nil, and detaches the
+// interceptor when nothing else needs it.
+func (h *Handlers) stopReplay(tabID string, only *bridge.HARReplay) bool {
+	ic := h.intercepts.get(tabID)
+	if ic == nil {
+		return false
+	}
+	replay := ic.Replay()
+	if replay == nil || (only != nil && replay != only) {
+		return false
+	}
+	ic.SetReplay(nil)
+	if ic.Empty() {
+		if tabCtx, _, err := h.Bridge.TabContext(tabID); err == nil {
+			ic.Detach(tabCtx)
+		}
+	}
+	return true
+}
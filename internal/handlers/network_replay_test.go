@@ -0,0 +1,95 @@
+package handlers
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const replayTestNDJSON = `{"request":{"method":"GET","url":"https://example.com/api"},"response":{"status":200,"content":{"text":"ok"}}}
+{"request":{"method":"POST","url":"https://example.com/api"},"response":{"status":201,"content":{"text":"made"}}}
+`
+
+func TestNetworkReplayLoadAndStop(t *testing.T) {
+	h, mux, attached := newInterceptTestHandler()
+
+	w := doJSON(t, mux, "POST", "/tabs/tab1/network/replay", map[string]any{
+		"har": replayTestNDJSON, "ignoreQuery": []string{"*"}, "unmatched": "passthrough",
+	})
+	if w.Code != 200 {
+		t.Fatalf("load: expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if *attached != 1 {
+		t.Errorf("expected the interceptor to be attached once, got %d", *attached)
+	}
+	ic := h.intercepts.get("tab1")
+	if ic == nil || ic.Replay() == nil || !ic.Replay().Passthrough() {
+		t.Fatal("expected tab1 to replay with passthrough")
+	}
+
+	w = doJSON(t, mux, "GET", "/tabs/tab1/network/replay", nil)
+	var status struct {
+		Active bool   `json:"active"`
+		Scope  string `json:"scope"`
+		Replay struct {
+			Entries int `json:"entries"`
+		} `json:"replay"`
+	}
+	_ = json.Unmarshal(w.Body.Bytes(), &status)
+	if !status.Active || status.Scope != "tab" || status.Replay.Entries != 2 {
+		t.Errorf("unexpected status %s", w.Body.String())
+	}
+
+	w = doJSON(t, mux, "DELETE", "/tabs/tab1/network/replay", nil)
+	if w.Code != 200 || ic.Replay() != nil {
+		t.Fatalf("stop: got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestNetworkReplayInstanceScope(t *testing.T) {
+	h, mux, _ := newInterceptTestHandler()
+	h.Config.StateDir = t.TempDir()
+	dir := filepath.Join(h.Config.StateDir, "exports")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "run.ndjson"), []byte(replayTestNDJSON), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	w := doJSON(t, mux, "POST", "/network/replay", map[string]any{"path": "../../run.ndjson", "scope": "instance"})
+	if w.Code != 200 {
+		t.Fatalf("load: expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	replay := h.intercepts.instanceReplay()
+	if replay == nil || replay.Status().Source != "run.ndjson" {
+		t.Fatal("expected an instance-wide replay from the export file")
+	}
+	if ic := h.intercepts.get("tab1"); ic == nil || ic.Replay() != replay {
+		t.Error("expected open tabs to replay the instance archive")
+	}
+
+	w = doJSON(t, mux, "DELETE", "/network/replay?scope=instance", nil)
+	if w.Code != 200 || h.intercepts.instanceReplay() != nil || h.intercepts.get("tab1").Replay() != nil {
+		t.Fatalf("stop: got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestNetworkReplayInvalid(t *testing.T) {
+	_, mux, attached := newInterceptTestHandler()
+	for name, body := range map[string]map[string]any{
+		"no archive": {},
+		"both":       {"har": replayTestNDJSON, "path": "run.har"},
+		"bad body":   {"har": replayTestNDJSON, "body": "fuzzy"},
+		"bad scope":  {"har": replayTestNDJSON, "scope": "global"},
+		"not export": {"har": map[string]any{"foo": 1}},
+	} {
+		if w := doJSON(t, mux, "POST", "/network/replay", body); w.Code != 400 {
+			t.Errorf("%s: expected 400, got %d: %s", name, w.Code, w.Body.String())
+		}
+	}
+	if *attached != 0 {
+		t.Errorf("invalid requests should not attach, got %d", *attached)
+	}
+}
@@ -600,6 +600,41 @@ func TestHandleNetworkStream_FilterApplied(t *testing.T) {
 	}
 }
 
+func TestHandleNetworkStream_Messages(t *testing.T) {
+	nm := bridge.NewNetworkMonitor(100)
+	buf := nm.GetOrCreateBufferForTest("tab1")
+	buf.Add(bridge.NetworkEntry{RequestID: "ws1", URL: "wss://example.com/live", Method: "GET", ResourceType: "WebSocket"})
+	h := newNetworkTestHandler(nm)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	req := httptest.NewRequest("GET", "/network/stream?direction=receive", nil).WithContext(ctx)
+	w := httptest.NewRecorder()
+
+	done := make(chan struct{})
+	go func() {
+		h.HandleNetworkStream(w, req)
+		close(done)
+	}()
+
+	time.Sleep(50 * time.Millisecond)
+
+	buf.AddMessage("ws1", bridge.NetworkMessage{Direction: "send", Opcode: 1, Data: "outgoing"})
+	buf.AddMessage("ws1", bridge.NetworkMessage{Direction: "receive", Opcode: 1, Data: "incoming"})
+
+	time.Sleep(50 * time.Millisecond)
+	cancel()
+	<-done
+
+	body := w.Body.String()
+	if !strings.Contains(body, "event: frame") || !strings.Contains(body, "incoming") {
+		t.Errorf("expected the received frame as a message event, got: %s", body)
+	}
+	if strings.Contains(body, "outgoing") {
+		t.Errorf("sent frame should have been filtered out, got: %s", body)
+	}
+}
+
 func TestHandleNetworkStream_NilMonitor(t *testing.T) {
 	h := newNetworkTestHandler(nil)
 
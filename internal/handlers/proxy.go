@@ -0,0 +1,325 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/pinchtab/pinchtab/internal/config"
+	"github.com/pinchtab/pinchtab/internal/httpx"
+	"github.com/pinchtab/pinchtab/internal/netguard"
+)
+
+const (
+	// defaultProxyCheckTarget answers 204 from anywhere, cheaply.
+	defaultProxyCheckTarget  = "https://www.gstatic.com/generate_204"
+	defaultProxyCheckTimeout = 10 * time.Second
+	maxProxyCheckTimeout     = 60 * time.Second
+)
+
+// proxyCheckRequest is the body of POST /network/proxy/check.
+type proxyCheckRequest struct {
+	TabID     string              `json:"tabId"`
+	Proxy     *config.ProxyConfig `json:"proxy"`
+	Target    string              `json:"target"`
+	TimeoutMs int                 `json:"timeoutMs"`
+}
+
+// resolveTabProxy validates the proxy a request asks a new tab to use.
+// nil or an empty URL means the instance proxy. Chrome would relay the tab's
+// traffic through whatever host the request names, so the proxy must be on a
+// public address unless it is in security.trustedProxyCIDRs.
+func (h *Handlers) resolveTabProxy(ctx context.Context, p *config.ProxyConfig) (config.ProxyConfig, error) {
+	if p == nil {
+		return config.ProxyConfig{}, nil
+	}
+	resolved, err := p.Resolve()
+	if err != nil || !resolved.Active() {
+		return resolved, err
+	}
+	if err := checkProxyHost(ctx, resolved, parseCIDRs(h.Config.TrustedProxyCIDRs)); err != nil {
+		return config.ProxyConfig{}, err
+	}
+	return resolved, nil
+}
+
+// checkProxyHost refuses a proxy whose host is, or resolves to, a private or
+// internal address outside trusted.
+func checkProxyHost(ctx context.Context, p config.ProxyConfig, trusted []*net.IPNet) error {
+	u, err := url.Parse(p.URL)
+	if err != nil {
+		return fmt.Errorf("invalid proxy url")
+	}
+	host := u.Hostname()
+	ips := []net.IP{net.ParseIP(host)}
+	if ips[0] == nil {
+		ips, err = netguard.ResolveHostIPs(ctx, "ip", host)
+		if err != nil || len(ips) == 0 {
+			return fmt.Errorf("unsafe proxy: %s: %w", host, netguard.ErrResolveHost)
+		}
+	}
+	for _, ip := range ips {
+		if err := netguard.ValidatePublicIP(ip); err != nil && !inCIDRs(ip, trusted) {
+			return fmt.Errorf("unsafe proxy: %s: %w", host, err)
+		}
+	}
+	return nil
+}
+
+// createTab opens a blank tab, in a browser context of its own when it has
+// a proxy of its own.
+func (h *Handlers) createTab(proxy config.ProxyConfig) (string, context.Context, error) {
+	if proxy.Active() {
+		tabID, ctx, _, err := h.Bridge.CreateTabWithProxy("", proxy)
+		return tabID, ctx, err
+	}
+	tabID, ctx, _, err := h.Bridge.CreateTab("")
+	return tabID, ctx, err
+}
+
+// setupProxyAuth answers the proxy auth challenges of a tab being set up,
+// before its first request, when its proxy has credentials.
+func (h *Handlers) setupProxyAuth(tabCtx context.Context, tabID string) {
+	pm := h.Bridge.ProxyManager()
+	if pm == nil {
+		return
+	}
+	p, _ := pm.Tab(tabID)
+	user, pass, ok := p.Credentials()
+	if !ok {
+		return
+	}
+	ic := h.interceptor(tabID)
+	ic.SetProxyAuth(user, pass)
+	// Network capture starts after setup; attachInterceptor would start it
+	// twice.
+	var err error
+	if h.interceptAttach != nil {
+		err = h.interceptAttach(tabCtx, tabID, ic)
+	} else if err = ic.Attach(tabCtx); err == nil {
+		context.AfterFunc(tabCtx, func() { h.intercepts.drop(tabID, ic) })
+	}
+	if err != nil {
+		slog.Warn("proxy auth setup failed", "tab", tabID, "err", err)
+	}
+}
+
+// proxyStatus describes a proxy without its password.
+func proxyStatus(p config.ProxyConfig) map[string]any {
+	_, _, auth := p.Credentials()
+	return map[string]any{
+		"proxy":   p.Redacted(),
+		"enabled": p.Active(),
+		"auth":    auth,
+	}
+}
+
+// HandleProxy reports the upstream proxy a tab's traffic goes through.
+//
+// @Endpoint GET /network/proxy
+// @Endpoint GET /tabs/{id}/network/proxy
+// @Description Shows the tab's upstream proxy and the instance default, without passwords
+//
+// @Param tabId string query Tab ID (optional, uses current tab if empty)
+//
+// @Response 200 application/json Tab and instance proxy
+// @Response 404 application/json Tab not found
+func (h *Handlers) HandleProxy(w http.ResponseWriter, r *http.Request) {
+	pm := h.Bridge.ProxyManager()
+	if pm == nil {
+		httpx.ErrorCode(w, 503, "proxy_unavailable", "proxy settings are not available", false, nil)
+		return
+	}
+	_, tabID, ok := h.requestTab(w, r, "")
+	if !ok {
+		return
+	}
+	p, own := pm.Tab(tabID)
+	scope := scopeInstance
+	if own {
+		scope = scopeTab
+	}
+	out := proxyStatus(p)
+	out["tabId"] = tabID
+	out["scope"] = scope
+	out["instance"] = proxyStatus(pm.Instance())
+	httpx.JSON(w, 200, out)
+}
+
+// HandleProxyCheck fetches a URL through an upstream proxy to tell whether
+// the proxy works and how fast it answers.
+//
+// @Endpoint POST /network/proxy/check
+// @Endpoint POST /tabs/{id}/network/proxy/check
+// @Description Health-checks the instance, tab or given proxy with a request through it
+//
+// @Param tabId string body Tab whose proxy to check (optional, instance proxy if empty)
+// @Param proxy object body Proxy to check instead: {url, bypass}
+// @Param target string body URL to fetch (default https://www.gstatic.com/generate_204)
+// @Param timeoutMs int body Request timeout (default 10000, max 60000)
+//
+// @Response 200 application/json Check result; ok is false when the proxy failed
+// @Response 400 application/json Invalid or unsafe proxy or target, or no proxy configured
+// @Response 404 application/json Tab not found
+func (h *Handlers) HandleProxyCheck(w http.ResponseWriter, r *http.Request) {
+	var req proxyCheckRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBodySize)).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		httpx.Error(w, 400, fmt.Errorf("decode: %w", err))
+		return
+	}
+
+	// The check fetches from the server, so a proxy given in the request and
+	// the target get the download guard's address checks; the instance and
+	// tab proxies were already accepted when they were set.
+	guard := newDownloadURLGuard(h.Config.DownloadAllowedDomains)
+	var p config.ProxyConfig
+	var untrusted bool
+	switch {
+	case req.Proxy != nil:
+		resolved, err := req.Proxy.Resolve()
+		if err != nil {
+			httpx.ErrorCode(w, 400, "invalid_proxy", err.Error(), false, nil)
+			return
+		}
+		if err := guard.Validate(proxyHostURL(resolved)); err != nil {
+			httpx.ErrorCode(w, 400, "invalid_proxy", fmt.Sprintf("unsafe proxy: %v", err), false, nil)
+			return
+		}
+		p, untrusted = resolved, !guard.isDomainAllowed(proxyHostURL(resolved))
+	default:
+		pm := h.Bridge.ProxyManager()
+		if pm == nil {
+			httpx.ErrorCode(w, 503, "proxy_unavailable", "proxy settings are not available", false, nil)
+			return
+		}
+		p = pm.Instance()
+		if r.PathValue("id") != "" || req.TabID != "" {
+			_, tabID, ok := h.requestTab(w, r, req.TabID)
+			if !ok {
+				return
+			}
+			p, _ = pm.Tab(tabID)
+		}
+	}
+	if !p.Active() {
+		httpx.ErrorCode(w, 400, "no_proxy", "no proxy configured", false, nil)
+		return
+	}
+
+	target := req.Target
+	if target == "" {
+		target = defaultProxyCheckTarget
+	}
+	if u, err := url.Parse(target); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		httpx.Error(w, 400, fmt.Errorf("target must be an http or https URL"))
+		return
+	}
+	if err := guard.Validate(target); err != nil {
+		httpx.Error(w, 400, fmt.Errorf("unsafe target: %w", err))
+		return
+	}
+	timeout := defaultProxyCheckTimeout
+	if req.TimeoutMs > 0 {
+		timeout = min(time.Duration(req.TimeoutMs)*time.Millisecond, maxProxyCheckTimeout)
+	}
+
+	result := checkProxy(r.Context(), p, target, timeout, untrusted)
+	result["proxy"] = p.Redacted()
+	result["target"] = target
+	httpx.JSON(w, 200, result)
+}
+
+// proxyHostURL turns a proxy into an http URL of its host, in the form the
+// download guard checks.
+func proxyHostURL(p config.ProxyConfig) string {
+	u, err := url.Parse(p.URL)
+	if err != nil {
+		return ""
+	}
+	return "http://" + u.Host
+}
+
+// publicDialControl refuses connections to private and internal addresses,
+// so a proxy host cannot be rebound to one after it was validated.
+func publicDialControl(_, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	if err := netguard.ValidatePublicIP(net.ParseIP(host)); err != nil {
+		return fmt.Errorf("proxy connected to blocked address %s", host)
+	}
+	return nil
+}
+
+// checkProxy fetches target through p. Failures are reported in the result
+// rather than as an error, since they are what the caller wants to know.
+// When untrusted is set, only public addresses may be dialled.
+func checkProxy(ctx context.Context, p config.ProxyConfig, target string, timeout time.Duration, untrusted bool) map[string]any {
+	proxyURL, err := url.Parse(p.URL)
+	if err != nil {
+		return map[string]any{"ok": false, "error": "invalid proxy url"}
+	}
+	if proxyURL.Scheme == "socks4" {
+		return map[string]any{"ok": false, "error": "socks4 proxies cannot be health-checked"}
+	}
+	transport := &http.Transport{
+		Proxy:               http.ProxyURL(proxyURL),
+		TLSHandshakeTimeout: timeout,
+		DisableKeepAlives:   true,
+	}
+	if untrusted {
+		dialer := &net.Dialer{Timeout: timeout, Control: publicDialControl}
+		transport.DialContext = dialer.DialContext
+	}
+	defer transport.CloseIdleConnections()
+	client := &http.Client{
+		Transport: transport,
+		Timeout:   timeout,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return map[string]any{"ok": false, "error": err.Error()}
+	}
+	start := time.Now()
+	resp, err := client.Do(req)
+	latency := time.Since(start).Milliseconds()
+	if err != nil {
+		return map[string]any{"ok": false, "latencyMs": latency, "error": redactProxyURL(err.Error(), p)}
+	}
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	_ = resp.Body.Close()
+
+	out := map[string]any{"ok": true, "status": resp.StatusCode, "latencyMs": latency}
+	switch resp.StatusCode {
+	case http.StatusProxyAuthRequired:
+		out["ok"] = false
+		out["error"] = "proxy authentication failed"
+	case http.StatusBadGateway, http.StatusGatewayTimeout:
+		out["ok"] = false
+		out["error"] = "proxy could not reach the target"
+	}
+	return out
+}
+
+// redactProxyURL keeps a transport error from echoing the proxy password.
+func redactProxyURL(msg string, p config.ProxyConfig) string {
+	if _, pass, ok := p.Credentials(); ok && pass != "" {
+		msg = strings.ReplaceAll(msg, p.URL, p.Redacted().URL)
+		msg = strings.ReplaceAll(msg, pass, "xxxxx")
+	}
+	return msg
+}
@@ -0,0 +1,274 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/chromedp/cdproto/cdp"
+	"github.com/chromedp/cdproto/dom"
+	"github.com/chromedp/chromedp"
+	"github.com/pinchtab/pinchtab/internal/bridge"
+	"github.com/pinchtab/pinchtab/internal/httpx"
+)
+
+// scriptVersion is the format version written into exported recordings.
+// Replay refuses scripts from a newer format.
+const scriptVersion = 1
+
+// maxRecordedSteps bounds a single recording; later steps are dropped.
+const maxRecordedSteps = 1000
+
+// Step types in a recorded script.
+const (
+	stepNavigate = "navigate"
+	stepAction   = "action"
+	stepWait     = "wait"
+)
+
+// stableAttrs are the element attributes captured with each recorded
+// target. They usually survive re-renders that reassign snapshot refs.
+var stableAttrs = []string{"id", "name", "data-testid", "data-test", "data-qa", "aria-label", "placeholder", "type", "href"}
+
+// recordedScript is a portable recording of the navigations, actions and
+// waits sent for one tab.
+type recordedScript struct {
+	Version    int            `json:"version"`
+	TabID      string         `json:"tabId,omitempty"`
+	RecordedAt time.Time      `json:"recordedAt"`
+	Truncated  bool           `json:"truncated,omitempty"`
+	Steps      []recordedStep `json:"steps"`
+}
+
+// recordedStep is one call captured by the recorder. Navigate steps use the
+// URL and wait fields, action steps use Action and Target, and wait steps
+// use Wait.
+type recordedStep struct {
+	Type         string                `json:"type"`
+	URL          string                `json:"url,omitempty"`
+	WaitFor      string                `json:"waitFor,omitempty"`
+	WaitSelector string                `json:"waitSelector,omitempty"`
+	Action       *bridge.ActionRequest `json:"action,omitempty"`
+	Target       *recordedTarget       `json:"target,omitempty"`
+	Wait         *waitRequest          `json:"wait,omitempty"`
+}
+
+// recordedTarget is the element an action hit: the selector as sent, the
+// ref it resolved to, and what the snapshot and DOM said about the element
+// at the time. Replay uses the descriptor when the ref no longer matches.
+type recordedTarget struct {
+	Selector string            `json:"selector,omitempty"`
+	Ref      string            `json:"ref,omitempty"`
+	Role     string            `json:"role,omitempty"`
+	Name     string            `json:"name,omitempty"`
+	Attrs    map[string]string `json:"attrs,omitempty"`
+}
+
+// recordingStore holds the active recordings, keyed by tab ID. A recording
+// is dropped when its tab closes.
+type recordingStore struct {
+	mu       sync.Mutex
+	sessions map[string]*recordedScript
+}
+
+// start begins a fresh recording for the tab, discarding any previous one.
+func (rs *recordingStore) start(tabID string) *recordedScript {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	if rs.sessions == nil {
+		rs.sessions = make(map[string]*recordedScript)
+	}
+	s := &recordedScript{Version: scriptVersion, TabID: tabID, RecordedAt: time.Now().UTC(), Steps: []recordedStep{}}
+	rs.sessions[tabID] = s
+	return s
+}
+
+// drop ends the tab's recording if it is still s, so a tab that closed
+// leaves nothing behind for a later tab with the same ID.
+func (rs *recordingStore) drop(tabID string, s *recordedScript) {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	if rs.sessions[tabID] == s {
+		delete(rs.sessions, tabID)
+	}
+}
+
+func (rs *recordingStore) active(tabID string) bool {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	_, ok := rs.sessions[tabID]
+	return ok
+}
+
+func (rs *recordingStore) add(tabID string, step recordedStep) {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	s, ok := rs.sessions[tabID]
+	if !ok {
+		return
+	}
+	if len(s.Steps) >= maxRecordedSteps {
+		s.Truncated = true
+		return
+	}
+	s.Steps = append(s.Steps, step)
+}
+
+// export returns a copy of the tab's recording; stop also ends it.
+func (rs *recordingStore) export(tabID string, stop bool) (recordedScript, bool) {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	s, ok := rs.sessions[tabID]
+	if !ok {
+		return recordedScript{}, false
+	}
+	if stop {
+		delete(rs.sessions, tabID)
+	}
+	out := *s
+	out.Steps = slices.Clone(s.Steps)
+	return out, true
+}
+
+// actionTarget describes the element an action is about to hit so the step
+// can be recorded once it succeeds. given is the selector as sent. It
+// returns nil when the tab is not being recorded or the action has no
+// element target.
+func (h *Handlers) actionTarget(ctx context.Context, tabID, given string, req bridge.ActionRequest) *recordedTarget {
+	if !h.recordings.active(tabID) || (given == "" && req.NodeID == 0) {
+		return nil
+	}
+	t := &recordedTarget{Selector: given, Ref: req.Ref}
+	if req.Ref != "" {
+		for _, n := range h.resolveSnapshotNodes(tabID) {
+			if n.Ref == req.Ref {
+				t.Role, t.Name = n.Role, n.Name
+				break
+			}
+		}
+	}
+	if req.NodeID != 0 {
+		t.Attrs = elementAttrs(ctx, req.NodeID)
+	}
+	return t
+}
+
+// elementAttrs reads the stable attributes of a DOM node. It is best
+// effort: a node that cannot be described yields no attributes.
+func elementAttrs(ctx context.Context, backendNodeID int64) map[string]string {
+	var node *cdp.Node
+	err := chromedp.Run(ctx, chromedp.ActionFunc(func(ctx context.Context) error {
+		var err error
+		node, err = dom.DescribeNode().WithBackendNodeID(cdp.BackendNodeID(backendNodeID)).Do(ctx)
+		return err
+	}))
+	if err != nil || node == nil {
+		return nil
+	}
+	attrs := make(map[string]string)
+	for i := 0; i+1 < len(node.Attributes); i += 2 {
+		if name, value := node.Attributes[i], node.Attributes[i+1]; value != "" && slices.Contains(stableAttrs, name) {
+			attrs[name] = value
+		}
+	}
+	if len(attrs) == 0 {
+		return nil
+	}
+	return attrs
+}
+
+// recordAction appends a successful action to the tab's recording. The
+// element is kept in target rather than in the request, whose ref and node
+// ID are only meaningful for the current snapshot.
+func (h *Handlers) recordAction(tabID string, req bridge.ActionRequest, target *recordedTarget) {
+	if !h.recordings.active(tabID) {
+		return
+	}
+	req.TabID, req.Owner = "", ""
+	req.Ref, req.Selector, req.NodeID = "", "", 0
+	h.recordings.add(tabID, recordedStep{Type: stepAction, Action: &req, Target: target})
+}
+
+func (h *Handlers) recordNavigate(tabID, url, waitFor, waitSelector string) {
+	h.recordings.add(tabID, recordedStep{Type: stepNavigate, URL: url, WaitFor: waitFor, WaitSelector: waitSelector})
+}
+
+func (h *Handlers) recordWait(tabID string, req waitRequest) {
+	req.TabID = ""
+	h.recordings.add(tabID, recordedStep{Type: stepWait, Wait: &req})
+}
+
+// recordingTab resolves the tab a recording request refers to: the path
+// ID, the tabId query parameter, or the tabId in a POST body.
+func (h *Handlers) recordingTab(w http.ResponseWriter, r *http.Request) (context.Context, string, bool) {
+	tabID := r.PathValue("id")
+	if tabID == "" {
+		tabID = r.URL.Query().Get("tabId")
+	}
+	if tabID == "" && r.Method == http.MethodPost {
+		var body struct {
+			TabID string `json:"tabId"`
+		}
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBodySize)).Decode(&body); err != nil && !errors.Is(err, io.EOF) {
+			httpx.Error(w, 400, fmt.Errorf("decode: %w", err))
+			return nil, "", false
+		}
+		tabID = body.TabID
+	}
+	tabCtx, resolvedTabID, err := h.tabContext(r, tabID)
+	if err != nil {
+		httpx.Error(w, 404, err)
+		return nil, "", false
+	}
+	return tabCtx, resolvedTabID, true
+}
+
+// HandleRecordStart starts recording the navigations, actions and waits
+// sent for a tab. Starting again discards the current recording.
+//
+// @Endpoint POST /record/start
+// @Endpoint POST /tabs/{id}/record/start
+func (h *Handlers) HandleRecordStart(w http.ResponseWriter, r *http.Request) {
+	tabCtx, tabID, ok := h.recordingTab(w, r)
+	if !ok {
+		return
+	}
+	s := h.recordings.start(tabID)
+	context.AfterFunc(tabCtx, func() { h.recordings.drop(tabID, s) })
+	httpx.JSON(w, 200, map[string]any{"recording": true, "tabId": tabID, "version": s.Version})
+}
+
+// HandleRecordStop ends a tab's recording and returns the script.
+//
+// @Endpoint POST /record/stop
+// @Endpoint POST /tabs/{id}/record/stop
+func (h *Handlers) HandleRecordStop(w http.ResponseWriter, r *http.Request) {
+	h.writeRecording(w, r, true)
+}
+
+// HandleRecord returns a tab's recording so far without ending it.
+//
+// @Endpoint GET /record
+// @Endpoint GET /tabs/{id}/record
+func (h *Handlers) HandleRecord(w http.ResponseWriter, r *http.Request) {
+	h.writeRecording(w, r, false)
+}
+
+func (h *Handlers) writeRecording(w http.ResponseWriter, r *http.Request, stop bool) {
+	_, tabID, ok := h.recordingTab(w, r)
+	if !ok {
+		return
+	}
+	s, ok := h.recordings.export(tabID, stop)
+	if !ok {
+		httpx.ErrorCode(w, 404, "not_recording", fmt.Sprintf("tab %s is not being recorded", tabID), false, nil)
+		return
+	}
+	httpx.JSON(w, 200, s)
+}
@@ -0,0 +1,199 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/pinchtab/pinchtab/internal/bridge"
+	"github.com/pinchtab/pinchtab/internal/config"
+	"github.com/pinchtab/semantic"
+)
+
+// recordMockBridge records the actions that reach the bridge. tabCtx, when
+// set, stands in for the tab's own context.
+type recordMockBridge struct {
+	findMockBridge
+	executed []bridge.ActionRequest
+	tabCtx   context.Context
+}
+
+func (m *recordMockBridge) TabContext(tabID string) (context.Context, string, error) {
+	if m.tabCtx != nil {
+		return m.tabCtx, "tab1", nil
+	}
+	return m.findMockBridge.TabContext(tabID)
+}
+
+func (m *recordMockBridge) ExecuteAction(ctx context.Context, kind string, req bridge.ActionRequest) (map[string]any, error) {
+	m.executed = append(m.executed, req)
+	return map[string]any{"ok": true}, nil
+}
+
+func newRecordTestHandler(nodes ...bridge.A11yNode) (*Handlers, *recordMockBridge, *http.ServeMux) {
+	cache := &bridge.RefCache{Refs: map[string]int64{}, Nodes: nodes}
+	for i, n := range nodes {
+		cache.Refs[n.Ref] = int64(100 + i)
+	}
+	mb := &recordMockBridge{findMockBridge: findMockBridge{refCache: cache}}
+	h := New(mb, &config.RuntimeConfig{ActionTimeout: 10 * time.Second}, nil, nil, nil)
+	h.Matcher = semantic.NewLexicalMatcher()
+	mux := http.NewServeMux()
+	h.RegisterRoutes(mux, nil)
+	return h, mb, mux
+}
+
+func doJSON(t *testing.T, mux *http.ServeMux, method, path string, body any) *httptest.ResponseRecorder {
+	t.Helper()
+	var payload []byte
+	if body != nil {
+		payload, _ = json.Marshal(body)
+	}
+	req := httptest.NewRequest(method, path, bytes.NewReader(payload))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, req)
+	return w
+}
+
+func TestRecordCapturesActionsAndWaits(t *testing.T) {
+	_, _, mux := newRecordTestHandler(bridge.A11yNode{Ref: "e1", Role: "button", Name: "Submit"})
+
+	if w := doJSON(t, mux, "POST", "/tabs/tab1/record/start", nil); w.Code != 200 {
+		t.Fatalf("start: expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	doJSON(t, mux, "POST", "/action", map[string]any{"kind": "click", "ref": "e1"})
+	doJSON(t, mux, "POST", "/tabs/tab1/actions", map[string]any{
+		"actions": []map[string]any{{"kind": "type", "selector": "#q", "text": "hello"}},
+	})
+	// A fixed wait without tabId is recorded against the current tab.
+	doJSON(t, mux, "POST", "/wait", map[string]any{"ms": 0})
+
+	w := doJSON(t, mux, "POST", "/tabs/tab1/record/stop", nil)
+	if w.Code != 200 {
+		t.Fatalf("stop: expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var script recordedScript
+	if err := json.Unmarshal(w.Body.Bytes(), &script); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if script.Version != scriptVersion || len(script.Steps) != 3 {
+		t.Fatalf("expected 3 steps at version %d, got %+v", scriptVersion, script)
+	}
+
+	click := script.Steps[0]
+	if click.Type != stepAction || click.Action.Kind != "click" || click.Action.Ref != "" || click.Action.NodeID != 0 {
+		t.Errorf("unexpected click step: %+v", click.Action)
+	}
+	if tgt := click.Target; tgt == nil || tgt.Ref != "e1" || tgt.Role != "button" || tgt.Name != "Submit" {
+		t.Errorf("expected the click target to carry the snapshot descriptor, got %+v", tgt)
+	}
+	if typ := script.Steps[1]; typ.Target == nil || typ.Target.Selector != "#q" || typ.Action.Text != "hello" {
+		t.Errorf("unexpected type step: %+v", typ)
+	}
+	if wait := script.Steps[2]; wait.Type != stepWait || wait.Wait == nil || wait.Wait.Ms == nil || wait.Wait.TabID != "" {
+		t.Errorf("unexpected wait step: %+v", wait)
+	}
+
+	if w := doJSON(t, mux, "GET", "/tabs/tab1/record", nil); w.Code != 404 {
+		t.Errorf("expected 404 after stop, got %d", w.Code)
+	}
+}
+
+func TestReplayReresolvesStaleRefs(t *testing.T) {
+	_, mb, mux := newRecordTestHandler(
+		bridge.A11yNode{Ref: "e1", Role: "link", Name: "Home"},
+		bridge.A11yNode{Ref: "e2", Role: "button", Name: "Submit"},
+	)
+	script := recordedScript{Version: scriptVersion, Steps: []recordedStep{
+		{Type: stepAction, Action: &bridge.ActionRequest{Kind: "click"},
+			Target: &recordedTarget{Selector: "e1", Ref: "e1", Role: "button", Name: "Submit"}},
+		{Type: stepAction, Action: &bridge.ActionRequest{Kind: "fill", Value: "pinchtab"},
+			Target: &recordedTarget{Selector: "e7", Ref: "e7", Role: "searchbox", Name: "Search", Attrs: map[string]string{"id": "q", "data-testid": "search"}}},
+		{Type: stepAction, Action: &bridge.ActionRequest{Kind: "click"},
+			Target: &recordedTarget{Selector: "e9", Ref: "e9", Role: "checkbox", Name: "zzz"}},
+		{Type: stepAction, Action: &bridge.ActionRequest{Kind: "click"}, Target: &recordedTarget{Selector: "e2"}},
+	}}
+
+	w := doJSON(t, mux, "POST", "/tabs/tab1/replay", map[string]any{"script": script})
+	if w.Code != 200 {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp struct {
+		Results    []replayStepResult `json:"results"`
+		Successful int                `json:"successful"`
+		Completed  bool               `json:"completed"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if resp.Completed || resp.Successful != 2 || len(resp.Results) != 3 {
+		t.Fatalf("expected to stop at the unresolvable third step, got %+v", resp)
+	}
+	if r := resp.Results[0]; r.Selector != "e2" || r.Resolution != resolvedSnapshot {
+		t.Errorf("expected the stale ref to resolve to e2 by role and name, got %+v", r)
+	}
+	if r := resp.Results[1]; r.Selector != `css:[data-testid="search"]` || r.Resolution != resolvedAttribute {
+		t.Errorf("expected a test-id fallback, got %+v", r)
+	}
+	if r := resp.Results[2]; r.Success || r.Error == "" {
+		t.Errorf("expected the third step to fail, got %+v", r)
+	}
+
+	if len(mb.executed) != 2 {
+		t.Fatalf("expected 2 actions to reach the bridge, got %d", len(mb.executed))
+	}
+	if got := mb.executed[0]; got.Ref != "e2" || got.NodeID != 101 {
+		t.Errorf("expected the click on e2 (node 101), got ref=%q node=%d", got.Ref, got.NodeID)
+	}
+	if got := mb.executed[1]; got.Selector != `[data-testid="search"]` || got.Value != "pinchtab" {
+		t.Errorf("unexpected fill request: %+v", got)
+	}
+}
+
+func TestReplayRejectsBadScripts(t *testing.T) {
+	_, _, mux := newRecordTestHandler()
+	for _, script := range []recordedScript{
+		{Version: scriptVersion + 1, Steps: []recordedStep{{Type: stepNavigate, URL: "https://example.com"}}},
+		{Version: scriptVersion},
+	} {
+		if w := doJSON(t, mux, "POST", "/replay", map[string]any{"script": script}); w.Code != 400 {
+			t.Errorf("expected 400 for %+v, got %d", script, w.Code)
+		}
+	}
+}
+
+func TestRecordingDroppedWhenTabCloses(t *testing.T) {
+	h, mb, mux := newRecordTestHandler()
+	tabCtx, closeTab := context.WithCancel(context.Background())
+	mb.tabCtx = tabCtx
+
+	if w := doJSON(t, mux, "POST", "/tabs/tab1/record/start", nil); w.Code != 200 {
+		t.Fatalf("start: expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	doJSON(t, mux, "POST", "/wait", map[string]any{"ms": 0})
+	closeTab()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for h.recordings.active("tab1") {
+		if time.Now().After(deadline) {
+			t.Fatal("recording should be dropped when its tab closes")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	// A new tab with the same ID starts clean, and the old tab's cleanup
+	// does not end its recording.
+	mb.tabCtx = context.Background()
+	doJSON(t, mux, "POST", "/tabs/tab1/record/start", nil)
+	w := doJSON(t, mux, "GET", "/tabs/tab1/record", nil)
+	var script recordedScript
+	_ = json.Unmarshal(w.Body.Bytes(), &script)
+	if w.Code != 200 || len(script.Steps) != 0 {
+		t.Errorf("expected a fresh recording, got %d: %s", w.Code, w.Body.String())
+	}
+}
@@ -0,0 +1,275 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/pinchtab/pinchtab/internal/httpx"
+	"github.com/pinchtab/pinchtab/internal/selector"
+	"github.com/pinchtab/semantic"
+)
+
+// Target resolutions reported per replayed action.
+const (
+	resolvedSelector  = "selector"  // recorded selector used as-is
+	resolvedRef       = "ref"       // recorded ref still names the same element
+	resolvedSnapshot  = "snapshot"  // unique role and name match in a fresh snapshot
+	resolvedAttribute = "attribute" // stable attribute turned into a CSS selector
+	resolvedFind      = "find"      // semantic match on the recorded role and name
+)
+
+// attrSelectorOrder is the preference order for attribute fallbacks; test
+// IDs are the most deliberate, accessible labels the least unique.
+var attrSelectorOrder = []string{"data-testid", "data-test", "data-qa", "id", "name", "aria-label", "placeholder"}
+
+type replayRequest struct {
+	TabID           string         `json:"tabId"`
+	Owner           string         `json:"owner"`
+	Script          recordedScript `json:"script"`
+	ContinueOnError bool           `json:"continueOnError"`
+}
+
+type replayStepResult struct {
+	Index      int             `json:"index"`
+	Type       string          `json:"type"`
+	Success    bool            `json:"success"`
+	Status     int             `json:"status"`
+	Selector   string          `json:"selector,omitempty"`
+	Resolution string          `json:"resolution,omitempty"`
+	Result     json.RawMessage `json:"result,omitempty"`
+	Error      string          `json:"error,omitempty"`
+}
+
+// HandleReplay replays a recorded script against a tab. Each step goes
+// through the same handler that recorded it. Action targets recorded as
+// refs are re-resolved against a fresh snapshot first, falling back to
+// stable attributes and semantic matching when the ref no longer matches.
+//
+// @Endpoint POST /replay
+// @Endpoint POST /tabs/{id}/replay
+func (h *Handlers) HandleReplay(w http.ResponseWriter, r *http.Request) {
+	var req replayRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBodySize)).Decode(&req); err != nil {
+		httpx.Error(w, 400, fmt.Errorf("decode: %w", err))
+		return
+	}
+	if pathID := r.PathValue("id"); pathID != "" {
+		if req.TabID != "" && req.TabID != pathID {
+			httpx.Error(w, 400, fmt.Errorf("tabId in body does not match path id"))
+			return
+		}
+		req.TabID = pathID
+	}
+	if req.Script.Version < 1 || req.Script.Version > scriptVersion {
+		httpx.ErrorCode(w, 400, "bad_script", fmt.Sprintf("unsupported script version %d (supported: %d)", req.Script.Version, scriptVersion), false, nil)
+		return
+	}
+	if len(req.Script.Steps) == 0 {
+		httpx.ErrorCode(w, 400, "bad_script", "script has no steps", false, nil)
+		return
+	}
+
+	ctx, resolvedTabID, err := h.tabContext(r, req.TabID)
+	if err != nil {
+		httpx.Error(w, 404, err)
+		return
+	}
+	owner := resolveOwner(r, req.Owner)
+	if err := h.enforceTabLease(resolvedTabID, owner); err != nil {
+		httpx.ErrorCode(w, 423, "tab_locked", err.Error(), false, nil)
+		return
+	}
+
+	results := make([]replayStepResult, 0, len(req.Script.Steps))
+	successful := 0
+	for i, step := range req.Script.Steps {
+		res := h.replayStep(ctx, r, resolvedTabID, owner, step)
+		res.Index, res.Type = i, step.Type
+		results = append(results, res)
+		if res.Success {
+			successful++
+		} else if !req.ContinueOnError {
+			break
+		}
+	}
+
+	httpx.JSON(w, 200, map[string]any{
+		"tabId":      resolvedTabID,
+		"results":    results,
+		"total":      len(req.Script.Steps),
+		"successful": successful,
+		"failed":     len(results) - successful,
+		"completed":  successful == len(req.Script.Steps),
+	})
+}
+
+func (h *Handlers) replayStep(ctx context.Context, r *http.Request, tabID, owner string, step recordedStep) replayStepResult {
+	var res replayStepResult
+	var body []byte
+	switch step.Type {
+	case stepNavigate:
+		res.Status, body = replayCall(r, owner, h.HandleNavigate, map[string]any{
+			"tabId": tabID, "url": step.URL, "waitFor": step.WaitFor, "waitSelector": step.WaitSelector,
+		})
+	case stepWait:
+		if step.Wait == nil {
+			res.Error = "wait step has no wait"
+			return res
+		}
+		wait := *step.Wait
+		wait.TabID = tabID
+		res.Status, body = replayCall(r, owner, h.HandleWait, wait)
+	case stepAction:
+		if step.Action == nil {
+			res.Error = "action step has no action"
+			return res
+		}
+		action := *step.Action
+		action.TabID, action.Owner = tabID, owner
+		if step.Target != nil {
+			sel, how, err := h.resolveReplayTarget(ctx, tabID, step.Target)
+			if err != nil {
+				res.Error = err.Error()
+				return res
+			}
+			action.Selector, res.Selector, res.Resolution = sel, sel, how
+		}
+		res.Status, body = replayCall(r, owner, h.HandleAction, action)
+	default:
+		res.Error = fmt.Sprintf("unknown step type %q", step.Type)
+		return res
+	}
+
+	if res.Status >= 400 {
+		var payload struct {
+			Error string `json:"error"`
+		}
+		_ = json.Unmarshal(body, &payload)
+		res.Error = payload.Error
+		if res.Error == "" {
+			res.Error = strings.TrimSpace(string(body))
+		}
+		return res
+	}
+	// Waits report a timeout with 200 and waited=false.
+	if step.Type == stepWait {
+		var wr waitResponse
+		if err := json.Unmarshal(body, &wr); err == nil && !wr.Waited {
+			res.Error = wr.Error
+			return res
+		}
+	}
+	res.Success = true
+	res.Result = json.RawMessage(body)
+	return res
+}
+
+// resolveReplayTarget picks the selector a replayed action should use.
+// CSS, XPath, text and semantic selectors are resolved again by the action
+// handler, so only ref and node targets need work here.
+func (h *Handlers) resolveReplayTarget(ctx context.Context, tabID string, t *recordedTarget) (string, string, error) {
+	if t.Selector != "" && selector.Parse(t.Selector).Kind != selector.KindRef {
+		return t.Selector, resolvedSelector, nil
+	}
+
+	h.refreshRefCache(ctx, tabID)
+	nodes := h.resolveSnapshotNodes(tabID)
+	described := t.Role != "" || t.Name != ""
+
+	for _, n := range nodes {
+		if n.Ref == t.Ref && (!described || (n.Role == t.Role && n.Name == t.Name)) {
+			return n.Ref, resolvedRef, nil
+		}
+	}
+	if described {
+		match := ""
+		for _, n := range nodes {
+			if n.Role == t.Role && n.Name == t.Name {
+				if match != "" {
+					match = ""
+					break
+				}
+				match = n.Ref
+			}
+		}
+		if match != "" {
+			return match, resolvedSnapshot, nil
+		}
+	}
+	for _, attr := range attrSelectorOrder {
+		if v := t.Attrs[attr]; v != "" {
+			return "css:" + cssAttrSelector(attr, v), resolvedAttribute, nil
+		}
+	}
+	if described && h.Matcher != nil && len(nodes) > 0 {
+		descs := make([]semantic.ElementDescriptor, len(nodes))
+		for i, n := range nodes {
+			descs[i] = semantic.ElementDescriptor{Ref: n.Ref, Role: n.Role, Name: n.Name, Value: n.Value}
+		}
+		result, err := h.Matcher.Find(ctx, strings.TrimSpace(t.Name+" "+t.Role), descs, semantic.FindOptions{
+			Threshold: 0.3, TopK: 1,
+		})
+		if err == nil && result.BestRef != "" {
+			return result.BestRef, resolvedFind, nil
+		}
+	}
+	return "", "", fmt.Errorf("target %s (%s %q) not found in the current page", t.Ref, t.Role, t.Name)
+}
+
+// cssAttrSelector builds an exact-match attribute selector.
+func cssAttrSelector(attr, value string) string {
+	value = strings.ReplaceAll(value, `\`, `\\`)
+	value = strings.ReplaceAll(value, `"`, `\"`)
+	return fmt.Sprintf(`[%s="%s"]`, attr, value)
+}
+
+// replayCall runs a step through handler as if the client had sent body
+// itself, and returns the status and body the handler wrote.
+func replayCall(r *http.Request, owner string, handler http.HandlerFunc, body any) (int, []byte) {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return 500, []byte(fmt.Sprintf(`{"error":%q}`, err.Error()))
+	}
+	req := r.Clone(r.Context())
+	req.Method = http.MethodPost
+	req.Body = io.NopCloser(bytes.NewReader(payload))
+	req.ContentLength = int64(len(payload))
+	req.Header = r.Header.Clone()
+	req.Header.Set("Content-Type", "application/json")
+	if owner != "" {
+		req.Header.Set("X-Owner", owner)
+	}
+	rw := &replayWriter{header: http.Header{}}
+	handler(rw, req)
+	if rw.status == 0 {
+		rw.status = http.StatusOK
+	}
+	return rw.status, rw.body.Bytes()
+}
+
+// replayWriter captures the response of a replayed step.
+type replayWriter struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (rw *replayWriter) Header() http.Header { return rw.header }
+
+func (rw *replayWriter) WriteHeader(status int) {
+	if rw.status == 0 {
+		rw.status = status
+	}
+}
+
+func (rw *replayWriter) Write(b []byte) (int, error) {
+	if rw.status == 0 {
+		rw.status = http.StatusOK
+	}
+	return rw.body.Write(b)
+}
@@ -17,7 +17,9 @@ import (
 	"github.com/pinchtab/pinchtab/internal/httpx"
 )
 
-// HandleScreenshot captures a screenshot of the current tab.
+// HandleScreenshot captures a screenshot of the current tab. With
+// marks=true, the interactive refs of the tab's snapshot are drawn on the
+// image and their boxes returned alongside it.
 //
 // @Endpoint GET /screenshot
 func (h *Handlers) HandleScreenshot(w http.ResponseWriter, r *http.Request) {
@@ -33,6 +35,7 @@ func (h *Handlers) HandleScreenshot(w http.ResponseWriter, r *http.Request) {
 	tabID := r.URL.Query().Get("tabId")
 	output := r.URL.Query().Get("output")
 	reqNoAnim := r.URL.Query().Get("noAnimations") == "true"
+	withMarks := r.URL.Query().Get("marks") == "true"
 
 	ctx, resolvedTabID, err := h.tabContext(r, tabID)
 	if err != nil {
@@ -72,7 +75,17 @@ func (h *Handlers) HandleScreenshot(w http.ResponseWriter, r *http.Request) {
 		ext = ".png"
 	}
 
-	if err := chromedp.Run(tCtx,
+	var marks map[string]screenshotMark
+	removeMarks := func() {}
+	if withMarks {
+		marks, removeMarks, err = h.drawMarks(tCtx, ctx, resolvedTabID)
+		if err != nil {
+			httpx.Error(w, 500, err)
+			return
+		}
+	}
+
+	err = chromedp.Run(tCtx,
 		chromedp.ActionFunc(func(ctx context.Context) error {
 			var err error
 			shot := page.CaptureScreenshot().WithFormat(format)
@@ -82,7 +95,9 @@ func (h *Handlers) HandleScreenshot(w http.ResponseWriter, r *http.Request) {
 			buf, err = shot.Do(ctx)
 			return err
 		}),
-	); err != nil {
+	)
+	removeMarks()
+	if err != nil {
 		httpx.Error(w, 500, fmt.Errorf("screenshot: %w", err))
 		return
 	}
@@ -103,12 +118,16 @@ func (h *Handlers) HandleScreenshot(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 
-		httpx.JSON(w, 200, map[string]any{
+		resp := map[string]any{
 			"path":      filePath,
 			"size":      len(buf),
 			"format":    string(format),
 			"timestamp": timestamp,
-		})
+		}
+		if withMarks {
+			resp["marks"] = marks
+		}
+		httpx.JSON(w, 200, resp)
 		return
 	}
 
@@ -120,10 +139,14 @@ func (h *Handlers) HandleScreenshot(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	httpx.JSON(w, 200, map[string]any{
+	resp := map[string]any{
 		"format": string(format),
 		"base64": base64.StdEncoding.EncodeToString(buf),
-	})
+	}
+	if withMarks {
+		resp["marks"] = marks
+	}
+	httpx.JSON(w, 200, resp)
 }
 
 // HandleTabScreenshot returns screenshot bytes for a tab identified by path ID.
@@ -0,0 +1,113 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/chromedp/chromedp"
+	"github.com/pinchtab/pinchtab/internal/assets"
+	"github.com/pinchtab/pinchtab/internal/bridge"
+)
+
+// screenshotMark is a ref drawn on a set-of-marks screenshot. The box is
+// in image pixels.
+type screenshotMark struct {
+	Role string     `json:"role"`
+	Name string     `json:"name,omitempty"`
+	Box  bridge.Box `json:"box"`
+}
+
+// refBox is a ref's box in CSS pixels, as passed to and from the overlay
+// script.
+type refBox struct {
+	Ref string `json:"ref"`
+	bridge.Box
+}
+
+// drawnMarks is what the overlay script reports: the boxes it drew,
+// clipped to the viewport, and the device pixel ratio.
+type drawnMarks struct {
+	Scale float64  `json:"scale"`
+	Marks []refBox `json:"marks"`
+}
+
+// drawMarks overlays a labelled box on every interactive ref of the tab's
+// snapshot that is visible in the viewport. It returns the drawn boxes
+// keyed by ref and a function that removes the overlay. Refs come from the
+// current ref cache, so labels match the caller's last snapshot.
+func (h *Handlers) drawMarks(ctx, tabCtx context.Context, tabID string) (map[string]screenshotMark, func(), error) {
+	cache := h.Bridge.GetRefCache(tabID)
+	if cache == nil || len(cache.Nodes) == 0 {
+		h.refreshRefCache(ctx, tabID)
+		cache = h.Bridge.GetRefCache(tabID)
+	}
+	if cache == nil {
+		return map[string]screenshotMark{}, func() {}, nil
+	}
+
+	nodes := make(map[string]bridge.A11yNode)
+	var boxes []refBox
+	for _, n := range cache.Nodes {
+		if n.Hidden || !bridge.InteractiveRoles[n.Role] {
+			continue
+		}
+		nodeID, ok := cache.Refs[n.Ref]
+		if !ok {
+			continue
+		}
+		box, err := bridge.GetElementBox(ctx, nodeID)
+		if err != nil {
+			// Not rendered: nothing to draw.
+			continue
+		}
+		nodes[n.Ref] = n
+		boxes = append(boxes, refBox{Ref: n.Ref, Box: box})
+	}
+	if boxes == nil {
+		boxes = []refBox{}
+	}
+
+	payload, err := json.Marshal(boxes)
+	if err != nil {
+		return nil, func() {}, err
+	}
+	var drawn drawnMarks
+	js := fmt.Sprintf("(%s)(%s)", strings.TrimSpace(assets.MarksJS), payload)
+	if err := chromedp.Run(ctx, chromedp.Evaluate(js, &drawn)); err != nil {
+		return nil, func() {}, fmt.Errorf("draw marks: %w", err)
+	}
+
+	remove := func() {
+		// The request context may be done by now; the overlay must still go.
+		cCtx, cancel := context.WithTimeout(tabCtx, 5*time.Second)
+		defer cancel()
+		if err := chromedp.Run(cCtx, chromedp.Evaluate(assets.MarksClearJS, nil)); err != nil {
+			slog.Warn("remove screenshot marks failed", "tab", tabID, "err", err)
+		}
+	}
+	return scaleMarks(drawn, nodes), remove, nil
+}
+
+// scaleMarks converts drawn boxes from CSS pixels to image pixels.
+func scaleMarks(drawn drawnMarks, nodes map[string]bridge.A11yNode) map[string]screenshotMark {
+	scale := drawn.Scale
+	if scale <= 0 {
+		scale = 1
+	}
+	round := func(v float64) float64 { return math.Round(v * scale) }
+	out := make(map[string]screenshotMark, len(drawn.Marks))
+	for _, m := range drawn.Marks {
+		n := nodes[m.Ref]
+		out[m.Ref] = screenshotMark{
+			Role: n.Role,
+			Name: n.Name,
+			Box:  bridge.Box{X: round(m.X), Y: round(m.Y), Width: round(m.Width), Height: round(m.Height)},
+		}
+	}
+	return out
+}
@@ -5,6 +5,7 @@ import (
 	"net/http/httptest"
 	"testing"
 
+	"github.com/pinchtab/pinchtab/internal/bridge"
 	"github.com/pinchtab/pinchtab/internal/config"
 )
 
@@ -49,3 +50,20 @@ func TestHandleTabScreenshot_NoTab(t *testing.T) {
 		t.Errorf("expected 404, got %d", w.Code)
 	}
 }
+
+func TestScaleMarks(t *testing.T) {
+	drawn := drawnMarks{Scale: 2, Marks: []refBox{
+		{Ref: "e3", Box: bridge.Box{X: 10.2, Y: 0, Width: 50, Height: 20.5}},
+	}}
+	nodes := map[string]bridge.A11yNode{"e3": {Ref: "e3", Role: "button", Name: "Buy"}}
+
+	got := scaleMarks(drawn, nodes)
+	want := screenshotMark{Role: "button", Name: "Buy", Box: bridge.Box{X: 20, Y: 0, Width: 100, Height: 41}}
+	if len(got) != 1 || got["e3"] != want {
+		t.Errorf("scaleMarks = %+v, want e3: %+v", got, want)
+	}
+
+	if b, ok := bridge.BoxFromQuad([]float64{30, 10, 80, 12, 78, 40, 28, 38}); !ok || b != (bridge.Box{X: 28, Y: 10, Width: 52, Height: 30}) {
+		t.Errorf("BoxFromQuad = %+v, %v", b, ok)
+	}
+}
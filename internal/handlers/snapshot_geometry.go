@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/chromedp/chromedp"
+	"github.com/pinchtab/pinchtab/internal/assets"
+	"github.com/pinchtab/pinchtab/internal/bridge"
+)
+
+// nodeLayout is what the geometry script reports for one node. Rect is in
+// the CSS pixels of the node's own frame.
+type nodeLayout struct {
+	Rect            bridge.Box `json:"rect"`
+	Occluded        bool       `json:"occluded"`
+	OccludedBy      string     `json:"occludedBy"`
+	ScrollContainer string     `json:"scrollContainer"`
+}
+
+// attachGeometry sets the Geometry of every node that is rendered. Nodes
+// are first tagged with their refs so that occluders and scroll containers
+// can be reported by ref; nodes that cannot be measured are left without
+// geometry.
+func attachGeometry(ctx context.Context, nodes []bridge.A11yNode, refs map[string]int64) error {
+	var viewport [2]float64
+	if err := chromedp.Run(ctx, chromedp.Evaluate(`[window.innerWidth, window.innerHeight]`, &viewport)); err != nil {
+		return fmt.Errorf("viewport: %w", err)
+	}
+
+	token := strconv.FormatInt(time.Now().UnixNano(), 36)
+	for _, n := range nodes {
+		if nodeID, ok := refs[n.Ref]; ok {
+			_ = bridge.CallFunctionOnNode(ctx, nodeID, assets.GeometryTagJS, nil, token, n.Ref)
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+	}
+
+	for i, n := range nodes {
+		nodeID, ok := refs[n.Ref]
+		if !ok {
+			continue
+		}
+		var layout *nodeLayout
+		if err := bridge.CallFunctionOnNode(ctx, nodeID, assets.GeometryJS, &layout, token); err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			continue
+		}
+		if layout == nil {
+			// Not rendered.
+			continue
+		}
+		// DOM.getBoxModel gives main-frame coordinates, which also holds for
+		// nodes inside iframes; text nodes have no box model and keep the
+		// rect measured by the script.
+		box, err := bridge.GetElementBox(ctx, nodeID)
+		if err != nil {
+			box = layout.Rect
+		}
+		nodes[i].Geometry = nodeGeometry(box, viewport[0], viewport[1], layout)
+	}
+	return nil
+}
+
+// nodeGeometry rounds a box to whole pixels and works out whether any of
+// it is inside a viewport of the given size.
+func nodeGeometry(box bridge.Box, viewportW, viewportH float64, layout *nodeLayout) *bridge.Geometry {
+	return &bridge.Geometry{
+		X:               int(math.Round(box.X)),
+		Y:               int(math.Round(box.Y)),
+		Width:           int(math.Round(box.Width)),
+		Height:          int(math.Round(box.Height)),
+		InViewport:      box.X < viewportW && box.Y < viewportH && box.X+box.Width > 0 && box.Y+box.Height > 0,
+		Occluded:        layout.Occluded,
+		OccludedBy:      layout.OccludedBy,
+		ScrollContainer: layout.ScrollContainer,
+	}
+}
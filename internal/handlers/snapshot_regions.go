@@ -0,0 +1,306 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/chromedp/chromedp"
+	"github.com/pinchtab/pinchtab/internal/bridge"
+	"github.com/pinchtab/pinchtab/internal/httpx"
+	"gopkg.in/yaml.v3"
+)
+
+// snapshotLandmarks maps the landmark names accepted by region= to the
+// accessibility roles they match. Anything else is a selector.
+var snapshotLandmarks = map[string][]string{
+	"main":          {"main"},
+	"nav":           {"navigation"},
+	"navigation":    {"navigation"},
+	"dialog":        {"dialog", "alertdialog"},
+	"form":          {"form"},
+	"search":        {"search"},
+	"banner":        {"banner"},
+	"header":        {"banner"},
+	"contentinfo":   {"contentinfo"},
+	"footer":        {"contentinfo"},
+	"complementary": {"complementary"},
+	"aside":         {"complementary"},
+	"region":        {"region"},
+}
+
+type regionOptions struct {
+	filter    string
+	format    string
+	maxDepth  int
+	maxTokens int
+	geometry  bool
+}
+
+// snapshotRegion is one labelled part of a region snapshot.
+type snapshotRegion struct {
+	Region    string            `json:"region" yaml:"region"`
+	Name      string            `json:"name,omitempty" yaml:"name,omitempty"`
+	Nodes     []bridge.A11yNode `json:"nodes" yaml:"nodes"`
+	Count     int               `json:"count" yaml:"count"`
+	Truncated bool              `json:"truncated,omitempty" yaml:"truncated,omitempty"`
+	Error     string            `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+// writeRegionSnapshot answers a snapshot with one or more region= params.
+// Each region is a landmark or a selector; a landmark that occurs several
+// times yields one region per occurrence. Regions are snapshotted
+// separately, so depth and maxTokens apply to each on its own, but share
+// one set of refs.
+func (h *Handlers) writeRegionSnapshot(w http.ResponseWriter, r *http.Request, ctx context.Context, tabID string, raw []bridge.RawAXNode, specs []string, opts regionOptions) {
+	var regions []snapshotRegion
+	for _, spec := range specs {
+		regions = append(regions, buildRegions(ctx, raw, strings.TrimSpace(spec), opts)...)
+	}
+
+	// Regions may overlap, say a form inside main. Refs are assigned once
+	// per element so the copies agree.
+	var union []bridge.A11yNode
+	pos := make([][]int, len(regions))
+	seen := make(map[int64]int)
+	for i, reg := range regions {
+		pos[i] = make([]int, len(reg.Nodes))
+		for j, n := range reg.Nodes {
+			if p, ok := seen[n.NodeID]; ok && n.NodeID != 0 {
+				pos[i][j] = p
+				continue
+			}
+			if n.NodeID != 0 {
+				seen[n.NodeID] = len(union)
+			}
+			pos[i][j] = len(union)
+			union = append(union, n)
+		}
+	}
+	cache := bridge.NewRefCache(h.Bridge.GetRefCache(tabID), union, nil)
+	for i := range regions {
+		for j := range regions[i].Nodes {
+			regions[i].Nodes[j].Ref = union[pos[i][j]].Ref
+		}
+	}
+
+	total := 0
+	for i := range regions {
+		reg := &regions[i]
+		if opts.maxTokens > 0 {
+			reg.Nodes, reg.Truncated = bridge.TruncateToTokens(reg.Nodes, opts.maxTokens, opts.format)
+		}
+		if opts.geometry && len(reg.Nodes) > 0 {
+			if err := attachGeometry(ctx, reg.Nodes, cache.Refs); err != nil {
+				httpx.Error(w, 500, fmt.Errorf("geometry: %w", err))
+				return
+			}
+			if opts.maxTokens > 0 {
+				var cut bool
+				reg.Nodes, cut = bridge.TruncateToTokens(reg.Nodes, opts.maxTokens, opts.format)
+				reg.Truncated = reg.Truncated || cut
+			}
+		}
+		reg.Count = len(reg.Nodes)
+		total += reg.Count
+	}
+	h.Bridge.SetRefCache(tabID, cache)
+
+	var url, title string
+	_ = chromedp.Run(ctx, chromedp.Location(&url), chromedp.Title(&title))
+	h.recordResolvedURL(r, url)
+
+	var structured *structuredData
+	if opts.format == "structured" {
+		var err error
+		if structured, err = fetchStructuredData(ctx); err != nil {
+			httpx.Error(w, 500, fmt.Errorf("structured data: %w", err))
+			return
+		}
+	}
+
+	var scan strings.Builder
+	for _, reg := range regions {
+		scan.WriteString(snapshotScanText(reg.Nodes))
+	}
+	scan.WriteString(structured.scanText())
+	wrapContent := h.Config.IDPI.Enabled && h.Config.IDPI.WrapContent
+	idpiResult := h.IDPIGuard.ScanContent(scan.String())
+	if idpiResult.Blocked {
+		httpx.Error(w, http.StatusForbidden,
+			fmt.Errorf("snapshot blocked by IDPI scanner: %s", idpiResult.Reason))
+		return
+	}
+	if idpiResult.Threat {
+		w.Header().Set("X-IDPI-Warning", idpiResult.Reason)
+		if idpiResult.Pattern != "" {
+			w.Header().Set("X-IDPI-Pattern", idpiResult.Pattern)
+		}
+	}
+
+	switch opts.format {
+	case "text", "compact", "markdown":
+		var b strings.Builder
+		for _, reg := range regions {
+			b.WriteString(regionHeading(reg, opts))
+			switch opts.format {
+			case "text":
+				b.WriteString(bridge.FormatSnapshotText(reg.Nodes))
+			case "compact":
+				b.WriteString(bridge.FormatSnapshotCompact(reg.Nodes))
+			default:
+				b.WriteString(bridge.FormatSnapshotMarkdown(reg.Nodes))
+			}
+		}
+		content := b.String()
+		if wrapContent {
+			content = h.IDPIGuard.WrapContent(content, url)
+		}
+		contentType := "text/plain; charset=utf-8"
+		if opts.format == "markdown" {
+			contentType = "text/markdown; charset=utf-8"
+		}
+		w.Header().Set("Content-Type", contentType)
+		w.WriteHeader(200)
+		_, _ = fmt.Fprintf(w, "# %s | %s | %d nodes in %d regions\n", title, url, total, len(regions))
+		_, _ = w.Write([]byte(content))
+	case "yaml":
+		out, err := yaml.Marshal(map[string]any{
+			"url":     url,
+			"title":   title,
+			"regions": regions,
+			"count":   total,
+		})
+		if err != nil {
+			httpx.Error(w, 500, fmt.Errorf("marshal yaml: %w", err))
+			return
+		}
+		w.Header().Set("Content-Type", "text/yaml; charset=utf-8")
+		w.WriteHeader(200)
+		_, _ = w.Write(out)
+	default:
+		resp := map[string]any{
+			"url":     url,
+			"title":   title,
+			"engine":  "chrome",
+			"regions": regions,
+			"count":   total,
+		}
+		if opts.maxTokens > 0 {
+			resp["maxTokens"] = opts.maxTokens
+		}
+		if structured != nil {
+			resp["structured"] = structured
+		}
+		if idpiResult.Threat {
+			resp["idpiWarning"] = idpiResult.Reason
+		}
+		if wrapContent {
+			resp["untrustedContent"] = true
+		}
+		httpx.JSON(w, 200, resp)
+	}
+}
+
+// buildRegions snapshots the subtrees one region spec refers to. A spec
+// that matches nothing yields a single region carrying the reason.
+func buildRegions(ctx context.Context, raw []bridge.RawAXNode, spec string, opts regionOptions) []snapshotRegion {
+	var roots []bridge.RawAXNode
+	if roles, ok := snapshotLandmarks[strings.ToLower(spec)]; ok {
+		roots = findLandmarks(raw, roles)
+		if len(roots) == 0 {
+			return []snapshotRegion{{Region: spec, Nodes: []bridge.A11yNode{}, Error: fmt.Sprintf("no %s on the page", spec)}}
+		}
+	} else {
+		nodeID, err := resolveSnapshotScope(ctx, spec)
+		if err != nil {
+			return []snapshotRegion{{Region: spec, Nodes: []bridge.A11yNode{}, Error: err.Error()}}
+		}
+		for _, n := range raw {
+			if n.BackendDOMNodeID == nodeID {
+				roots = append(roots, n)
+				break
+			}
+		}
+		if len(roots) == 0 {
+			return []snapshotRegion{{Region: spec, Nodes: []bridge.A11yNode{}, Error: "element is not in the accessibility tree"}}
+		}
+	}
+
+	out := make([]snapshotRegion, 0, len(roots))
+	for i, root := range roots {
+		label := spec
+		if len(roots) > 1 {
+			label = fmt.Sprintf("%s[%d]", spec, i+1)
+		}
+		nodes, _ := bridge.BuildSnapshot(bridge.FilterSubtree(raw, root.BackendDOMNodeID), opts.filter, opts.maxDepth)
+		out = append(out, snapshotRegion{Region: label, Name: root.Name.String(), Nodes: nodes})
+	}
+	return out
+}
+
+// findLandmarks returns the outermost non-ignored nodes with one of the
+// roles, in document order.
+func findLandmarks(raw []bridge.RawAXNode, roles []string) []bridge.RawAXNode {
+	parent := make(map[string]string, len(raw))
+	for _, n := range raw {
+		for _, c := range n.ChildIDs {
+			parent[c] = n.NodeID
+		}
+	}
+	match := make(map[string]bool)
+	for _, n := range raw {
+		if n.Ignored || n.BackendDOMNodeID == 0 {
+			continue
+		}
+		for _, role := range roles {
+			if n.Role.String() == role {
+				match[n.NodeID] = true
+			}
+		}
+	}
+	var out []bridge.RawAXNode
+	for _, n := range raw {
+		if !match[n.NodeID] {
+			continue
+		}
+		nested := false
+		cur := n.NodeID
+		for range len(raw) {
+			p, ok := parent[cur]
+			if !ok {
+				break
+			}
+			if match[p] {
+				nested = true
+				break
+			}
+			cur = p
+		}
+		if !nested {
+			out = append(out, n)
+		}
+	}
+	return out
+}
+
+// regionHeading is the line that introduces a region in the text formats.
+func regionHeading(reg snapshotRegion, opts regionOptions) string {
+	label := reg.Region
+	if reg.Name != "" {
+		label += fmt.Sprintf(" %q", reg.Name)
+	}
+	switch {
+	case reg.Error != "":
+		label += ": " + reg.Error
+	case reg.Truncated:
+		label += fmt.Sprintf(" | %d nodes (truncated to ~%d tokens)", reg.Count, opts.maxTokens)
+	default:
+		label += fmt.Sprintf(" | %d nodes", reg.Count)
+	}
+	if opts.format == "markdown" {
+		return "\n---\n\n**Region: " + label + "**\n\n"
+	}
+	return "\n## region " + label + "\n"
+}
@@ -0,0 +1,63 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/pinchtab/pinchtab/internal/bridge"
+)
+
+func axNode(id, role, name string, backend int64, children ...string) bridge.RawAXNode {
+	val := func(s string) *bridge.RawAXValue {
+		b, _ := json.Marshal(s)
+		return &bridge.RawAXValue{Value: b}
+	}
+	return bridge.RawAXNode{NodeID: id, Role: val(role), Name: val(name), BackendDOMNodeID: backend, ChildIDs: children}
+}
+
+func TestBuildRegions_Landmarks(t *testing.T) {
+	raw := []bridge.RawAXNode{
+		axNode("root", "RootWebArea", "Shop", 1, "nav1", "main", "nav2"),
+		axNode("nav1", "navigation", "Primary", 2, "l1"),
+		axNode("l1", "link", "Home", 3),
+		axNode("main", "main", "", 4, "h", "inner"),
+		axNode("h", "heading", "Cart", 5),
+		axNode("inner", "navigation", "Breadcrumbs", 6),
+		axNode("nav2", "navigation", "Footer", 7, "l2"),
+		axNode("l2", "link", "Contact", 8),
+	}
+
+	navs := buildRegions(context.Background(), raw, "nav", regionOptions{maxDepth: -1})
+	if len(navs) != 3 {
+		t.Fatalf("expected 3 nav regions, got %+v", navs)
+	}
+	if navs[0].Region != "nav[1]" || navs[0].Name != "Primary" {
+		t.Errorf("first region = %q %q", navs[0].Region, navs[0].Name)
+	}
+	if len(navs[0].Nodes) != 2 || navs[0].Nodes[1].Name != "Home" || navs[0].Nodes[0].Depth != 0 {
+		t.Errorf("first region nodes = %+v", navs[0].Nodes)
+	}
+
+	main := buildRegions(context.Background(), raw, "MAIN", regionOptions{filter: bridge.FilterInteractive, maxDepth: -1})
+	if len(main) != 1 || main[0].Region != "MAIN" || len(main[0].Nodes) != 0 {
+		t.Errorf("main region = %+v", main)
+	}
+
+	dialog := buildRegions(context.Background(), raw, "dialog", regionOptions{maxDepth: -1})
+	if len(dialog) != 1 || dialog[0].Error == "" || dialog[0].Nodes == nil {
+		t.Errorf("missing dialog should report an error, got %+v", dialog)
+	}
+}
+
+func TestFindLandmarks_Outermost(t *testing.T) {
+	raw := []bridge.RawAXNode{
+		axNode("root", "RootWebArea", "", 1, "outer"),
+		axNode("outer", "region", "Outer", 2, "inner"),
+		axNode("inner", "region", "Inner", 3),
+	}
+	got := findLandmarks(raw, []string{"region"})
+	if len(got) != 1 || got[0].NodeID != "outer" {
+		t.Errorf("findLandmarks = %+v", got)
+	}
+}
@@ -0,0 +1,334 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/chromedp/cdproto/page"
+	"github.com/chromedp/cdproto/runtime"
+	"github.com/chromedp/chromedp"
+	"github.com/pinchtab/pinchtab/internal/assets"
+	"github.com/pinchtab/pinchtab/internal/bridge"
+	"github.com/pinchtab/pinchtab/internal/httpx"
+)
+
+const (
+	snapshotStreamDebounce    = 250 * time.Millisecond
+	snapshotStreamMinDebounce = 50 * time.Millisecond
+	snapshotStreamMaxDebounce = 10 * time.Second
+	// snapshotStreamMaxWait bounds how long a page that never settles can
+	// hold back a delta, as a multiple of the debounce interval.
+	snapshotStreamMaxWait = 8
+)
+
+// snapshotStreamSeq numbers page bindings so concurrent streams on one tab
+// do not share an observer.
+var snapshotStreamSeq atomic.Int64
+
+// snapshotDelta is one event of a snapshot stream.
+type snapshotDelta struct {
+	Seq         int               `json:"seq"`
+	URL         string            `json:"url"`
+	Title       string            `json:"title"`
+	Added       []bridge.A11yNode `json:"added"`
+	Changed     []bridge.A11yNode `json:"changed"`
+	Removed     []bridge.A11yNode `json:"removed"`
+	Counts      map[string]int    `json:"counts"`
+	IDPIWarning string            `json:"idpiWarning,omitempty"`
+}
+
+// HandleSnapshotStream streams accessibility tree deltas via Server-Sent
+// Events as the page changes.
+//
+// @Endpoint GET /snapshot/stream
+// @Description Sends a full snapshot, then a delta of added, changed and removed nodes each time the page settles after a change
+//
+// @Param tabId string query Tab ID (optional, uses current tab if empty)
+// @Param filter string query Filter type: "interactive" or "all" (optional, default: "all")
+// @Param depth int query Max nesting depth (optional, default: -1 for full tree)
+// @Param debounce int query Quiet period in milliseconds before a delta is sent (optional, default: 250)
+//
+// @Response 200 text/event-stream SSE stream of snapshot and delta events
+func (h *Handlers) HandleSnapshotStream(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		httpx.Problem(w, http.StatusInternalServerError, "streaming_not_supported", "streaming not supported", false, nil)
+		return
+	}
+
+	q := r.URL.Query()
+	filter := q.Get("filter")
+	maxDepth := -1
+	if v := q.Get("depth"); v != "" {
+		d, err := strconv.Atoi(v)
+		if err != nil {
+			httpx.Error(w, 400, fmt.Errorf("invalid depth %q", v))
+			return
+		}
+		maxDepth = d
+	}
+	debounce := snapshotStreamDebounce
+	if v := q.Get("debounce"); v != "" {
+		ms, err := strconv.Atoi(v)
+		if err != nil || ms < 0 {
+			httpx.Error(w, 400, fmt.Errorf("invalid debounce %q", v))
+			return
+		}
+		debounce = min(max(time.Duration(ms)*time.Millisecond, snapshotStreamMinDebounce), snapshotStreamMaxDebounce)
+	}
+
+	_ = http.NewResponseController(w).SetWriteDeadline(time.Time{})
+
+	if err := h.ensureChrome(); err != nil {
+		if h.writeBridgeUnavailable(w, err) {
+			return
+		}
+		httpx.Error(w, 500, fmt.Errorf("chrome initialization: %w", err))
+		return
+	}
+
+	tabID := q.Get("tabId")
+	h.recordReadRequest(r, "snapshot", tabID)
+	ctx, resolvedTabID, err := h.tabContext(r, tabID)
+	if err != nil {
+		httpx.Error(w, 404, err)
+		return
+	}
+	if _, ok := h.enforceCurrentTabDomainPolicy(w, r, ctx, resolvedTabID); !ok {
+		return
+	}
+
+	// Every snapshot taken by the stream replaces the tab's ref cache, so
+	// refs in events can be used with /action straight away.
+	snapshot := func() ([]bridge.A11yNode, string, string, error) {
+		tCtx, cancel := context.WithTimeout(ctx, h.Config.ActionTimeout)
+		defer cancel()
+		raw, err := bridge.FetchAXTree(tCtx)
+		if err != nil {
+			return nil, "", "", err
+		}
+		flat, _ := bridge.BuildSnapshot(raw, filter, maxDepth)
+		prev := h.Bridge.GetRefCache(resolvedTabID)
+		h.Bridge.SetRefCache(resolvedTabID, bridge.NewRefCache(prev, flat, bridge.SnapshotCoverage(filter, maxDepth, false)))
+		var url, title string
+		_ = chromedp.Run(tCtx, chromedp.Location(&url), chromedp.Title(&title))
+		return flat, url, title, nil
+	}
+
+	done := make(chan struct{})
+	defer close(done)
+	trigger := make(chan struct{}, 1)
+	poke := func() {
+		select {
+		case trigger <- struct{}{}:
+		default:
+		}
+	}
+
+	binding := fmt.Sprintf("__pinchtabSnapshot%d", snapshotStreamSeq.Add(1))
+	observer := "(" + assets.SnapshotObserverJS + ")(" + strconv.Quote(binding) + ")"
+	chromedp.ListenTarget(ctx, func(ev any) {
+		select {
+		case <-done:
+			return
+		default:
+		}
+		switch e := ev.(type) {
+		case *runtime.EventBindingCalled:
+			if e.Name == binding {
+				poke()
+			}
+		case *page.EventFrameNavigated:
+			if e.Frame != nil && e.Frame.ParentID == "" {
+				poke()
+			}
+		}
+	})
+
+	var scriptID page.ScriptIdentifier
+	if err := chromedp.Run(ctx, chromedp.ActionFunc(func(c context.Context) error {
+		if err := runtime.AddBinding(binding).Do(c); err != nil {
+			return err
+		}
+		id, err := page.AddScriptToEvaluateOnNewDocument(observer).Do(c)
+		if err != nil {
+			return err
+		}
+		scriptID = id
+		return nil
+	}), chromedp.Evaluate(observer, nil)); err != nil {
+		httpx.Error(w, 500, fmt.Errorf("install mutation observer: %w", err))
+		return
+	}
+	defer func() {
+		cCtx, cancel := context.WithTimeout(ctx, 2*time.Second)
+		defer cancel()
+		_ = chromedp.Run(cCtx,
+			chromedp.ActionFunc(func(c context.Context) error {
+				_ = runtime.RemoveBinding(binding).Do(c)
+				return page.RemoveScriptToEvaluateOnNewDocument(scriptID).Do(c)
+			}),
+			chromedp.Evaluate(fmt.Sprintf(`window[%q] && window[%q].disconnect()`, "__pinchtabObserver_"+binding, "__pinchtabObserver_"+binding), nil),
+		)
+	}()
+
+	prev, url, title, err := snapshot()
+	if err != nil {
+		httpx.Error(w, 500, fmt.Errorf("a11y tree: %w", err))
+		return
+	}
+	h.recordResolvedURL(r, url)
+	idpiResult := h.IDPIGuard.ScanContent(snapshotScanText(prev))
+	if idpiResult.Blocked {
+		httpx.Error(w, http.StatusForbidden,
+			fmt.Errorf("snapshot blocked by IDPI scanner: %s", idpiResult.Reason))
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+
+	initial := map[string]any{
+		"seq":   0,
+		"url":   url,
+		"title": title,
+		"nodes": prev,
+		"count": len(prev),
+	}
+	if idpiResult.Threat {
+		initial["idpiWarning"] = idpiResult.Reason
+	}
+	if h.Config.IDPI.Enabled && h.Config.IDPI.WrapContent {
+		initial["untrustedContent"] = true
+		initial["idpiNotice"] = snapshotIDPINotice
+	}
+	if writeSSE(w, flusher, "snapshot", initial) != nil {
+		return
+	}
+
+	keepalive := time.NewTicker(15 * time.Second)
+	defer keepalive.Stop()
+	timer := time.NewTimer(time.Hour)
+	timer.Stop()
+	defer timer.Stop()
+
+	seq := 0
+	var first time.Time
+	pending := false
+	for {
+		select {
+		case <-trigger:
+			now := time.Now()
+			if !pending {
+				first, pending = now, true
+			}
+			timer.Reset(debounceWait(first, now, debounce, debounce*snapshotStreamMaxWait))
+
+		case <-timer.C:
+			pending = false
+			curr, url, title, err := snapshot()
+			if err != nil {
+				if ctx.Err() != nil {
+					_ = writeSSE(w, flusher, "end", map[string]any{"reason": "tab closed"})
+					return
+				}
+				if writeSSE(w, flusher, "error", map[string]any{"error": err.Error()}) != nil {
+					return
+				}
+				continue
+			}
+			added, changed, removed := bridge.DiffSnapshot(prev, curr)
+			prev = curr
+			if len(added)+len(changed)+len(removed) == 0 {
+				continue
+			}
+			seq++
+			delta := snapshotDelta{
+				Seq:     seq,
+				URL:     url,
+				Title:   title,
+				Added:   added,
+				Changed: changed,
+				Removed: removed,
+				Counts: map[string]int{
+					"added":   len(added),
+					"changed": len(changed),
+					"removed": len(removed),
+					"total":   len(curr),
+				},
+			}
+			idpiResult := h.IDPIGuard.ScanContent(snapshotScanText(added) + snapshotScanText(changed))
+			if idpiResult.Blocked {
+				_ = writeSSE(w, flusher, "error", map[string]any{
+					"error": fmt.Sprintf("snapshot blocked by IDPI scanner: %s", idpiResult.Reason),
+				})
+				return
+			}
+			if idpiResult.Threat {
+				delta.IDPIWarning = idpiResult.Reason
+			}
+			if writeSSE(w, flusher, "delta", delta) != nil {
+				return
+			}
+
+		case <-keepalive.C:
+			if _, err := fmt.Fprintf(w, ": keepalive\n\n"); err != nil {
+				return
+			}
+			flusher.Flush()
+
+		case <-ctx.Done():
+			_ = writeSSE(w, flusher, "end", map[string]any{"reason": "tab closed"})
+			return
+
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
+
+// debounceWait returns how long to wait after a change at now, in a burst
+// that started at first: the quiet period, but never past maxWait from the
+// start of the burst.
+func debounceWait(first, now time.Time, quiet, maxWait time.Duration) time.Duration {
+	left := maxWait - now.Sub(first)
+	return max(min(quiet, left), 0)
+}
+
+func writeSSE(w http.ResponseWriter, flusher http.Flusher, event string, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, data); err != nil {
+		return err
+	}
+	flusher.Flush()
+	return nil
+}
+
+// HandleTabSnapshotStream streams snapshot deltas for a tab identified by
+// path ID.
+//
+// @Endpoint GET /tabs/{id}/snapshot/stream
+func (h *Handlers) HandleTabSnapshotStream(w http.ResponseWriter, r *http.Request) {
+	tabID := r.PathValue("id")
+	if tabID == "" {
+		httpx.Error(w, 400, fmt.Errorf("tab id required"))
+		return
+	}
+	q := r.URL.Query()
+	q.Set("tabId", tabID)
+	req := r.Clone(r.Context())
+	u := *r.URL
+	u.RawQuery = q.Encode()
+	req.URL = &u
+	h.HandleSnapshotStream(w, req)
+}
@@ -0,0 +1,51 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/pinchtab/pinchtab/internal/config"
+)
+
+func TestHandleSnapshotStream_InvalidParams(t *testing.T) {
+	h := New(&mockBridge{}, &config.RuntimeConfig{}, nil, nil, nil)
+	for _, q := range []string{"debounce=soon", "debounce=-5", "depth=deep"} {
+		w := httptest.NewRecorder()
+		h.HandleSnapshotStream(w, httptest.NewRequest("GET", "/snapshot/stream?"+q, nil))
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected 400, got %d", q, w.Code)
+		}
+	}
+}
+
+func TestHandleSnapshotStream_NoTab(t *testing.T) {
+	h := New(&mockBridge{failTab: true}, &config.RuntimeConfig{}, nil, nil, nil)
+	req := httptest.NewRequest("GET", "/tabs/tab_123/snapshot/stream", nil)
+	req.SetPathValue("id", "tab_123")
+	w := httptest.NewRecorder()
+	h.HandleTabSnapshotStream(w, req)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("expected 404, got %d", w.Code)
+	}
+}
+
+func TestDebounceWait(t *testing.T) {
+	start := time.Unix(0, 0)
+	quiet, maxWait := 250*time.Millisecond, 2*time.Second
+	tests := []struct {
+		since time.Duration
+		want  time.Duration
+	}{
+		{0, quiet},
+		{time.Second, quiet},
+		{1900 * time.Millisecond, 100 * time.Millisecond},
+		{3 * time.Second, 0},
+	}
+	for _, tt := range tests {
+		if got := debounceWait(start, start.Add(tt.since), quiet, maxWait); got != tt.want {
+			t.Errorf("debounceWait after %v = %v, want %v", tt.since, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/chromedp/chromedp"
+	"github.com/pinchtab/pinchtab/internal/assets"
+)
+
+// structuredData is the machine-readable metadata a page publishes about
+// itself, returned alongside the snapshot by format=structured.
+type structuredData struct {
+	JSONLD    []map[string]any `json:"jsonLd"`
+	OpenGraph map[string]any   `json:"openGraph"`
+	Microdata []map[string]any `json:"microdata"`
+}
+
+// fetchStructuredData collects the page's schema.org JSON-LD blocks,
+// OpenGraph meta tags and top-level microdata items.
+func fetchStructuredData(ctx context.Context) (*structuredData, error) {
+	var data structuredData
+	if err := chromedp.Run(ctx, chromedp.Evaluate("("+assets.StructuredDataJS+")()", &data)); err != nil {
+		return nil, err
+	}
+	if data.JSONLD == nil {
+		data.JSONLD = []map[string]any{}
+	}
+	if data.OpenGraph == nil {
+		data.OpenGraph = map[string]any{}
+	}
+	if data.Microdata == nil {
+		data.Microdata = []map[string]any{}
+	}
+	return &data, nil
+}
+
+// scanText returns the structured data as JSON for the IDPI scanner.
+func (d *structuredData) scanText() string {
+	if d == nil {
+		return ""
+	}
+	b, _ := json.Marshal(d)
+	return string(b)
+}
@@ -5,6 +5,7 @@ import (
 	"net/http/httptest"
 	"testing"
 
+	"github.com/pinchtab/pinchtab/internal/bridge"
 	"github.com/pinchtab/pinchtab/internal/config"
 )
 
@@ -69,3 +70,28 @@ func TestHandleTabSnapshot_NoTab(t *testing.T) {
 		t.Errorf("expected 404, got %d", w.Code)
 	}
 }
+
+func TestNodeGeometry(t *testing.T) {
+	layout := &nodeLayout{ScrollContainer: "e4"}
+	tests := []struct {
+		box  bridge.Box
+		want bool
+	}{
+		{bridge.Box{X: 10.4, Y: 20.6, Width: 80, Height: 24}, true},
+		{bridge.Box{X: -50, Y: 10, Width: 60, Height: 10}, true},
+		{bridge.Box{X: 10, Y: 800, Width: 80, Height: 24}, false},
+		{bridge.Box{X: -90, Y: 10, Width: 80, Height: 24}, false},
+	}
+	for _, tt := range tests {
+		g := nodeGeometry(tt.box, 1280, 800, layout)
+		if g.InViewport != tt.want {
+			t.Errorf("box %+v: inViewport = %v, want %v", tt.box, g.InViewport, tt.want)
+		}
+		if g.ScrollContainer != "e4" {
+			t.Errorf("scroll container not kept: %+v", g)
+		}
+	}
+	if g := nodeGeometry(tests[0].box, 1280, 800, layout); g.X != 10 || g.Y != 21 {
+		t.Errorf("expected rounded coordinates, got %+v", g)
+	}
+}
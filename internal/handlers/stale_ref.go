@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/pinchtab/pinchtab/internal/bridge"
+	"github.com/pinchtab/pinchtab/internal/httpx"
+	"github.com/pinchtab/semantic"
+)
+
+// staleRef reports whether ref belonged to an element that has been removed
+// from the tab. The details name the element as last seen and, when the
+// current snapshot has something similar, a best-guess replacement.
+func (h *Handlers) staleRef(ctx context.Context, tabID, ref string) (*bridge.StaleRefError, map[string]any) {
+	cache := h.Bridge.GetRefCache(tabID)
+	old, ok := cache.Stale(ref)
+	if !ok {
+		return nil, nil
+	}
+	details := map[string]any{
+		"ref":     ref,
+		"element": map[string]any{"role": old.Role, "name": old.Name},
+	}
+	if n, score, ok := h.staleReplacement(ctx, cache.Nodes, old); ok {
+		details["replacement"] = map[string]any{
+			"ref":   n.Ref,
+			"role":  n.Role,
+			"name":  n.Name,
+			"score": score,
+		}
+	}
+	return &bridge.StaleRefError{Ref: ref, Node: old}, details
+}
+
+// staleReplacement finds the current node most like a removed one, using
+// the semantic matcher when configured and an exact role and name match
+// otherwise.
+func (h *Handlers) staleReplacement(ctx context.Context, nodes []bridge.A11yNode, old bridge.A11yNode) (bridge.A11yNode, float64, bool) {
+	if h.Matcher != nil && len(nodes) > 0 {
+		descs := make([]semantic.ElementDescriptor, len(nodes))
+		for i, n := range nodes {
+			descs[i] = semantic.ElementDescriptor{Ref: n.Ref, Role: n.Role, Name: n.Name, Value: n.Value}
+		}
+		result, err := h.Matcher.Find(ctx, old.Role+" "+old.Name, descs, semantic.FindOptions{Threshold: 0.3, TopK: 1})
+		if err == nil && result.BestRef != "" {
+			for _, n := range nodes {
+				if n.Ref == result.BestRef {
+					return n, result.BestScore, true
+				}
+			}
+		}
+	}
+	for _, n := range nodes {
+		if n.Role == old.Role && n.Name == old.Name {
+			return n, 1, true
+		}
+	}
+	return bridge.A11yNode{}, 0, false
+}
+
+// writeStaleRef writes the 409 response for a stale ref.
+func writeStaleRef(w http.ResponseWriter, err *bridge.StaleRefError, details map[string]any) {
+	httpx.ErrorCode(w, http.StatusConflict, "stale_ref", err.Error(), false, details)
+}
+
+// staleRefResult is the batch and macro counterpart of writeStaleRef.
+func staleRefResult(index int, err *bridge.StaleRefError, details map[string]any) actionResult {
+	return actionResult{Index: index, Success: false, Error: err.Error(), Code: "stale_ref", Details: details}
+}
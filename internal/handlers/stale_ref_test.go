@@ -0,0 +1,50 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/pinchtab/pinchtab/internal/bridge"
+)
+
+func TestHandleAction_StaleRef(t *testing.T) {
+	all := bridge.SnapshotCoverage("", -1, false)
+	cache := bridge.NewRefCache(nil, []bridge.A11yNode{
+		{Role: "button", Name: "Save draft", NodeID: 1},
+	}, all)
+	cache = bridge.NewRefCache(cache, []bridge.A11yNode{
+		{Role: "button", Name: "Save", NodeID: 2},
+		{Role: "link", Name: "Help", NodeID: 3},
+	}, all)
+
+	h := newFindTestHandler(cache, false)
+	req := httptest.NewRequest("POST", "/action", bytes.NewReader([]byte(`{"kind":"click","ref":"e0"}`)))
+	w := httptest.NewRecorder()
+	h.HandleAction(w, req)
+
+	if w.Code != http.StatusConflict {
+		t.Fatalf("expected 409, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp struct {
+		Code    string `json:"code"`
+		Details struct {
+			Ref         string `json:"ref"`
+			Replacement struct {
+				Ref  string `json:"ref"`
+				Name string `json:"name"`
+			} `json:"replacement"`
+		} `json:"details"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.Code != "stale_ref" || resp.Details.Ref != "e0" {
+		t.Errorf("unexpected response: %s", w.Body.String())
+	}
+	if resp.Details.Replacement.Ref != "e1" || resp.Details.Replacement.Name != "Save" {
+		t.Errorf("replacement = %+v, want e1 Save", resp.Details.Replacement)
+	}
+}
@@ -0,0 +1,214 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/chromedp/chromedp"
+	"github.com/pinchtab/pinchtab/internal/assets"
+	"github.com/pinchtab/pinchtab/internal/bridge"
+	"github.com/pinchtab/pinchtab/internal/extract"
+	"github.com/pinchtab/pinchtab/internal/httpx"
+	"github.com/pinchtab/pinchtab/internal/selector"
+)
+
+// HandleTables finds tables on the current tab and returns their rows.
+// <table> elements, ARIA grids and repeated sibling lists are detected;
+// selector (or ref) limits the search to one region of the page.
+//
+// @Endpoint GET /tables
+func (h *Handlers) HandleTables(w http.ResponseWriter, r *http.Request) {
+	if err := h.ensureChrome(); err != nil {
+		if h.writeBridgeUnavailable(w, err) {
+			return
+		}
+		httpx.Error(w, 500, fmt.Errorf("chrome initialization: %w", err))
+		return
+	}
+
+	q := r.URL.Query()
+	tabID := q.Get("tabId")
+	format := strings.ToLower(strings.TrimSpace(q.Get("format")))
+	if format == "" {
+		format = "json"
+	}
+	if format != "json" && format != "csv" {
+		httpx.Error(w, 400, fmt.Errorf("format must be json or csv"))
+		return
+	}
+	index := -1
+	if v := q.Get("index"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			httpx.Error(w, 400, fmt.Errorf("index must be a non-negative integer"))
+			return
+		}
+		index = n
+	}
+	maxRows := 0
+	if v := q.Get("maxRows"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			maxRows = n
+		}
+	}
+	kinds := extract.TableKinds
+	if v := q.Get("kind"); v != "" {
+		kinds = nil
+		for _, k := range strings.Split(v, ",") {
+			k = strings.TrimSpace(k)
+			if !slices.Contains(extract.TableKinds, k) {
+				httpx.Error(w, 400, fmt.Errorf("unknown table kind %q (use %s)", k, strings.Join(extract.TableKinds, ", ")))
+				return
+			}
+			kinds = append(kinds, k)
+		}
+	}
+	region := q.Get("selector")
+	if ref := q.Get("ref"); ref != "" {
+		region = selector.FromRef(ref).String()
+	}
+
+	h.recordReadRequest(r, "tables", tabID)
+	ctx, resolvedTabID, err := h.tabContextWithHeader(w, r, tabID)
+	if err != nil {
+		httpx.Error(w, 404, err)
+		return
+	}
+	if _, ok := h.enforceCurrentTabDomainPolicy(w, r, ctx, resolvedTabID); !ok {
+		return
+	}
+
+	tCtx, tCancel := context.WithTimeout(ctx, h.Config.ActionTimeout)
+	defer tCancel()
+	go httpx.CancelOnClientDone(r.Context(), tCancel)
+
+	opts := map[string]any{"kinds": kinds}
+	optsJSON, _ := json.Marshal(opts)
+	var tables []extract.Table
+	if region == "" {
+		js := fmt.Sprintf("(%s).call(document, %s)", strings.TrimSpace(assets.TablesJS), optsJSON)
+		err = chromedp.Run(tCtx, chromedp.Evaluate(js, &tables))
+	} else {
+		sel := selector.Parse(region)
+		if sel.Kind == selector.KindSemantic {
+			httpx.Error(w, 400, fmt.Errorf("find: selectors are not supported here; resolve a ref with /find first"))
+			return
+		}
+		nodeID, rerr := bridge.ResolveUnifiedSelector(tCtx, sel, h.Bridge.GetRefCache(resolvedTabID))
+		if rerr != nil {
+			if stale, details := h.staleRef(tCtx, resolvedTabID, sel.Value); sel.Kind == selector.KindRef && stale != nil {
+				writeStaleRef(w, stale, details)
+				return
+			}
+			httpx.Error(w, 404, fmt.Errorf("table region: %w", rerr))
+			return
+		}
+		err = bridge.CallFunctionOnNode(tCtx, nodeID, assets.TablesJS, &tables, opts)
+	}
+	if err != nil {
+		httpx.Error(w, 500, fmt.Errorf("tables: %w", err))
+		return
+	}
+	for i := range tables {
+		tables[i].Normalize(maxRows)
+	}
+	first := 0
+	if index >= 0 {
+		if index >= len(tables) {
+			httpx.ErrorCode(w, 404, "table_not_found", fmt.Sprintf("table %d not found (%d found)", index, len(tables)), false, nil)
+			return
+		}
+		tables, first = tables[index:index+1], index
+	}
+
+	var scanned strings.Builder
+	for _, t := range tables {
+		scanned.WriteString(strings.Join(t.Headers, " "))
+		for _, row := range t.Rows {
+			scanned.WriteByte('\n')
+			scanned.WriteString(strings.Join(row, " "))
+		}
+	}
+	idpiResult := h.IDPIGuard.ScanContent(scanned.String())
+	if idpiResult.Blocked {
+		httpx.Error(w, http.StatusForbidden,
+			fmt.Errorf("content blocked by IDPI scanner: %s", idpiResult.Reason))
+		return
+	}
+	if idpiResult.Threat {
+		w.Header().Set("X-IDPI-Warning", idpiResult.Reason)
+		if idpiResult.Pattern != "" {
+			w.Header().Set("X-IDPI-Pattern", idpiResult.Pattern)
+		}
+	}
+
+	if format == "csv" {
+		if len(tables) == 0 {
+			httpx.ErrorCode(w, 404, "table_not_found", "no tables found", false, nil)
+			return
+		}
+		var buf bytes.Buffer
+		if err := tables[0].WriteCSV(&buf); err != nil {
+			httpx.Error(w, 500, fmt.Errorf("csv: %w", err))
+			return
+		}
+		w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+		w.WriteHeader(200)
+		_, _ = w.Write(buf.Bytes())
+		return
+	}
+
+	out := make([]map[string]any, len(tables))
+	for i, t := range tables {
+		out[i] = map[string]any{
+			"index":    first + i,
+			"kind":     t.Kind,
+			"selector": t.Selector,
+			"headers":  t.Headers,
+			"rows":     t.Records(),
+			"rowCount": t.RowCount,
+		}
+		if t.Caption != "" {
+			out[i]["caption"] = t.Caption
+		}
+		if t.Truncated {
+			out[i]["truncated"] = true
+		}
+	}
+	resp := map[string]any{
+		"tabId":  resolvedTabID,
+		"tables": out,
+		"count":  len(out),
+	}
+	if idpiResult.Threat {
+		resp["idpiWarning"] = idpiResult.Reason
+	}
+	httpx.JSON(w, 200, resp)
+}
+
+// HandleTabTables finds tables for a tab identified by path ID.
+//
+// @Endpoint GET /tabs/{id}/tables
+func (h *Handlers) HandleTabTables(w http.ResponseWriter, r *http.Request) {
+	tabID := r.PathValue("id")
+	if tabID == "" {
+		httpx.Error(w, 400, fmt.Errorf("tab id required"))
+		return
+	}
+
+	q := r.URL.Query()
+	q.Set("tabId", tabID)
+
+	req := r.Clone(r.Context())
+	u := *r.URL
+	u.RawQuery = q.Encode()
+	req.URL = &u
+
+	h.HandleTables(w, req)
+}
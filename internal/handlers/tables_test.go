@@ -0,0 +1,26 @@
+package handlers
+
+import (
+	"testing"
+)
+
+func TestHandleTablesRejectsBadQueries(t *testing.T) {
+	_, _, mux := newRecordTestHandler()
+	for _, q := range []string{
+		"format=xml",
+		"index=-1",
+		"kind=table,chart",
+		"selector=find:pricing%20table",
+	} {
+		if w := doJSON(t, mux, "GET", "/tabs/tab1/tables?"+q, nil); w.Code != 400 {
+			t.Errorf("%s: expected 400, got %d: %s", q, w.Code, w.Body.String())
+		}
+	}
+}
+
+func TestHandleTablesUnknownRef(t *testing.T) {
+	_, _, mux := newRecordTestHandler()
+	if w := doJSON(t, mux, "GET", "/tables?ref=e42", nil); w.Code != 404 {
+		t.Errorf("expected 404 for a ref missing from the snapshot, got %d", w.Code)
+	}
+}
@@ -11,17 +11,49 @@ import (
 	"github.com/pinchtab/pinchtab/internal/assets"
 	"github.com/pinchtab/pinchtab/internal/engine"
 	"github.com/pinchtab/pinchtab/internal/httpx"
+	"github.com/pinchtab/pinchtab/internal/readability"
 )
 
+// textModeReadability selects main-article extraction rendered as Markdown.
+const textModeReadability = "readability"
+
 // HandleText extracts readable text from the current tab.
 //
 // @Endpoint GET /text
 func (h *Handlers) HandleText(w http.ResponseWriter, r *http.Request) {
+	mode := r.URL.Query().Get("mode")
+	format := strings.ToLower(strings.TrimSpace(r.URL.Query().Get("format")))
+	maxChars := -1
+	if v := r.URL.Query().Get("maxChars"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			maxChars = n
+		}
+	}
+
 	// --- Lite engine fast path ---
 	tabID := r.URL.Query().Get("tabId")
 	h.recordReadRequest(r, "text", tabID)
 	if h.useLite(engine.CapText, "") {
 		h.recordEngine(r, "lite")
+		if mode == textModeReadability {
+			ae, ok := h.Router.Lite().(engine.ArticleEngine)
+			if !ok {
+				httpx.Error(w, http.StatusNotImplemented, engine.ErrLiteNotSupported)
+				return
+			}
+			result, err := ae.Article(r.Context(), tabID)
+			if err != nil {
+				if engine.IsIDPIBlocked(err) {
+					httpx.Error(w, http.StatusForbidden, err)
+				} else {
+					httpx.Error(w, 500, fmt.Errorf("lite article: %w", err))
+				}
+				return
+			}
+			w.Header().Set("X-Engine", "lite")
+			h.writeArticle(w, &result.Article, result.URL, maxChars, format)
+			return
+		}
 		result, err := h.Router.Lite().Text(r.Context(), tabID)
 		if err != nil {
 			if engine.IsIDPIBlocked(err) {
@@ -49,15 +81,6 @@ func (h *Handlers) HandleText(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	mode := r.URL.Query().Get("mode")
-	format := strings.ToLower(strings.TrimSpace(r.URL.Query().Get("format")))
-	maxChars := -1
-	if v := r.URL.Query().Get("maxChars"); v != "" {
-		if n, err := strconv.Atoi(v); err == nil && n > 0 {
-			maxChars = n
-		}
-	}
-
 	ctx, resolvedTabID, err := h.tabContextWithHeader(w, r, tabID)
 	if err != nil {
 		httpx.Error(w, 404, err)
@@ -71,6 +94,28 @@ func (h *Handlers) HandleText(w http.ResponseWriter, r *http.Request) {
 	defer tCancel()
 	go httpx.CancelOnClientDone(r.Context(), tCancel)
 
+	if mode == textModeReadability {
+		var page, url string
+		if err := chromedp.Run(tCtx,
+			chromedp.Evaluate(`document.documentElement.outerHTML`, &page),
+			chromedp.Location(&url),
+		); err != nil {
+			httpx.Error(w, 500, fmt.Errorf("text extract: %w", err))
+			return
+		}
+		h.recordResolvedURL(r, url)
+		article, err := readability.Extract(page, url)
+		if err != nil {
+			httpx.Error(w, 500, fmt.Errorf("readability: %w", err))
+			return
+		}
+		if article.Title == "" {
+			_ = chromedp.Run(tCtx, chromedp.Title(&article.Title))
+		}
+		h.writeArticle(w, article, url, maxChars, format)
+		return
+	}
+
 	var text string
 	if mode == "raw" {
 		if err := chromedp.Run(tCtx,
@@ -140,6 +185,63 @@ func (h *Handlers) HandleText(w http.ResponseWriter, r *http.Request) {
 	httpx.JSON(w, 200, resp)
 }
 
+// writeArticle writes a readability result with the same truncation, IDPI
+// and format handling as plain text. The Markdown body goes in "text".
+func (h *Handlers) writeArticle(w http.ResponseWriter, article *readability.Article, url string, maxChars int, format string) {
+	text := article.Markdown
+	truncated := false
+	if maxChars > -1 && len(text) > maxChars {
+		text = text[:maxChars]
+		truncated = true
+	}
+
+	idpiResult := h.IDPIGuard.ScanContent(article.Title + "\n" + text)
+	if idpiResult.Blocked {
+		httpx.Error(w, http.StatusForbidden,
+			fmt.Errorf("content blocked by IDPI scanner: %s", idpiResult.Reason))
+		return
+	}
+	if idpiResult.Threat {
+		w.Header().Set("X-IDPI-Warning", idpiResult.Reason)
+		if idpiResult.Pattern != "" {
+			w.Header().Set("X-IDPI-Pattern", idpiResult.Pattern)
+		}
+	}
+	if h.Config.IDPI.Enabled && h.Config.IDPI.WrapContent {
+		text = h.IDPIGuard.WrapContent(text, url)
+	}
+
+	if format == "text" || format == "plain" {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.WriteHeader(200)
+		_, _ = w.Write([]byte(text))
+		return
+	}
+
+	resp := map[string]any{
+		"url":       url,
+		"title":     article.Title,
+		"text":      text,
+		"truncated": truncated,
+		"mode":      textModeReadability,
+	}
+	for key, val := range map[string]string{
+		"byline":    article.Byline,
+		"published": article.Published,
+		"leadImage": article.LeadImage,
+		"siteName":  article.SiteName,
+		"excerpt":   article.Excerpt,
+	} {
+		if val != "" {
+			resp[key] = val
+		}
+	}
+	if idpiResult.Threat {
+		resp["idpiWarning"] = idpiResult.Reason
+	}
+	httpx.JSON(w, 200, resp)
+}
+
 // HandleTabText extracts text for a tab identified by path ID.
 //
 // @Endpoint GET /tabs/{id}/text
@@ -0,0 +1,435 @@
+package handlers
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/chromedp/cdproto/dom"
+	"github.com/chromedp/cdproto/page"
+	"github.com/chromedp/chromedp"
+	"github.com/pinchtab/pinchtab/internal/bridge"
+	"github.com/pinchtab/pinchtab/internal/httpx"
+	"github.com/pinchtab/pinchtab/internal/selector"
+	"github.com/pinchtab/pinchtab/internal/visualdiff"
+)
+
+// docRectJS returns an element's box relative to its document.
+const docRectJS = `function() {
+	const r = this.getBoundingClientRect();
+	const d = this.ownerDocument.documentElement.getBoundingClientRect();
+	return { x: r.left - d.left, y: r.top - d.top, width: r.width, height: r.height };
+}`
+
+type visualBaselineRequest struct {
+	Name     string            `json:"name"`
+	TabID    string            `json:"tabId"`
+	Selector string            `json:"selector"`
+	FullPage bool              `json:"fullPage"`
+	Masks    []visualdiff.Mask `json:"masks"`
+}
+
+type visualCompareRequest struct {
+	Name                string            `json:"name"`
+	TabID               string            `json:"tabId"`
+	Threshold           float64           `json:"threshold"`
+	MaxMismatch         float64           `json:"maxMismatch"`
+	IncludeAntialiasing bool              `json:"includeAntialiasing"`
+	Masks               []visualdiff.Mask `json:"masks"`
+	Diff                string            `json:"diff"`
+	Update              bool              `json:"update"`
+}
+
+// HandleVisualBaseline captures a lossless screenshot of the viewport, the
+// full page or one element and stores it as a named baseline under
+// StateDir. Masks are stored with it and applied to every comparison.
+//
+// @Endpoint POST /visual/baseline
+// @Endpoint POST /tabs/{id}/visual/baseline
+func (h *Handlers) HandleVisualBaseline(w http.ResponseWriter, r *http.Request) {
+	if err := h.ensureChrome(); err != nil {
+		if h.writeBridgeUnavailable(w, err) {
+			return
+		}
+		httpx.Error(w, 500, fmt.Errorf("chrome initialization: %w", err))
+		return
+	}
+
+	var req visualBaselineRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBodySize)).Decode(&req); err != nil {
+		httpx.Error(w, 400, fmt.Errorf("decode: %w", err))
+		return
+	}
+	if pathID := r.PathValue("id"); pathID != "" {
+		req.TabID = pathID
+	}
+	if err := visualdiff.ValidateName(req.Name); err != nil {
+		httpx.Error(w, 400, err)
+		return
+	}
+	if req.Selector != "" && req.FullPage {
+		httpx.Error(w, 400, fmt.Errorf("selector and fullPage cannot be combined"))
+		return
+	}
+	if err := validateMasks(req.Masks); err != nil {
+		httpx.Error(w, 400, err)
+		return
+	}
+
+	h.recordReadRequest(r, "visual", req.TabID)
+	ctx, resolvedTabID, err := h.tabContextWithHeader(w, r, req.TabID)
+	if err != nil {
+		httpx.Error(w, 404, err)
+		return
+	}
+	if _, ok := h.enforceCurrentTabDomainPolicy(w, r, ctx, resolvedTabID); !ok {
+		return
+	}
+
+	tCtx, tCancel := context.WithTimeout(ctx, h.Config.ActionTimeout)
+	defer tCancel()
+	go httpx.CancelOnClientDone(r.Context(), tCancel)
+
+	refs := h.Bridge.GetRefCache(resolvedTabID)
+	shot, _, status, err := captureVisual(tCtx, req.Selector, req.FullPage, refs)
+	if err != nil {
+		httpx.Error(w, status, err)
+		return
+	}
+
+	var url string
+	_ = chromedp.Run(tCtx, chromedp.Location(&url))
+	h.recordResolvedURL(r, url)
+
+	b := &visualdiff.Baseline{Name: req.Name, URL: url, Selector: req.Selector, FullPage: req.FullPage, Masks: req.Masks}
+	if err := visualdiff.Save(h.Config.StateDir, b, shot); err != nil {
+		httpx.Error(w, 500, err)
+		return
+	}
+	httpx.JSON(w, 200, map[string]any{
+		"baseline": b,
+		"tabId":    resolvedTabID,
+	})
+}
+
+// HandleVisualCompare captures the tab the way the named baseline was
+// captured and compares the two. The response gives the mismatch
+// percentage, the changed regions and a diff image; passed is true when
+// the mismatch is at most maxMismatch percent.
+//
+// @Endpoint POST /visual/compare
+// @Endpoint POST /tabs/{id}/visual/compare
+func (h *Handlers) HandleVisualCompare(w http.ResponseWriter, r *http.Request) {
+	if err := h.ensureChrome(); err != nil {
+		if h.writeBridgeUnavailable(w, err) {
+			return
+		}
+		httpx.Error(w, 500, fmt.Errorf("chrome initialization: %w", err))
+		return
+	}
+
+	var req visualCompareRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBodySize)).Decode(&req); err != nil {
+		httpx.Error(w, 400, fmt.Errorf("decode: %w", err))
+		return
+	}
+	if pathID := r.PathValue("id"); pathID != "" {
+		req.TabID = pathID
+	}
+	if err := visualdiff.ValidateName(req.Name); err != nil {
+		httpx.Error(w, 400, err)
+		return
+	}
+	if req.Threshold < 0 || req.Threshold > 1 {
+		httpx.Error(w, 400, fmt.Errorf("threshold must be between 0 and 1"))
+		return
+	}
+	if req.MaxMismatch < 0 || req.MaxMismatch > 100 {
+		httpx.Error(w, 400, fmt.Errorf("maxMismatch must be a percentage between 0 and 100"))
+		return
+	}
+	switch req.Diff {
+	case "":
+		req.Diff = "inline"
+	case "inline", "file", "none":
+	default:
+		httpx.Error(w, 400, fmt.Errorf("diff must be inline, file or none"))
+		return
+	}
+	if err := validateMasks(req.Masks); err != nil {
+		httpx.Error(w, 400, err)
+		return
+	}
+
+	baseline, baseData, err := visualdiff.Load(h.Config.StateDir, req.Name)
+	if errors.Is(err, visualdiff.ErrNotFound) {
+		httpx.ErrorCode(w, 404, "baseline_not_found", fmt.Sprintf("baseline %q not found", req.Name), false, nil)
+		return
+	}
+	if err != nil {
+		httpx.Error(w, 500, err)
+		return
+	}
+	baseImg, err := visualdiff.Decode(baseData)
+	if err != nil {
+		httpx.Error(w, 500, fmt.Errorf("baseline: %w", err))
+		return
+	}
+
+	h.recordReadRequest(r, "visual", req.TabID)
+	ctx, resolvedTabID, err := h.tabContextWithHeader(w, r, req.TabID)
+	if err != nil {
+		httpx.Error(w, 404, err)
+		return
+	}
+	if _, ok := h.enforceCurrentTabDomainPolicy(w, r, ctx, resolvedTabID); !ok {
+		return
+	}
+
+	tCtx, tCancel := context.WithTimeout(ctx, h.Config.ActionTimeout)
+	defer tCancel()
+	go httpx.CancelOnClientDone(r.Context(), tCancel)
+
+	refs := h.Bridge.GetRefCache(resolvedTabID)
+	shot, clip, status, err := captureVisual(tCtx, baseline.Selector, baseline.FullPage, refs)
+	if err != nil {
+		httpx.Error(w, status, err)
+		return
+	}
+	curImg, err := visualdiff.Decode(shot)
+	if err != nil {
+		httpx.Error(w, 500, err)
+		return
+	}
+	masks, err := resolveMasks(tCtx, slices.Concat(baseline.Masks, req.Masks), clip, refs)
+	if err != nil {
+		httpx.Error(w, 400, err)
+		return
+	}
+
+	var url string
+	_ = chromedp.Run(tCtx, chromedp.Location(&url))
+	h.recordResolvedURL(r, url)
+
+	res := visualdiff.Compare(baseImg, curImg, visualdiff.Options{
+		Threshold:           req.Threshold,
+		IncludeAntialiasing: req.IncludeAntialiasing,
+		Masks:               masks,
+	})
+	resp := map[string]any{
+		"name":            req.Name,
+		"tabId":           resolvedTabID,
+		"url":             url,
+		"passed":          res.MismatchPercent <= req.MaxMismatch && !res.SizeChanged,
+		"mismatchPercent": res.MismatchPercent,
+		"changedPixels":   res.ChangedPixels,
+		"comparedPixels":  res.ComparedPixels,
+		"antialiasPixels": res.AntialiasPixels,
+		"regions":         res.Regions,
+		"baselineSize":    map[string]int{"width": baseImg.Bounds().Dx(), "height": baseImg.Bounds().Dy()},
+		"currentSize":     map[string]int{"width": curImg.Bounds().Dx(), "height": curImg.Bounds().Dy()},
+	}
+	if res.SizeChanged {
+		resp["sizeChanged"] = true
+	}
+
+	if req.Diff != "none" {
+		diffPNG, err := visualdiff.Encode(res.Diff)
+		if err != nil {
+			httpx.Error(w, 500, fmt.Errorf("encode diff: %w", err))
+			return
+		}
+		if req.Diff == "file" {
+			dir := filepath.Join(h.Config.StateDir, "visual-diffs")
+			if err := os.MkdirAll(dir, 0750); err != nil {
+				httpx.Error(w, 500, fmt.Errorf("create diff dir: %w", err))
+				return
+			}
+			path := filepath.Join(dir, fmt.Sprintf("%s-%s.png", req.Name, time.Now().Format("20060102-150405")))
+			if err := os.WriteFile(path, diffPNG, 0600); err != nil {
+				httpx.Error(w, 500, fmt.Errorf("write diff: %w", err))
+				return
+			}
+			resp["diffPath"] = path
+		} else {
+			resp["diff"] = base64.StdEncoding.EncodeToString(diffPNG)
+		}
+	}
+
+	if req.Update {
+		baseline.URL = url
+		baseline.CreatedAt = time.Time{}
+		if err := visualdiff.Save(h.Config.StateDir, baseline, shot); err != nil {
+			httpx.Error(w, 500, err)
+			return
+		}
+		resp["updated"] = true
+	}
+	httpx.JSON(w, 200, resp)
+}
+
+// HandleVisualBaselines lists the stored baselines.
+//
+// @Endpoint GET /visual/baselines
+func (h *Handlers) HandleVisualBaselines(w http.ResponseWriter, r *http.Request) {
+	list, err := visualdiff.List(h.Config.StateDir)
+	if err != nil {
+		httpx.Error(w, 500, err)
+		return
+	}
+	httpx.JSON(w, 200, map[string]any{
+		"baselines": list,
+		"count":     len(list),
+	})
+}
+
+// HandleVisualBaselineImage returns a baseline's PNG.
+//
+// @Endpoint GET /visual/baseline
+func (h *Handlers) HandleVisualBaselineImage(w http.ResponseWriter, r *http.Request) {
+	_, data, err := visualdiff.Load(h.Config.StateDir, r.URL.Query().Get("name"))
+	if errors.Is(err, visualdiff.ErrNotFound) {
+		httpx.ErrorCode(w, 404, "baseline_not_found", "baseline not found", false, nil)
+		return
+	}
+	if err != nil {
+		httpx.Error(w, 400, err)
+		return
+	}
+	w.Header().Set("Content-Type", "image/png")
+	_, _ = w.Write(data)
+}
+
+// HandleVisualBaselineDelete removes a baseline.
+//
+// @Endpoint DELETE /visual/baseline
+func (h *Handlers) HandleVisualBaselineDelete(w http.ResponseWriter, r *http.Request) {
+	name := r.URL.Query().Get("name")
+	err := visualdiff.Delete(h.Config.StateDir, name)
+	if errors.Is(err, visualdiff.ErrNotFound) {
+		httpx.ErrorCode(w, 404, "baseline_not_found", fmt.Sprintf("baseline %q not found", name), false, nil)
+		return
+	}
+	if err != nil {
+		httpx.Error(w, 400, err)
+		return
+	}
+	httpx.JSON(w, 200, map[string]any{"deleted": name})
+}
+
+// captureVisual takes a PNG screenshot at one image pixel per CSS pixel, so
+// captures from screens with different pixel ratios compare equal. With a
+// selector only that element is captured. It returns the captured area in
+// document coordinates and, on error, the status to report.
+func captureVisual(ctx context.Context, sel string, fullPage bool, refs *bridge.RefCache) ([]byte, bridge.Box, int, error) {
+	var visual *page.VisualViewport
+	var content *dom.Rect
+	err := chromedp.Run(ctx, chromedp.ActionFunc(func(ctx context.Context) error {
+		var err error
+		_, _, _, _, visual, content, err = page.GetLayoutMetrics().Do(ctx)
+		return err
+	}))
+	if err != nil {
+		return nil, bridge.Box{}, 500, fmt.Errorf("layout metrics: %w", err)
+	}
+
+	var clip bridge.Box
+	switch {
+	case sel != "":
+		box, status, err := elementDocBox(ctx, sel, refs)
+		if err != nil {
+			return nil, bridge.Box{}, status, err
+		}
+		clip = box
+	case fullPage:
+		clip = bridge.Box{Width: content.Width, Height: content.Height}
+	default:
+		clip = bridge.Box{X: visual.PageX, Y: visual.PageY, Width: visual.ClientWidth, Height: visual.ClientHeight}
+	}
+
+	// Fractional clips are not captured reliably; round outwards.
+	x, y := math.Floor(clip.X), math.Floor(clip.Y)
+	clip = bridge.Box{X: x, Y: y, Width: math.Ceil(clip.X + clip.Width - x), Height: math.Ceil(clip.Y + clip.Height - y)}
+	if clip.Width <= 0 || clip.Height <= 0 {
+		return nil, bridge.Box{}, 422, fmt.Errorf("nothing to capture: the area is empty")
+	}
+
+	var buf []byte
+	err = chromedp.Run(ctx, chromedp.ActionFunc(func(ctx context.Context) error {
+		var err error
+		buf, err = page.CaptureScreenshot().
+			WithFormat(page.CaptureScreenshotFormatPng).
+			WithCaptureBeyondViewport(sel != "" || fullPage).
+			WithFromSurface(true).
+			WithClip(&page.Viewport{X: clip.X, Y: clip.Y, Width: clip.Width, Height: clip.Height, Scale: 1}).
+			Do(ctx)
+		return err
+	}))
+	if err != nil {
+		return nil, bridge.Box{}, 500, fmt.Errorf("screenshot: %w", err)
+	}
+	return buf, clip, 200, nil
+}
+
+// elementDocBox resolves a selector and returns the element's box in
+// document coordinates.
+func elementDocBox(ctx context.Context, sel string, refs *bridge.RefCache) (bridge.Box, int, error) {
+	parsed := selector.Parse(sel)
+	if parsed.Kind == selector.KindSemantic {
+		return bridge.Box{}, 400, fmt.Errorf("find: selectors are not supported here; resolve a ref with /find first")
+	}
+	nodeID, err := bridge.ResolveUnifiedSelector(ctx, parsed, refs)
+	if err != nil {
+		return bridge.Box{}, 404, fmt.Errorf("selector %q: %w", sel, err)
+	}
+	var box bridge.Box
+	if err := bridge.CallFunctionOnNode(ctx, nodeID, docRectJS, &box); err != nil {
+		return bridge.Box{}, 500, fmt.Errorf("selector %q: %w", sel, err)
+	}
+	return box, 200, nil
+}
+
+// resolveMasks turns masks into image rectangles relative to the captured
+// area. Selector masks are measured on the current page; a selector that
+// matches nothing is skipped, since the element it hides may be gone.
+func resolveMasks(ctx context.Context, masks []visualdiff.Mask, clip bridge.Box, refs *bridge.RefCache) ([]visualdiff.Rect, error) {
+	out := make([]visualdiff.Rect, 0, len(masks))
+	for _, m := range masks {
+		box := bridge.Box{X: m.X, Y: m.Y, Width: m.Width, Height: m.Height}
+		if m.Selector != "" {
+			b, status, err := elementDocBox(ctx, m.Selector, refs)
+			if status == 404 {
+				continue
+			}
+			if err != nil {
+				return nil, fmt.Errorf("mask: %w", err)
+			}
+			box = bridge.Box{X: b.X - clip.X, Y: b.Y - clip.Y, Width: b.Width, Height: b.Height}
+		}
+		x, y := math.Floor(box.X), math.Floor(box.Y)
+		out = append(out, visualdiff.Rect{
+			X:      int(x),
+			Y:      int(y),
+			Width:  int(math.Ceil(box.X + box.Width - x)),
+			Height: int(math.Ceil(box.Y + box.Height - y)),
+		})
+	}
+	return out, nil
+}
+
+func validateMasks(masks []visualdiff.Mask) error {
+	for i, m := range masks {
+		if m.Selector == "" && (m.Width <= 0 || m.Height <= 0) {
+			return fmt.Errorf("mask %d needs a selector or a positive width and height", i)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+package handlers
+
+import (
+	"encoding/json"
+	"image"
+	"testing"
+
+	"github.com/pinchtab/pinchtab/internal/visualdiff"
+)
+
+func TestHandleVisualRejectsBadRequests(t *testing.T) {
+	h, _, mux := newRecordTestHandler()
+	h.Config.StateDir = t.TempDir()
+	for _, tc := range []struct {
+		path string
+		body map[string]any
+	}{
+		{"/visual/baseline", map[string]any{"name": "../home"}},
+		{"/visual/baseline", map[string]any{"name": "home", "selector": "main", "fullPage": true}},
+		{"/visual/baseline", map[string]any{"name": "home", "masks": []map[string]any{{"x": 10}}}},
+		{"/visual/compare", map[string]any{"name": "home", "threshold": 2}},
+		{"/visual/compare", map[string]any{"name": "home", "maxMismatch": -1}},
+		{"/visual/compare", map[string]any{"name": "home", "diff": "gif"}},
+	} {
+		if w := doJSON(t, mux, "POST", "/tabs/tab1"+tc.path, tc.body); w.Code != 400 {
+			t.Errorf("%s %v: expected 400, got %d: %s", tc.path, tc.body, w.Code, w.Body.String())
+		}
+	}
+	w := doJSON(t, mux, "POST", "/visual/compare", map[string]any{"name": "missing"})
+	if w.Code != 404 {
+		t.Errorf("expected 404 for a missing baseline, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestHandleVisualBaselines(t *testing.T) {
+	h, _, mux := newRecordTestHandler()
+	h.Config.StateDir = t.TempDir()
+	data, err := visualdiff.Encode(image.NewNRGBA(image.Rect(0, 0, 4, 3)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := visualdiff.Save(h.Config.StateDir, &visualdiff.Baseline{Name: "home", FullPage: true}, data); err != nil {
+		t.Fatal(err)
+	}
+
+	w := doJSON(t, mux, "GET", "/visual/baselines", nil)
+	var list struct {
+		Baselines []visualdiff.Baseline `json:"baselines"`
+		Count     int                   `json:"count"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &list); err != nil || list.Count != 1 || list.Baselines[0].Width != 4 {
+		t.Fatalf("unexpected list: %s", w.Body.String())
+	}
+	if w := doJSON(t, mux, "GET", "/visual/baseline?name=home", nil); w.Code != 200 || w.Header().Get("Content-Type") != "image/png" {
+		t.Errorf("expected the PNG, got %d %s", w.Code, w.Header().Get("Content-Type"))
+	}
+	if w := doJSON(t, mux, "DELETE", "/visual/baseline?name=home", nil); w.Code != 200 {
+		t.Errorf("delete: expected 200, got %d", w.Code)
+	}
+	if w := doJSON(t, mux, "DELETE", "/visual/baseline?name=home", nil); w.Code != 404 {
+		t.Errorf("second delete: expected 404, got %d", w.Code)
+	}
+}
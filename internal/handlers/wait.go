@@ -157,6 +157,10 @@ func (h *Handlers) handleWaitCore(w http.ResponseWriter, r *http.Request, req wa
 		}
 		select {
 		case <-time.After(time.Duration(ms) * time.Millisecond):
+			// Record against the current tab too, like the other modes.
+			if _, resolvedTabID, err := h.Bridge.TabContext(req.TabID); err == nil {
+				h.recordWait(resolvedTabID, req)
+			}
 			httpx.JSON(w, 200, waitResponse{
 				Waited:  true,
 				Elapsed: time.Since(start).Milliseconds(),
@@ -217,6 +221,7 @@ func (h *Handlers) handleWaitCore(w http.ResponseWriter, r *http.Request, req wa
 		var result bool
 		evalErr := chromedp.Run(tCtx, chromedp.Evaluate(js, &result))
 		if evalErr == nil && result {
+			h.recordWait(resolvedTabID, req)
 			httpx.JSON(w, 200, waitResponse{
 				Waited:  true,
 				Elapsed: time.Since(start).Milliseconds(),
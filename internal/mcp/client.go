@@ -79,6 +79,28 @@ func (c *Client) Get(ctx context.Context, path string, query url.Values) ([]byte
 
 // Post performs a POST request with a JSON body.
 func (c *Client) Post(ctx context.Context, path string, payload any) ([]byte, int, error) {
+	return c.sendJSON(ctx, http.MethodPost, path, payload)
+}
+
+// Put performs a PUT request with a JSON body.
+func (c *Client) Put(ctx context.Context, path string, payload any) ([]byte, int, error) {
+	return c.sendJSON(ctx, http.MethodPut, path, payload)
+}
+
+// Delete performs a DELETE request.
+func (c *Client) Delete(ctx context.Context, path string, query url.Values) ([]byte, int, error) {
+	u := c.url(path)
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, u, nil)
+	if err != nil {
+		return nil, 0, err
+	}
+	return c.do(req)
+}
+
+func (c *Client) sendJSON(ctx context.Context, method, path string, payload any) ([]byte, int, error) {
 	var body io.Reader
 	if payload != nil {
 		b, err := json.Marshal(payload)
@@ -87,7 +109,7 @@ func (c *Client) Post(ctx context.Context, path string, payload any) ([]byte, in
 		}
 		body = bytes.NewReader(b)
 	}
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url(path), body)
+	req, err := http.NewRequestWithContext(ctx, method, c.url(path), body)
 	if err != nil {
 		return nil, 0, err
 	}
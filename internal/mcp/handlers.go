@@ -59,6 +59,11 @@ func handlerMap(c *Client) map[string]func(context.Context, mcp.CallToolRequest)
 		"pinchtab_network_detail": handleNetworkDetail(c),
 		"pinchtab_network_clear":  handleNetworkClear(c),
 
+		// Interception
+		"pinchtab_intercept":        handleIntercept(c),
+		"pinchtab_intercept_list":   handleInterceptList(c),
+		"pinchtab_intercept_remove": handleInterceptRemove(c),
+
 		// Dialog
 		"pinchtab_dialog": handleDialog(c),
 	}
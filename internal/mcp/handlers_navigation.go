@@ -57,6 +57,9 @@ func handleSnapshot(c *Client) func(context.Context, mcp.CallToolRequest) (*mcp.
 		if sel := optString(r, "selector"); sel != "" {
 			q.Set("selector", sel)
 		}
+		for _, region := range r.GetStringSlice("regions", nil) {
+			q.Add("region", region)
+		}
 		if v := optNumber(r, "maxTokens"); v > 0 {
 			q.Set("maxTokens", formatInt(v))
 		}
@@ -77,10 +80,10 @@ func handleSnapshot(c *Client) func(context.Context, mcp.CallToolRequest) (*mcp.
 func normalizeSnapshotFormat(v string) (string, error) {
 	format := strings.ToLower(strings.TrimSpace(v))
 	switch format {
-	case "compact", "text":
+	case "compact", "text", "markdown", "structured":
 		return format, nil
 	default:
-		return "", fmt.Errorf("format must be 'compact' or 'text'")
+		return "", fmt.Errorf("format must be 'compact', 'text', 'markdown' or 'structured'")
 	}
 }
 
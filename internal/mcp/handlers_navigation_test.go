@@ -113,6 +113,34 @@ func TestHandleSnapshotFormatText(t *testing.T) {
 	}
 }
 
+func TestHandleSnapshotFormatMarkdown(t *testing.T) {
+	srv := mockPinchTab()
+	defer srv.Close()
+
+	r := callTool(t, "pinchtab_snapshot", map[string]any{
+		"format": "Markdown",
+	}, srv)
+
+	text := resultText(t, r)
+	if !strings.Contains(text, "markdown") {
+		t.Errorf("expected format=markdown in query, got %s", text)
+	}
+}
+
+func TestHandleSnapshotRegions(t *testing.T) {
+	srv := mockPinchTab()
+	defer srv.Close()
+
+	r := callTool(t, "pinchtab_snapshot", map[string]any{
+		"regions": []any{"main", "dialog"},
+	}, srv)
+
+	text := resultText(t, r)
+	if !strings.Contains(text, "main") || !strings.Contains(text, "dialog") {
+		t.Errorf("expected both regions in query, got %s", text)
+	}
+}
+
 func TestHandleSnapshotFormatRejectsUnsupportedValues(t *testing.T) {
 	srv := mockPinchTab()
 	defer srv.Close()
@@ -26,6 +26,12 @@ func handleNetwork(c *Client) func(context.Context, mcp.CallToolRequest) (*mcp.C
 		if typ := optString(r, "type"); typ != "" {
 			q.Set("type", typ)
 		}
+		if message := optString(r, "message"); message != "" {
+			q.Set("message", message)
+		}
+		if direction := optString(r, "direction"); direction != "" {
+			q.Set("direction", direction)
+		}
 		if limit, ok := optFloat(r, "limit"); ok {
 			q.Set("limit", fmt.Sprintf("%d", int(limit)))
 		}
@@ -75,3 +81,70 @@ func handleNetworkClear(c *Client) func(context.Context, mcp.CallToolRequest) (*
 		return resultFromBytes(body, code)
 	}
 }
+
+func handleIntercept(c *Client) func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, r mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		action, err := r.RequireString("action")
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		payload := map[string]any{"action": action}
+		for _, key := range []string{"tabId", "url", "urlRegex", "method", "resourceType", "body", "rewriteUrl", "errorReason"} {
+			if v := optString(r, key); v != "" {
+				payload[key] = v
+			}
+		}
+		for _, key := range []string{"status", "delayMs", "times"} {
+			if v, ok := optFloat(r, key); ok {
+				payload[key] = int(v)
+			}
+		}
+		if headers, ok := r.GetArguments()["headers"].(map[string]any); ok && len(headers) > 0 {
+			payload["headers"] = headers
+		}
+
+		var body []byte
+		var code int
+		if ruleID := optString(r, "ruleId"); ruleID != "" {
+			body, code, err = c.Put(ctx, "/intercept/"+url.PathEscape(ruleID), payload)
+		} else {
+			body, code, err = c.Post(ctx, "/intercept", payload)
+		}
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		return resultFromBytes(body, code)
+	}
+}
+
+func handleInterceptList(c *Client) func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, r mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		q := url.Values{}
+		if tabID := optString(r, "tabId"); tabID != "" {
+			q.Set("tabId", tabID)
+		}
+		body, code, err := c.Get(ctx, "/intercept", q)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		return resultFromBytes(body, code)
+	}
+}
+
+func handleInterceptRemove(c *Client) func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, r mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		q := url.Values{}
+		if tabID := optString(r, "tabId"); tabID != "" {
+			q.Set("tabId", tabID)
+		}
+		path := "/intercept"
+		if ruleID := optString(r, "ruleId"); ruleID != "" {
+			path += "/" + url.PathEscape(ruleID)
+		}
+		body, code, err := c.Delete(ctx, path, q)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		return resultFromBytes(body, code)
+	}
+}
@@ -91,3 +91,34 @@ func TestHandleNetworkClearWithTab(t *testing.T) {
 		t.Errorf("expected /network/clear path, got %s", text)
 	}
 }
+
+func TestHandleIntercept(t *testing.T) {
+	srv := mockPinchTab()
+	defer srv.Close()
+
+	r := callTool(t, "pinchtab_intercept", map[string]any{
+		"action":  "fulfill",
+		"url":     "*/api/items*",
+		"status":  float64(503),
+		"headers": map[string]any{"Retry-After": "1"},
+		"times":   float64(2),
+	}, srv)
+	text := resultText(t, r)
+	for _, want := range []string{`"path":"/intercept"`, `"method":"POST"`, `"status":503`, `"Retry-After":"1"`, `"times":2`} {
+		if !strings.Contains(text, want) {
+			t.Errorf("expected %s in %s", want, text)
+		}
+	}
+
+	r = callTool(t, "pinchtab_intercept", map[string]any{"action": "fail", "url": "*", "ruleId": "r1"}, srv)
+	text = resultText(t, r)
+	if !strings.Contains(text, `"path":"/intercept/r1"`) || !strings.Contains(text, `"method":"PUT"`) {
+		t.Errorf("expected PUT /intercept/r1, got %s", text)
+	}
+
+	r = callTool(t, "pinchtab_intercept_remove", map[string]any{"ruleId": "r1", "tabId": "t1"}, srv)
+	text = resultText(t, r)
+	if !strings.Contains(text, `"path":"/intercept/r1"`) || !strings.Contains(text, `"method":"DELETE"`) {
+		t.Errorf("expected DELETE /intercept/r1, got %s", text)
+	}
+}
@@ -23,7 +23,7 @@ func mockPinchTab() *httptest.Server {
 			resp["query"] = r.URL.Query()
 		}
 
-		if r.Method == http.MethodPost {
+		if r.Method == http.MethodPost || r.Method == http.MethodPut {
 			body, _ := io.ReadAll(r.Body)
 			if len(body) > 0 {
 				var parsed map[string]any
@@ -16,9 +16,10 @@ func allTools() []mcp.Tool {
 			mcp.WithString("tabId", mcp.Description("Target tab ID")),
 			mcp.WithBoolean("interactive", mcp.Description("Only interactive elements (buttons, links, inputs)")),
 			mcp.WithBoolean("compact", mcp.Description("Compact format (most token-efficient)")),
-			mcp.WithString("format", mcp.Description("Output format: 'compact' or 'text'")),
+			mcp.WithString("format", mcp.Description("Output format: 'compact', 'text', 'markdown' (headings, lists and links with inline refs) or 'structured' (JSON plus the page's JSON-LD, OpenGraph and microdata)")),
 			mcp.WithBoolean("diff", mcp.Description("Only changes since last snapshot")),
 			mcp.WithString("selector", mcp.Description("Unified selector to scope the snapshot (CSS, XPath, text, or ref)")),
+			mcp.WithArray("regions", mcp.WithStringItems(), mcp.Description("Snapshot several parts of the page at once, each labelled in the response: landmarks ('main', 'dialog', 'nav', 'form', 'search', 'header', 'footer', 'aside') or selectors. maxTokens and depth apply to each region")),
 			mcp.WithNumber("maxTokens", mcp.Description("Maximum estimated tokens in response (e.g. 300)")),
 			mcp.WithNumber("depth", mcp.Description("Maximum tree depth (e.g. 3)")),
 			mcp.WithBoolean("noAnimations", mcp.Description("Disable animations before capturing the snapshot")),
@@ -191,12 +192,14 @@ func allTools() []mcp.Tool {
 
 		// ── Network Monitoring ──────────────────────────────────────
 		mcp.NewTool("pinchtab_network",
-			mcp.WithDescription("List recent network requests captured from the browser. Returns method, status, URL, type, size, and timing for each request."),
+			mcp.WithDescription("List recent network requests captured from the browser. Returns method, status, URL, type, size, and timing for each request, and the frames of WebSocket and EventSource connections under messages."),
 			mcp.WithString("tabId", mcp.Description("Target tab ID (optional, uses current tab if empty)")),
 			mcp.WithString("filter", mcp.Description("URL pattern filter (substring match)")),
 			mcp.WithString("method", mcp.Description("HTTP method filter (GET, POST, etc)")),
 			mcp.WithString("status", mcp.Description("Status code range filter (e.g. '4xx', '5xx', '200')")),
-			mcp.WithString("type", mcp.Description("Resource type filter (xhr, fetch, document, stylesheet, script, image, etc)")),
+			mcp.WithString("type", mcp.Description("Resource type filter (xhr, fetch, document, stylesheet, script, image, websocket, eventsource, etc)")),
+			mcp.WithString("message", mcp.Description("Only WebSocket/EventSource messages containing this text")),
+			mcp.WithString("direction", mcp.Description("Only WebSocket messages sent or received: 'send' or 'receive'")),
 			mcp.WithNumber("limit", mcp.Description("Maximum number of entries to return")),
 			mcp.WithNumber("bufferSize", mcp.Description("Per-tab buffer size for new capture (default from config, typically 100)")),
 		),
@@ -211,6 +214,34 @@ func allTools() []mcp.Tool {
 			mcp.WithString("tabId", mcp.Description("Target tab ID (optional, clears all if empty)")),
 		),
 
+		// ── Interception ────────────────────────────────────────────
+		mcp.NewTool("pinchtab_intercept",
+			mcp.WithDescription("Add a request interception rule to a tab, or replace one when ruleId is given. The first matching rule handles a request: fulfill answers it with a canned response, modify changes its headers or body, rewrite sends it to another URL, fail aborts it with a net error, delay holds it back. Hits show up in pinchtab_network."),
+			mcp.WithString("action", mcp.Required(), mcp.Description("fulfill, modify, rewrite, fail or delay")),
+			mcp.WithString("url", mcp.Description("URL glob; * matches any characters, ? one (url or urlRegex required)")),
+			mcp.WithString("urlRegex", mcp.Description("URL regular expression, instead of url")),
+			mcp.WithString("method", mcp.Description("Only match this HTTP method")),
+			mcp.WithString("resourceType", mcp.Description("Only match this resource type (Document, XHR, Fetch, Script, Image, etc)")),
+			mcp.WithNumber("status", mcp.Description("Response status for fulfill (default: 200)")),
+			mcp.WithObject("headers", mcp.Description("Response headers for fulfill, or request headers to set for modify (empty value removes)")),
+			mcp.WithString("body", mcp.Description("Response body for fulfill, or request body for modify")),
+			mcp.WithString("rewriteUrl", mcp.Description("Target URL for rewrite; may use $1 groups from urlRegex")),
+			mcp.WithString("errorReason", mcp.Description("Net error for fail, e.g. ConnectionRefused, TimedOut, NameNotResolved (default: Failed)")),
+			mcp.WithNumber("delayMs", mcp.Description("Delay in milliseconds before the action; required for delay")),
+			mcp.WithNumber("times", mcp.Description("Stop matching after this many hits")),
+			mcp.WithString("ruleId", mcp.Description("Replace this rule instead of adding one")),
+			mcp.WithString("tabId", mcp.Description("Target tab ID")),
+		),
+		mcp.NewTool("pinchtab_intercept_list",
+			mcp.WithDescription("List a tab's request interception rules in match order, with hit counts"),
+			mcp.WithString("tabId", mcp.Description("Target tab ID")),
+		),
+		mcp.NewTool("pinchtab_intercept_remove",
+			mcp.WithDescription("Remove a request interception rule, or all of a tab's rules when ruleId is omitted"),
+			mcp.WithString("ruleId", mcp.Description("Rule ID from pinchtab_intercept_list (optional, removes all if empty)")),
+			mcp.WithString("tabId", mcp.Description("Target tab ID")),
+		),
+
 		// ── Dialog ──────────────────────────────────────────────────
 		mcp.NewTool("pinchtab_dialog",
 			mcp.WithDescription("Handle a JavaScript dialog (alert, confirm, prompt). Accept or dismiss the currently open dialog."),
@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/pinchtab/pinchtab/internal/authn"
+	"github.com/pinchtab/pinchtab/internal/config"
 	"github.com/pinchtab/pinchtab/internal/httpx"
 )
 
@@ -17,6 +18,9 @@ type startInstanceRequest struct {
 	Mode           string   `json:"mode,omitempty"`
 	Port           string   `json:"port,omitempty"`
 	ExtensionPaths []string `json:"extensionPaths,omitempty"`
+
+	Network *config.NetworkConditions `json:"network,omitempty"`
+	Proxy   *config.ProxyConfig       `json:"proxy,omitempty"`
 }
 
 func (o *Orchestrator) handleGetInstance(w http.ResponseWriter, r *http.Request) {
@@ -237,6 +241,23 @@ func (o *Orchestrator) handleStartInstance(w http.ResponseWriter, r *http.Reques
 }
 
 func (o *Orchestrator) startInstanceWithRequest(w http.ResponseWriter, r *http.Request, req startInstanceRequest, auditEvent string) {
+	if req.Network != nil {
+		nc, err := req.Network.Resolve()
+		if err != nil {
+			httpx.Error(w, 400, fmt.Errorf("network: %w", err))
+			return
+		}
+		req.Network = &nc
+	}
+	if req.Proxy != nil {
+		p, err := req.Proxy.Resolve()
+		if err != nil {
+			httpx.Error(w, 400, fmt.Errorf("proxy: %w", err))
+			return
+		}
+		req.Proxy = &p
+	}
+
 	var profileName string
 	var err error
 
@@ -252,7 +273,11 @@ func (o *Orchestrator) startInstanceWithRequest(w http.ResponseWriter, r *http.R
 
 	headless := req.Mode != "headed"
 
-	inst, err := o.Launch(profileName, req.Port, headless, req.ExtensionPaths)
+	inst, err := o.LaunchWithOptions(profileName, req.Port, headless, LaunchOptions{
+		ExtensionPaths: req.ExtensionPaths,
+		Network:        req.Network,
+		Proxy:          req.Proxy,
+	})
 	if err != nil {
 		statusCode := classifyLaunchError(err)
 		httpx.Error(w, statusCode, err)
@@ -4,10 +4,12 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"os"
 	"strings"
 	"testing"
 
 	"github.com/pinchtab/pinchtab/internal/bridge"
+	"github.com/pinchtab/pinchtab/internal/config"
 	"github.com/pinchtab/pinchtab/internal/profiles"
 )
 
@@ -64,3 +66,127 @@ func TestHandleLaunchByNameAliasesStartSemantics(t *testing.T) {
 		t.Fatal("Headless = true, want false for mode=headed")
 	}
 }
+
+func TestHandleStartInstanceWritesNetworkConditions(t *testing.T) {
+	old := processAliveFunc
+	processAliveFunc = func(pid int) bool { return pid > 0 }
+	defer func() { processAliveFunc = old }()
+
+	runner := &mockRunner{portAvail: true}
+	o := NewOrchestratorWithRunner(t.TempDir(), runner)
+
+	req := httptest.NewRequest(http.MethodPost, "/instances/start", strings.NewReader(`{"network":{"profile":"slow-3g","latencyMs":500}}`))
+	w := httptest.NewRecorder()
+	o.handleStartInstance(w, req)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("status = %d, want %d body=%s", w.Code, http.StatusCreated, w.Body.String())
+	}
+
+	var configPath string
+	for _, kv := range runner.env {
+		if v, ok := strings.CutPrefix(kv, "PINCHTAB_CONFIG="); ok {
+			configPath = v
+		}
+	}
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		t.Fatalf("read child config: %v", err)
+	}
+	var fc config.FileConfig
+	if err := json.Unmarshal(data, &fc); err != nil {
+		t.Fatalf("decode child config: %v", err)
+	}
+	nc := fc.InstanceDefaults.Network
+	if nc == nil || nc.Profile != "slow-3g" || nc.LatencyMs != 500 || nc.DownloadKbps != 400 {
+		t.Fatalf("child network = %+v, want resolved slow-3g with 500ms latency", nc)
+	}
+}
+
+func TestHandleStartInstanceRejectsUnknownNetworkProfile(t *testing.T) {
+	runner := &mockRunner{portAvail: true}
+	o := NewOrchestratorWithRunner(t.TempDir(), runner)
+
+	req := httptest.NewRequest(http.MethodPost, "/instances/start", strings.NewReader(`{"network":{"profile":"dialup"}}`))
+	w := httptest.NewRecorder()
+	o.handleStartInstance(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusBadRequest)
+	}
+	if runner.runCalled {
+		t.Fatal("an invalid network profile should not launch an instance")
+	}
+}
+
+// childConfig reads the config file the runner was pointed at.
+func childConfig(t *testing.T, runner *mockRunner) config.FileConfig {
+	t.Helper()
+	var configPath string
+	for _, kv := range runner.env {
+		if v, ok := strings.CutPrefix(kv, "PINCHTAB_CONFIG="); ok {
+			configPath = v
+		}
+	}
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		t.Fatalf("read child config: %v", err)
+	}
+	var fc config.FileConfig
+	if err := json.Unmarshal(data, &fc); err != nil {
+		t.Fatalf("decode child config: %v", err)
+	}
+	return fc
+}
+
+func TestHandleStartInstanceWritesProxy(t *testing.T) {
+	old := processAliveFunc
+	processAliveFunc = func(pid int) bool { return pid > 0 }
+	defer func() { processAliveFunc = old }()
+
+	baseDir := t.TempDir()
+	runner := &mockRunner{portAvail: true}
+	o := NewOrchestratorWithRunner(baseDir, runner)
+	pm := profiles.NewProfileManager(baseDir)
+	if err := pm.CreateWithMeta("de", profiles.ProfileMeta{Proxy: &config.ProxyConfig{URL: "http://u:p@de.proxy.example:3128"}}); err != nil {
+		t.Fatalf("CreateWithMeta: %v", err)
+	}
+	o.profiles = pm
+
+	req := httptest.NewRequest(http.MethodPost, "/instances/start", strings.NewReader(`{"profileId":"de"}`))
+	w := httptest.NewRecorder()
+	o.handleStartInstance(w, req)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("status = %d, want %d body=%s", w.Code, http.StatusCreated, w.Body.String())
+	}
+	if p := childConfig(t, runner).InstanceDefaults.Proxy; p == nil || p.URL != "http://u:p@de.proxy.example:3128" {
+		t.Fatalf("child proxy = %+v, want the profile's", p)
+	}
+}
+
+func TestHandleStartInstanceProxyOverridesProfile(t *testing.T) {
+	old := processAliveFunc
+	processAliveFunc = func(pid int) bool { return pid > 0 }
+	defer func() { processAliveFunc = old }()
+
+	runner := &mockRunner{portAvail: true}
+	o := NewOrchestratorWithRunner(t.TempDir(), runner)
+
+	req := httptest.NewRequest(http.MethodPost, "/instances/start", strings.NewReader(`{"proxy":{"url":"SOCKS5://us.proxy.example:1080","bypass":"*.internal"}}`))
+	w := httptest.NewRecorder()
+	o.handleStartInstance(w, req)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("status = %d, want %d body=%s", w.Code, http.StatusCreated, w.Body.String())
+	}
+	p := childConfig(t, runner).InstanceDefaults.Proxy
+	if p == nil || p.URL != "socks5://us.proxy.example:1080" || p.Bypass != "*.internal" {
+		t.Fatalf("child proxy = %+v, want the resolved request proxy", p)
+	}
+
+	runner = &mockRunner{portAvail: true}
+	o = NewOrchestratorWithRunner(t.TempDir(), runner)
+	req = httptest.NewRequest(http.MethodPost, "/instances/start", strings.NewReader(`{"proxy":{"url":"socks5://u:p@proxy:1080"}}`))
+	w = httptest.NewRecorder()
+	o.handleStartInstance(w, req)
+	if w.Code != http.StatusBadRequest || runner.runCalled {
+		t.Fatalf("socks credentials: status = %d, launched = %v", w.Code, runner.runCalled)
+	}
+}
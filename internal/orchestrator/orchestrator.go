@@ -244,7 +244,22 @@ func installStableBinary(src, dst string) error {
 	return err
 }
 
+// LaunchOptions are per-launch settings written into the instance's config
+// on top of the orchestrator's own.
+type LaunchOptions struct {
+	ExtensionPaths []string
+	Network        *config.NetworkConditions
+	// Proxy overrides the profile's upstream proxy, which overrides the
+	// orchestrator's.
+	Proxy *config.ProxyConfig
+}
+
 func (o *Orchestrator) Launch(name, port string, headless bool, extensionPaths []string) (*bridge.Instance, error) {
+	return o.LaunchWithOptions(name, port, headless, LaunchOptions{ExtensionPaths: extensionPaths})
+}
+
+// LaunchWithOptions launches an instance like Launch, with extra settings.
+func (o *Orchestrator) LaunchWithOptions(name, port string, headless bool, opts LaunchOptions) (*bridge.Instance, error) {
 	// Validate profile name to prevent path traversal attacks
 	if err := profiles.ValidateProfileName(name); err != nil {
 		return nil, err
@@ -328,7 +343,13 @@ func (o *Orchestrator) Launch(name, port string, headless bool, extensionPaths [
 		return nil, fmt.Errorf("create state dir: %w", err)
 	}
 
-	childConfigPath, err := o.writeChildConfig(port, cdpPort, profilePath, instanceStateDir, headless, extensionPaths)
+	if opts.Proxy == nil && o.profiles != nil {
+		if p, ok := o.profiles.Proxy(name); ok {
+			opts.Proxy = &p
+		}
+	}
+
+	childConfigPath, err := o.writeChildConfig(port, cdpPort, profilePath, instanceStateDir, headless, opts)
 	if err != nil {
 		return nil, fmt.Errorf("write child config: %w", err)
 	}
@@ -374,7 +395,7 @@ func (o *Orchestrator) Launch(name, port string, headless bool, extensionPaths [
 	return &inst.Instance, nil
 }
 
-func (o *Orchestrator) writeChildConfig(port string, cdpPort int, profilePath, instanceStateDir string, headless bool, extensionPaths []string) (string, error) {
+func (o *Orchestrator) writeChildConfig(port string, cdpPort int, profilePath, instanceStateDir string, headless bool, opts LaunchOptions) (string, error) {
 	fc := config.FileConfigFromRuntime(o.runtimeCfg)
 	fc.Server.Port = port
 	fc.Server.StateDir = instanceStateDir
@@ -389,6 +410,14 @@ func (o *Orchestrator) writeChildConfig(port string, cdpPort int, profilePath, i
 		fc.InstanceDefaults.Mode = "headed"
 	}
 
+	if opts.Network != nil {
+		fc.InstanceDefaults.Network = opts.Network
+	}
+	if opts.Proxy != nil {
+		fc.InstanceDefaults.Proxy = opts.Proxy
+	}
+
+	extensionPaths := opts.ExtensionPaths
 	if len(extensionPaths) > 0 {
 		seen := make(map[string]bool)
 		unique := make([]string, 0, len(fc.Browser.ExtensionPaths)+len(extensionPaths))
@@ -412,7 +441,11 @@ func (o *Orchestrator) writeChildConfig(port string, cdpPort int, profilePath, i
 	if err != nil {
 		return "", err
 	}
-	if err := os.WriteFile(configPath, data, 0644); err != nil {
+	// The config holds the token and any proxy credentials.
+	if err := os.WriteFile(configPath, data, 0600); err != nil {
+		return "", err
+	}
+	if err := os.Chmod(configPath, 0600); err != nil {
 		return "", err
 	}
 	return configPath, nil
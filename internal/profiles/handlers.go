@@ -7,6 +7,7 @@ import (
 	"strings"
 
 	"github.com/pinchtab/pinchtab/internal/authn"
+	"github.com/pinchtab/pinchtab/internal/config"
 	"github.com/pinchtab/pinchtab/internal/httpx"
 )
 
@@ -23,6 +24,22 @@ func profileMutationStatus(err error) int {
 	}
 }
 
+// resolveProfileProxy validates a proxy sent for a profile. An empty URL
+// resolves to nil, which removes the profile's proxy.
+func resolveProfileProxy(p *config.ProxyConfig) (*config.ProxyConfig, error) {
+	if p == nil {
+		return nil, nil
+	}
+	resolved, err := p.Resolve()
+	if err != nil {
+		return nil, fmt.Errorf("proxy: %w", err)
+	}
+	if !resolved.Active() {
+		return nil, nil
+	}
+	return &resolved, nil
+}
+
 func (pm *ProfileManager) RegisterHandlers(mux *http.ServeMux) {
 	mux.HandleFunc("GET /profiles", pm.handleList)
 	mux.HandleFunc("POST /profiles", pm.handleCreate)
@@ -68,6 +85,7 @@ func (pm *ProfileManager) handleList(w http.ResponseWriter, r *http.Request) {
 					"hasAccount":        p.HasAccount,
 					"useWhen":           p.UseWhen,
 					"description":       p.Description,
+					"proxy":             p.Proxy,
 				})
 			}
 		}
@@ -80,9 +98,10 @@ func (pm *ProfileManager) handleList(w http.ResponseWriter, r *http.Request) {
 
 func (pm *ProfileManager) handleCreate(w http.ResponseWriter, r *http.Request) {
 	var req struct {
-		Name        string `json:"name"`
-		Description string `json:"description"`
-		UseWhen     string `json:"useWhen"`
+		Name        string              `json:"name"`
+		Description string              `json:"description"`
+		UseWhen     string              `json:"useWhen"`
+		Proxy       *config.ProxyConfig `json:"proxy"`
 	}
 	if err := httpx.DecodeJSONBody(w, r, 0, &req); err != nil {
 		httpx.Error(w, httpx.StatusForJSONDecodeError(err), err)
@@ -92,10 +111,16 @@ func (pm *ProfileManager) handleCreate(w http.ResponseWriter, r *http.Request) {
 		httpx.Error(w, 400, fmt.Errorf("name required"))
 		return
 	}
+	proxy, err := resolveProfileProxy(req.Proxy)
+	if err != nil {
+		httpx.Error(w, 400, err)
+		return
+	}
 
 	meta := ProfileMeta{
 		Description: req.Description,
 		UseWhen:     req.UseWhen,
+		Proxy:       proxy,
 	}
 
 	if err := pm.CreateWithMeta(req.Name, meta); err != nil {
@@ -143,9 +168,10 @@ func (pm *ProfileManager) handleImport(w http.ResponseWriter, r *http.Request) {
 
 func (pm *ProfileManager) handleUpdateMeta(w http.ResponseWriter, r *http.Request) {
 	var req struct {
-		Name        string  `json:"name"`
-		Description *string `json:"description"`
-		UseWhen     *string `json:"useWhen"`
+		Name        string              `json:"name"`
+		Description *string             `json:"description"`
+		UseWhen     *string             `json:"useWhen"`
+		Proxy       *config.ProxyConfig `json:"proxy"`
 	}
 	if err := httpx.DecodeJSONBody(w, r, 0, &req); err != nil {
 		httpx.Error(w, httpx.StatusForJSONDecodeError(err), err)
@@ -155,6 +181,11 @@ func (pm *ProfileManager) handleUpdateMeta(w http.ResponseWriter, r *http.Reques
 		httpx.Error(w, 400, fmt.Errorf("name required"))
 		return
 	}
+	proxy, err := resolveProfileProxy(req.Proxy)
+	if err != nil {
+		httpx.Error(w, 400, err)
+		return
+	}
 
 	updates := make(map[string]string)
 	if req.Description != nil {
@@ -168,6 +199,12 @@ func (pm *ProfileManager) handleUpdateMeta(w http.ResponseWriter, r *http.Reques
 		httpx.Error(w, profileMutationStatus(err), err)
 		return
 	}
+	if req.Proxy != nil {
+		if err := pm.SetProxy(req.Name, proxy); err != nil {
+			httpx.Error(w, profileMutationStatus(err), err)
+			return
+		}
+	}
 	authn.AuditLog(r, "profile.meta_updated", "profileName", req.Name)
 	httpx.JSON(w, 200, map[string]string{"status": "updated", "name": req.Name})
 }
@@ -202,6 +239,7 @@ func (pm *ProfileManager) handleGetByID(w http.ResponseWriter, r *http.Request)
 			"hasAccount":        p.HasAccount,
 			"useWhen":           p.UseWhen,
 			"description":       p.Description,
+			"proxy":             p.Proxy,
 		}
 		break
 	}
@@ -260,14 +298,20 @@ func (pm *ProfileManager) handleUpdateByID(w http.ResponseWriter, r *http.Reques
 	}
 
 	var req struct {
-		Name        *string `json:"name"`
-		UseWhen     *string `json:"useWhen"`
-		Description *string `json:"description"`
+		Name        *string             `json:"name"`
+		UseWhen     *string             `json:"useWhen"`
+		Description *string             `json:"description"`
+		Proxy       *config.ProxyConfig `json:"proxy"`
 	}
 	if err := httpx.DecodeJSONBody(w, r, 0, &req); err != nil {
 		httpx.Error(w, httpx.StatusForJSONDecodeError(err), fmt.Errorf("invalid JSON"))
 		return
 	}
+	proxy, err := resolveProfileProxy(req.Proxy)
+	if err != nil {
+		httpx.Error(w, 400, err)
+		return
+	}
 
 	finalName := name
 	if req.Name != nil && *req.Name != name {
@@ -291,6 +335,12 @@ func (pm *ProfileManager) handleUpdateByID(w http.ResponseWriter, r *http.Reques
 			return
 		}
 	}
+	if req.Proxy != nil {
+		if err := pm.SetProxy(finalName, proxy); err != nil {
+			httpx.Error(w, profileMutationStatus(err), err)
+			return
+		}
+	}
 
 	authn.AuditLog(r, "profile.updated", "profileId", profileID(finalName), "profileName", finalName)
 	httpx.JSON(w, 200, map[string]any{"status": "updated", "id": profileID(finalName), "name": finalName})
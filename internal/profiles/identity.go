@@ -105,5 +105,11 @@ func writeProfileMeta(profileDir string, meta ProfileMeta) error {
 	if err != nil {
 		return err
 	}
-	return os.WriteFile(filepath.Join(profileDir, "profile.json"), data, 0644)
+	// 0600: the proxy URL may carry credentials. WriteFile keeps the mode
+	// of an existing file, so tighten files written before that.
+	path := filepath.Join(profileDir, "profile.json")
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return err
+	}
+	return os.Chmod(path, 0600)
 }
@@ -15,6 +15,7 @@ import (
 
 	"github.com/pinchtab/pinchtab/internal/activity"
 	"github.com/pinchtab/pinchtab/internal/bridge"
+	"github.com/pinchtab/pinchtab/internal/config"
 	"github.com/pinchtab/pinchtab/internal/ids"
 )
 
@@ -98,10 +99,11 @@ type ProfileManager struct {
 }
 
 type ProfileMeta struct {
-	ID          string `json:"id,omitempty"`
-	Name        string `json:"name,omitempty"`
-	UseWhen     string `json:"useWhen,omitempty"`
-	Description string `json:"description,omitempty"`
+	ID          string              `json:"id,omitempty"`
+	Name        string              `json:"name,omitempty"`
+	UseWhen     string              `json:"useWhen,omitempty"`
+	Description string              `json:"description,omitempty"`
+	Proxy       *config.ProxyConfig `json:"proxy,omitempty"` // upstream proxy instances of the profile launch with
 }
 
 type ProfileDetailedInfo struct {
@@ -117,6 +119,8 @@ type ProfileDetailedInfo struct {
 	HasAccount        bool      `json:"hasAccount,omitempty"`
 	UseWhen           string    `json:"useWhen,omitempty"`
 	Description       string    `json:"description,omitempty"`
+
+	Proxy *config.ProxyConfig `json:"proxy,omitempty"` // password masked
 }
 
 func NewProfileManager(baseDir string) *ProfileManager {
@@ -222,6 +226,7 @@ func (pm *ProfileManager) List() ([]bridge.ProfileInfo, error) {
 			HasAccount:        info.HasAccount,
 			UseWhen:           info.UseWhen,
 			Description:       info.Description,
+			Proxy:             info.Proxy,
 		})
 	}
 	sort.Slice(profiles, func(i, j int) bool { return profiles[i].Name < profiles[j].Name })
@@ -277,9 +282,19 @@ func (pm *ProfileManager) profileInfo(dirName string) (ProfileDetailedInfo, erro
 		HasAccount:        hasAccount,
 		UseWhen:           meta.UseWhen,
 		Description:       meta.Description,
+		Proxy:             redactedProxy(meta.Proxy),
 	}, nil
 }
 
+// redactedProxy masks the password of a stored proxy.
+func redactedProxy(p *config.ProxyConfig) *config.ProxyConfig {
+	if p == nil || !p.Active() {
+		return nil
+	}
+	r := p.Redacted()
+	return &r
+}
+
 func (pm *ProfileManager) Import(name, sourcePath string) error {
 	if err := ValidateProfileName(name); err != nil {
 		return err
@@ -614,6 +629,48 @@ func (pm *ProfileManager) UpdateMeta(name string, meta map[string]string) error
 	return writeProfileMeta(dir, existing)
 }
 
+// SetProxy sets the upstream proxy the profile's instances launch with. A
+// nil or empty proxy removes it.
+func (pm *ProfileManager) SetProxy(name string, proxy *config.ProxyConfig) error {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+
+	if err := ValidateProfileName(name); err != nil {
+		return err
+	}
+	dir, err := pm.findProfileDirByName(name)
+	if err != nil {
+		return err
+	}
+
+	existing := readProfileMeta(dir)
+	if existing.Name == "" {
+		existing.Name = name
+	}
+	existing.Proxy = nil
+	if proxy != nil && proxy.Active() {
+		p := *proxy
+		existing.Proxy = &p
+	}
+	return writeProfileMeta(dir, existing)
+}
+
+// Proxy returns the upstream proxy of a profile, credentials included.
+func (pm *ProfileManager) Proxy(name string) (config.ProxyConfig, bool) {
+	pm.mu.RLock()
+	defer pm.mu.RUnlock()
+
+	dir, err := pm.findProfileDirByName(name)
+	if err != nil {
+		return config.ProxyConfig{}, false
+	}
+	meta := readProfileMeta(dir)
+	if meta.Proxy == nil || !meta.Proxy.Active() {
+		return config.ProxyConfig{}, false
+	}
+	return *meta.Proxy, true
+}
+
 func (pm *ProfileManager) Rename(oldName, newName string) error {
 	if err := ValidateProfileName(oldName); err != nil {
 		return err
@@ -0,0 +1,137 @@
+package readability
+
+import (
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+func attr(n *html.Node, key string) string {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+	return ""
+}
+
+func hasAttr(n *html.Node, key string) bool {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return true
+		}
+	}
+	return false
+}
+
+// forEach visits the descendants of n in document order. Returning false
+// from fn skips the children of the node.
+func forEach(n *html.Node, fn func(*html.Node) bool) {
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if fn(c) {
+			forEach(c, fn)
+		}
+	}
+}
+
+// find returns the first element below n with the given tag.
+func find(n *html.Node, a atom.Atom) *html.Node {
+	var found *html.Node
+	forEach(n, func(c *html.Node) bool {
+		if found != nil {
+			return false
+		}
+		if c.Type == html.ElementNode && c.DataAtom == a {
+			found = c
+			return false
+		}
+		return true
+	})
+	return found
+}
+
+func count(n *html.Node, a atom.Atom) int {
+	total := 0
+	forEach(n, func(c *html.Node) bool {
+		if c.Type == html.ElementNode && c.DataAtom == a {
+			total++
+		}
+		return true
+	})
+	return total
+}
+
+func hasAncestor(n *html.Node, tags ...atom.Atom) bool {
+	for p := n.Parent; p != nil; p = p.Parent {
+		for _, t := range tags {
+			if p.DataAtom == t {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func textContent(n *html.Node) string {
+	if n.Type == html.TextNode {
+		return n.Data
+	}
+	var b strings.Builder
+	forEach(n, func(c *html.Node) bool {
+		if c.Type == html.TextNode {
+			b.WriteString(c.Data)
+		}
+		return true
+	})
+	return b.String()
+}
+
+// collapse folds runs of whitespace into single spaces and trims the ends.
+func collapse(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+func firstNonEmpty(vals ...string) string {
+	for _, v := range vals {
+		if v = strings.TrimSpace(v); v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// imageSrc returns an image's URL, preferring lazy-loading attributes over
+// a data: placeholder.
+func imageSrc(n *html.Node) string {
+	src := attr(n, "src")
+	if src == "" || strings.HasPrefix(src, "data:") {
+		for _, key := range []string{"data-src", "data-original", "data-lazy-src"} {
+			if v := attr(n, key); v != "" {
+				return v
+			}
+		}
+		if set := attr(n, "srcset"); set != "" {
+			return strings.Fields(set)[0]
+		}
+	}
+	return src
+}
+
+// absURL resolves ref against base. Unparseable references and
+// javascript: links resolve to "".
+func absURL(base *url.URL, ref string) string {
+	ref = strings.TrimSpace(ref)
+	if ref == "" || strings.HasPrefix(strings.ToLower(ref), "javascript:") {
+		return ""
+	}
+	u, err := url.Parse(ref)
+	if err != nil {
+		return ""
+	}
+	if base == nil {
+		return u.String()
+	}
+	return base.ResolveReference(u).String()
+}
@@ -0,0 +1,304 @@
+package readability
+
+import (
+	"net/url"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+type renderer struct {
+	base *url.URL
+}
+
+// renderMarkdown renders nodes as Markdown blocks separated by blank lines.
+func renderMarkdown(nodes []*html.Node, base *url.URL) string {
+	r := &renderer{base: base}
+	var blocks []string
+	for _, n := range nodes {
+		blocks = append(blocks, r.block(n)...)
+	}
+	return strings.Join(blocks, "\n\n")
+}
+
+func (r *renderer) block(n *html.Node) []string {
+	switch n.DataAtom {
+	case atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6:
+		text := strings.ReplaceAll(r.inlineText(n), "\n", " ")
+		if text == "" {
+			return nil
+		}
+		level := int(n.Data[1] - '0')
+		return []string{strings.Repeat("#", level) + " " + text}
+	case atom.P, atom.Summary, atom.Dd:
+		return nonEmpty(r.inlineText(n))
+	case atom.Dt:
+		return nonEmpty(emphasis(r.inlineText(n), "**"))
+	case atom.Figcaption:
+		return nonEmpty(emphasis(r.inlineText(n), "_"))
+	case atom.Pre:
+		return nonEmpty(codeBlock(n))
+	case atom.Ul, atom.Ol:
+		return nonEmpty(r.list(n))
+	case atom.Table:
+		return r.table(n)
+	case atom.Hr:
+		return []string{"---"}
+	case atom.Blockquote:
+		inner := strings.Join(r.container(n), "\n\n")
+		if inner == "" {
+			return nil
+		}
+		lines := strings.Split(inner, "\n")
+		for i, l := range lines {
+			lines[i] = strings.TrimRight("> "+l, " ")
+		}
+		return []string{strings.Join(lines, "\n")}
+	}
+	return r.container(n)
+}
+
+// container renders the children of n, grouping runs of inline content
+// into paragraphs.
+func (r *renderer) container(n *html.Node) []string {
+	var out []string
+	var buf strings.Builder
+	flush := func() {
+		if s := finishInline(buf.String()); s != "" {
+			out = append(out, s)
+		}
+		buf.Reset()
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type == html.ElementNode && blockTags[c.DataAtom] {
+			flush()
+			out = append(out, r.block(c)...)
+			continue
+		}
+		r.inline(c, &buf)
+	}
+	flush()
+	return out
+}
+
+func (r *renderer) inlineText(n *html.Node) string {
+	var buf strings.Builder
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		r.inline(c, &buf)
+	}
+	return finishInline(buf.String())
+}
+
+func (r *renderer) inline(n *html.Node, b *strings.Builder) {
+	if n.Type == html.TextNode {
+		lead, body, trail := splitSpace(n.Data)
+		if lead != "" {
+			b.WriteByte(' ')
+		}
+		b.WriteString(strings.Join(strings.FieldsFunc(body, isSpace), " "))
+		if trail != "" && body != "" {
+			b.WriteByte(' ')
+		}
+		return
+	}
+	if n.Type != html.ElementNode {
+		return
+	}
+	inner := func() string {
+		var sub strings.Builder
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			r.inline(c, &sub)
+		}
+		return sub.String()
+	}
+	switch n.DataAtom {
+	case atom.Br:
+		b.WriteByte('\n')
+	case atom.Img:
+		if src := absURL(r.base, imageSrc(n)); src != "" {
+			b.WriteString("![" + collapse(attr(n, "alt")) + "](" + src + ")")
+		}
+	case atom.A:
+		text := inner()
+		href := absURL(r.base, attr(n, "href"))
+		if strings.TrimSpace(text) == "" || href == "" || strings.HasPrefix(attr(n, "href"), "#") {
+			b.WriteString(text)
+			return
+		}
+		lead, body, trail := splitSpace(text)
+		b.WriteString(lead + "[" + strings.ReplaceAll(body, "\n", " ") + "](" + href + ")" + trail)
+	case atom.Strong, atom.B:
+		b.WriteString(emphasis(inner(), "**"))
+	case atom.Em, atom.I, atom.Cite:
+		b.WriteString(emphasis(inner(), "_"))
+	case atom.Del, atom.S, atom.Strike:
+		b.WriteString(emphasis(inner(), "~~"))
+	case atom.Code, atom.Kbd, atom.Samp:
+		text := textContent(n)
+		if strings.TrimSpace(text) == "" {
+			return
+		}
+		fence := "`"
+		if strings.Contains(text, "`") {
+			fence = "``"
+		}
+		b.WriteString(fence + collapse(text) + fence)
+	default:
+		b.WriteString(inner())
+		if blockTags[n.DataAtom] {
+			b.WriteByte(' ')
+		}
+	}
+}
+
+// list renders a ul or ol, indenting nested lists and continuation lines
+// under their item marker.
+func (r *renderer) list(n *html.Node) string {
+	num := 1
+	if v, err := strconv.Atoi(attr(n, "start")); err == nil {
+		num = v
+	}
+	var out []string
+	for li := n.FirstChild; li != nil; li = li.NextSibling {
+		if li.Type != html.ElementNode || li.DataAtom != atom.Li {
+			continue
+		}
+		marker := "- "
+		if n.DataAtom == atom.Ol {
+			marker = strconv.Itoa(num) + ". "
+			num++
+		}
+		pad := strings.Repeat(" ", len(marker))
+		first := true
+		for _, part := range r.container(li) {
+			for _, line := range strings.Split(part, "\n") {
+				if first {
+					out = append(out, marker+line)
+					first = false
+				} else {
+					out = append(out, strings.TrimRight(pad+line, " "))
+				}
+			}
+		}
+	}
+	return strings.Join(out, "\n")
+}
+
+// table renders a table as a pipe table with the first row as header.
+// Layout tables with a single column are rendered as their content.
+func (r *renderer) table(n *html.Node) []string {
+	var rows [][]string
+	cols := 0
+	forEach(n, func(c *html.Node) bool {
+		if c.Type != html.ElementNode {
+			return true
+		}
+		if c.DataAtom == atom.Table {
+			return false
+		}
+		if c.DataAtom != atom.Tr {
+			return true
+		}
+		var row []string
+		for cell := c.FirstChild; cell != nil; cell = cell.NextSibling {
+			if cell.DataAtom == atom.Td || cell.DataAtom == atom.Th {
+				text := strings.ReplaceAll(r.inlineText(cell), "\n", " ")
+				row = append(row, strings.ReplaceAll(text, "|", `\|`))
+			}
+		}
+		if len(row) > 0 {
+			rows = append(rows, row)
+			cols = max(cols, len(row))
+		}
+		return false
+	})
+	if len(rows) == 0 {
+		return nil
+	}
+	if cols == 1 {
+		return r.container(n)
+	}
+	var b strings.Builder
+	for i, row := range rows {
+		for len(row) < cols {
+			row = append(row, "")
+		}
+		b.WriteString("| " + strings.Join(row, " | ") + " |")
+		if i == 0 {
+			b.WriteString("\n|" + strings.Repeat(" --- |", cols))
+		}
+		if i < len(rows)-1 {
+			b.WriteByte('\n')
+		}
+	}
+	return []string{b.String()}
+}
+
+func codeBlock(n *html.Node) string {
+	text := strings.Trim(textContent(n), "\n")
+	if strings.TrimSpace(text) == "" {
+		return ""
+	}
+	lang := codeLanguage(n)
+	if code := find(n, atom.Code); lang == "" && code != nil {
+		lang = codeLanguage(code)
+	}
+	fence := "```"
+	if strings.Contains(text, "```") {
+		fence = "~~~~"
+	}
+	return fence + lang + "\n" + text + "\n" + fence
+}
+
+func codeLanguage(n *html.Node) string {
+	for _, cls := range strings.Fields(attr(n, "class")) {
+		for _, prefix := range []string{"language-", "lang-"} {
+			if lang, ok := strings.CutPrefix(cls, prefix); ok {
+				return lang
+			}
+		}
+	}
+	return ""
+}
+
+// finishInline trims each line of rendered inline content and drops empty
+// lines.
+func finishInline(s string) string {
+	var lines []string
+	for _, l := range strings.Split(s, "\n") {
+		if l = collapse(l); l != "" {
+			lines = append(lines, l)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// emphasis wraps text in mark, keeping surrounding whitespace outside so
+// the markers stay attached to the words.
+func emphasis(text, mark string) string {
+	lead, body, trail := splitSpace(text)
+	if body == "" {
+		return text
+	}
+	return lead + mark + body + mark + trail
+}
+
+func splitSpace(s string) (lead, body, trail string) {
+	body = strings.TrimLeftFunc(s, isSpace)
+	lead = s[:len(s)-len(body)]
+	trimmed := strings.TrimRightFunc(body, isSpace)
+	return lead, trimmed, body[len(trimmed):]
+}
+
+func isSpace(r rune) bool {
+	return r == ' ' || r == '\t' || r == '\n' || r == '\r' || r == '\f' || r == '\u00a0'
+}
+
+func nonEmpty(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return []string{s}
+}
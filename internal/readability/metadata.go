@@ -0,0 +1,196 @@
+package readability
+
+import (
+	"encoding/json"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+type metadata struct {
+	title     string
+	byline    string
+	published string
+	image     string
+	siteName  string
+	excerpt   string
+}
+
+var articleTypes = map[string]bool{
+	"Article": true, "NewsArticle": true, "BlogPosting": true, "Report": true,
+	"ScholarlyArticle": true, "TechArticle": true, "AnalysisNewsArticle": true,
+	"OpinionNewsArticle": true, "ReportageNewsArticle": true, "LiveBlogPosting": true,
+}
+
+// readMetadata collects article metadata from JSON-LD, OpenGraph, Twitter,
+// Dublin Core and schema.org microdata, in that order of preference.
+func readMetadata(doc *html.Node) metadata {
+	metas := map[string]string{}
+	var ld metadata
+	var docTitle string
+	forEach(doc, func(n *html.Node) bool {
+		if n.Type != html.ElementNode {
+			return true
+		}
+		switch n.DataAtom {
+		case atom.Meta:
+			content := strings.TrimSpace(attr(n, "content"))
+			if content == "" {
+				return false
+			}
+			for _, key := range []string{"property", "name", "itemprop"} {
+				for _, k := range strings.Fields(strings.ToLower(attr(n, key))) {
+					if _, ok := metas[k]; !ok {
+						metas[k] = content
+					}
+				}
+			}
+		case atom.Title:
+			if docTitle == "" {
+				docTitle = collapse(textContent(n))
+			}
+		case atom.Script:
+			if ld.title == "" && strings.EqualFold(attr(n, "type"), "application/ld+json") {
+				ld = readJSONLD(textContent(n))
+			}
+			return false
+		}
+		return true
+	})
+
+	m := metadata{
+		title: firstNonEmpty(ld.title, metas["og:title"], metas["twitter:title"],
+			metas["dc.title"], metas["headline"], cleanTitle(docTitle)),
+		byline: firstNonEmpty(ld.byline, metas["author"], nonURL(metas["article:author"]),
+			metas["dc.creator"], metas["parsely-author"]),
+		published: firstNonEmpty(ld.published, metas["article:published_time"],
+			metas["og:published_time"], metas["datepublished"], metas["parsely-pub-date"],
+			metas["dc.date"], metas["date"], metas["pubdate"]),
+		image: firstNonEmpty(metas["og:image"], metas["og:image:url"], metas["twitter:image"],
+			metas["twitter:image:src"], ld.image),
+		siteName: firstNonEmpty(metas["og:site_name"], ld.siteName),
+		excerpt: firstNonEmpty(metas["og:description"], metas["description"],
+			metas["twitter:description"], ld.excerpt),
+	}
+	return m
+}
+
+// readJSONLD returns metadata from the first article object in a JSON-LD
+// block, looking inside arrays and @graph.
+func readJSONLD(src string) metadata {
+	var v any
+	if err := json.Unmarshal([]byte(src), &v); err != nil {
+		return metadata{}
+	}
+	var obj map[string]any
+	var search func(v any)
+	search = func(v any) {
+		if obj != nil {
+			return
+		}
+		switch t := v.(type) {
+		case []any:
+			for _, item := range t {
+				search(item)
+			}
+		case map[string]any:
+			if isArticleType(t["@type"]) {
+				obj = t
+				return
+			}
+			if g, ok := t["@graph"]; ok {
+				search(g)
+			}
+		}
+	}
+	search(v)
+	if obj == nil {
+		return metadata{}
+	}
+	m := metadata{
+		title:     firstNonEmpty(str(obj["headline"]), str(obj["name"])),
+		byline:    names(obj["author"]),
+		published: str(obj["datePublished"]),
+		image:     imageURL(obj["image"]),
+		excerpt:   str(obj["description"]),
+	}
+	if pub, ok := obj["publisher"].(map[string]any); ok {
+		m.siteName = str(pub["name"])
+	}
+	return m
+}
+
+func isArticleType(v any) bool {
+	switch t := v.(type) {
+	case string:
+		return articleTypes[t]
+	case []any:
+		for _, item := range t {
+			if s, ok := item.(string); ok && articleTypes[s] {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func str(v any) string {
+	s, _ := v.(string)
+	return strings.TrimSpace(s)
+}
+
+// names joins author names given as a string, an object or a list of
+// either.
+func names(v any) string {
+	switch t := v.(type) {
+	case string:
+		return strings.TrimSpace(t)
+	case map[string]any:
+		return str(t["name"])
+	case []any:
+		var out []string
+		for _, item := range t {
+			if n := names(item); n != "" {
+				out = append(out, n)
+			}
+		}
+		return strings.Join(out, ", ")
+	}
+	return ""
+}
+
+func imageURL(v any) string {
+	switch t := v.(type) {
+	case string:
+		return strings.TrimSpace(t)
+	case map[string]any:
+		return str(t["url"])
+	case []any:
+		if len(t) > 0 {
+			return imageURL(t[0])
+		}
+	}
+	return ""
+}
+
+// cleanTitle removes a site name appended to a document title, as in
+// "Headline | Site" or "Headline - Site", when the headline part is long
+// enough to stand on its own.
+func cleanTitle(title string) string {
+	for _, sep := range []string{" | ", " - ", " – ", " — ", " :: ", " » "} {
+		if i := strings.LastIndex(title, sep); i > 0 {
+			if head := strings.TrimSpace(title[:i]); len(strings.Fields(head)) >= 3 {
+				return head
+			}
+		}
+	}
+	return title
+}
+
+func nonURL(s string) string {
+	if strings.HasPrefix(s, "http://") || strings.HasPrefix(s, "https://") {
+		return ""
+	}
+	return s
+}
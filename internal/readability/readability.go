@@ -0,0 +1,428 @@
+// Package readability finds the main article of an HTML page and renders
+// it as Markdown, leaving out navigation, footers, banners and other page
+// chrome. Candidate blocks are scored by their text and link density in
+// the manner of Mozilla's Readability; title, byline, publish date and
+// lead image come from page metadata when present.
+package readability
+
+import (
+	"errors"
+	"math"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// Article is the main content of a page.
+type Article struct {
+	Title     string `json:"title"`
+	Byline    string `json:"byline,omitempty"`
+	Published string `json:"published,omitempty"`
+	LeadImage string `json:"leadImage,omitempty"`
+	SiteName  string `json:"siteName,omitempty"`
+	Excerpt   string `json:"excerpt,omitempty"`
+	Markdown  string `json:"markdown"`
+}
+
+// minArticleChars is the body length below which extraction is retried
+// without dropping elements for their class names.
+const minArticleChars = 250
+
+var (
+	unlikelyRe = regexp.MustCompile(`(?i)-ad-|ai2html|banner|breadcrumbs|combx|comment|community|consent|cookie|cover-wrap|disqus|extra|footer|gdpr|header|legends|menu|newsletter|related|remark|replies|rss|share|shoutbox|sidebar|skyscraper|social|sponsor|subscribe|supplemental|ad-break|agegate|pagination|pager|popup|promo|yom-remote`)
+	maybeRe    = regexp.MustCompile(`(?i)and|article|body|column|content|main|shadow|story|post|entry|text`)
+	positiveRe = regexp.MustCompile(`(?i)article|body|content|entry|hentry|h-entry|main|page|post|text|blog|story`)
+	negativeRe = regexp.MustCompile(`(?i)-ad-|hidden|^hid$| hid$| hid |^hid |banner|combx|comment|com-|contact|foot|footer|footnote|gdpr|masthead|media|meta|outbrain|promo|related|scroll|share|shoutbox|sidebar|skyscraper|sponsor|shopping|tags|tool|widget`)
+	bylineRe   = regexp.MustCompile(`(?i)byline|author|dateline|writtenby|p-author`)
+	hiddenRe   = regexp.MustCompile(`(?i)display\s*:\s*none|visibility\s*:\s*hidden`)
+)
+
+// removedTags are dropped before scoring: scripts, embeds and form
+// controls never belong to the article text.
+var removedTags = map[atom.Atom]bool{
+	atom.Script: true, atom.Style: true, atom.Noscript: true, atom.Template: true,
+	atom.Iframe: true, atom.Svg: true, atom.Canvas: true, atom.Object: true,
+	atom.Embed: true, atom.Link: true, atom.Meta: true, atom.Form: true,
+	atom.Button: true, atom.Input: true, atom.Select: true, atom.Textarea: true,
+	atom.Nav: true, atom.Aside: true, atom.Footer: true, atom.Dialog: true,
+}
+
+var removedRoles = map[string]bool{
+	"navigation": true, "banner": true, "contentinfo": true, "complementary": true,
+	"dialog": true, "alertdialog": true, "menu": true, "menubar": true, "search": true,
+}
+
+var blockTags = map[atom.Atom]bool{
+	atom.Address: true, atom.Article: true, atom.Blockquote: true, atom.Dd: true,
+	atom.Div: true, atom.Dl: true, atom.Dt: true, atom.Figcaption: true,
+	atom.Figure: true, atom.H1: true, atom.H2: true, atom.H3: true, atom.H4: true,
+	atom.H5: true, atom.H6: true, atom.Header: true, atom.Hr: true, atom.Li: true,
+	atom.Main: true, atom.Ol: true, atom.P: true, atom.Pre: true, atom.Section: true,
+	atom.Table: true, atom.Tbody: true, atom.Thead: true, atom.Tfoot: true,
+	atom.Tr: true, atom.Td: true, atom.Th: true, atom.Ul: true, atom.Details: true,
+	atom.Summary: true,
+}
+
+// Extract returns the main article of an HTML document. pageURL is used to
+// make links and images absolute.
+func Extract(src, pageURL string) (*Article, error) {
+	base, _ := url.Parse(pageURL)
+	var art *Article
+	for _, strict := range []bool{true, false} {
+		doc, err := html.Parse(strings.NewReader(src))
+		if err != nil {
+			return nil, err
+		}
+		if b := find(doc, atom.Base); b != nil {
+			if href, err := url.Parse(attr(b, "href")); err == nil && base != nil {
+				base = base.ResolveReference(href)
+			}
+		}
+		meta := readMetadata(doc)
+		body := find(doc, atom.Body)
+		if body == nil {
+			return nil, errors.New("no body element")
+		}
+
+		byline := prepare(body, strict)
+		nodes := mainContent(body)
+		md := renderMarkdown(nodes, base)
+
+		art = &Article{
+			Title:     meta.title,
+			Byline:    firstNonEmpty(meta.byline, byline),
+			Published: meta.published,
+			LeadImage: absURL(base, meta.image),
+			SiteName:  meta.siteName,
+			Excerpt:   meta.excerpt,
+			Markdown:  md,
+		}
+		if art.Published == "" {
+			for _, n := range nodes {
+				if t := find(n, atom.Time); t != nil && attr(t, "datetime") != "" {
+					art.Published = attr(t, "datetime")
+					break
+				}
+			}
+		}
+		if art.LeadImage == "" {
+			for _, n := range nodes {
+				if img := find(n, atom.Img); img != nil {
+					art.LeadImage = absURL(base, imageSrc(img))
+					break
+				}
+			}
+		}
+		art.Title, art.Markdown = dedupeTitle(art.Title, art.Markdown, doc)
+		if len(art.Markdown) >= minArticleChars {
+			break
+		}
+	}
+	return art, nil
+}
+
+// prepare removes page chrome and hidden elements from body. In strict mode
+// elements whose class or id suggests chrome (comments, sidebars, share
+// bars) are removed too. The text of a byline element, if one is found, is
+// returned and the element removed.
+func prepare(body *html.Node, strict bool) string {
+	byline := ""
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		for c := n.FirstChild; c != nil; {
+			next := c.NextSibling
+			if c.Type == html.CommentNode {
+				n.RemoveChild(c)
+			} else if c.Type == html.ElementNode {
+				if shouldRemove(c, strict) {
+					n.RemoveChild(c)
+				} else if byline == "" && isByline(c) {
+					byline = collapse(textContent(c))
+					n.RemoveChild(c)
+				} else {
+					walk(c)
+				}
+			}
+			c = next
+		}
+	}
+	walk(body)
+	return strings.TrimPrefix(strings.TrimPrefix(byline, "By "), "by ")
+}
+
+func shouldRemove(n *html.Node, strict bool) bool {
+	if removedTags[n.DataAtom] || removedRoles[attr(n, "role")] {
+		return true
+	}
+	if hasAttr(n, "hidden") || attr(n, "aria-hidden") == "true" || hiddenRe.MatchString(attr(n, "style")) {
+		return true
+	}
+	if n.DataAtom == atom.Header && !hasAncestor(n, atom.Article, atom.Main) {
+		return true
+	}
+	if !strict || n.DataAtom == atom.Body || n.DataAtom == atom.A || hasAncestor(n, atom.Table, atom.Code, atom.Pre) {
+		return false
+	}
+	match := attr(n, "class") + " " + attr(n, "id")
+	return unlikelyRe.MatchString(match) && !maybeRe.MatchString(match)
+}
+
+func isByline(n *html.Node) bool {
+	match := attr(n, "class") + " " + attr(n, "id")
+	if attr(n, "rel") != "author" && !strings.Contains(attr(n, "itemprop"), "author") && !bylineRe.MatchString(match) {
+		return false
+	}
+	text := collapse(textContent(n))
+	return text != "" && len(text) < 100
+}
+
+// mainContent scores paragraph-like blocks, credits their ancestors and
+// returns the best candidate together with related siblings.
+func mainContent(body *html.Node) []*html.Node {
+	scores := map[*html.Node]float64{}
+	var candidates []*html.Node
+	init := func(n *html.Node) {
+		if _, ok := scores[n]; ok {
+			return
+		}
+		scores[n] = tagWeight(n) + classWeight(n)
+		candidates = append(candidates, n)
+	}
+
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			if c.Type != html.ElementNode {
+				continue
+			}
+			if scoreable(c) {
+				text := collapse(textContent(c))
+				if len(text) >= 25 {
+					score := 1 + float64(strings.Count(text, ",")) + math.Min(float64(len(text))/100, 3)
+					level := 0
+					for a := c.Parent; a != nil && a.Type == html.ElementNode && level < 5; a = a.Parent {
+						init(a)
+						divider := 1.0
+						switch {
+						case level == 1:
+							divider = 2
+						case level > 1:
+							divider = float64(level * 3)
+						}
+						scores[a] += score / divider
+						level++
+					}
+				}
+			}
+			walk(c)
+		}
+	}
+	walk(body)
+
+	var top *html.Node
+	best := 0.0
+	for _, c := range candidates {
+		scores[c] *= 1 - linkDensity(c)
+		if top == nil || scores[c] > best {
+			top, best = c, scores[c]
+		}
+	}
+	if top == nil {
+		return []*html.Node{body}
+	}
+	// A candidate that holds nearly all of its parent's text is usually a
+	// wrapper; the parent then also holds headings and lead images.
+	for top.Parent != nil && top.Parent != body && top.Parent.Type == html.ElementNode &&
+		len(textContent(top)) > 0 && float64(len(textContent(top)))/float64(len(textContent(top.Parent))+1) > 0.9 {
+		top = top.Parent
+	}
+	if top == body || top.Parent == nil {
+		cleanConditionally(top)
+		return []*html.Node{top}
+	}
+
+	threshold := math.Max(10, best*0.2)
+	var out []*html.Node
+	for s := top.Parent.FirstChild; s != nil; s = s.NextSibling {
+		if s.Type != html.ElementNode {
+			continue
+		}
+		keep := s == top
+		if !keep {
+			bonus := 0.0
+			if cls := attr(top, "class"); cls != "" && attr(s, "class") == cls {
+				bonus = best * 0.2
+			}
+			if score, ok := scores[s]; ok && score+bonus >= threshold {
+				keep = true
+			} else if s.DataAtom == atom.P || isHeading(s) {
+				text := collapse(textContent(s))
+				density := linkDensity(s)
+				keep = (len(text) > 80 && density < 0.25) ||
+					(len(text) > 0 && density == 0 && strings.Contains(text, ". ")) ||
+					(isHeading(s) && len(text) > 0 && density == 0)
+			}
+		}
+		if keep {
+			cleanConditionally(s)
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// scoreable reports whether n holds paragraph text: a p, pre or td, or a
+// div or section with no block children.
+func scoreable(n *html.Node) bool {
+	switch n.DataAtom {
+	case atom.P, atom.Pre, atom.Td:
+		return true
+	case atom.Div, atom.Section:
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			if c.Type == html.ElementNode && blockTags[c.DataAtom] {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+func tagWeight(n *html.Node) float64 {
+	switch n.DataAtom {
+	case atom.Div, atom.Article, atom.Main:
+		return 5
+	case atom.Pre, atom.Td, atom.Blockquote:
+		return 3
+	case atom.Address, atom.Ol, atom.Ul, atom.Dl, atom.Dd, atom.Dt, atom.Li, atom.Form:
+		return -3
+	case atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6, atom.Th:
+		return -5
+	}
+	return 0
+}
+
+func classWeight(n *html.Node) float64 {
+	w := 0.0
+	for _, v := range []string{attr(n, "class"), attr(n, "id")} {
+		if v == "" {
+			continue
+		}
+		if negativeRe.MatchString(v) {
+			w -= 25
+		}
+		if positiveRe.MatchString(v) {
+			w += 25
+		}
+	}
+	return w
+}
+
+// cleanConditionally removes lists, tables and boxes inside n that look
+// like link farms or widgets rather than article content.
+func cleanConditionally(n *html.Node) {
+	for c := n.FirstChild; c != nil; {
+		next := c.NextSibling
+		if c.Type == html.ElementNode {
+			if junk(c) {
+				n.RemoveChild(c)
+			} else {
+				cleanConditionally(c)
+			}
+		}
+		c = next
+	}
+}
+
+func junk(n *html.Node) bool {
+	switch n.DataAtom {
+	case atom.Div, atom.Section, atom.Ul, atom.Ol, atom.Table:
+	default:
+		return false
+	}
+	if hasAncestor(n, atom.Pre, atom.Code) {
+		return false
+	}
+	text := collapse(textContent(n))
+	if strings.Count(text, ",") >= 10 {
+		return false
+	}
+	weight := classWeight(n)
+	if weight < 0 {
+		return true
+	}
+	density := linkDensity(n)
+	imgs, paras, items := count(n, atom.Img), count(n, atom.P), count(n, atom.Li)
+	switch {
+	case n.DataAtom == atom.Ul || n.DataAtom == atom.Ol:
+		return density > 0.5 && weight < 25
+	case n.DataAtom == atom.Table:
+		return density > 0.5
+	case items > paras && paras == 0 && density > 0.2:
+		return true
+	case len(text) < 25 && imgs == 0 && count(n, atom.Pre) == 0 && count(n, atom.Table) == 0:
+		return !hasHeading(n)
+	case weight < 25 && density > 0.2:
+		return true
+	case weight >= 25 && density > 0.5:
+		return true
+	}
+	return false
+}
+
+// dedupeTitle drops a leading heading that repeats the title, or takes the
+// title from it when the page has none.
+func dedupeTitle(title, md string, doc *html.Node) (string, string) {
+	first, rest, _ := strings.Cut(md, "\n\n")
+	heading, isH1 := strings.CutPrefix(first, "# ")
+	if title == "" {
+		if isH1 {
+			return heading, strings.TrimSpace(rest)
+		}
+		if h1 := find(doc, atom.H1); h1 != nil {
+			return collapse(textContent(h1)), md
+		}
+		return "", md
+	}
+	if isH1 && (strings.EqualFold(heading, title) || strings.Contains(strings.ToLower(title), strings.ToLower(heading))) {
+		return heading, strings.TrimSpace(rest)
+	}
+	return title, md
+}
+
+func isHeading(n *html.Node) bool {
+	switch n.DataAtom {
+	case atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6:
+		return true
+	}
+	return false
+}
+
+func hasHeading(n *html.Node) bool {
+	for _, a := range []atom.Atom{atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6} {
+		if find(n, a) != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// linkDensity is the share of n's text that sits inside links.
+func linkDensity(n *html.Node) float64 {
+	total := len(collapse(textContent(n)))
+	if total == 0 {
+		return 0
+	}
+	links := 0
+	forEach(n, func(c *html.Node) bool {
+		if c.DataAtom == atom.A {
+			links += len(collapse(textContent(c)))
+			return false
+		}
+		return true
+	})
+	return float64(links) / float64(total)
+}
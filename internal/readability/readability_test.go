@@ -0,0 +1,145 @@
+package readability
+
+import (
+	"strings"
+	"testing"
+)
+
+const articlePage = `<!doctype html>
+<html><head>
+<title>Why Go slices alias - The Example Blog</title>
+<meta property="og:image" content="/img/slices.png">
+<meta property="og:site_name" content="The Example Blog">
+<meta name="description" content="A short tour of slice aliasing.">
+<script type="application/ld+json">
+{"@context":"https://schema.org","@graph":[
+ {"@type":"WebSite","name":"The Example Blog"},
+ {"@type":"BlogPosting","headline":"Why Go slices alias","datePublished":"2024-05-01T09:00:00Z",
+  "author":[{"@type":"Person","name":"Ada Lovelace"},{"@type":"Person","name":"Alan Turing"}]}
+]}
+</script>
+</head>
+<body>
+<header class="site-header"><a href="/">Home</a> <a href="/about">About</a></header>
+<nav><ul><li><a href="/a">Archive</a></li><li><a href="/b">Tags</a></li></ul></nav>
+<div id="cookie-banner">We use cookies, cookies and more cookies. <button>Accept</button></div>
+<main>
+ <article class="post">
+  <h1>Why Go slices alias</h1>
+  <p>Appending to a slice can write into memory that another slice still sees, which surprises people coming from other languages, and it is worth knowing why.</p>
+  <h2>The backing array</h2>
+  <p>A slice is a view onto an array: a pointer, a length and a capacity. Two slices made from the same array share it, so a write through one is visible through the other.</p>
+  <ul>
+   <li>Length is how many elements the slice shows.</li>
+   <li>Capacity is how far it can grow in place:
+    <ol><li>within capacity, <code>append</code> reuses the array;</li><li>beyond it, a new one is allocated.</li></ol>
+   </li>
+  </ul>
+  <p>See <a href="/posts/append">how append works</a> and the <em>spec</em> for <strong>details</strong>, and note that copying with the full slice expression, which caps capacity, avoids the problem altogether.</p>
+  <pre><code class="language-go">b := a[:2:2]
+b = append(b, 9)</code></pre>
+  <blockquote><p>Slices are not arrays, but they are built on them.</p></blockquote>
+ </article>
+ <div class="share-tools"><a href="https://x.example/share">Share</a> <a href="https://fb.example/share">Post</a></div>
+ <section class="related"><h3>Related</h3><ul><li><a href="/1">One</a></li><li><a href="/2">Two</a></li></ul></section>
+</main>
+<footer><p>Copyright 2024, all rights reserved, no really, all of them.</p></footer>
+</body></html>`
+
+func TestExtract(t *testing.T) {
+	art, err := Extract(articlePage, "https://blog.example/posts/slices")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if art.Title != "Why Go slices alias" {
+		t.Errorf("title = %q", art.Title)
+	}
+	if art.Byline != "Ada Lovelace, Alan Turing" {
+		t.Errorf("byline = %q", art.Byline)
+	}
+	if art.Published != "2024-05-01T09:00:00Z" {
+		t.Errorf("published = %q", art.Published)
+	}
+	if art.LeadImage != "https://blog.example/img/slices.png" {
+		t.Errorf("leadImage = %q", art.LeadImage)
+	}
+	if art.SiteName != "The Example Blog" || art.Excerpt != "A short tour of slice aliasing." {
+		t.Errorf("siteName = %q, excerpt = %q", art.SiteName, art.Excerpt)
+	}
+
+	md := art.Markdown
+	for _, want := range []string{
+		"## The backing array",
+		"- Length is how many elements the slice shows.",
+		"- Capacity is how far it can grow in place:\n  1. within capacity, `append` reuses the array;\n  2. beyond it, a new one is allocated.",
+		"See [how append works](https://blog.example/posts/append) and the _spec_ for **details**,",
+		"```go\nb := a[:2:2]\nb = append(b, 9)\n```",
+		"> Slices are not arrays, but they are built on them.",
+	} {
+		if !strings.Contains(md, want) {
+			t.Errorf("markdown missing %q:\n%s", want, md)
+		}
+	}
+	for _, unwanted := range []string{"# Why Go slices alias", "Archive", "cookies", "About", "Share", "Related", "Copyright"} {
+		if strings.Contains(md, unwanted) {
+			t.Errorf("markdown should not contain %q:\n%s", unwanted, md)
+		}
+	}
+	if !strings.HasPrefix(md, "Appending to a slice") {
+		t.Errorf("markdown should start with the first paragraph:\n%s", md)
+	}
+}
+
+func TestExtractWithoutMetadata(t *testing.T) {
+	page := `<html><head><title>Release notes | Project</title></head><body>
+<div class="sidebar"><a href="/x">x</a><a href="/y">y</a></div>
+<div id="content">
+ <h1>Release notes</h1>
+ <p class="byline">By Grace Hopper</p>
+ <time datetime="2023-11-02">2 November</time>
+ <p>This release, which took longer than planned, brings a faster parser, fewer allocations, and a new configuration format that replaces the old one.</p>
+ <figure><img data-src="shot.png" src="data:image/gif;base64,R0lGOD" alt="Screenshot"><figcaption>The new settings page</figcaption></figure>
+ <table><tr><th>Version</th><th>Date</th></tr><tr><td>1.2</td><td>2023-11-02</td></tr></table>
+</div></body></html>`
+	art, err := Extract(page, "https://example.com/notes/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if art.Title != "Release notes" {
+		t.Errorf("title = %q", art.Title)
+	}
+	if art.Byline != "Grace Hopper" {
+		t.Errorf("byline = %q", art.Byline)
+	}
+	if art.Published != "2023-11-02" {
+		t.Errorf("published = %q", art.Published)
+	}
+	if art.LeadImage != "https://example.com/notes/shot.png" {
+		t.Errorf("leadImage = %q", art.LeadImage)
+	}
+	for _, want := range []string{
+		"![Screenshot](https://example.com/notes/shot.png)",
+		"_The new settings page_",
+		"| Version | Date |\n| --- | --- |\n| 1.2 | 2023-11-02 |",
+	} {
+		if !strings.Contains(art.Markdown, want) {
+			t.Errorf("markdown missing %q:\n%s", want, art.Markdown)
+		}
+	}
+	if strings.Contains(art.Markdown, "Grace Hopper") || strings.Contains(art.Markdown, "](https://example.com/x)") {
+		t.Errorf("byline or sidebar leaked into body:\n%s", art.Markdown)
+	}
+}
+
+func TestCleanTitle(t *testing.T) {
+	for in, want := range map[string]string{
+		"A long headline here | Site": "A long headline here",
+		"Short | Site":                "Short | Site",
+		"Plain title":                 "Plain title",
+		"One two three - Four - Site": "One two three - Four",
+	} {
+		if got := cleanTitle(in); got != want {
+			t.Errorf("cleanTitle(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
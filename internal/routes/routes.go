@@ -52,6 +52,7 @@ var coreEndpoints = []Endpoint{
 
 	// Content extraction
 	{"GET", "/snapshot", "Accessibility snapshot", CapNone, true},
+	{"GET", "/snapshot/stream", "Snapshot delta SSE stream", CapNone, true},
 	{"GET", "/screenshot", "Page screenshot", CapNone, true},
 	{"GET", "/text", "Extract page text", CapNone, true},
 	{"GET", "/pdf", "Export as PDF (GET)", CapNone, true},
@@ -63,6 +64,21 @@ var coreEndpoints = []Endpoint{
 	{"POST", "/dialog", "Handle dialog", CapNone, true},
 	{"POST", "/wait", "Wait for condition", CapNone, true},
 	{"POST", "/find", "Find elements", CapNone, true},
+	{"POST", "/extract", "Extract structured data", CapNone, true},
+	{"GET", "/tables", "Extract tables as JSON or CSV", CapNone, true},
+
+	// Visual regression
+	{"POST", "/visual/baseline", "Capture a named baseline screenshot", CapNone, true},
+	{"POST", "/visual/compare", "Compare the tab against a baseline", CapNone, true},
+	{"GET", "/visual/baselines", "List baseline screenshots", CapNone, false},
+	{"GET", "/visual/baseline", "Get a baseline image", CapNone, false},
+	{"DELETE", "/visual/baseline", "Delete a baseline", CapNone, false},
+
+	// Record and replay
+	{"POST", "/record/start", "Start recording a tab", CapNone, true},
+	{"POST", "/record/stop", "Stop recording and export the script", CapNone, true},
+	{"GET", "/record", "Export the current recording", CapNone, true},
+	{"POST", "/replay", "Replay a recorded script", CapNone, true},
 
 	// Tab management
 	{"POST", "/tab", "Open or switch tab", CapNone, false},
@@ -81,9 +97,25 @@ var coreEndpoints = []Endpoint{
 	{"GET", "/network/stream", "Network SSE stream", CapNone, true},
 	{"GET", "/network/export", "Export HAR", CapNone, true},
 	{"GET", "/network/export/stream", "Export HAR stream", CapNone, true},
+	{"GET", "/network/conditions", "Network emulation status", CapNone, true},
+	{"POST", "/network/conditions", "Emulate network conditions", CapNone, true},
+	{"DELETE", "/network/conditions", "Reset network conditions", CapNone, true},
+	{"GET", "/network/proxy", "Upstream proxy status", CapNone, true},
+	{"POST", "/network/proxy/check", "Upstream proxy health check", CapNone, true},
+	{"GET", "/network/replay", "HAR replay status", CapNone, true},
+	{"POST", "/network/replay", "Replay HAR archive", CapNone, true},
+	{"DELETE", "/network/replay", "Stop HAR replay", CapNone, true},
 	{"GET", "/network/{requestId}", "Single network request", CapNone, true},
 	{"POST", "/network/clear", "Clear network log", CapNone, false},
 
+	// Interception
+	{"GET", "/intercept", "List interception rules", CapNone, true},
+	{"POST", "/intercept", "Add an interception rule", CapNone, true},
+	{"DELETE", "/intercept", "Remove all interception rules", CapNone, true},
+	{"GET", "/intercept/{ruleId}", "Get an interception rule", CapNone, true},
+	{"PUT", "/intercept/{ruleId}", "Replace an interception rule", CapNone, true},
+	{"DELETE", "/intercept/{ruleId}", "Remove an interception rule", CapNone, true},
+
 	// Console & errors
 	{"GET", "/console", "Console logs", CapNone, false},
 	{"POST", "/console/clear", "Clear console logs", CapNone, false},
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"log/slog"
 	"net/http"
+	"slices"
 
 	"github.com/pinchtab/pinchtab/internal/httpx"
 )
@@ -22,20 +23,32 @@ type BatchRequest struct {
 
 // BatchTaskDef defines a single task inside a batch.
 type BatchTaskDef struct {
-	Action   string         `json:"action"`
-	TabID    string         `json:"tabId,omitempty"`
-	Ref      string         `json:"ref,omitempty"`
-	Params   map[string]any `json:"params,omitempty"`
-	Priority int            `json:"priority,omitempty"`
-	Deadline string         `json:"deadline,omitempty"`
+	Action      string         `json:"action"`
+	TabID       string         `json:"tabId,omitempty"`
+	Ref         string         `json:"ref,omitempty"`
+	Params      map[string]any `json:"params,omitempty"`
+	Steps       []TaskStep     `json:"steps,omitempty"`
+	StopOnError bool           `json:"stopOnError,omitempty"`
+	Priority    int            `json:"priority,omitempty"`
+	Deadline    string         `json:"deadline,omitempty"`
+	Retry       *RetryPolicy   `json:"retry,omitempty"`
+	Target      *TabTarget     `json:"target,omitempty"`
+
+	// ID, DependsOn and Inputs turn the batch into a workflow. IDs are
+	// local to the batch; DependsOn and Inputs refer to them.
+	ID        string               `json:"id,omitempty"`
+	DependsOn []string             `json:"dependsOn,omitempty"`
+	Inputs    map[string]TaskInput `json:"inputs,omitempty"`
 }
 
 // BatchResponseItem is the result for each submitted task in the batch.
 type BatchResponseItem struct {
+	ID       string    `json:"id,omitempty"`
 	TaskID   string    `json:"taskId"`
 	State    TaskState `json:"state"`
 	Position int       `json:"position,omitempty"`
 	Error    string    `json:"error,omitempty"`
+	Code     string    `json:"code,omitempty"`
 }
 
 func (s *Scheduler) handleBatch(w http.ResponseWriter, r *http.Request) {
@@ -64,39 +77,102 @@ func (s *Scheduler) handleBatch(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	results := make([]BatchResponseItem, 0, len(req.Tasks))
-	for _, td := range req.Tasks {
+	order := make([]int, len(req.Tasks))
+	for i := range order {
+		order[i] = i
+	}
+	workflowID := ""
+	if isWorkflowBatch(req.Tasks) {
+		planned, err := planWorkflow(req.Tasks)
+		if err != nil {
+			httpx.ErrorCode(w, 400, "invalid_workflow", err.Error(), false, nil)
+			return
+		}
+		order = planned
+		workflowID = generateWorkflowID()
+	}
+
+	// Tasks are submitted in dependency order so upstream task IDs are known
+	// when dependents are created; the response keeps the request order.
+	results := make([]BatchResponseItem, len(req.Tasks))
+	taskIDs := make(map[string]string, len(req.Tasks))
+	for _, i := range order {
+		td := req.Tasks[i]
 		sr := SubmitRequest{
 			AgentID:     req.AgentID,
 			Action:      td.Action,
 			TabID:       td.TabID,
 			Ref:         td.Ref,
 			Params:      td.Params,
+			Steps:       td.Steps,
+			StopOnError: td.StopOnError,
 			Priority:    td.Priority,
 			Deadline:    td.Deadline,
 			CallbackURL: req.CallbackURL,
+			Retry:       td.Retry,
+			Target:      td.Target,
+		}
+
+		var decorate func(*Task)
+		if workflowID != "" {
+			deps := make([]string, 0, len(td.DependsOn))
+			for _, dep := range td.DependsOn {
+				deps = append(deps, taskIDs[dep])
+			}
+			if slices.Contains(deps, "") {
+				results[i] = BatchResponseItem{ID: td.ID, State: StateRejected, Error: "a dependency was not submitted"}
+				continue
+			}
+			var inputs map[string]TaskInput
+			if len(td.Inputs) > 0 {
+				inputs = make(map[string]TaskInput, len(td.Inputs))
+				for name, in := range td.Inputs {
+					inputs[name] = TaskInput{From: taskIDs[in.From], Path: in.Path}
+				}
+			}
+			decorate = func(t *Task) {
+				t.WorkflowID = workflowID
+				t.WorkflowRef = td.ID
+				t.DependsOn = deps
+				t.Inputs = inputs
+			}
 		}
 
-		task, err := s.Submit(sr)
+		task, err := s.submit(sr, decorate)
 		if err != nil {
-			item := BatchResponseItem{State: StateRejected, Error: err.Error()}
+			item := BatchResponseItem{ID: td.ID, State: StateRejected, Error: err.Error()}
+			var ae *admissionError
+			if errors.As(err, &ae) {
+				item.Code = ae.Code
+			}
 			if task != nil {
 				item.TaskID = task.ID
+				if td.ID != "" {
+					taskIDs[td.ID] = task.ID
+				}
 			}
-			results = append(results, item)
+			results[i] = item
 			slog.Warn("batch: task rejected", "agent", req.AgentID, "action", td.Action, "err", err)
 			continue
 		}
+		if td.ID != "" {
+			taskIDs[td.ID] = task.ID
+		}
 
-		results = append(results, BatchResponseItem{
+		results[i] = BatchResponseItem{
+			ID:       td.ID,
 			TaskID:   task.ID,
 			State:    task.GetState(),
 			Position: task.Position,
-		})
+		}
 	}
 
-	httpx.JSON(w, 202, map[string]any{
+	resp := map[string]any{
 		"tasks":     results,
 		"submitted": len(results),
-	})
+	}
+	if workflowID != "" {
+		resp["workflowId"] = workflowID
+	}
+	httpx.JSON(w, 202, resp)
 }
@@ -0,0 +1,65 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseCronNext(t *testing.T) {
+	base := time.Date(2026, 3, 10, 12, 7, 30, 0, time.UTC) // Tuesday
+
+	tests := []struct {
+		expr string
+		want time.Time
+	}{
+		{"* * * * *", time.Date(2026, 3, 10, 12, 8, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2026, 3, 10, 12, 15, 0, 0, time.UTC)},
+		{"0 9-17 * * *", time.Date(2026, 3, 10, 13, 0, 0, 0, time.UTC)},
+		{"30 6 * * 1", time.Date(2026, 3, 16, 6, 30, 0, 0, time.UTC)},
+		{"0 0 1 * *", time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 0 1,15 * *", time.Date(2026, 3, 15, 0, 0, 0, 0, time.UTC)},
+		{"@daily", time.Date(2026, 3, 11, 0, 0, 0, 0, time.UTC)},
+		{"0 0 * * 7", time.Date(2026, 3, 15, 0, 0, 0, 0, time.UTC)},
+		// Restricted day-of-month and day-of-week match when either does.
+		{"0 0 20 * 5", time.Date(2026, 3, 13, 0, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		cs, err := parseCron(tt.expr)
+		if err != nil {
+			t.Errorf("%q: parse failed: %v", tt.expr, err)
+			continue
+		}
+		if got := cs.Next(base); !got.Equal(tt.want) {
+			t.Errorf("%q: expected %s, got %s", tt.expr, tt.want, got)
+		}
+	}
+}
+
+func TestParseCronInvalid(t *testing.T) {
+	for _, expr := range []string{
+		"",
+		"* * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"*/0 * * * *",
+		"5-2 * * * *",
+		"a * * * *",
+	} {
+		if _, err := parseCron(expr); err == nil {
+			t.Errorf("%q: expected parse error", expr)
+		}
+	}
+}
+
+func TestCronNextImpossibleDate(t *testing.T) {
+	cs, err := parseCron("0 0 30 2 *")
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+	if got := cs.Next(time.Now()); !got.IsZero() {
+		t.Errorf("expected zero time for February 30, got %s", got)
+	}
+}
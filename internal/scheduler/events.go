@@ -0,0 +1,197 @@
+package scheduler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/pinchtab/pinchtab/internal/httpx"
+)
+
+// EventExpired is the event name for tasks that failed because their
+// deadline passed while they were queued. Every other event is named after
+// the state the task entered.
+const EventExpired = "expired"
+
+// errDeadlineQueued is the task error set when a queued task expires.
+const errDeadlineQueued = "deadline exceeded while queued"
+
+// TaskEvent is one task state transition, as delivered on GET /tasks/events.
+type TaskEvent struct {
+	Event   string    `json:"event"`
+	TaskID  string    `json:"taskId"`
+	AgentID string    `json:"agentId"`
+	State   TaskState `json:"state"`
+	Time    time.Time `json:"time"`
+	Task    *Task     `json:"task"`
+}
+
+// eventHub fans task events out to subscribers. Slow subscribers miss
+// events rather than blocking the scheduler.
+type eventHub struct {
+	subMu       sync.Mutex
+	subscribers map[int]chan TaskEvent
+	nextSubID   int
+}
+
+func newEventHub() *eventHub {
+	return &eventHub{subscribers: make(map[int]chan TaskEvent)}
+}
+
+func (h *eventHub) subscribe() (int, <-chan TaskEvent) {
+	h.subMu.Lock()
+	defer h.subMu.Unlock()
+	id := h.nextSubID
+	h.nextSubID++
+	ch := make(chan TaskEvent, 64)
+	h.subscribers[id] = ch
+	return id, ch
+}
+
+func (h *eventHub) unsubscribe(id int) {
+	h.subMu.Lock()
+	defer h.subMu.Unlock()
+	if ch, ok := h.subscribers[id]; ok {
+		close(ch)
+		delete(h.subscribers, id)
+	}
+}
+
+func (h *eventHub) publish(ev TaskEvent) {
+	h.subMu.Lock()
+	defer h.subMu.Unlock()
+	for _, ch := range h.subscribers {
+		select {
+		case ch <- ev:
+		default:
+		}
+	}
+}
+
+func (h *eventHub) closeAll() {
+	h.subMu.Lock()
+	defer h.subMu.Unlock()
+	for id, ch := range h.subscribers {
+		close(ch)
+		delete(h.subscribers, id)
+	}
+}
+
+// SubscribeEvents returns a channel receiving every task state transition.
+// Callers must release it with UnsubscribeEvents.
+func (s *Scheduler) SubscribeEvents() (int, <-chan TaskEvent) {
+	return s.events.subscribe()
+}
+
+// UnsubscribeEvents removes a subscriber and closes its channel.
+func (s *Scheduler) UnsubscribeEvents(id int) {
+	s.events.unsubscribe(id)
+}
+
+// emit publishes the task's current state.
+func (s *Scheduler) emit(t *Task) {
+	s.events.publish(taskEvent(t.Snapshot()))
+}
+
+func taskEvent(snap *Task) TaskEvent {
+	name := string(snap.State)
+	if snap.State == StateFailed && snap.Error == errDeadlineQueued {
+		name = EventExpired
+	}
+	return TaskEvent{
+		Event:   name,
+		TaskID:  snap.ID,
+		AgentID: snap.AgentID,
+		State:   snap.State,
+		Time:    timeNow(),
+		Task:    snap,
+	}
+}
+
+// handleEvents streams task state transitions via Server-Sent Events,
+// optionally filtered by agentId and taskId. With a taskId filter the
+// current state is sent first and the stream ends once the task is terminal.
+func (s *Scheduler) handleEvents(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		httpx.Problem(w, http.StatusInternalServerError, "streaming_not_supported", "streaming not supported", false, nil)
+		return
+	}
+
+	// Clear write deadline for long-lived SSE connections; ignore errors
+	// (e.g. httptest.ResponseRecorder doesn't support this).
+	_ = http.NewResponseController(w).SetWriteDeadline(time.Time{})
+
+	agentID := r.URL.Query().Get("agentId")
+	taskID := r.URL.Query().Get("taskId")
+
+	// Subscribe before reading the task so no transition is missed.
+	subID, ch := s.SubscribeEvents()
+	defer s.UnsubscribeEvents(subID)
+
+	var current *Task
+	if taskID != "" {
+		t := s.GetTask(taskID)
+		if t == nil {
+			httpx.ErrorCode(w, 404, "not_found", "task not found", false, nil)
+			return
+		}
+		current = t.Snapshot()
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	flusher.Flush()
+
+	write := func(ev TaskEvent) bool {
+		data, err := json.Marshal(ev)
+		if err != nil {
+			return true
+		}
+		if _, err := fmt.Fprintf(w, "event: task\ndata: %s\n\n", data); err != nil {
+			return false
+		}
+		flusher.Flush()
+		return true
+	}
+
+	if current != nil {
+		if !write(taskEvent(current)) || current.State.IsTerminal() {
+			return
+		}
+	}
+
+	keepalive := time.NewTicker(15 * time.Second)
+	defer keepalive.Stop()
+
+	for {
+		select {
+		case ev, ok := <-ch:
+			if !ok {
+				return
+			}
+			if (agentID != "" && ev.AgentID != agentID) || (taskID != "" && ev.TaskID != taskID) {
+				continue
+			}
+			if !write(ev) {
+				return
+			}
+			if taskID != "" && ev.State.IsTerminal() {
+				return
+			}
+
+		case <-keepalive.C:
+			if _, err := fmt.Fprintf(w, ": keepalive\n\n"); err != nil {
+				return
+			}
+			flusher.Flush()
+
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
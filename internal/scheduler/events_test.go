@@ -0,0 +1,120 @@
+package scheduler
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func readTaskEvents(t *testing.T, resp *http.Response, n int) []TaskEvent {
+	t.Helper()
+	var events []TaskEvent
+	scanner := bufio.NewScanner(resp.Body)
+	for len(events) < n && scanner.Scan() {
+		line := scanner.Text()
+		if !strings.HasPrefix(line, "data: ") {
+			continue
+		}
+		var ev TaskEvent
+		if err := json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &ev); err != nil {
+			t.Fatalf("decode event: %v", err)
+		}
+		events = append(events, ev)
+	}
+	return events
+}
+
+func TestEventsPublishLifecycle(t *testing.T) {
+	s, _, executor := setupHandlerTest(t)
+	defer executor.Close()
+
+	subID, ch := s.SubscribeEvents()
+	defer s.UnsubscribeEvents(subID)
+
+	task, err := s.Submit(SubmitRequest{AgentID: "a1", Action: "click", TabID: "tab-1"})
+	if err != nil {
+		t.Fatalf("submit failed: %v", err)
+	}
+	s.dispatch(s.queue.Dequeue(s.inflightLimits()))
+
+	var got []string
+	for len(got) < 4 {
+		select {
+		case ev := <-ch:
+			if ev.TaskID != task.ID {
+				t.Fatalf("unexpected task %s", ev.TaskID)
+			}
+			got = append(got, ev.Event)
+		case <-time.After(time.Second):
+			t.Fatalf("timed out, got %v", got)
+		}
+	}
+	want := []string{"queued", "assigned", "running", "done"}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("event %d: got %q, want %q", i, got[i], want[i])
+		}
+	}
+}
+
+func TestTaskEventExpired(t *testing.T) {
+	ev := taskEvent(&Task{ID: "tsk_1", State: StateFailed, Error: errDeadlineQueued})
+	if ev.Event != EventExpired || ev.State != StateFailed {
+		t.Errorf("expected expired event in failed state, got %+v", ev)
+	}
+}
+
+func TestHandleEventsStreamsFilteredTask(t *testing.T) {
+	s, mux, executor := setupHandlerTest(t)
+	defer executor.Close()
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	other, _ := s.Submit(SubmitRequest{AgentID: "a2", Action: "click", TabID: "tab-2"})
+	task, _ := s.Submit(SubmitRequest{AgentID: "a1", Action: "click", TabID: "tab-1"})
+
+	resp, err := http.Get(srv.URL + "/tasks/events?agentId=a1&taskId=" + task.ID)
+	if err != nil {
+		t.Fatalf("get failed: %v", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("expected text/event-stream, got %q", ct)
+	}
+
+	if err := s.Cancel(other.ID); err != nil {
+		t.Fatalf("cancel other failed: %v", err)
+	}
+	if err := s.Cancel(task.ID); err != nil {
+		t.Fatalf("cancel failed: %v", err)
+	}
+
+	events := readTaskEvents(t, resp, 3)
+	if len(events) != 2 {
+		t.Fatalf("expected current state and cancellation before the stream ends, got %+v", events)
+	}
+	if events[0].Event != "queued" || events[1].Event != "cancelled" {
+		t.Errorf("unexpected events: %s, %s", events[0].Event, events[1].Event)
+	}
+	for _, ev := range events {
+		if ev.TaskID != task.ID || ev.Task == nil {
+			t.Errorf("unexpected event payload: %+v", ev)
+		}
+	}
+}
+
+func TestHandleEventsUnknownTask(t *testing.T) {
+	_, mux, executor := setupHandlerTest(t)
+	defer executor.Close()
+
+	req := httptest.NewRequest("GET", "/tasks/events?taskId=tsk_missing", nil)
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, req)
+	if w.Code != 404 {
+		t.Errorf("expected 404, got %d", w.Code)
+	}
+}
@@ -1,7 +1,10 @@
 package scheduler
 
 import (
+	"errors"
+	"math"
 	"net/http"
+	"strconv"
 	"strings"
 
 	"github.com/pinchtab/pinchtab/internal/httpx"
@@ -11,10 +14,22 @@ import (
 func (s *Scheduler) RegisterHandlers(mux *http.ServeMux) {
 	mux.HandleFunc("POST /tasks", s.handleSubmit)
 	mux.HandleFunc("GET /tasks", s.handleList)
+	mux.HandleFunc("GET /tasks/events", s.handleEvents)
 	mux.HandleFunc("GET /tasks/{id}", s.handleGet)
 	mux.HandleFunc("POST /tasks/{id}/cancel", s.handleCancel)
 	mux.HandleFunc("GET /scheduler/stats", s.handleStats)
+	mux.HandleFunc("GET /scheduler/webhooks/dead-letters", s.handleDeadLetterList)
+	mux.HandleFunc("GET /scheduler/webhooks/dead-letters/{id}", s.handleDeadLetterGet)
+	mux.HandleFunc("POST /scheduler/webhooks/dead-letters/{id}/replay", s.handleDeadLetterReplay)
+	mux.HandleFunc("DELETE /scheduler/webhooks/dead-letters/{id}", s.handleDeadLetterDelete)
 	mux.HandleFunc("POST /tasks/batch", s.handleBatch)
+	mux.HandleFunc("GET /tasks/workflows/{id}", s.handleWorkflowGet)
+	mux.HandleFunc("POST /tasks/recurring", s.handleRecurringCreate)
+	mux.HandleFunc("GET /tasks/recurring", s.handleRecurringList)
+	mux.HandleFunc("GET /tasks/recurring/{id}", s.handleRecurringGet)
+	mux.HandleFunc("POST /tasks/recurring/{id}/pause", s.handleRecurringPause)
+	mux.HandleFunc("POST /tasks/recurring/{id}/resume", s.handleRecurringResume)
+	mux.HandleFunc("DELETE /tasks/recurring/{id}", s.handleRecurringDelete)
 }
 
 func (s *Scheduler) handleSubmit(w http.ResponseWriter, r *http.Request) {
@@ -26,6 +41,11 @@ func (s *Scheduler) handleSubmit(w http.ResponseWriter, r *http.Request) {
 
 	task, err := s.Submit(req)
 	if err != nil {
+		var ae *admissionError
+		if errors.As(err, &ae) {
+			writeAdmissionError(w, req.AgentID, ae)
+			return
+		}
 		if task != nil && task.State == StateRejected {
 			stats := s.QueueStats()
 			httpx.ErrorCode(w, 429, "queue_full", err.Error(), true, map[string]any{
@@ -49,6 +69,18 @@ func (s *Scheduler) handleSubmit(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// writeAdmissionError answers a submission refused by an agent policy with
+// 429 and a Retry-After header.
+func writeAdmissionError(w http.ResponseWriter, agentID string, ae *admissionError) {
+	retryAfter := int(math.Ceil(ae.RetryAfter.Seconds()))
+	w.Header().Set("Retry-After", strconv.Itoa(max(1, retryAfter)))
+	httpx.ErrorCode(w, 429, ae.Code, ae.Error(), true, map[string]any{
+		"agentId":       agentID,
+		"group":         ae.Group,
+		"retryAfterSec": max(1, retryAfter),
+	})
+}
+
 func (s *Scheduler) handleGet(w http.ResponseWriter, r *http.Request) {
 	taskID := r.PathValue("id")
 	if taskID == "" {
@@ -113,16 +145,24 @@ func (s *Scheduler) handleStats(w http.ResponseWriter, _ *http.Request) {
 	queue := s.QueueStats()
 	metrics := s.GetMetrics()
 	httpx.JSON(w, 200, map[string]any{
-		"queue":   queue,
-		"metrics": metrics,
+		"queue":       queue,
+		"metrics":     metrics,
+		"agentGroups": s.AgentPolicies(),
 		"config": map[string]any{
-			"strategy":          s.cfg.Strategy,
-			"maxQueueSize":      s.cfg.MaxQueueSize,
-			"maxPerAgent":       s.cfg.MaxPerAgent,
-			"maxInflight":       s.cfg.MaxInflight,
-			"maxPerAgentFlight": s.cfg.MaxPerAgentFlight,
-			"workerCount":       s.cfg.WorkerCount,
-			"resultTTL":         s.cfg.ResultTTL.String(),
+			"strategy":           s.cfg.Strategy,
+			"maxQueueSize":       s.cfg.MaxQueueSize,
+			"maxPerAgent":        s.cfg.MaxPerAgent,
+			"maxInflight":        s.cfg.MaxInflight,
+			"maxPerAgentFlight":  s.cfg.MaxPerAgentFlight,
+			"workerCount":        s.cfg.WorkerCount,
+			"resultTTL":          s.cfg.ResultTTL.String(),
+			"persist":            s.cfg.JournalPath != "",
+			"recoverRunning":     s.cfg.RecoverRunning,
+			"maxTabs":            s.cfg.MaxTabs,
+			"retry":              s.cfg.Retry,
+			"webhookSigned":      s.cfg.WebhookSecret != "",
+			"webhookMaxAttempts": s.cfg.WebhookMaxAttempts,
+			"webhookBackoff":     s.cfg.WebhookBackoff.String(),
 		},
 	})
 }
@@ -0,0 +1,203 @@
+package scheduler
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+)
+
+// Recovery policies for tasks that were assigned or running when the
+// scheduler went down.
+const (
+	RecoverFail    = "fail"
+	RecoverRequeue = "requeue"
+)
+
+// journalCompactThreshold is the number of appended records after which the
+// result reaper rewrites the journal from the current task set.
+const journalCompactThreshold = 1000
+
+// Journal is an append-only JSONL log of task snapshots. On replay the last
+// record for each task ID wins, and a record with Deleted set drops the task.
+type Journal struct {
+	mu      sync.Mutex
+	path    string
+	f       *os.File
+	appends int
+}
+
+type journalRecord struct {
+	Task    *Task  `json:"task,omitempty"`
+	Deleted string `json:"deleted,omitempty"`
+}
+
+// OpenJournal replays the journal at path and opens it for appending.
+// Unreadable lines, such as a record truncated by a crash mid-write, are
+// skipped. The returned tasks are ordered by creation time.
+func OpenJournal(path string) (*Journal, []*Task, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+		return nil, nil, fmt.Errorf("create journal dir: %w", err)
+	}
+
+	tasks, err := replayJournal(path)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return nil, nil, fmt.Errorf("open journal: %w", err)
+	}
+	return &Journal{path: path, f: f}, tasks, nil
+}
+
+func replayJournal(path string) ([]*Task, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("open journal: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	latest := make(map[string]*Task)
+	skipped := 0
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		var rec journalRecord
+		if err := json.Unmarshal(scanner.Bytes(), &rec); err != nil {
+			skipped++
+			continue
+		}
+		switch {
+		case rec.Deleted != "":
+			delete(latest, rec.Deleted)
+		case rec.Task != nil && rec.Task.ID != "":
+			latest[rec.Task.ID] = rec.Task
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("scan journal: %w", err)
+	}
+	if skipped > 0 {
+		slog.Warn("scheduler journal: skipped unreadable records", "path", path, "count", skipped)
+	}
+
+	tasks := make([]*Task, 0, len(latest))
+	for _, t := range latest {
+		tasks = append(tasks, t)
+	}
+	sort.Slice(tasks, func(i, j int) bool {
+		return tasks[i].CreatedAt.Before(tasks[j].CreatedAt)
+	})
+	return tasks, nil
+}
+
+// Append records the latest snapshot of a task and syncs it to disk.
+func (j *Journal) Append(t *Task) error {
+	return j.write(journalRecord{Task: t})
+}
+
+// Remove records that a task was dropped from the result store.
+func (j *Journal) Remove(taskID string) error {
+	return j.write(journalRecord{Deleted: taskID})
+}
+
+func (j *Journal) write(rec journalRecord) error {
+	line, err := json.Marshal(rec)
+	if err != nil {
+		return fmt.Errorf("marshal journal record: %w", err)
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.f == nil {
+		return fmt.Errorf("journal closed")
+	}
+	if _, err := j.f.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("write journal record: %w", err)
+	}
+	if err := j.f.Sync(); err != nil {
+		return fmt.Errorf("sync journal: %w", err)
+	}
+	j.appends++
+	return nil
+}
+
+// Compact atomically rewrites the journal so it holds exactly one record per
+// given task.
+func (j *Journal) Compact(tasks []*Task) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.f == nil {
+		return fmt.Errorf("journal closed")
+	}
+
+	tmpPath := j.path + ".tmp"
+	tmp, err := os.OpenFile(tmpPath, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("create compacted journal: %w", err)
+	}
+	w := bufio.NewWriter(tmp)
+	for _, t := range tasks {
+		line, err := json.Marshal(journalRecord{Task: t})
+		if err != nil {
+			_ = tmp.Close()
+			_ = os.Remove(tmpPath)
+			return fmt.Errorf("marshal journal record: %w", err)
+		}
+		_, _ = w.Write(append(line, '\n'))
+	}
+	if err := w.Flush(); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmpPath)
+		return fmt.Errorf("write compacted journal: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmpPath)
+		return fmt.Errorf("sync compacted journal: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		_ = os.Remove(tmpPath)
+		return fmt.Errorf("close compacted journal: %w", err)
+	}
+	if err := os.Rename(tmpPath, j.path); err != nil {
+		_ = os.Remove(tmpPath)
+		return fmt.Errorf("replace journal: %w", err)
+	}
+
+	f, err := os.OpenFile(j.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("reopen journal: %w", err)
+	}
+	_ = j.f.Close()
+	j.f = f
+	j.appends = 0
+	return nil
+}
+
+// Close flushes and closes the journal file.
+func (j *Journal) Close() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.f == nil {
+		return nil
+	}
+	err := j.f.Close()
+	j.f = nil
+	return err
+}
+
+func (j *Journal) needsCompaction() bool {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.appends >= journalCompactThreshold
+}
@@ -0,0 +1,235 @@
+package scheduler
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestJournalReplayLatestWins(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tasks.jsonl")
+
+	j, tasks, err := OpenJournal(path)
+	if err != nil {
+		t.Fatalf("open failed: %v", err)
+	}
+	if len(tasks) != 0 {
+		t.Fatalf("expected empty journal, got %d tasks", len(tasks))
+	}
+
+	now := time.Now()
+	_ = j.Append(&Task{ID: "t1", AgentID: "a1", State: StateQueued, CreatedAt: now})
+	_ = j.Append(&Task{ID: "t2", AgentID: "a1", State: StateQueued, CreatedAt: now.Add(time.Second)})
+	_ = j.Append(&Task{ID: "t1", AgentID: "a1", State: StateDone, CreatedAt: now})
+	_ = j.Append(&Task{ID: "t3", AgentID: "a2", State: StateQueued, CreatedAt: now.Add(2 * time.Second)})
+	_ = j.Remove("t3")
+	if err := j.Close(); err != nil {
+		t.Fatalf("close failed: %v", err)
+	}
+
+	// Simulate a crash in the middle of a write.
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		t.Fatalf("reopen failed: %v", err)
+	}
+	_, _ = f.WriteString(`{"task":{"taskId":"t4","sta`)
+	_ = f.Close()
+
+	j, tasks, err = OpenJournal(path)
+	if err != nil {
+		t.Fatalf("replay failed: %v", err)
+	}
+	defer func() { _ = j.Close() }()
+
+	if len(tasks) != 2 {
+		t.Fatalf("expected 2 tasks, got %d", len(tasks))
+	}
+	if tasks[0].ID != "t1" || tasks[0].State != StateDone {
+		t.Errorf("expected t1 done first, got %s %s", tasks[0].ID, tasks[0].State)
+	}
+	if tasks[1].ID != "t2" || tasks[1].State != StateQueued {
+		t.Errorf("expected t2 queued second, got %s %s", tasks[1].ID, tasks[1].State)
+	}
+}
+
+func TestJournalCompact(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tasks.jsonl")
+	j, _, err := OpenJournal(path)
+	if err != nil {
+		t.Fatalf("open failed: %v", err)
+	}
+
+	for range 5 {
+		_ = j.Append(&Task{ID: "t1", AgentID: "a1", State: StateQueued})
+	}
+	if err := j.Compact([]*Task{{ID: "t1", AgentID: "a1", State: StateDone}}); err != nil {
+		t.Fatalf("compact failed: %v", err)
+	}
+	_ = j.Append(&Task{ID: "t2", AgentID: "a1", State: StateQueued})
+	_ = j.Close()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read failed: %v", err)
+	}
+	lines := 0
+	for _, b := range data {
+		if b == '\n' {
+			lines++
+		}
+	}
+	if lines != 2 {
+		t.Errorf("expected 2 records after compaction, got %d", lines)
+	}
+}
+
+func newJournalScheduler(t *testing.T, path, policy string) *Scheduler {
+	t.Helper()
+	cfg := DefaultConfig()
+	cfg.WorkerCount = 1
+	cfg.JournalPath = path
+	cfg.RecoverRunning = policy
+	return New(cfg, &mockResolver{})
+}
+
+func TestSchedulerJournalRequeuesQueuedTasks(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tasks.jsonl")
+
+	s := newJournalScheduler(t, path, RecoverFail)
+	task, err := s.Submit(SubmitRequest{AgentID: "a1", Action: "click", TabID: "tab-1"})
+	if err != nil {
+		t.Fatalf("submit failed: %v", err)
+	}
+	// Never started, so the task is still queued when the scheduler stops.
+	s.Stop()
+
+	restarted := newJournalScheduler(t, path, RecoverFail)
+	restarted.recover()
+	defer restarted.Stop()
+
+	got := restarted.GetTask(task.ID)
+	if got == nil {
+		t.Fatal("expected queued task to survive restart")
+	}
+	if got.GetState() != StateQueued {
+		t.Errorf("expected queued, got %s", got.GetState())
+	}
+	if stats := restarted.QueueStats(); stats.TotalQueued != 1 {
+		t.Errorf("expected 1 queued task, got %d", stats.TotalQueued)
+	}
+}
+
+func TestSchedulerJournalRecoverRunning(t *testing.T) {
+	tests := []struct {
+		policy string
+		want   TaskState
+	}{
+		{RecoverFail, StateFailed},
+		{RecoverRequeue, StateQueued},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.policy, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "tasks.jsonl")
+			j, _, err := OpenJournal(path)
+			if err != nil {
+				t.Fatalf("open failed: %v", err)
+			}
+			now := time.Now()
+			_ = j.Append(&Task{
+				ID:        "tsk_running",
+				AgentID:   "a1",
+				Action:    "click",
+				TabID:     "tab-1",
+				State:     StateRunning,
+				CreatedAt: now,
+				StartedAt: now,
+				Deadline:  now.Add(time.Minute),
+			})
+			_ = j.Close()
+
+			s := newJournalScheduler(t, path, tt.policy)
+			s.recover()
+			defer s.Stop()
+
+			got := s.GetTask("tsk_running")
+			if got == nil {
+				t.Fatal("expected task to be recovered")
+			}
+			if got.GetState() != tt.want {
+				t.Errorf("expected %s, got %s", tt.want, got.GetState())
+			}
+		})
+	}
+}
+
+func TestSchedulerJournalDropsExpiredResults(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tasks.jsonl")
+	j, _, err := OpenJournal(path)
+	if err != nil {
+		t.Fatalf("open failed: %v", err)
+	}
+	old := time.Now().Add(-time.Hour)
+	_ = j.Append(&Task{ID: "tsk_old", AgentID: "a1", State: StateDone, CreatedAt: old, CompletedAt: old})
+	_ = j.Append(&Task{ID: "tsk_new", AgentID: "a1", State: StateDone, CreatedAt: time.Now(), CompletedAt: time.Now()})
+	_ = j.Close()
+
+	s := newJournalScheduler(t, path, RecoverFail)
+	s.recover()
+	defer s.Stop()
+
+	if s.GetTask("tsk_old") != nil {
+		t.Error("expected result past TTL to be dropped")
+	}
+	if s.GetTask("tsk_new") == nil {
+		t.Error("expected recent result to be kept")
+	}
+}
+
+func TestResultStoreReadersSkipJournalWrites(t *testing.T) {
+	j, _, err := OpenJournal(filepath.Join(t.TempDir(), "tasks.jsonl"))
+	if err != nil {
+		t.Fatalf("open failed: %v", err)
+	}
+	defer func() { _ = j.Close() }()
+	rs := NewResultStore(5 * time.Minute)
+	rs.SetJournal(j)
+
+	// Hold the journal as a slow disk would; the map must still be readable.
+	j.mu.Lock()
+	stored := make(chan struct{})
+	go func() {
+		rs.Store(&Task{ID: "t1", AgentID: "a1", State: StateRunning})
+		close(stored)
+	}()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for rs.Get("t1") == nil {
+		if time.Now().After(deadline) {
+			j.mu.Unlock()
+			t.Fatal("reader waited on the journal write")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if got := rs.List("a1", nil); len(got) != 1 {
+		t.Errorf("List = %d tasks, want 1", len(got))
+	}
+	select {
+	case <-stored:
+		t.Error("Store returned before its record was written")
+	default:
+	}
+	j.mu.Unlock()
+	<-stored
+
+	_ = j.Close()
+	reopened, tasks, err := OpenJournal(j.path)
+	if err != nil {
+		t.Fatalf("reopen failed: %v", err)
+	}
+	_ = reopened.Close()
+	if len(tasks) != 1 || tasks[0].State != StateRunning {
+		t.Fatalf("replayed %v", tasks)
+	}
+}
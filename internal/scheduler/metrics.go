@@ -8,14 +8,18 @@ import (
 
 // Metrics tracks scheduler-level counters and latency for observability.
 type Metrics struct {
-	TasksSubmitted  atomic.Uint64
-	TasksCompleted  atomic.Uint64
-	TasksFailed     atomic.Uint64
-	TasksCancelled  atomic.Uint64
-	TasksRejected   atomic.Uint64
-	TasksExpired    atomic.Uint64
-	DispatchTotal   atomic.Uint64
-	DispatchLatency atomic.Uint64 // cumulative milliseconds
+	TasksSubmitted atomic.Uint64
+	TasksCompleted atomic.Uint64
+	TasksFailed    atomic.Uint64
+	TasksCancelled atomic.Uint64
+	TasksRejected  atomic.Uint64
+	TasksExpired   atomic.Uint64
+	TasksRetried   atomic.Uint64 // failed attempts that were requeued
+	RetryExhausted atomic.Uint64 // retryable failures out of attempts or time
+
+	WebhooksDeadLettered atomic.Uint64
+	DispatchTotal        atomic.Uint64
+	DispatchLatency      atomic.Uint64 // cumulative milliseconds
 
 	// Per-agent counters protected by mutex.
 	mu         sync.RWMutex
@@ -29,6 +33,7 @@ type AgentMetrics struct {
 	Failed    uint64 `json:"failed"`
 	Cancelled uint64 `json:"cancelled"`
 	Rejected  uint64 `json:"rejected"`
+	Retried   uint64 `json:"retried"`
 }
 
 func newMetrics() *Metrics {
@@ -62,6 +67,19 @@ func (m *Metrics) recordCancel(agentID string) {
 	m.agentMetric(agentID).add(func(a *AgentMetrics) { a.Cancelled++ })
 }
 
+func (m *Metrics) recordRetry(agentID string) {
+	m.TasksRetried.Add(1)
+	m.agentMetric(agentID).add(func(a *AgentMetrics) { a.Retried++ })
+}
+
+func (m *Metrics) recordRetryExhausted() {
+	m.RetryExhausted.Add(1)
+}
+
+func (m *Metrics) recordWebhookDeadLetter() {
+	m.WebhooksDeadLettered.Add(1)
+}
+
 func (m *Metrics) recordExpire() {
 	m.TasksExpired.Add(1)
 }
@@ -93,35 +111,42 @@ func (m *Metrics) Snapshot() MetricsSnapshot {
 			Failed:    a.Failed,
 			Cancelled: a.Cancelled,
 			Rejected:  a.Rejected,
+			Retried:   a.Retried,
 		}
 		a.mu.RUnlock()
 	}
 	m.mu.RUnlock()
 
 	return MetricsSnapshot{
-		TasksSubmitted:     m.TasksSubmitted.Load(),
-		TasksCompleted:     m.TasksCompleted.Load(),
-		TasksFailed:        m.TasksFailed.Load(),
-		TasksCancelled:     m.TasksCancelled.Load(),
-		TasksRejected:      m.TasksRejected.Load(),
-		TasksExpired:       m.TasksExpired.Load(),
-		DispatchCount:      dispatched,
-		AvgDispatchLatency: avgMs,
-		Agents:             agents,
+		TasksSubmitted:       m.TasksSubmitted.Load(),
+		TasksCompleted:       m.TasksCompleted.Load(),
+		TasksFailed:          m.TasksFailed.Load(),
+		TasksCancelled:       m.TasksCancelled.Load(),
+		TasksRejected:        m.TasksRejected.Load(),
+		TasksExpired:         m.TasksExpired.Load(),
+		TasksRetried:         m.TasksRetried.Load(),
+		RetryExhausted:       m.RetryExhausted.Load(),
+		WebhooksDeadLettered: m.WebhooksDeadLettered.Load(),
+		DispatchCount:        dispatched,
+		AvgDispatchLatency:   avgMs,
+		Agents:               agents,
 	}
 }
 
 // MetricsSnapshot is a serializable point-in-time view of scheduler metrics.
 type MetricsSnapshot struct {
-	TasksSubmitted     uint64                  `json:"tasksSubmitted"`
-	TasksCompleted     uint64                  `json:"tasksCompleted"`
-	TasksFailed        uint64                  `json:"tasksFailed"`
-	TasksCancelled     uint64                  `json:"tasksCancelled"`
-	TasksRejected      uint64                  `json:"tasksRejected"`
-	TasksExpired       uint64                  `json:"tasksExpired"`
-	DispatchCount      uint64                  `json:"dispatchCount"`
-	AvgDispatchLatency float64                 `json:"avgDispatchLatencyMs"`
-	Agents             map[string]AgentMetrics `json:"agents"`
+	TasksSubmitted       uint64                  `json:"tasksSubmitted"`
+	TasksCompleted       uint64                  `json:"tasksCompleted"`
+	TasksFailed          uint64                  `json:"tasksFailed"`
+	TasksCancelled       uint64                  `json:"tasksCancelled"`
+	TasksRejected        uint64                  `json:"tasksRejected"`
+	TasksExpired         uint64                  `json:"tasksExpired"`
+	TasksRetried         uint64                  `json:"tasksRetried"`
+	RetryExhausted       uint64                  `json:"retryExhausted"`
+	WebhooksDeadLettered uint64                  `json:"webhooksDeadLettered"`
+	DispatchCount        uint64                  `json:"dispatchCount"`
+	AvgDispatchLatency   float64                 `json:"avgDispatchLatencyMs"`
+	Agents               map[string]AgentMetrics `json:"agents"`
 }
 
 // agentMetricEntry wraps AgentMetrics with its own lock for concurrent updates.
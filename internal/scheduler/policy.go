@@ -0,0 +1,336 @@
+package scheduler
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Admission error codes returned when an agent policy refuses a task.
+const (
+	CodeRateLimited   = "rate_limited"
+	CodeQuotaExceeded = "quota_exceeded"
+)
+
+// maxAgentWeight bounds the fair-share weight of a group.
+const maxAgentWeight = 100
+
+// AgentPolicy is the fair-share weight and submission limits for a group of
+// agents. Agents lists agent IDs; an entry ending in "*" matches every ID
+// with that prefix. The weight applies to each member agent, while the rate
+// limit and daily quota are shared by the whole group. Zero limits mean
+// unlimited.
+type AgentPolicy struct {
+	Agents     []string `json:"agents"`
+	Weight     int      `json:"weight,omitempty"`
+	RatePerSec float64  `json:"ratePerSec,omitempty"`
+	Burst      int      `json:"burst,omitempty"`
+	DailyQuota int      `json:"dailyQuota,omitempty"`
+}
+
+// Validate checks the policy bounds.
+func (p *AgentPolicy) Validate() error {
+	if len(p.Agents) == 0 {
+		return fmt.Errorf("agents is required")
+	}
+	for _, a := range p.Agents {
+		if a == "" || strings.Contains(strings.TrimSuffix(a, "*"), "*") {
+			return fmt.Errorf("invalid agent pattern %q", a)
+		}
+	}
+	if p.Weight < 0 || p.Weight > maxAgentWeight {
+		return fmt.Errorf("weight must be between 0 (default 1) and %d", maxAgentWeight)
+	}
+	if p.RatePerSec < 0 || p.Burst < 0 || p.DailyQuota < 0 {
+		return fmt.Errorf("limits must not be negative")
+	}
+	return nil
+}
+
+func (p AgentPolicy) weight() int {
+	if p.Weight <= 0 {
+		return 1
+	}
+	return p.Weight
+}
+
+// burst is the bucket size: Burst when set, else one second of rate.
+func (p AgentPolicy) burst() float64 {
+	if p.Burst > 0 {
+		return float64(p.Burst)
+	}
+	return math.Max(1, math.Ceil(p.RatePerSec))
+}
+
+// admissionError is a submission refused by an agent policy.
+type admissionError struct {
+	Code       string
+	Group      string
+	RetryAfter time.Duration
+}
+
+func (e *admissionError) Error() string {
+	if e.Code == CodeQuotaExceeded {
+		return fmt.Sprintf("daily task quota exhausted for group %q", e.Group)
+	}
+	return fmt.Sprintf("submission rate limit exceeded for group %q", e.Group)
+}
+
+// groupUsage is the live rate and quota state of one group.
+type groupUsage struct {
+	tokens        float64
+	refilled      time.Time
+	day           string
+	used          int
+	rateLimited   uint64
+	quotaRejected uint64
+}
+
+// PolicyUsage reports a group's policy and its current usage.
+type PolicyUsage struct {
+	AgentPolicy
+	TokensAvailable float64   `json:"tokensAvailable,omitempty"`
+	UsedToday       int       `json:"usedToday"`
+	RemainingToday  *int      `json:"remainingToday,omitempty"`
+	ResetAt         time.Time `json:"resetAt"`
+	RateLimited     uint64    `json:"rateLimited"`
+	QuotaRejected   uint64    `json:"quotaRejected"`
+}
+
+// policySet resolves agents to groups and tracks group usage. When path is
+// set, daily quota usage is saved there on every change, so a restart does
+// not hand every group a fresh quota.
+type policySet struct {
+	mu     sync.Mutex
+	groups map[string]AgentPolicy
+	usage  map[string]*groupUsage
+	path   string
+}
+
+// savedUsage is the persisted daily quota usage of one group.
+type savedUsage struct {
+	Day  string `json:"day"`
+	Used int    `json:"used"`
+}
+
+func newPolicySet(groups map[string]AgentPolicy, path string) *policySet {
+	ps := &policySet{usage: make(map[string]*groupUsage), path: path}
+	if path != "" {
+		ps.load()
+	}
+	ps.set(groups)
+	return ps
+}
+
+func (ps *policySet) load() {
+	data, err := os.ReadFile(ps.path)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			slog.Warn("agent quotas: failed to read", "path", ps.path, "err", err)
+		}
+		return
+	}
+	var saved map[string]savedUsage
+	if err := json.Unmarshal(data, &saved); err != nil {
+		slog.Warn("agent quotas: failed to parse", "path", ps.path, "err", err)
+		return
+	}
+	// Tokens start full; usageLocked caps them at the group's burst and
+	// drops usage from an earlier day.
+	now := timeNow()
+	for name, su := range saved {
+		ps.usage[name] = &groupUsage{tokens: math.MaxFloat64, refilled: now, day: su.Day, used: su.Used}
+	}
+}
+
+// saveLocked writes the daily usage of every group with a quota. Caller
+// holds ps.mu.
+func (ps *policySet) saveLocked() {
+	if ps.path == "" {
+		return
+	}
+	saved := make(map[string]savedUsage, len(ps.usage))
+	for name, u := range ps.usage {
+		if ps.groups[name].DailyQuota > 0 && u.used > 0 {
+			saved[name] = savedUsage{Day: u.day, Used: u.used}
+		}
+	}
+	data, err := json.MarshalIndent(saved, "", "  ")
+	if err != nil {
+		slog.Warn("agent quotas: failed to encode", "err", err)
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(ps.path), 0750); err != nil {
+		slog.Warn("agent quotas: failed to create dir", "err", err)
+		return
+	}
+	tmpPath := ps.path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0600); err != nil {
+		slog.Warn("agent quotas: failed to write", "err", err)
+		return
+	}
+	if err := os.Rename(tmpPath, ps.path); err != nil {
+		slog.Warn("agent quotas: failed to replace", "err", err)
+	}
+}
+
+// set replaces the group policies. Usage is kept for groups that remain.
+func (ps *policySet) set(groups map[string]AgentPolicy) {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	ps.groups = groups
+	for name, u := range ps.usage {
+		p, ok := groups[name]
+		if !ok {
+			delete(ps.usage, name)
+			continue
+		}
+		u.tokens = math.Min(u.tokens, p.burst())
+	}
+}
+
+// groupOf returns the group an agent belongs to, or "" for ungrouped agents.
+// An exact ID match wins over prefix patterns; the longest prefix wins among
+// patterns.
+func (ps *policySet) groupOf(agentID string) (string, AgentPolicy, bool) {
+	bestName, bestLen := "", -1
+	for name, p := range ps.groups {
+		for _, pattern := range p.Agents {
+			n := -1
+			if pattern == agentID {
+				n = math.MaxInt
+			} else if prefix, ok := strings.CutSuffix(pattern, "*"); ok && strings.HasPrefix(agentID, prefix) {
+				n = len(prefix)
+			}
+			if n > bestLen || (n == bestLen && name < bestName) {
+				bestName, bestLen = name, n
+			}
+		}
+	}
+	if bestLen < 0 {
+		return "", AgentPolicy{}, false
+	}
+	return bestName, ps.groups[bestName], true
+}
+
+// weight returns the fair-share weight of an agent.
+func (ps *policySet) weight(agentID string) int {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	_, p, _ := ps.groupOf(agentID)
+	return p.weight()
+}
+
+// usageLocked returns the group's usage, refilled and rolled to now.
+func (ps *policySet) usageLocked(name string, p AgentPolicy, now time.Time) *groupUsage {
+	u, ok := ps.usage[name]
+	if !ok {
+		u = &groupUsage{tokens: p.burst(), refilled: now}
+		ps.usage[name] = u
+	}
+	if day := now.UTC().Format(time.DateOnly); u.day != day {
+		u.day, u.used = day, 0
+	}
+	if p.RatePerSec > 0 {
+		elapsed := now.Sub(u.refilled).Seconds()
+		u.tokens = math.Min(p.burst(), u.tokens+elapsed*p.RatePerSec)
+	}
+	u.refilled = now
+	return u
+}
+
+// admit charges one submission to the agent's group, or returns an
+// *admissionError when the daily quota or the rate limit refuses it.
+func (ps *policySet) admit(agentID string) error {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	name, p, ok := ps.groupOf(agentID)
+	if !ok {
+		return nil
+	}
+	now := timeNow()
+	u := ps.usageLocked(name, p, now)
+
+	if p.DailyQuota > 0 && u.used >= p.DailyQuota {
+		u.quotaRejected++
+		return &admissionError{Code: CodeQuotaExceeded, Group: name, RetryAfter: nextUTCDay(now).Sub(now)}
+	}
+	if p.RatePerSec > 0 {
+		if u.tokens < 1 {
+			u.rateLimited++
+			wait := time.Duration((1 - u.tokens) / p.RatePerSec * float64(time.Second))
+			return &admissionError{Code: CodeRateLimited, Group: name, RetryAfter: wait}
+		}
+		u.tokens--
+	}
+	u.used++
+	if p.DailyQuota > 0 {
+		ps.saveLocked()
+	}
+	return nil
+}
+
+// refund returns a charge taken by admit for a task that was not queued.
+func (ps *policySet) refund(agentID string) {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	name, p, ok := ps.groupOf(agentID)
+	if !ok {
+		return
+	}
+	u := ps.usageLocked(name, p, timeNow())
+	if p.RatePerSec > 0 {
+		u.tokens = math.Min(p.burst(), u.tokens+1)
+	}
+	if u.used > 0 {
+		u.used--
+		if p.DailyQuota > 0 {
+			ps.saveLocked()
+		}
+	}
+}
+
+// snapshot reports every group's policy and usage.
+func (ps *policySet) snapshot() map[string]PolicyUsage {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	now := timeNow()
+	out := make(map[string]PolicyUsage, len(ps.groups))
+	for name, p := range ps.groups {
+		u := ps.usageLocked(name, p, now)
+		pu := PolicyUsage{
+			AgentPolicy:   p,
+			UsedToday:     u.used,
+			ResetAt:       nextUTCDay(now),
+			RateLimited:   u.rateLimited,
+			QuotaRejected: u.quotaRejected,
+		}
+		pu.Weight = p.weight()
+		if p.RatePerSec > 0 {
+			pu.Burst = int(p.burst())
+			pu.TokensAvailable = math.Floor(u.tokens*100) / 100
+		}
+		if p.DailyQuota > 0 {
+			remaining := max(0, p.DailyQuota-u.used)
+			pu.RemainingToday = &remaining
+		}
+		out[name] = pu
+	}
+	return out
+}
+
+func nextUTCDay(now time.Time) time.Time {
+	y, m, d := now.UTC().Date()
+	return time.Date(y, m, d+1, 0, 0, 0, 0, time.UTC)
+}
+
+// AgentPolicies reports the configured agent groups with their usage.
+func (s *Scheduler) AgentPolicies() map[string]PolicyUsage {
+	return s.policies.snapshot()
+}
@@ -0,0 +1,171 @@
+package scheduler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPolicyGroupOf(t *testing.T) {
+	ps := newPolicySet(map[string]AgentPolicy{
+		"search":  {Agents: []string{"search-*"}},
+		"crawler": {Agents: []string{"search-crawl-*"}},
+		"vip":     {Agents: []string{"search-crawl-01"}},
+	}, "")
+	tests := map[string]string{
+		"search-index":    "search",
+		"search-crawl-02": "crawler",
+		"search-crawl-01": "vip",
+		"billing":         "",
+	}
+	for agent, want := range tests {
+		if got, _, _ := ps.groupOf(agent); got != want {
+			t.Errorf("%s: got group %q, want %q", agent, got, want)
+		}
+	}
+	if w := ps.weight("billing"); w != 1 {
+		t.Errorf("ungrouped agents should have weight 1, got %d", w)
+	}
+}
+
+func TestPolicyRateLimit(t *testing.T) {
+	old := timeNow
+	defer func() { timeNow = old }()
+	now := time.Date(2026, 3, 8, 12, 0, 0, 0, time.UTC)
+	timeNow = func() time.Time { return now }
+
+	ps := newPolicySet(map[string]AgentPolicy{
+		"team": {Agents: []string{"a1", "a2"}, RatePerSec: 2, Burst: 2},
+	}, "")
+	if err := ps.admit("a1"); err != nil {
+		t.Fatalf("first submit: %v", err)
+	}
+	if err := ps.admit("a2"); err != nil {
+		t.Fatalf("second submit: %v", err)
+	}
+
+	// The bucket is shared by the group.
+	err := ps.admit("a1")
+	var ae *admissionError
+	if !errors.As(err, &ae) || ae.Code != CodeRateLimited {
+		t.Fatalf("expected rate_limited, got %v", err)
+	}
+	if ae.RetryAfter != 500*time.Millisecond {
+		t.Errorf("expected 500ms retry-after, got %s", ae.RetryAfter)
+	}
+
+	now = now.Add(500 * time.Millisecond)
+	if err := ps.admit("a1"); err != nil {
+		t.Errorf("expected a refilled token: %v", err)
+	}
+	if err := ps.admit("other"); err != nil {
+		t.Errorf("ungrouped agents are unlimited: %v", err)
+	}
+}
+
+func TestPolicyDailyQuota(t *testing.T) {
+	old := timeNow
+	defer func() { timeNow = old }()
+	now := time.Date(2026, 3, 8, 23, 0, 0, 0, time.UTC)
+	timeNow = func() time.Time { return now }
+
+	ps := newPolicySet(map[string]AgentPolicy{
+		"team": {Agents: []string{"team-*"}, DailyQuota: 2},
+	}, "")
+	_ = ps.admit("team-a")
+	_ = ps.admit("team-b")
+	ps.refund("team-b")
+	_ = ps.admit("team-b")
+
+	err := ps.admit("team-a")
+	var ae *admissionError
+	if !errors.As(err, &ae) || ae.Code != CodeQuotaExceeded || ae.RetryAfter != time.Hour {
+		t.Fatalf("expected quota_exceeded until midnight UTC, got %v", err)
+	}
+
+	usage := ps.snapshot()["team"]
+	if usage.UsedToday != 2 || usage.RemainingToday == nil || *usage.RemainingToday != 0 || usage.QuotaRejected != 1 {
+		t.Errorf("unexpected usage: %+v", usage)
+	}
+
+	now = now.Add(time.Hour)
+	if err := ps.admit("team-a"); err != nil {
+		t.Errorf("quota should reset at midnight UTC: %v", err)
+	}
+}
+
+func TestPolicyQuotaSurvivesRestart(t *testing.T) {
+	old := timeNow
+	defer func() { timeNow = old }()
+	now := time.Date(2026, 3, 8, 12, 0, 0, 0, time.UTC)
+	timeNow = func() time.Time { return now }
+
+	groups := map[string]AgentPolicy{"team": {Agents: []string{"team-*"}, DailyQuota: 2}}
+	path := filepath.Join(t.TempDir(), "agent-quotas.json")
+	ps := newPolicySet(groups, path)
+	_ = ps.admit("team-a")
+	_ = ps.admit("team-b")
+
+	restarted := newPolicySet(groups, path)
+	if usage := restarted.snapshot()["team"]; usage.UsedToday != 2 {
+		t.Fatalf("expected usage to survive a restart, got %d", usage.UsedToday)
+	}
+	var ae *admissionError
+	if err := restarted.admit("team-a"); !errors.As(err, &ae) || ae.Code != CodeQuotaExceeded {
+		t.Errorf("expected quota_exceeded after restart, got %v", err)
+	}
+
+	// Usage from an earlier day does not count.
+	now = now.Add(24 * time.Hour)
+	if err := newPolicySet(groups, path).admit("team-a"); err != nil {
+		t.Errorf("quota should reset on a new day: %v", err)
+	}
+}
+
+func TestHandleSubmitRateLimited(t *testing.T) {
+	s, mux, executor := setupHandlerTest(t)
+	defer executor.Close()
+	s.ReloadConfig(Config{AgentGroups: map[string]AgentPolicy{
+		"team": {Agents: []string{"a1"}, RatePerSec: 0.1, Burst: 1, DailyQuota: 100},
+	}})
+
+	submit := func() *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", "/tasks", strings.NewReader(`{"agentId":"a1","action":"click","tabId":"tab-1"}`))
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+		return w
+	}
+	if w := submit(); w.Code != 202 {
+		t.Fatalf("expected 202, got %d: %s", w.Code, w.Body.String())
+	}
+	w := submit()
+	if w.Code != 429 {
+		t.Fatalf("expected 429, got %d", w.Code)
+	}
+	if !strings.Contains(w.Body.String(), CodeRateLimited) {
+		t.Errorf("expected rate_limited code, got %s", w.Body.String())
+	}
+	if w.Header().Get("Retry-After") == "" {
+		t.Error("expected Retry-After header")
+	}
+
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest("GET", "/scheduler/stats", nil))
+	var stats struct {
+		AgentGroups map[string]PolicyUsage `json:"agentGroups"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &stats); err != nil {
+		t.Fatalf("decode stats: %v", err)
+	}
+	team := stats.AgentGroups["team"]
+	if team.UsedToday != 1 || team.RateLimited != 1 || team.RemainingToday == nil || *team.RemainingToday != 99 {
+		t.Errorf("unexpected group usage: %+v", team)
+	}
+	if got := s.GetMetrics().TasksRejected; got != 1 {
+		t.Errorf("expected 1 rejected task, got %d", got)
+	}
+}
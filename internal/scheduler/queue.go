@@ -13,17 +13,26 @@ type TaskQueue struct {
 	totalCount  int
 	maxTotal    int
 	maxPerAgent int
+	weightOf    func(agentID string) int
+	// reserved counts slots set aside per agent for tasks that will be
+	// queued later; they count against the limits like queued tasks.
+	reserved      map[string]int
+	reservedTotal int
 }
 
 type agentQueue struct {
 	tasks    taskHeap
 	inflight int
+	// vtime is the agent's dispatch count divided by its weight, used to
+	// share dispatches between agents in proportion to their weights.
+	vtime float64
 }
 
 // NewTaskQueue creates a queue with the given global and per-agent limits.
 func NewTaskQueue(maxTotal, maxPerAgent int) *TaskQueue {
 	return &TaskQueue{
 		agents:      make(map[string]*agentQueue),
+		reserved:    make(map[string]int),
 		maxTotal:    maxTotal,
 		maxPerAgent: maxPerAgent,
 	}
@@ -41,34 +50,116 @@ func (q *TaskQueue) SetLimits(maxTotal, maxPerAgent int) {
 	}
 }
 
+// SetWeights sets the function that returns an agent's fair-share weight.
+// Without it every agent has weight 1.
+func (q *TaskQueue) SetWeights(weightOf func(agentID string) int) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.weightOf = weightOf
+}
+
+func (q *TaskQueue) weight(agentID string) float64 {
+	if q.weightOf == nil {
+		return 1
+	}
+	return float64(max(1, q.weightOf(agentID)))
+}
+
 // Enqueue adds a task. Returns the queue position or an error if limits are hit.
 func (q *TaskQueue) Enqueue(t *Task) (int, error) {
 	q.mu.Lock()
 	defer q.mu.Unlock()
 
-	if q.totalCount >= q.maxTotal {
-		return 0, fmt.Errorf("global queue full (%d/%d)", q.totalCount, q.maxTotal)
+	if err := q.checkLimitsLocked(t.AgentID); err != nil {
+		return 0, err
 	}
 
 	aq, ok := q.agents[t.AgentID]
 	if !ok {
-		aq = &agentQueue{}
+		// A returning agent starts at the lowest vtime among waiting agents
+		// rather than at zero, so it cannot claim the share it missed while
+		// idle.
+		aq = &agentQueue{vtime: q.minVTimeLocked()}
 		heap.Init(&aq.tasks)
 		q.agents[t.AgentID] = aq
 	}
 
-	if aq.tasks.Len() >= q.maxPerAgent {
-		return 0, fmt.Errorf("agent queue full for %q (%d/%d)", t.AgentID, aq.tasks.Len(), q.maxPerAgent)
+	heap.Push(&aq.tasks, t)
+	q.totalCount++
+	return q.totalCount, nil
+}
+
+// checkLimitsLocked reports whether one more task for agentID, queued or
+// reserved, would exceed the queue limits. Caller holds q.mu.
+func (q *TaskQueue) checkLimitsLocked(agentID string) error {
+	if total := q.totalCount + q.reservedTotal; total >= q.maxTotal {
+		return fmt.Errorf("global queue full (%d/%d)", total, q.maxTotal)
+	}
+	n := q.reserved[agentID]
+	if aq, ok := q.agents[agentID]; ok {
+		n += aq.tasks.Len()
+	}
+	if n >= q.maxPerAgent {
+		return fmt.Errorf("agent queue full for %q (%d/%d)", agentID, n, q.maxPerAgent)
+	}
+	return nil
+}
+
+// Reserve sets a slot aside for a task that will be queued later, such as a
+// workflow task waiting on its dependencies. The slot counts against the
+// limits until Unreserve or EnqueueReserved gives it back.
+func (q *TaskQueue) Reserve(agentID string) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if err := q.checkLimitsLocked(agentID); err != nil {
+		return err
 	}
+	q.reserved[agentID]++
+	q.reservedTotal++
+	return nil
+}
 
+// Unreserve gives back a slot set aside with Reserve.
+func (q *TaskQueue) Unreserve(agentID string) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.unreserveLocked(agentID)
+}
+
+func (q *TaskQueue) unreserveLocked(agentID string) {
+	if q.reserved[agentID] == 0 {
+		return
+	}
+	q.reserved[agentID]--
+	q.reservedTotal--
+	if q.reserved[agentID] == 0 {
+		delete(q.reserved, agentID)
+	}
+}
+
+// EnqueueReserved queues a task in the slot Reserve set aside for it. It
+// cannot fail on the limits, which were checked when the slot was reserved.
+func (q *TaskQueue) EnqueueReserved(t *Task) int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.unreserveLocked(t.AgentID)
+
+	aq, ok := q.agents[t.AgentID]
+	if !ok {
+		aq = &agentQueue{vtime: q.minVTimeLocked()}
+		heap.Init(&aq.tasks)
+		q.agents[t.AgentID] = aq
+	}
 	heap.Push(&aq.tasks, t)
 	q.totalCount++
-	return q.totalCount, nil
+	return q.totalCount
 }
 
-// Dequeue picks the next task using fair round-robin: the agent with the
-// fewest in-flight tasks gets served first. Among tasks for that agent,
-// the heap ordering (priority then creation time) decides.
+// Dequeue picks the next task using weighted fair sharing: the agent with
+// the fewest in-flight tasks per unit of weight gets served first, and ties
+// go to the agent that has been served least relative to its weight. Among
+// tasks for that agent, the heap ordering (priority then creation time)
+// decides.
 func (q *TaskQueue) Dequeue(maxPerAgentInflight, maxGlobalInflight int) *Task {
 	q.mu.Lock()
 	defer q.mu.Unlock()
@@ -82,7 +173,7 @@ func (q *TaskQueue) Dequeue(maxPerAgentInflight, maxGlobalInflight int) *Task {
 	}
 
 	var bestAgent string
-	bestInflight := int(^uint(0) >> 1) // max int
+	var bestLoad, bestVTime float64
 
 	for agentID, aq := range q.agents {
 		if aq.tasks.Len() == 0 {
@@ -91,9 +182,10 @@ func (q *TaskQueue) Dequeue(maxPerAgentInflight, maxGlobalInflight int) *Task {
 		if aq.inflight >= maxPerAgentInflight {
 			continue
 		}
-		if aq.inflight < bestInflight {
-			bestInflight = aq.inflight
-			bestAgent = agentID
+		load := float64(aq.inflight) / q.weight(agentID)
+		if bestAgent == "" || load < bestLoad ||
+			(load == bestLoad && (aq.vtime < bestVTime || (aq.vtime == bestVTime && agentID < bestAgent))) {
+			bestAgent, bestLoad, bestVTime = agentID, load, aq.vtime
 		}
 	}
 
@@ -104,10 +196,25 @@ func (q *TaskQueue) Dequeue(maxPerAgentInflight, maxGlobalInflight int) *Task {
 	aq := q.agents[bestAgent]
 	t := heap.Pop(&aq.tasks).(*Task)
 	aq.inflight++
+	aq.vtime += 1 / q.weight(bestAgent)
 	q.totalCount--
 	return t
 }
 
+// minVTimeLocked returns the lowest vtime among agents with queued work.
+func (q *TaskQueue) minVTimeLocked() float64 {
+	first, low := true, 0.0
+	for _, aq := range q.agents {
+		if aq.tasks.Len() == 0 {
+			continue
+		}
+		if first || aq.vtime < low {
+			first, low = false, aq.vtime
+		}
+	}
+	return low
+}
+
 // Complete marks a task as no longer in-flight for its agent.
 func (q *TaskQueue) Complete(agentID string) {
 	q.mu.Lock()
@@ -181,6 +288,7 @@ func (q *TaskQueue) Stats() QueueStats {
 
 	s := QueueStats{
 		TotalQueued: q.totalCount,
+		TotalHeld:   q.reservedTotal,
 		Agents:      make(map[string]AgentStats, len(q.agents)),
 	}
 	for agentID, aq := range q.agents {
@@ -190,6 +298,11 @@ func (q *TaskQueue) Stats() QueueStats {
 			Inflight: aq.inflight,
 		}
 	}
+	for agentID, n := range q.reserved {
+		as := s.Agents[agentID]
+		as.Held = n
+		s.Agents[agentID] = as
+	}
 	return s
 }
 
@@ -197,6 +310,7 @@ func (q *TaskQueue) Stats() QueueStats {
 type QueueStats struct {
 	TotalQueued   int                   `json:"totalQueued"`
 	TotalInflight int                   `json:"totalInflight"`
+	TotalHeld     int                   `json:"totalHeld"`
 	Agents        map[string]AgentStats `json:"agents"`
 }
 
@@ -204,6 +318,7 @@ type QueueStats struct {
 type AgentStats struct {
 	Queued   int `json:"queued"`
 	Inflight int `json:"inflight"`
+	Held     int `json:"held,omitempty"`
 }
 
 // --- heap implementation ---
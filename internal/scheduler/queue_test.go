@@ -102,6 +102,40 @@ func TestQueueFairness(t *testing.T) {
 	}
 }
 
+func TestQueueWeightedFairShare(t *testing.T) {
+	q := NewTaskQueue(100, 100)
+	q.SetWeights(func(agentID string) int {
+		if agentID == "heavy" {
+			return 3
+		}
+		return 1
+	})
+
+	for i := range 8 {
+		for _, agent := range []string{"heavy", "light"} {
+			if _, err := q.Enqueue(&Task{ID: agent + "-" + string(rune('0'+i)), AgentID: agent, CreatedAt: time.Now()}); err != nil {
+				t.Fatalf("enqueue failed: %v", err)
+			}
+		}
+	}
+
+	// Each task completes before the next dispatch, so only the weighted
+	// dispatch share decides.
+	counts := map[string]int{}
+	for range 8 {
+		got := q.Dequeue(10, 20)
+		if got == nil {
+			t.Fatal("expected a task")
+			return
+		}
+		counts[got.AgentID]++
+		q.Complete(got.AgentID)
+	}
+	if counts["heavy"] != 6 || counts["light"] != 2 {
+		t.Errorf("expected a 3:1 split, got %v", counts)
+	}
+}
+
 func TestQueueInflightLimit(t *testing.T) {
 	q := NewTaskQueue(100, 100)
 
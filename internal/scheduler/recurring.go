@@ -0,0 +1,502 @@
+package scheduler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/rand/v2"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pinchtab/pinchtab/internal/httpx"
+)
+
+// minRecurringInterval is the shortest fixed interval a template may use.
+const minRecurringInterval = time.Second
+
+var errRecurringNotFound = errors.New("recurring task not found")
+
+// RecurringRequest is the JSON body for POST /tasks/recurring.
+type RecurringRequest struct {
+	Name          string        `json:"name,omitempty"`
+	Cron          string        `json:"cron,omitempty"`
+	Interval      string        `json:"interval,omitempty"`
+	Jitter        string        `json:"jitter,omitempty"`
+	MaxConcurrent int           `json:"maxConcurrent,omitempty"`
+	DeadlineSec   int           `json:"deadlineSec,omitempty"`
+	Task          SubmitRequest `json:"task"`
+}
+
+// Recurring is a task template that the scheduler submits on a cron
+// expression or a fixed interval. Every run is an ordinary Task tagged with
+// the template ID.
+type Recurring struct {
+	ID            string        `json:"id"`
+	Name          string        `json:"name,omitempty"`
+	Cron          string        `json:"cron,omitempty"`
+	Interval      string        `json:"interval,omitempty"`
+	Jitter        string        `json:"jitter,omitempty"`
+	MaxConcurrent int           `json:"maxConcurrent"`
+	DeadlineSec   int           `json:"deadlineSec,omitempty"`
+	Task          SubmitRequest `json:"task"`
+	Paused        bool          `json:"paused"`
+	CreatedAt     time.Time     `json:"createdAt"`
+	NextRunAt     time.Time     `json:"nextRunAt,omitempty"`
+	LastRunAt     time.Time     `json:"lastRunAt,omitempty"`
+	LastTaskID    string        `json:"lastTaskId,omitempty"`
+	Runs          uint64        `json:"runs"`
+	Skipped       uint64        `json:"skipped"`
+	Rejected      uint64        `json:"rejected"`
+	ActiveRuns    int           `json:"activeRuns"`
+	ActiveTaskIDs []string      `json:"activeTaskIds,omitempty"`
+
+	cron     *cronSchedule
+	interval time.Duration
+	jitter   time.Duration
+	active   []string
+}
+
+// recurringSet holds the registered templates. When path is set the
+// definitions are saved there on every change.
+type recurringSet struct {
+	mu    sync.Mutex
+	items map[string]*Recurring
+	path  string
+}
+
+func newRecurringSet(path string) *recurringSet {
+	rs := &recurringSet{items: make(map[string]*Recurring), path: path}
+	if path != "" {
+		rs.load()
+	}
+	return rs
+}
+
+func (req *RecurringRequest) build() (*Recurring, error) {
+	if (req.Cron == "") == (req.Interval == "") {
+		return nil, fmt.Errorf("exactly one of 'cron' or 'interval' is required")
+	}
+	if req.Task.Deadline != "" {
+		return nil, fmt.Errorf("task.deadline is not supported for recurring tasks, use deadlineSec")
+	}
+	if req.DeadlineSec < 0 {
+		return nil, fmt.Errorf("deadlineSec must be >= 0")
+	}
+	if err := req.Task.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid task: %w", err)
+	}
+
+	rec := &Recurring{
+		ID:            generateRecurringID(),
+		Name:          req.Name,
+		Cron:          req.Cron,
+		Interval:      req.Interval,
+		Jitter:        req.Jitter,
+		MaxConcurrent: req.MaxConcurrent,
+		DeadlineSec:   req.DeadlineSec,
+		Task:          req.Task,
+		CreatedAt:     timeNow(),
+	}
+	if err := rec.compile(); err != nil {
+		return nil, err
+	}
+	return rec, nil
+}
+
+// compile parses the schedule fields into their runtime form.
+func (r *Recurring) compile() error {
+	if r.MaxConcurrent <= 0 {
+		r.MaxConcurrent = 1
+	}
+	if r.Cron != "" {
+		cs, err := parseCron(r.Cron)
+		if err != nil {
+			return fmt.Errorf("invalid cron: %w", err)
+		}
+		r.cron = cs
+	} else {
+		d, err := time.ParseDuration(r.Interval)
+		if err != nil {
+			return fmt.Errorf("invalid interval: %w", err)
+		}
+		if d < minRecurringInterval {
+			return fmt.Errorf("interval must be at least %s", minRecurringInterval)
+		}
+		r.interval = d
+	}
+	if r.Jitter != "" {
+		d, err := time.ParseDuration(r.Jitter)
+		if err != nil || d < 0 {
+			return fmt.Errorf("invalid jitter %q", r.Jitter)
+		}
+		r.jitter = d
+	}
+	return nil
+}
+
+// next returns the run time following now, including a random jitter.
+func (r *Recurring) next(now time.Time) time.Time {
+	var at time.Time
+	if r.cron != nil {
+		at = r.cron.Next(now)
+		if at.IsZero() {
+			return at
+		}
+	} else {
+		at = now.Add(r.interval)
+	}
+	if r.jitter > 0 {
+		at = at.Add(rand.N(r.jitter))
+	}
+	return at
+}
+
+func (r *Recurring) snapshot() Recurring {
+	return Recurring{
+		ID:            r.ID,
+		Name:          r.Name,
+		Cron:          r.Cron,
+		Interval:      r.Interval,
+		Jitter:        r.Jitter,
+		MaxConcurrent: r.MaxConcurrent,
+		DeadlineSec:   r.DeadlineSec,
+		Task:          r.Task,
+		Paused:        r.Paused,
+		CreatedAt:     r.CreatedAt,
+		NextRunAt:     r.NextRunAt,
+		LastRunAt:     r.LastRunAt,
+		LastTaskID:    r.LastTaskID,
+		Runs:          r.Runs,
+		Skipped:       r.Skipped,
+		Rejected:      r.Rejected,
+		ActiveRuns:    len(r.active),
+		ActiveTaskIDs: append([]string(nil), r.active...),
+	}
+}
+
+// AddRecurring registers a new recurring template.
+func (s *Scheduler) AddRecurring(req RecurringRequest) (Recurring, error) {
+	rec, err := req.build()
+	if err != nil {
+		return Recurring{}, err
+	}
+	rec.NextRunAt = rec.next(timeNow())
+
+	s.recurring.mu.Lock()
+	defer s.recurring.mu.Unlock()
+	s.recurring.items[rec.ID] = rec
+	s.recurring.saveLocked()
+	slog.Info("recurring task added", "id", rec.ID, "agent", rec.Task.AgentID, "cron", rec.Cron, "interval", rec.Interval, "next", rec.NextRunAt)
+	return rec.snapshot(), nil
+}
+
+// ListRecurring returns all templates ordered by creation time.
+func (s *Scheduler) ListRecurring() []Recurring {
+	s.recurring.mu.Lock()
+	defer s.recurring.mu.Unlock()
+
+	out := make([]Recurring, 0, len(s.recurring.items))
+	for _, r := range s.recurring.items {
+		s.pruneRecurringActive(r)
+		out = append(out, r.snapshot())
+	}
+	sort.Slice(out, func(i, j int) bool {
+		return out[i].CreatedAt.Before(out[j].CreatedAt)
+	})
+	return out
+}
+
+// GetRecurring returns one template by ID.
+func (s *Scheduler) GetRecurring(id string) (Recurring, error) {
+	s.recurring.mu.Lock()
+	defer s.recurring.mu.Unlock()
+	r, ok := s.recurring.items[id]
+	if !ok {
+		return Recurring{}, errRecurringNotFound
+	}
+	s.pruneRecurringActive(r)
+	return r.snapshot(), nil
+}
+
+// PauseRecurring stops a template from creating new runs. Runs already
+// submitted are not affected.
+func (s *Scheduler) PauseRecurring(id string) (Recurring, error) {
+	s.recurring.mu.Lock()
+	defer s.recurring.mu.Unlock()
+	r, ok := s.recurring.items[id]
+	if !ok {
+		return Recurring{}, errRecurringNotFound
+	}
+	r.Paused = true
+	r.NextRunAt = time.Time{}
+	s.recurring.saveLocked()
+	slog.Info("recurring task paused", "id", id)
+	return r.snapshot(), nil
+}
+
+// ResumeRecurring re-enables a paused template, scheduling its next run from
+// the current time.
+func (s *Scheduler) ResumeRecurring(id string) (Recurring, error) {
+	s.recurring.mu.Lock()
+	defer s.recurring.mu.Unlock()
+	r, ok := s.recurring.items[id]
+	if !ok {
+		return Recurring{}, errRecurringNotFound
+	}
+	if r.Paused {
+		r.Paused = false
+		r.NextRunAt = r.next(timeNow())
+		s.recurring.saveLocked()
+		slog.Info("recurring task resumed", "id", id, "next", r.NextRunAt)
+	}
+	return r.snapshot(), nil
+}
+
+// DeleteRecurring removes a template. Runs already submitted are not affected.
+func (s *Scheduler) DeleteRecurring(id string) error {
+	s.recurring.mu.Lock()
+	defer s.recurring.mu.Unlock()
+	if _, ok := s.recurring.items[id]; !ok {
+		return errRecurringNotFound
+	}
+	delete(s.recurring.items, id)
+	s.recurring.saveLocked()
+	slog.Info("recurring task deleted", "id", id)
+	return nil
+}
+
+// pruneRecurringActive drops runs that have finished from the template's
+// active list. Caller holds s.recurring.mu.
+func (s *Scheduler) pruneRecurringActive(r *Recurring) {
+	kept := r.active[:0]
+	for _, id := range r.active {
+		if t := s.GetTask(id); t != nil && !t.GetState().IsTerminal() {
+			kept = append(kept, id)
+		}
+	}
+	r.active = kept
+}
+
+// recurringRun is a run of a template that came due, submitted once
+// s.recurring.mu is released.
+type recurringRun struct {
+	id  string
+	req SubmitRequest
+}
+
+// fireRecurring submits a run for every template that is due. A template
+// already at its MaxConcurrent limit skips the run and waits for the next one.
+// Run counts and active runs are saved whenever a template comes due, so the
+// concurrency guard still sees runs in flight after a restart.
+//
+// Runs are submitted without holding s.recurring.mu, so template reads do not
+// wait on the queue and journal. fireRecurring only runs on the recurring
+// loop, and a due template's NextRunAt moves on before its lock is released,
+// so no run is submitted twice.
+func (s *Scheduler) fireRecurring(now time.Time) {
+	s.recurring.mu.Lock()
+	due := false
+	var runs []recurringRun
+	for _, r := range s.recurring.items {
+		if r.Paused || r.NextRunAt.IsZero() || r.NextRunAt.After(now) {
+			continue
+		}
+		due = true
+		r.NextRunAt = r.next(now)
+
+		s.pruneRecurringActive(r)
+		if len(r.active) >= r.MaxConcurrent {
+			r.Skipped++
+			slog.Info("recurring task skipped, previous run still active", "id", r.ID, "active", len(r.active))
+			continue
+		}
+
+		req := r.Task
+		if r.DeadlineSec > 0 {
+			req.Deadline = now.Add(time.Duration(r.DeadlineSec) * time.Second).Format(time.RFC3339)
+		}
+		runs = append(runs, recurringRun{id: r.ID, req: req})
+	}
+	if len(runs) == 0 {
+		if due {
+			s.recurring.saveLocked()
+		}
+		s.recurring.mu.Unlock()
+		return
+	}
+	s.recurring.mu.Unlock()
+
+	tasks := make([]*Task, len(runs))
+	errs := make([]error, len(runs))
+	for n, run := range runs {
+		recurringID := run.id
+		tasks[n], errs[n] = s.submit(run.req, func(t *Task) { t.RecurringID = recurringID })
+	}
+
+	s.recurring.mu.Lock()
+	defer s.recurring.mu.Unlock()
+	for n, run := range runs {
+		r, ok := s.recurring.items[run.id]
+		if !ok {
+			// Deleted while its run was submitted; the run goes on.
+			continue
+		}
+		r.LastRunAt = now
+		if tasks[n] != nil {
+			r.LastTaskID = tasks[n].ID
+		}
+		if errs[n] != nil {
+			r.Rejected++
+			slog.Warn("recurring task run rejected", "id", r.ID, "err", errs[n])
+			continue
+		}
+		r.Runs++
+		r.active = append(r.active, tasks[n].ID)
+	}
+	s.recurring.saveLocked()
+}
+
+func (s *Scheduler) recurringLoop() {
+	defer s.wg.Done()
+	ticker := time.NewTicker(1 * time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.stopCh:
+			return
+		case <-ticker.C:
+			s.fireRecurring(timeNow())
+		}
+	}
+}
+
+func (rs *recurringSet) load() {
+	data, err := os.ReadFile(rs.path)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			slog.Warn("recurring tasks: failed to read", "path", rs.path, "err", err)
+		}
+		return
+	}
+	var items []*Recurring
+	if err := json.Unmarshal(data, &items); err != nil {
+		slog.Warn("recurring tasks: failed to parse", "path", rs.path, "err", err)
+		return
+	}
+	now := timeNow()
+	for _, r := range items {
+		if err := r.compile(); err != nil {
+			slog.Warn("recurring tasks: dropping invalid template", "id", r.ID, "err", err)
+			continue
+		}
+		// Runs still in flight are pruned once the journal has been
+		// replayed and they can be looked up again.
+		r.active = r.ActiveTaskIDs
+		r.ActiveTaskIDs = nil
+		r.NextRunAt = time.Time{}
+		if !r.Paused {
+			r.NextRunAt = r.next(now)
+		}
+		rs.items[r.ID] = r
+	}
+}
+
+func (rs *recurringSet) saveLocked() {
+	if rs.path == "" {
+		return
+	}
+	items := make([]Recurring, 0, len(rs.items))
+	for _, r := range rs.items {
+		items = append(items, r.snapshot())
+	}
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].CreatedAt.Before(items[j].CreatedAt)
+	})
+
+	data, err := json.MarshalIndent(items, "", "  ")
+	if err != nil {
+		slog.Warn("recurring tasks: failed to encode", "err", err)
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(rs.path), 0750); err != nil {
+		slog.Warn("recurring tasks: failed to create dir", "err", err)
+		return
+	}
+	// Atomic write: temp file + rename
+	tmpPath := rs.path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0600); err != nil {
+		slog.Warn("recurring tasks: failed to write", "err", err)
+		return
+	}
+	if err := os.Rename(tmpPath, rs.path); err != nil {
+		slog.Warn("recurring tasks: failed to replace", "err", err)
+	}
+}
+
+// generateRecurringID produces a random template ID in the format rec_XXXXXXXX.
+func generateRecurringID() string {
+	return "rec_" + strings.TrimPrefix(generateTaskID(), "tsk_")
+}
+
+func (s *Scheduler) handleRecurringCreate(w http.ResponseWriter, r *http.Request) {
+	var req RecurringRequest
+	if err := httpx.DecodeJSONBody(w, r, 0, &req); err != nil {
+		httpx.Error(w, httpx.StatusForJSONDecodeError(err), err)
+		return
+	}
+
+	rec, err := s.AddRecurring(req)
+	if err != nil {
+		httpx.Error(w, 400, err)
+		return
+	}
+	httpx.JSON(w, 201, rec)
+}
+
+func (s *Scheduler) handleRecurringList(w http.ResponseWriter, _ *http.Request) {
+	items := s.ListRecurring()
+	httpx.JSON(w, 200, map[string]any{"recurring": items, "count": len(items)})
+}
+
+func (s *Scheduler) handleRecurringGet(w http.ResponseWriter, r *http.Request) {
+	rec, err := s.GetRecurring(r.PathValue("id"))
+	if err != nil {
+		httpx.ErrorCode(w, 404, "not_found", err.Error(), false, nil)
+		return
+	}
+	httpx.JSON(w, 200, rec)
+}
+
+func (s *Scheduler) handleRecurringPause(w http.ResponseWriter, r *http.Request) {
+	rec, err := s.PauseRecurring(r.PathValue("id"))
+	if err != nil {
+		httpx.ErrorCode(w, 404, "not_found", err.Error(), false, nil)
+		return
+	}
+	httpx.JSON(w, 200, rec)
+}
+
+func (s *Scheduler) handleRecurringResume(w http.ResponseWriter, r *http.Request) {
+	rec, err := s.ResumeRecurring(r.PathValue("id"))
+	if err != nil {
+		httpx.ErrorCode(w, 404, "not_found", err.Error(), false, nil)
+		return
+	}
+	httpx.JSON(w, 200, rec)
+}
+
+func (s *Scheduler) handleRecurringDelete(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if err := s.DeleteRecurring(id); err != nil {
+		httpx.ErrorCode(w, 404, "not_found", err.Error(), false, nil)
+		return
+	}
+	httpx.JSON(w, 200, map[string]string{"status": "deleted", "id": id})
+}
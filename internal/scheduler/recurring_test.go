@@ -0,0 +1,277 @@
+package scheduler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRecurringRequestValidation(t *testing.T) {
+	s := New(DefaultConfig(), &mockResolver{})
+	task := SubmitRequest{AgentID: "a1", Action: "click", TabID: "tab-1"}
+
+	tests := []struct {
+		name string
+		req  RecurringRequest
+	}{
+		{"no schedule", RecurringRequest{Task: task}},
+		{"both schedules", RecurringRequest{Cron: "* * * * *", Interval: "1m", Task: task}},
+		{"bad cron", RecurringRequest{Cron: "nope", Task: task}},
+		{"interval too short", RecurringRequest{Interval: "10ms", Task: task}},
+		{"bad jitter", RecurringRequest{Interval: "1m", Jitter: "-1s", Task: task}},
+		{"absolute deadline", RecurringRequest{Interval: "1m", Task: SubmitRequest{AgentID: "a1", Action: "click", Deadline: "2030-01-01T00:00:00Z"}}},
+		{"invalid task", RecurringRequest{Interval: "1m", Task: SubmitRequest{Action: "click"}}},
+	}
+	for _, tt := range tests {
+		if _, err := s.AddRecurring(tt.req); err == nil {
+			t.Errorf("%s: expected error", tt.name)
+		}
+	}
+}
+
+func TestRecurringFiresAndGuardsConcurrency(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	old := timeNow
+	defer func() { timeNow = old }()
+	timeNow = func() time.Time { return now }
+
+	s := New(DefaultConfig(), &mockResolver{})
+	rec, err := s.AddRecurring(RecurringRequest{
+		Interval: "1m",
+		Task:     SubmitRequest{AgentID: "a1", Action: "click", TabID: "tab-1"},
+	})
+	if err != nil {
+		t.Fatalf("add failed: %v", err)
+	}
+	if !rec.NextRunAt.Equal(now.Add(time.Minute)) {
+		t.Errorf("expected next run in 1m, got %s", rec.NextRunAt)
+	}
+
+	// Not due yet.
+	s.fireRecurring(now)
+	if got := s.QueueStats().TotalQueued; got != 0 {
+		t.Fatalf("expected nothing queued yet, got %d", got)
+	}
+
+	now = now.Add(time.Minute)
+	s.fireRecurring(now)
+	got, _ := s.GetRecurring(rec.ID)
+	if got.Runs != 1 || got.ActiveRuns != 1 {
+		t.Fatalf("expected 1 active run, got runs=%d active=%d", got.Runs, got.ActiveRuns)
+	}
+	task := s.GetTask(got.LastTaskID)
+	if task == nil || task.RecurringID != rec.ID {
+		t.Fatalf("expected run linked to template, got %+v", task)
+	}
+
+	// The first run is still queued, so the next one is skipped.
+	now = now.Add(time.Minute)
+	s.fireRecurring(now)
+	got, _ = s.GetRecurring(rec.ID)
+	if got.Runs != 1 || got.Skipped != 1 {
+		t.Errorf("expected skip while previous run active, got runs=%d skipped=%d", got.Runs, got.Skipped)
+	}
+
+	// Once it finishes, the template runs again.
+	if err := s.Cancel(task.ID); err != nil {
+		t.Fatalf("cancel failed: %v", err)
+	}
+	now = now.Add(time.Minute)
+	s.fireRecurring(now)
+	got, _ = s.GetRecurring(rec.ID)
+	if got.Runs != 2 {
+		t.Errorf("expected second run after the first finished, got %d", got.Runs)
+	}
+}
+
+func TestRecurringPauseResumeDelete(t *testing.T) {
+	s := New(DefaultConfig(), &mockResolver{})
+	rec, err := s.AddRecurring(RecurringRequest{
+		Cron: "*/5 * * * *",
+		Task: SubmitRequest{AgentID: "a1", Action: "click", TabID: "tab-1"},
+	})
+	if err != nil {
+		t.Fatalf("add failed: %v", err)
+	}
+
+	paused, err := s.PauseRecurring(rec.ID)
+	if err != nil || !paused.Paused || !paused.NextRunAt.IsZero() {
+		t.Fatalf("pause failed: %+v, %v", paused, err)
+	}
+	s.fireRecurring(time.Now().Add(time.Hour))
+	if got, _ := s.GetRecurring(rec.ID); got.Runs != 0 {
+		t.Errorf("paused template should not run, got %d runs", got.Runs)
+	}
+
+	resumed, err := s.ResumeRecurring(rec.ID)
+	if err != nil || resumed.Paused || resumed.NextRunAt.IsZero() {
+		t.Fatalf("resume failed: %+v, %v", resumed, err)
+	}
+
+	if err := s.DeleteRecurring(rec.ID); err != nil {
+		t.Fatalf("delete failed: %v", err)
+	}
+	if _, err := s.GetRecurring(rec.ID); err == nil {
+		t.Error("expected deleted template to be gone")
+	}
+	if err := s.DeleteRecurring(rec.ID); err == nil {
+		t.Error("expected error deleting unknown template")
+	}
+}
+
+func TestRecurringPersistedWithJournal(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tasks.jsonl")
+	cfg := DefaultConfig()
+	cfg.JournalPath = path
+
+	s := New(cfg, &mockResolver{})
+	rec, err := s.AddRecurring(RecurringRequest{
+		Name:     "poll-dashboard",
+		Interval: "5m",
+		Task:     SubmitRequest{AgentID: "a1", Action: "click", TabID: "tab-1"},
+	})
+	if err != nil {
+		t.Fatalf("add failed: %v", err)
+	}
+	if _, err := s.PauseRecurring(rec.ID); err != nil {
+		t.Fatalf("pause failed: %v", err)
+	}
+	s.Stop()
+
+	restarted := New(cfg, &mockResolver{})
+	defer restarted.Stop()
+	got, err := restarted.GetRecurring(rec.ID)
+	if err != nil {
+		t.Fatalf("expected template to survive restart: %v", err)
+	}
+	if got.Name != "poll-dashboard" || !got.Paused {
+		t.Errorf("unexpected restored template: %+v", got)
+	}
+}
+
+func TestHandlerRecurringRoutes(t *testing.T) {
+	s, mux, executor := setupHandlerTest(t)
+	defer executor.Close()
+	defer s.Stop()
+
+	body := `{"interval":"1m","task":{"agentId":"a1","action":"click","tabId":"tab-1"}}`
+	req := httptest.NewRequest("POST", "/tasks/recurring", strings.NewReader(body))
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, req)
+	if w.Code != 201 {
+		t.Fatalf("expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+
+	items := s.ListRecurring()
+	if len(items) != 1 {
+		t.Fatalf("expected 1 template, got %d", len(items))
+	}
+	id := items[0].ID
+
+	for _, tc := range []struct {
+		method, path string
+		want         int
+	}{
+		{"GET", "/tasks/recurring", 200},
+		{"GET", "/tasks/recurring/" + id, 200},
+		{"POST", "/tasks/recurring/" + id + "/pause", 200},
+		{"POST", "/tasks/recurring/" + id + "/resume", 200},
+		{"DELETE", "/tasks/recurring/" + id, 200},
+		{"GET", "/tasks/recurring/" + id, 404},
+	} {
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, httptest.NewRequest(tc.method, tc.path, http.NoBody))
+		if w.Code != tc.want {
+			t.Errorf("%s %s: expected %d, got %d: %s", tc.method, tc.path, tc.want, w.Code, w.Body.String())
+		}
+	}
+}
+
+func TestRecurringCountsRejectionsAndPersistsActiveRuns(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	old := timeNow
+	defer func() { timeNow = old }()
+	timeNow = func() time.Time { return now }
+
+	cfg := DefaultConfig()
+	cfg.JournalPath = filepath.Join(t.TempDir(), "tasks.jsonl")
+	cfg.MaxPerAgent = 1
+
+	s := New(cfg, &mockResolver{})
+	rec, err := s.AddRecurring(RecurringRequest{
+		Interval:      "1m",
+		MaxConcurrent: 2,
+		Task:          SubmitRequest{AgentID: "a1", Action: "click", TabID: "tab-1"},
+	})
+	if err != nil {
+		t.Fatalf("add failed: %v", err)
+	}
+
+	now = now.Add(time.Minute)
+	s.fireRecurring(now)
+	// The agent's queue limit turns the second run away.
+	now = now.Add(time.Minute)
+	s.fireRecurring(now)
+	got, _ := s.GetRecurring(rec.ID)
+	if got.Runs != 1 || got.Rejected != 1 || got.ActiveRuns != 1 {
+		t.Fatalf("expected 1 run and 1 rejection, got runs=%d rejected=%d active=%d", got.Runs, got.Rejected, got.ActiveRuns)
+	}
+	s.Stop()
+
+	restarted := New(cfg, &mockResolver{})
+	restarted.recover()
+	defer restarted.Stop()
+	got, err = restarted.GetRecurring(rec.ID)
+	if err != nil {
+		t.Fatalf("expected template to survive restart: %v", err)
+	}
+	if got.Runs != 1 || got.Rejected != 1 || got.ActiveRuns != 1 || restarted.GetTask(got.ActiveTaskIDs[0]) == nil {
+		t.Errorf("run stats lost on restart: %+v", got)
+	}
+}
+
+func TestRecurringReadsDoNotWaitOnSubmit(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	old := timeNow
+	defer func() { timeNow = old }()
+	timeNow = func() time.Time { return now }
+
+	s := New(DefaultConfig(), &mockResolver{})
+	rec, err := s.AddRecurring(RecurringRequest{
+		Interval: "1m",
+		Task:     SubmitRequest{AgentID: "a1", Action: "click", TabID: "tab-1"},
+	})
+	if err != nil {
+		t.Fatalf("add failed: %v", err)
+	}
+
+	// Stall the run's submit where it stores the task, as a slow journal would.
+	s.results.writeMu.Lock()
+	fired := make(chan struct{})
+	go func() {
+		s.fireRecurring(now.Add(time.Minute))
+		close(fired)
+	}()
+
+	listed := make(chan int, 1)
+	go func() { listed <- len(s.ListRecurring()) }()
+	select {
+	case n := <-listed:
+		if n != 1 {
+			t.Errorf("expected 1 template, got %d", n)
+		}
+	case <-time.After(2 * time.Second):
+		s.results.writeMu.Unlock()
+		t.Fatal("ListRecurring waited on a run being submitted")
+	}
+	s.results.writeMu.Unlock()
+	<-fired
+
+	got, _ := s.GetRecurring(rec.ID)
+	if got.Runs != 1 || got.ActiveRuns != 1 {
+		t.Errorf("expected the run recorded, got runs=%d active=%d", got.Runs, got.ActiveRuns)
+	}
+}
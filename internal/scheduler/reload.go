@@ -26,6 +26,19 @@ func (s *Scheduler) ReloadConfig(cfg Config) {
 			"maxPerAgentFlight", cfg.MaxPerAgentFlight,
 		)
 	}
+	if cfg.Retry.MaxAttempts > 0 {
+		s.cfgMu.Lock()
+		s.cfg.Retry = cfg.Retry
+		s.cfgMu.Unlock()
+		slog.Info("scheduler: retry policy reloaded", "maxAttempts", cfg.Retry.MaxAttempts)
+	}
+	if cfg.AgentGroups != nil {
+		s.policies.set(cfg.AgentGroups)
+		s.cfgMu.Lock()
+		s.cfg.AgentGroups = cfg.AgentGroups
+		s.cfgMu.Unlock()
+		slog.Info("scheduler: agent groups reloaded", "groups", len(cfg.AgentGroups))
+	}
 	if cfg.ResultTTL > 0 {
 		s.results.SetTTL(cfg.ResultTTL)
 		slog.Info("scheduler: result TTL reloaded", "ttl", cfg.ResultTTL)
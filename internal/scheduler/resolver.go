@@ -18,3 +18,14 @@ func (r *ManagerResolver) ResolveTabInstance(tabID string) (string, error) {
 	}
 	return inst.Port, nil
 }
+
+// RunningInstances lists running instances for tasks that target an
+// instance or profile instead of a tab.
+func (r *ManagerResolver) RunningInstances() []InstanceRef {
+	running := r.Mgr.Running()
+	out := make([]InstanceRef, 0, len(running))
+	for _, inst := range running {
+		out = append(out, InstanceRef{ID: inst.ID, ProfileName: inst.ProfileName, Port: inst.Port})
+	}
+	return out
+}
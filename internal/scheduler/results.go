@@ -1,15 +1,23 @@
 package scheduler
 
 import (
+	"log/slog"
 	"sync"
 	"time"
 )
 
 // ResultStore holds completed task results in memory with TTL-based expiry.
+// When a journal is attached, every stored snapshot and eviction is also
+// written to it so the task set can be rebuilt after a restart.
+//
+// Journal writes happen outside mu, so readers never wait on disk I/O.
+// writeMu keeps the journal records in the order the map was updated.
 type ResultStore struct {
+	writeMu sync.Mutex
 	mu      sync.RWMutex
 	tasks   map[string]*Task
 	ttl     time.Duration
+	journal *Journal
 	closeCh chan struct{}
 }
 
@@ -55,8 +63,37 @@ func (rs *ResultStore) SetTTL(ttl time.Duration) {
 	rs.mu.Unlock()
 }
 
+func (rs *ResultStore) getTTL() time.Duration {
+	rs.mu.RLock()
+	defer rs.mu.RUnlock()
+	return rs.ttl
+}
+
+// SetJournal attaches a durable journal to the store.
+func (rs *ResultStore) SetJournal(j *Journal) {
+	rs.mu.Lock()
+	rs.journal = j
+	rs.mu.Unlock()
+}
+
 // Store saves a task snapshot into the result store.
 func (rs *ResultStore) Store(t *Task) {
+	snap := t.Snapshot()
+	rs.writeMu.Lock()
+	defer rs.writeMu.Unlock()
+	rs.mu.Lock()
+	rs.tasks[snap.ID] = snap
+	j := rs.journal
+	rs.mu.Unlock()
+	if j != nil {
+		if err := j.Append(snap); err != nil {
+			slog.Warn("scheduler journal: append failed", "task", snap.ID, "err", err)
+		}
+	}
+}
+
+// restore puts a replayed snapshot back into the store without journaling it.
+func (rs *ResultStore) restore(t *Task) {
 	snap := t.Snapshot()
 	rs.mu.Lock()
 	rs.tasks[snap.ID] = snap
@@ -95,19 +132,65 @@ func (rs *ResultStore) List(agentID string, states []TaskState) []*Task {
 
 // Delete removes a task from the store.
 func (rs *ResultStore) Delete(taskID string) {
+	rs.writeMu.Lock()
+	defer rs.writeMu.Unlock()
 	rs.mu.Lock()
 	delete(rs.tasks, taskID)
+	j := rs.journal
 	rs.mu.Unlock()
+	if j != nil {
+		if err := j.Remove(taskID); err != nil {
+			slog.Warn("scheduler journal: remove failed", "task", taskID, "err", err)
+		}
+	}
+}
+
+// compact rewrites the attached journal from the current task set.
+func (rs *ResultStore) compact() error {
+	rs.writeMu.Lock()
+	defer rs.writeMu.Unlock()
+	j, tasks := rs.journalTasks()
+	if j == nil {
+		return nil
+	}
+	return j.Compact(tasks)
+}
+
+// journalTasks returns the attached journal and the task set to compact it
+// from. The caller holds writeMu, so the set cannot change before the
+// journal is rewritten.
+func (rs *ResultStore) journalTasks() (*Journal, []*Task) {
+	rs.mu.RLock()
+	defer rs.mu.RUnlock()
+	if rs.journal == nil {
+		return nil, nil
+	}
+	tasks := make([]*Task, 0, len(rs.tasks))
+	for _, t := range rs.tasks {
+		tasks = append(tasks, t)
+	}
+	return rs.journal, tasks
 }
 
 func (rs *ResultStore) evict() {
-	rs.mu.Lock()
-	defer rs.mu.Unlock()
+	rs.writeMu.Lock()
+	defer rs.writeMu.Unlock()
 
+	rs.mu.Lock()
 	cutoff := timeNow().Add(-rs.ttl)
 	for id, t := range rs.tasks {
 		if t.State.IsTerminal() && !t.CompletedAt.IsZero() && t.CompletedAt.Before(cutoff) {
 			delete(rs.tasks, id)
 		}
 	}
+	rs.mu.Unlock()
+
+	// Evictions are folded into the periodic compaction rather than logged
+	// one by one; a replay drops expired results on its own.
+	j, tasks := rs.journalTasks()
+	if j != nil && j.needsCompaction() {
+		if err := j.Compact(tasks); err != nil {
+			slog.Warn("scheduler journal: compaction failed", "err", err)
+		}
+	}
 }
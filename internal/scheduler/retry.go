@@ -0,0 +1,271 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"time"
+)
+
+// Error classes a retry policy can list in RetryOn.
+const (
+	RetryOnServerError = "server_error"     // executor answered 5xx
+	RetryOnTabLocked   = "tab_locked"       // executor answered 423
+	RetryOnDraining    = "browser_draining" // browser restart in progress
+	RetryOnNetwork     = "network"          // executor unreachable
+)
+
+// maxRetryAttempts bounds the attempts a single task may request.
+const maxRetryAttempts = 10
+
+// RetryPolicy controls how a task is retried after a transient failure.
+// Zero fields fall back to the scheduler default. The delay before attempt
+// n+1 is BackoffMs * 2^(n-1), capped at MaxBackoffMs.
+type RetryPolicy struct {
+	MaxAttempts  int      `json:"maxAttempts,omitempty"`
+	BackoffMs    int      `json:"backoffMs,omitempty"`
+	MaxBackoffMs int      `json:"maxBackoffMs,omitempty"`
+	RetryOn      []string `json:"retryOn,omitempty"`
+}
+
+// DefaultRetryPolicy returns the built-in policy: a single attempt, so tasks
+// are not retried unless the config or the task asks for it.
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts:  1,
+		BackoffMs:    500,
+		MaxBackoffMs: 30000,
+		RetryOn:      []string{RetryOnServerError, RetryOnTabLocked, RetryOnDraining, RetryOnNetwork},
+	}
+}
+
+// Validate checks attempt and backoff bounds and the error class names.
+func (p *RetryPolicy) Validate() error {
+	if p.MaxAttempts < 0 || p.MaxAttempts > maxRetryAttempts {
+		return fmt.Errorf("maxAttempts must be between 0 (default) and %d", maxRetryAttempts)
+	}
+	if p.BackoffMs < 0 || p.MaxBackoffMs < 0 {
+		return fmt.Errorf("backoff must not be negative")
+	}
+	for _, class := range p.RetryOn {
+		if !isRetryClass(class) {
+			return fmt.Errorf("unknown retryOn class %q", class)
+		}
+	}
+	return nil
+}
+
+func isRetryClass(class string) bool {
+	switch class {
+	case RetryOnServerError, RetryOnTabLocked, RetryOnDraining, RetryOnNetwork:
+		return true
+	}
+	return false
+}
+
+// merge returns p with zero fields filled from def.
+func (p RetryPolicy) merge(def RetryPolicy) RetryPolicy {
+	if p.MaxAttempts == 0 {
+		p.MaxAttempts = def.MaxAttempts
+	}
+	if p.BackoffMs == 0 {
+		p.BackoffMs = def.BackoffMs
+	}
+	if p.MaxBackoffMs == 0 {
+		p.MaxBackoffMs = def.MaxBackoffMs
+	}
+	if len(p.RetryOn) == 0 {
+		p.RetryOn = def.RetryOn
+	}
+	return p
+}
+
+// backoff returns the delay before the attempt that follows attempt n.
+func (p RetryPolicy) backoff(n int) time.Duration {
+	d := time.Duration(p.BackoffMs) * time.Millisecond
+	limit := time.Duration(p.MaxBackoffMs) * time.Millisecond
+	for i := 1; i < n && d < limit; i++ {
+		d *= 2
+	}
+	if limit > 0 && d > limit {
+		d = limit
+	}
+	return d
+}
+
+// TaskAttempt records one execution of a task.
+type TaskAttempt struct {
+	Attempt    int       `json:"attempt"`
+	StartedAt  time.Time `json:"startedAt"`
+	EndedAt    time.Time `json:"endedAt"`
+	Error      string    `json:"error,omitempty"`
+	ErrorClass string    `json:"errorClass,omitempty"`
+	RetryAt    time.Time `json:"retryAt,omitempty"`
+}
+
+// executorError is a failed call to the executor. Status is zero when the
+// request never got a response.
+type executorError struct {
+	Status int
+	Code   string
+	Body   string
+	Err    error
+}
+
+func (e *executorError) Error() string {
+	if e.Status == 0 {
+		return fmt.Sprintf("executor request failed: %v", e.Err)
+	}
+	return fmt.Sprintf("executor returned %d: %s", e.Status, e.Body)
+}
+
+func (e *executorError) Unwrap() error { return e.Err }
+
+// classifyError maps an execution error to a retry class, or "" when the
+// error is not transient. Errors caused by the task's own context (deadline
+// or cancellation) are never retried.
+func classifyError(ctx context.Context, err error) string {
+	if err == nil || ctx.Err() != nil {
+		return ""
+	}
+	if errors.Is(err, errNoTabAvailable) {
+		return RetryOnTabLocked
+	}
+	var ee *executorError
+	if !errors.As(err, &ee) {
+		return ""
+	}
+	switch {
+	case ee.Status == 0:
+		return RetryOnNetwork
+	case ee.Code == "browser_draining":
+		return RetryOnDraining
+	case ee.Status == 423:
+		return RetryOnTabLocked
+	case ee.Status >= 500:
+		return RetryOnServerError
+	}
+	return ""
+}
+
+// retryPolicy resolves the effective policy for t.
+func (s *Scheduler) retryPolicy(t *Task) RetryPolicy {
+	s.cfgMu.RLock()
+	def := s.cfg.Retry.merge(DefaultRetryPolicy())
+	s.cfgMu.RUnlock()
+	if t.Retry == nil {
+		return def
+	}
+	return t.Retry.merge(def)
+}
+
+// scheduleRetry puts a task whose attempt just failed back on the queue
+// after its backoff. It returns false when the failure is not retryable,
+// the attempts are used up, or the retry would start past the deadline;
+// the caller then fails the task as usual.
+func (s *Scheduler) scheduleRetry(ctx context.Context, t *Task, execErr error, started time.Time) bool {
+	class := classifyError(ctx, execErr)
+	policy := s.retryPolicy(t)
+	now := timeNow()
+	attempt := TaskAttempt{
+		Attempt:    len(t.Attempts) + 1,
+		StartedAt:  started,
+		EndedAt:    now,
+		Error:      execErr.Error(),
+		ErrorClass: class,
+	}
+
+	retryable := class != "" && slices.Contains(policy.RetryOn, class)
+	delay := policy.backoff(attempt.Attempt)
+	if !retryable || attempt.Attempt >= policy.MaxAttempts || !now.Add(delay).Before(t.Deadline) {
+		t.recordAttempt(attempt)
+		if retryable && attempt.Attempt > 1 {
+			s.metrics.recordRetryExhausted()
+		}
+		return false
+	}
+
+	attempt.RetryAt = now.Add(delay)
+	t.recordAttempt(attempt)
+	if err := t.SetState(StateQueued); err != nil {
+		slog.Warn("task retry transition failed", "task", t.ID, "err", err)
+		return false
+	}
+	s.queue.Complete(t.AgentID)
+	s.results.Store(t)
+	s.metrics.recordRetry(t.AgentID)
+	slog.Info("task attempt failed, retrying", "task", t.ID, "agent", t.AgentID, "attempt", attempt.Attempt, "class", class, "delay", delay, "err", execErr)
+	s.emit(t)
+
+	s.backoffMu.Lock()
+	s.backoff[t.ID] = time.AfterFunc(delay, func() { s.requeue(t) })
+	s.backoffMu.Unlock()
+	return true
+}
+
+// requeue moves a task out of backoff and back onto the queue. The timer
+// lookup, state check and enqueue happen under backoffMu, which Cancel also
+// holds while it dequeues, so a task cancelled during its backoff is never
+// put back on the queue.
+func (s *Scheduler) requeue(t *Task) {
+	s.backoffMu.Lock()
+	_, waiting := s.backoff[t.ID]
+	delete(s.backoff, t.ID)
+	if !waiting || t.GetState() != StateQueued {
+		s.backoffMu.Unlock()
+		return
+	}
+	pos, err := s.queue.Enqueue(t)
+	failed := false
+	if err != nil {
+		t.Error = fmt.Sprintf("could not requeue for retry: %v", err)
+		failed = t.SetState(StateFailed) == nil
+	}
+	s.backoffMu.Unlock()
+
+	if err != nil {
+		if failed {
+			s.metrics.recordFail(t.AgentID)
+			slog.Warn("task retry rejected", "task", t.ID, "agent", t.AgentID, "err", err)
+			s.retire(t)
+		}
+		return
+	}
+	t.Position = pos
+	s.results.Store(t)
+}
+
+// dequeue takes a queued task off the queue, out of the held set and out of
+// backoff. It reports whether the task was held or backing off, and so
+// outside the queue's slot accounting.
+func (s *Scheduler) dequeue(t *Task) bool {
+	s.backoffMu.Lock()
+	defer s.backoffMu.Unlock()
+	s.queue.Remove(t.ID, t.AgentID)
+	s.heldMu.Lock()
+	_, parked := s.held[t.ID]
+	delete(s.held, t.ID)
+	s.heldMu.Unlock()
+	if parked {
+		s.queue.Unreserve(t.AgentID)
+	}
+	if timer, ok := s.backoff[t.ID]; ok {
+		timer.Stop()
+		delete(s.backoff, t.ID)
+		parked = true
+	}
+	return parked
+}
+
+// stopRetries stops every pending retry timer. Tasks stay queued, so a
+// journal replay picks them up again.
+func (s *Scheduler) stopRetries() {
+	s.backoffMu.Lock()
+	defer s.backoffMu.Unlock()
+	for id, timer := range s.backoff {
+		timer.Stop()
+		delete(s.backoff, id)
+	}
+}
@@ -0,0 +1,232 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestClassifyError(t *testing.T) {
+	ctx := context.Background()
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{"network", &executorError{Err: errors.New("connection refused")}, RetryOnNetwork},
+		{"locked", &executorError{Status: 423, Code: "tab_locked"}, RetryOnTabLocked},
+		{"draining", &executorError{Status: 503, Code: "browser_draining"}, RetryOnDraining},
+		{"server error", &executorError{Status: 502}, RetryOnServerError},
+		{"client error", &executorError{Status: 400}, ""},
+		{"wrapped step error", errors.Join(errors.New("step 0 (click)"), &executorError{Status: 500}), RetryOnServerError},
+		{"other", errors.New("tabId is required"), ""},
+	}
+	for _, tt := range tests {
+		if got := classifyError(ctx, tt.err); got != tt.want {
+			t.Errorf("%s: got %q, want %q", tt.name, got, tt.want)
+		}
+	}
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	if got := classifyError(cancelled, &executorError{Err: context.Canceled}); got != "" {
+		t.Errorf("errors after cancellation should not be retryable, got %q", got)
+	}
+}
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	p := RetryPolicy{BackoffMs: 100, MaxBackoffMs: 350}
+	want := []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 350 * time.Millisecond, 350 * time.Millisecond}
+	for i, w := range want {
+		if got := p.backoff(i + 1); got != w {
+			t.Errorf("attempt %d: got %s, want %s", i+1, got, w)
+		}
+	}
+
+	merged := RetryPolicy{MaxAttempts: 3}.merge(DefaultRetryPolicy())
+	if merged.MaxAttempts != 3 || merged.BackoffMs != 500 || len(merged.RetryOn) != 4 {
+		t.Errorf("unexpected merged policy: %+v", merged)
+	}
+}
+
+func TestSubmitRequestValidateRetry(t *testing.T) {
+	bad := []*RetryPolicy{
+		{MaxAttempts: 11},
+		{BackoffMs: -1},
+		{RetryOn: []string{"timeout"}},
+	}
+	for _, p := range bad {
+		req := SubmitRequest{AgentID: "a1", Action: "click", Retry: p}
+		if err := req.Validate(); err == nil {
+			t.Errorf("expected error for %+v", p)
+		}
+	}
+}
+
+func newFlakyExecutor(t *testing.T, failures int32, status int, body string) (*httptest.Server, string, *atomic.Int32) {
+	t.Helper()
+	var calls atomic.Int32
+	executor := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if calls.Add(1) <= failures {
+			w.WriteHeader(status)
+			_, _ = w.Write([]byte(body))
+			return
+		}
+		_, _ = w.Write([]byte(`{"success":true}`))
+	}))
+	parts := strings.Split(executor.URL, ":")
+	return executor, parts[len(parts)-1], &calls
+}
+
+func waitQueued(t *testing.T, s *Scheduler) *Task {
+	t.Helper()
+	limit := time.Now().Add(2 * time.Second)
+	for time.Now().Before(limit) {
+		if task := s.queue.Dequeue(s.inflightLimits()); task != nil {
+			return task
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatal("task was not requeued")
+	return nil
+}
+
+func TestRetryRequeuesTransientFailure(t *testing.T) {
+	executor, port, calls := newFlakyExecutor(t, 1, 423, `{"code":"tab_locked","error":"tab is locked"}`)
+	defer executor.Close()
+
+	s := New(DefaultConfig(), &mockResolver{port: port})
+	task, err := s.Submit(SubmitRequest{
+		AgentID: "a1",
+		Action:  "click",
+		TabID:   "tab-1",
+		Retry:   &RetryPolicy{MaxAttempts: 3, BackoffMs: 1},
+	})
+	if err != nil {
+		t.Fatalf("submit failed: %v", err)
+	}
+
+	s.dispatch(waitQueued(t, s))
+	snap := task.Snapshot()
+	if snap.State != StateQueued {
+		t.Fatalf("expected task back in queued, got %s", snap.State)
+	}
+	if len(snap.Attempts) != 1 || snap.Attempts[0].ErrorClass != RetryOnTabLocked || snap.Attempts[0].RetryAt.IsZero() {
+		t.Errorf("unexpected attempt history: %+v", snap.Attempts)
+	}
+
+	s.dispatch(waitQueued(t, s))
+	snap = task.Snapshot()
+	if snap.State != StateDone {
+		t.Fatalf("expected done after retry, got %s (%s)", snap.State, snap.Error)
+	}
+	if len(snap.Attempts) != 2 || snap.Attempts[1].Error != "" {
+		t.Errorf("expected a failed then a successful attempt, got %+v", snap.Attempts)
+	}
+	if calls.Load() != 2 {
+		t.Errorf("expected 2 executor calls, got %d", calls.Load())
+	}
+
+	m := s.GetMetrics()
+	if m.TasksRetried != 1 || m.Agents["a1"].Retried != 1 || m.TasksCompleted != 1 {
+		t.Errorf("unexpected metrics: %+v", m)
+	}
+}
+
+func TestRetrySkipsNonRetryableAndExhausted(t *testing.T) {
+	executor, port, _ := newFlakyExecutor(t, 10, 500, `{"error":"boom"}`)
+	defer executor.Close()
+
+	s := New(DefaultConfig(), &mockResolver{port: port})
+
+	// The default policy makes a single attempt.
+	task, _ := s.Submit(SubmitRequest{AgentID: "a1", Action: "click", TabID: "tab-1"})
+	s.dispatch(waitQueued(t, s))
+	if task.GetState() != StateFailed || len(task.Attempts) != 1 {
+		t.Fatalf("expected a single failed attempt, got %s with %d attempts", task.GetState(), len(task.Attempts))
+	}
+
+	// Server errors excluded from retryOn fail straight away.
+	task, _ = s.Submit(SubmitRequest{
+		AgentID: "a1", Action: "click", TabID: "tab-1",
+		Retry: &RetryPolicy{MaxAttempts: 3, BackoffMs: 1, RetryOn: []string{RetryOnTabLocked}},
+	})
+	s.dispatch(waitQueued(t, s))
+	if task.GetState() != StateFailed {
+		t.Fatalf("expected failed, got %s", task.GetState())
+	}
+
+	task, _ = s.Submit(SubmitRequest{
+		AgentID: "a1", Action: "click", TabID: "tab-1",
+		Retry: &RetryPolicy{MaxAttempts: 2, BackoffMs: 1},
+	})
+	s.dispatch(waitQueued(t, s))
+	s.dispatch(waitQueued(t, s))
+	if task.GetState() != StateFailed || len(task.Attempts) != 2 {
+		t.Fatalf("expected failure after 2 attempts, got %s with %d attempts", task.GetState(), len(task.Attempts))
+	}
+	if got := s.GetMetrics().RetryExhausted; got != 1 {
+		t.Errorf("expected 1 exhausted retry, got %d", got)
+	}
+}
+
+func TestCancelDuringBackoff(t *testing.T) {
+	executor, port, calls := newFlakyExecutor(t, 10, 502, `{"error":"bad gateway"}`)
+	defer executor.Close()
+
+	s := New(DefaultConfig(), &mockResolver{port: port})
+	task, _ := s.Submit(SubmitRequest{
+		AgentID: "a1", Action: "click", TabID: "tab-1",
+		Retry: &RetryPolicy{MaxAttempts: 3, BackoffMs: 50},
+	})
+	s.dispatch(waitQueued(t, s))
+
+	if err := s.Cancel(task.ID); err != nil {
+		t.Fatalf("cancel failed: %v", err)
+	}
+	time.Sleep(100 * time.Millisecond)
+	if task.GetState() != StateCancelled {
+		t.Errorf("expected cancelled, got %s", task.GetState())
+	}
+	if s.QueueStats().TotalQueued != 0 || calls.Load() != 1 {
+		t.Errorf("cancelled task should not be retried")
+	}
+}
+
+func TestCancelRacesBackoffExpiry(t *testing.T) {
+	executor, port, _ := newFlakyExecutor(t, 1000, 502, `{"error":"bad gateway"}`)
+	defer executor.Close()
+
+	s := New(DefaultConfig(), &mockResolver{port: port})
+	for i := 0; i < 50; i++ {
+		task, err := s.Submit(SubmitRequest{
+			AgentID: "a1", Action: "click", TabID: "tab-1",
+			Retry: &RetryPolicy{MaxAttempts: 3, BackoffMs: 60000},
+		})
+		if err != nil {
+			t.Fatalf("submit failed: %v", err)
+		}
+		s.dispatch(waitQueued(t, s))
+
+		// Expire the backoff by hand while the task is being cancelled.
+		var wg sync.WaitGroup
+		wg.Add(2)
+		go func() { defer wg.Done(); s.requeue(task) }()
+		go func() { defer wg.Done(); _ = s.Cancel(task.ID) }()
+		wg.Wait()
+
+		if task.GetState() != StateCancelled {
+			t.Fatalf("expected cancelled, got %s", task.GetState())
+		}
+		if n := s.QueueStats().TotalQueued; n != 0 {
+			t.Fatalf("cancelled task left on the queue (%d queued)", n)
+		}
+	}
+}
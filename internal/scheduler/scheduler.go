@@ -10,6 +10,7 @@ import (
 	"net"
 	"net/http"
 	"net/url"
+	"path/filepath"
 	"sync"
 	"time"
 
@@ -33,20 +34,46 @@ type Config struct {
 	WorkerCount       int           `json:"workerCount"`
 	MaxBatchSize      int           `json:"maxBatchSize"`
 	WatcherInterval   time.Duration `json:"watcherInterval"`
+
+	// JournalPath enables the durable task journal when non-empty.
+	JournalPath string `json:"journalPath,omitempty"`
+	// RecoverRunning decides what happens on replay to tasks that were
+	// assigned or running at shutdown: RecoverFail or RecoverRequeue.
+	RecoverRunning string `json:"recoverRunning,omitempty"`
+	// Retry is the default retry policy for tasks that do not set their own.
+	Retry RetryPolicy `json:"retry"`
+
+	// WebhookSecret signs callback payloads with HMAC-SHA256 when set.
+	WebhookSecret string `json:"-"`
+	// WebhookMaxAttempts and WebhookBackoff control callback redelivery.
+	WebhookMaxAttempts int           `json:"webhookMaxAttempts"`
+	WebhookBackoff     time.Duration `json:"webhookBackoff"`
+
+	// MaxTabs is the per-instance tab limit the scheduler respects when it
+	// opens tabs for targeted tasks. Zero means no limit.
+	MaxTabs int `json:"maxTabs,omitempty"`
+
+	// AgentGroups assigns fair-share weights, rate limits, and daily quotas
+	// to groups of agents, keyed by group name.
+	AgentGroups map[string]AgentPolicy `json:"agentGroups,omitempty"`
 }
 
 // DefaultConfig returns safe defaults.
 func DefaultConfig() Config {
 	return Config{
-		Strategy:          "fair-fifo",
-		MaxQueueSize:      1000,
-		MaxPerAgent:       100,
-		MaxInflight:       20,
-		MaxPerAgentFlight: 10,
-		ResultTTL:         5 * time.Minute,
-		WorkerCount:       4,
-		MaxBatchSize:      50,
-		WatcherInterval:   30 * time.Second,
+		Strategy:           "fair-fifo",
+		MaxQueueSize:       1000,
+		MaxPerAgent:        100,
+		MaxInflight:        20,
+		MaxPerAgentFlight:  10,
+		ResultTTL:          5 * time.Minute,
+		WorkerCount:        4,
+		MaxBatchSize:       50,
+		WatcherInterval:    30 * time.Second,
+		RecoverRunning:     RecoverFail,
+		Retry:              DefaultRetryPolicy(),
+		WebhookMaxAttempts: 3,
+		WebhookBackoff:     time.Second,
 	}
 }
 
@@ -60,12 +87,38 @@ type Scheduler struct {
 	client   *http.Client
 	metrics  *Metrics
 
+	// journal persists task snapshots when cfg.JournalPath is set; recovered
+	// holds the replayed tasks until Start puts them back into service.
+	journal   *Journal
+	recovered []*Task
+
+	recurring *recurringSet
+	policies  *policySet
+
+	// held tracks workflow tasks waiting for their dependencies.
+	held   map[string]*Task
+	heldMu sync.Mutex
+
+	events *eventHub
+
+	// leased maps the tabs that targeted tasks are running on, or are being
+	// locked for, to their instance; opening counts the tabs being opened
+	// per instance.
+	leased  map[string]string
+	opening map[string]int
+	tabsMu  sync.Mutex
+
+	// backoff holds retry timers for tasks waiting before their next attempt.
+	backoff   map[string]*time.Timer
+	backoffMu sync.Mutex
+
 	// tracks all live tasks (queued + in-flight) for lookup by ID.
 	live   map[string]*Task
 	liveMu sync.RWMutex
 
 	// webhookSem bounds the number of concurrent webhook delivery goroutines.
 	webhookSem chan struct{}
+	webhooks   *webhookSender
 
 	// cancellation
 	cancels   map[string]context.CancelFunc
@@ -102,8 +155,17 @@ func New(cfg Config, resolver InstanceResolver) *Scheduler {
 	if cfg.WatcherInterval <= 0 {
 		cfg.WatcherInterval = 30 * time.Second
 	}
+	if cfg.RecoverRunning != RecoverRequeue {
+		cfg.RecoverRunning = RecoverFail
+	}
+	if cfg.WebhookMaxAttempts <= 0 {
+		cfg.WebhookMaxAttempts = 3
+	}
+	if cfg.WebhookBackoff <= 0 {
+		cfg.WebhookBackoff = time.Second
+	}
 
-	return &Scheduler{
+	s := &Scheduler{
 		cfg:        cfg,
 		queue:      NewTaskQueue(cfg.MaxQueueSize, cfg.MaxPerAgent),
 		results:    NewResultStore(cfg.ResultTTL),
@@ -111,14 +173,54 @@ func New(cfg Config, resolver InstanceResolver) *Scheduler {
 		client:     &http.Client{Timeout: 60 * time.Second},
 		metrics:    newMetrics(),
 		live:       make(map[string]*Task),
+		held:       make(map[string]*Task),
+		backoff:    make(map[string]*time.Timer),
+		events:     newEventHub(),
+		leased:     make(map[string]string),
+		opening:    make(map[string]int),
 		cancels:    make(map[string]context.CancelFunc),
 		stopCh:     make(chan struct{}),
 		webhookSem: make(chan struct{}, 16),
 	}
+
+	recurringPath, deadLetterPath, quotaPath := "", "", ""
+	if cfg.JournalPath != "" {
+		recurringPath = filepath.Join(filepath.Dir(cfg.JournalPath), "recurring.json")
+		deadLetterPath = filepath.Join(filepath.Dir(cfg.JournalPath), "webhook-dead-letters.json")
+		quotaPath = filepath.Join(filepath.Dir(cfg.JournalPath), "agent-quotas.json")
+	}
+	s.recurring = newRecurringSet(recurringPath)
+	s.policies = newPolicySet(cfg.AgentGroups, quotaPath)
+	s.queue.SetWeights(s.policies.weight)
+	s.webhooks = &webhookSender{
+		secret: cfg.WebhookSecret,
+		retry: RetryPolicy{
+			MaxAttempts:  cfg.WebhookMaxAttempts,
+			BackoffMs:    int(cfg.WebhookBackoff / time.Millisecond),
+			MaxBackoffMs: DefaultRetryPolicy().MaxBackoffMs,
+		},
+		dead:    newDeadLetterStore(deadLetterPath),
+		stopCh:  s.stopCh,
+		metrics: s.metrics,
+	}
+
+	if cfg.JournalPath != "" {
+		journal, tasks, err := OpenJournal(cfg.JournalPath)
+		if err != nil {
+			slog.Error("scheduler journal unavailable, tasks will not survive restart", "path", cfg.JournalPath, "err", err)
+		} else {
+			s.journal = journal
+			s.recovered = tasks
+			s.results.SetJournal(journal)
+		}
+	}
+	return s
 }
 
-// Start launches workers and the deadline reaper.
+// Start replays any journaled tasks, then launches workers and the
+// deadline reaper.
 func (s *Scheduler) Start() {
+	s.recover()
 	s.results.StartReaper(10 * time.Second)
 
 	for i := range s.cfg.WorkerCount {
@@ -129,20 +231,27 @@ func (s *Scheduler) Start() {
 	s.wg.Add(1)
 	go s.deadlineReaper()
 
+	s.wg.Add(1)
+	go s.recurringLoop()
+
 	slog.Info("scheduler started", "workers", s.cfg.WorkerCount, "strategy", s.cfg.Strategy)
 }
 
-// Stop gracefully shuts down the scheduler. Queued tasks are cancelled.
+// Stop gracefully shuts down the scheduler. Queued tasks are cancelled,
+// unless a journal is configured, in which case they stay queued on disk and
+// are replayed by the next Start.
 func (s *Scheduler) Stop() {
 	s.stopOnce.Do(func() {
 		slog.Info("scheduler stopping")
 		close(s.stopCh)
 		s.wg.Wait()
+		s.stopRetries()
 		s.results.Stop()
+		s.events.closeAll()
 
 		s.liveMu.Lock()
 		for id, t := range s.live {
-			if !t.GetState().IsTerminal() {
+			if s.journal == nil && !t.GetState().IsTerminal() {
 				_ = t.SetState(StateCancelled)
 				t.Error = "scheduler shutdown"
 				s.results.Store(t)
@@ -151,12 +260,24 @@ func (s *Scheduler) Stop() {
 		}
 		s.liveMu.Unlock()
 
+		if s.journal != nil {
+			if err := s.journal.Close(); err != nil {
+				slog.Warn("scheduler journal: close failed", "err", err)
+			}
+		}
+
 		slog.Info("scheduler stopped")
 	})
 }
 
 // Submit creates a new task from the request and enqueues it.
 func (s *Scheduler) Submit(req SubmitRequest) (*Task, error) {
+	return s.submit(req, nil)
+}
+
+// submit is Submit with an optional hook that can annotate the task (for
+// example with the recurring template it came from) before it is queued.
+func (s *Scheduler) submit(req SubmitRequest, decorate func(*Task)) (*Task, error) {
 	if err := req.Validate(); err != nil {
 		return nil, fmt.Errorf("invalid task: %w", err)
 	}
@@ -174,28 +295,60 @@ func (s *Scheduler) Submit(req SubmitRequest) (*Task, error) {
 		deadline = parsed
 	}
 
+	action := req.Action
+	if len(req.Steps) > 0 {
+		action = StepsAction
+	}
+
 	t := &Task{
 		ID:          generateTaskID(),
 		AgentID:     req.AgentID,
-		Action:      req.Action,
+		Action:      action,
 		TabID:       req.TabID,
 		Ref:         req.Ref,
 		Params:      req.Params,
+		Steps:       req.Steps,
+		StopOnError: req.StopOnError,
 		Priority:    req.Priority,
 		State:       StateQueued,
 		Deadline:    deadline,
 		CreatedAt:   now,
 		CallbackURL: req.CallbackURL,
+		Retry:       req.Retry,
+		Target:      req.Target,
+	}
+	if decorate != nil {
+		decorate(t)
+	}
+
+	if err := s.policies.admit(t.AgentID); err != nil {
+		return s.reject(t, err)
+	}
+
+	if len(t.DependsOn) > 0 {
+		// The default deadline starts counting when the task is released.
+		if req.Deadline == "" {
+			t.Deadline = time.Time{}
+		}
+		if err := s.hold(t); err != nil {
+			s.policies.refund(t.AgentID)
+			return s.reject(t, err)
+		}
+		s.liveMu.Lock()
+		s.live[t.ID] = t
+		s.liveMu.Unlock()
+		s.results.Store(t)
+		s.metrics.recordSubmit(req.AgentID)
+		slog.Info("task submitted, waiting on dependencies", "task", t.ID, "agent", req.AgentID, "workflow", t.WorkflowID, "dependsOn", t.DependsOn)
+		s.emit(t)
+		s.reviewHeld(t)
+		return t, nil
 	}
 
 	pos, err := s.queue.Enqueue(t)
 	if err != nil {
-		t.State = StateRejected
-		t.Error = err.Error()
-		s.results.Store(t)
-		s.metrics.recordReject(req.AgentID)
-		slog.Warn("task rejected", "task", t.ID, "agent", req.AgentID, "err", err)
-		return t, fmt.Errorf("rejected: %w", err)
+		s.policies.refund(t.AgentID)
+		return s.reject(t, err)
 	}
 
 	t.Position = pos
@@ -207,9 +360,21 @@ func (s *Scheduler) Submit(req SubmitRequest) (*Task, error) {
 	s.results.Store(t)
 	s.metrics.recordSubmit(req.AgentID)
 	slog.Info("task submitted", "task", t.ID, "agent", req.AgentID, "action", t.Action, "priority", t.Priority, "position", pos)
+	s.emit(t)
 	return t, nil
 }
 
+// reject records a task refused at admission.
+func (s *Scheduler) reject(t *Task, err error) (*Task, error) {
+	t.State = StateRejected
+	t.Error = err.Error()
+	s.results.Store(t)
+	s.metrics.recordReject(t.AgentID)
+	slog.Warn("task rejected", "task", t.ID, "agent", t.AgentID, "err", err)
+	s.emit(t)
+	return t, fmt.Errorf("rejected: %w", err)
+}
+
 // GetTask retrieves a task by ID from live or completed results.
 func (s *Scheduler) GetTask(taskID string) *Task {
 	s.liveMu.RLock()
@@ -243,8 +408,10 @@ func (s *Scheduler) Cancel(taskID string) error {
 		return fmt.Errorf("task %q already in terminal state %q", taskID, state)
 	}
 
+	// Held and backing-off tasks are queued but outside the queue.
+	parked := false
 	if state == StateQueued {
-		s.queue.Remove(t.ID, t.AgentID)
+		parked = s.dequeue(t)
 	}
 
 	s.cancelsMu.Lock()
@@ -260,7 +427,11 @@ func (s *Scheduler) Cancel(taskID string) error {
 	s.metrics.recordCancel(t.AgentID)
 	slog.Info("task cancelled", "task", t.ID, "agent", t.AgentID, "previousState", state)
 
-	s.finishTask(t)
+	if parked {
+		s.retire(t)
+	} else {
+		s.finishTask(t)
+	}
 	return nil
 }
 
@@ -320,6 +491,7 @@ func (s *Scheduler) dispatch(t *Task) {
 	}
 	slog.Info("task assigned", "task", t.ID, "agent", t.AgentID, "action", t.Action)
 	s.results.Store(t)
+	s.emit(t)
 
 	ctx, cancel := context.WithDeadline(context.Background(), t.Deadline)
 
@@ -341,20 +513,29 @@ func (s *Scheduler) dispatch(t *Task) {
 	}
 	slog.Info("task running", "task", t.ID, "agent", t.AgentID)
 	s.results.Store(t)
+	s.emit(t)
 
 	result, execErr := s.executeTask(ctx, t)
 
 	latency := timeNow().Sub(dispatchStart)
 	s.metrics.recordDispatchLatency(latency)
 
+	if execErr != nil && s.scheduleRetry(ctx, t, execErr, dispatchStart) {
+		return
+	}
+
 	if execErr != nil {
 		t.Error = execErr.Error()
+		if result != nil {
+			t.Result = result
+		}
 		if stateErr := t.SetState(StateFailed); stateErr != nil {
 			slog.Warn("failed to mark task as failed", "task", t.ID, "err", stateErr)
 		}
 		s.metrics.recordFail(t.AgentID)
 		slog.Info("task failed", "task", t.ID, "agent", t.AgentID, "err", execErr, "latencyMs", latency.Milliseconds())
 	} else {
+		t.recordAttempt(TaskAttempt{Attempt: len(t.Attempts) + 1, StartedAt: dispatchStart, EndedAt: timeNow()})
 		t.Result = result
 		if stateErr := t.SetState(StateDone); stateErr != nil {
 			slog.Warn("failed to mark task as done", "task", t.ID, "err", stateErr)
@@ -367,13 +548,28 @@ func (s *Scheduler) dispatch(t *Task) {
 }
 
 func (s *Scheduler) executeTask(ctx context.Context, t *Task) (any, error) {
-	if t.TabID == "" {
-		return nil, fmt.Errorf("tabId is required for task execution")
+	var port string
+	if t.Target != nil {
+		lease, err := s.acquireTab(ctx, t)
+		if err != nil {
+			return nil, err
+		}
+		defer s.releaseTab(t, lease)
+		t.setTab(lease)
+		port = lease.port
+	} else {
+		if t.TabID == "" {
+			return nil, fmt.Errorf("tabId is required for task execution")
+		}
+		var err error
+		port, err = s.resolver.ResolveTabInstance(t.TabID)
+		if err != nil {
+			return nil, fmt.Errorf("could not resolve tab %q: %w", t.TabID, err)
+		}
 	}
 
-	port, err := s.resolver.ResolveTabInstance(t.TabID)
-	if err != nil {
-		return nil, fmt.Errorf("could not resolve tab %q: %w", t.TabID, err)
+	if len(t.Steps) > 0 {
+		return s.executeSteps(ctx, t, port)
 	}
 
 	// Build the request body matching the immediate-path action format.
@@ -387,31 +583,47 @@ func (s *Scheduler) executeTask(ctx context.Context, t *Task) (any, error) {
 		body[k] = v
 	}
 
-	payload, err := json.Marshal(body)
-	if err != nil {
-		return nil, fmt.Errorf("failed to encode task body: %w", err)
+	return s.callTab(ctx, t, port, http.MethodPost, fmt.Sprintf("/tabs/%s/action", t.TabID), nil, body)
+}
+
+// callTab sends one request to a tab route on the instance at port and
+// decodes the JSON response. Non-JSON responses are returned as a string.
+func (s *Scheduler) callTab(ctx context.Context, t *Task, port, method, path string, query url.Values, body map[string]any) (any, error) {
+	var reqBody io.Reader
+	if body != nil {
+		payload, err := json.Marshal(body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode task body: %w", err)
+		}
+		reqBody = bytes.NewReader(payload)
 	}
 
 	targetURL := &url.URL{
-		Scheme: "http",
-		Host:   net.JoinHostPort("localhost", port),
-		Path:   fmt.Sprintf("/tabs/%s/action", t.TabID),
+		Scheme:   "http",
+		Host:     net.JoinHostPort("localhost", port),
+		Path:     path,
+		RawQuery: query.Encode(),
 	}
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, targetURL.String(), bytes.NewReader(payload))
+	req, err := http.NewRequestWithContext(ctx, method, targetURL.String(), reqBody)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
-	req.Header.Set("Content-Type", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
 	req.Header.Set(activity.HeaderPTSource, "scheduler")
 	req.Header.Set(activity.HeaderPTTabID, t.TabID)
+	if t.leaseOwner != "" {
+		req.Header.Set("X-Owner", t.leaseOwner)
+	}
 	if t.AgentID != "" {
 		req.Header.Set(activity.HeaderAgentID, t.AgentID)
 	}
 
 	resp, err := s.client.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("executor request failed: %w", err)
+		return nil, &executorError{Err: err}
 	}
 	defer func() { _ = resp.Body.Close() }()
 
@@ -421,7 +633,11 @@ func (s *Scheduler) executeTask(ctx context.Context, t *Task) (any, error) {
 	}
 
 	if resp.StatusCode >= 400 {
-		return nil, fmt.Errorf("executor returned %d: %s", resp.StatusCode, string(respBody))
+		var payload struct {
+			Code string `json:"code"`
+		}
+		_ = json.Unmarshal(respBody, &payload)
+		return nil, &executorError{Status: resp.StatusCode, Code: payload.Code, Body: string(respBody)}
 	}
 
 	var result any
@@ -432,23 +648,45 @@ func (s *Scheduler) executeTask(ctx context.Context, t *Task) (any, error) {
 }
 
 func (s *Scheduler) finishTask(t *Task) {
-	s.results.Store(t)
 	s.queue.Complete(t.AgentID)
+	s.retire(t)
+}
+
+// retire stores a terminal task, drops it from the live set and wakes
+// anything waiting on it. Tasks that never reached the queue (held workflow
+// tasks) are retired directly so the agent's inflight count is untouched.
+func (s *Scheduler) retire(t *Task) {
+	s.results.Store(t)
 
 	s.liveMu.Lock()
 	delete(s.live, t.ID)
 	s.liveMu.Unlock()
 
-	// Fire webhook asynchronously if configured.
+	s.notify(t)
+	s.emit(t)
+
+	if t.WorkflowID != "" {
+		s.resolveDependents(t)
+	}
+}
+
+// notify fires the task's webhook asynchronously if one is configured and
+// the task has reached a terminal state.
+func (s *Scheduler) notify(t *Task) {
 	if t.CallbackURL != "" && t.GetState().IsTerminal() {
+		d := newWebhookDelivery(t.CallbackURL, t)
+		if d == nil {
+			return
+		}
 		select {
 		case s.webhookSem <- struct{}{}:
 			go func() {
 				defer func() { <-s.webhookSem }()
-				sendWebhook(t.CallbackURL, t)
+				s.webhooks.deliver(d)
 			}()
 		default:
-			slog.Warn("webhook: too many in-flight deliveries, dropping", "task", t.ID)
+			d.LastError = "too many in-flight deliveries"
+			s.webhooks.bury(d)
 		}
 	}
 }
@@ -465,7 +703,7 @@ func (s *Scheduler) deadlineReaper() {
 		case <-ticker.C:
 			expired := s.queue.ExpireDeadlined()
 			for _, t := range expired {
-				t.Error = "deadline exceeded while queued"
+				t.Error = errDeadlineQueued
 				if err := t.SetState(StateFailed); err != nil {
 					slog.Warn("deadline reaper state transition failed", "task", t.ID, "err", err)
 				}
@@ -477,3 +715,109 @@ func (s *Scheduler) deadlineReaper() {
 		}
 	}
 }
+
+// recover puts journaled tasks back into service. Terminal results are kept
+// until their TTL runs out, queued tasks go back on the queue, and tasks that
+// were assigned or running at shutdown are failed or requeued according to
+// cfg.RecoverRunning. The journal is compacted afterwards.
+func (s *Scheduler) recover() {
+	if s.journal == nil {
+		return
+	}
+	tasks := s.recovered
+	s.recovered = nil
+
+	now := timeNow()
+	s.cfgMu.RLock()
+	policy := s.cfg.RecoverRunning
+	s.cfgMu.RUnlock()
+	cutoff := now.Add(-s.results.getTTL())
+
+	var requeued, failed int
+	var held []*Task
+	for _, t := range tasks {
+		if t.State == StateAssigned || t.State == StateRunning {
+			if policy == RecoverRequeue {
+				t.State = StateQueued
+				t.StartedAt = time.Time{}
+			} else {
+				t.State = StateFailed
+				t.Error = "interrupted by scheduler restart"
+				t.CompletedAt = now
+				if !t.StartedAt.IsZero() {
+					t.LatencyMs = now.Sub(t.StartedAt).Milliseconds()
+				}
+				s.metrics.recordFail(t.AgentID)
+				s.results.restore(t)
+				s.notify(t)
+				failed++
+				continue
+			}
+		}
+
+		if t.State.IsTerminal() {
+			if !t.CompletedAt.IsZero() && t.CompletedAt.Before(cutoff) {
+				continue
+			}
+			s.results.restore(t)
+			continue
+		}
+
+		if len(t.DependsOn) > 0 {
+			if err := s.hold(t); err != nil {
+				t.Error = fmt.Sprintf("could not hold after restart: %v", err)
+				_ = t.SetState(StateFailed)
+				s.metrics.recordFail(t.AgentID)
+				s.results.restore(t)
+				s.notify(t)
+				failed++
+				continue
+			}
+			s.liveMu.Lock()
+			s.live[t.ID] = t
+			s.liveMu.Unlock()
+			s.results.restore(t)
+			held = append(held, t)
+			continue
+		}
+
+		if !t.Deadline.IsZero() && t.Deadline.Before(now) {
+			t.Error = errDeadlineQueued
+			_ = t.SetState(StateFailed)
+			s.metrics.recordExpire()
+			s.metrics.recordFail(t.AgentID)
+			s.results.restore(t)
+			s.notify(t)
+			failed++
+			continue
+		}
+
+		pos, err := s.queue.Enqueue(t)
+		if err != nil {
+			t.Error = fmt.Sprintf("could not requeue after restart: %v", err)
+			_ = t.SetState(StateFailed)
+			s.metrics.recordFail(t.AgentID)
+			s.results.restore(t)
+			s.notify(t)
+			failed++
+			continue
+		}
+		t.Position = pos
+		s.liveMu.Lock()
+		s.live[t.ID] = t
+		s.liveMu.Unlock()
+		s.results.restore(t)
+		requeued++
+	}
+
+	// Workflow tasks are re-evaluated once every task is back, since their
+	// dependencies may have finished, failed or been dropped meanwhile.
+	for _, t := range held {
+		s.reviewHeld(t)
+	}
+
+	if err := s.results.compact(); err != nil {
+		slog.Warn("scheduler journal: compaction failed", "err", err)
+	}
+	slog.Info("scheduler journal replayed", "path", s.cfg.JournalPath, "records", len(tasks), "requeued", requeued, "held", len(held), "failed", failed)
+}
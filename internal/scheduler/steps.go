@@ -0,0 +1,125 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// Step kinds that map to a tab route other than /action. Any other kind is
+// treated as an action kind and posted to /tabs/{id}/action, as in /macro.
+const (
+	StepNavigate = "navigate"
+	StepWait     = "wait"
+	StepSnapshot = "snapshot"
+	StepText     = "text"
+	StepFind     = "find"
+)
+
+// StepsAction is the task action recorded for multi-step tasks.
+const StepsAction = "macro"
+
+// maxTaskSteps bounds the number of steps a single task may carry.
+const maxTaskSteps = 50
+
+// TaskStep is one entry of a multi-step task. Params carries the request
+// fields of the route the step is sent to; for snapshot and text steps they
+// are sent as query parameters. Ref only applies to action steps.
+type TaskStep struct {
+	Kind   string         `json:"kind"`
+	Ref    string         `json:"ref,omitempty"`
+	Params map[string]any `json:"params,omitempty"`
+}
+
+// StepResult is the outcome of one step, mirroring the /macro result shape.
+type StepResult struct {
+	Index   int    `json:"index"`
+	Kind    string `json:"kind"`
+	Success bool   `json:"success"`
+	Result  any    `json:"result,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+func validateSteps(steps []TaskStep) error {
+	if len(steps) > maxTaskSteps {
+		return fmt.Errorf("too many steps (%d, max %d)", len(steps), maxTaskSteps)
+	}
+	for i, step := range steps {
+		if step.Kind == "" {
+			return fmt.Errorf("step %d: missing required field 'kind'", i)
+		}
+	}
+	return nil
+}
+
+// executeSteps runs each step in order against the task's tab. A failed step
+// fails the task only when StopOnError is set; otherwise the failure is
+// reported in the step results and the remaining steps still run.
+func (s *Scheduler) executeSteps(ctx context.Context, t *Task, port string) (any, error) {
+	results := make([]StepResult, 0, len(t.Steps))
+	var stopErr error
+
+	for i, step := range t.Steps {
+		if err := ctx.Err(); err != nil {
+			stopErr = fmt.Errorf("step %d (%s): %w", i, step.Kind, err)
+			break
+		}
+
+		res, err := s.executeStep(ctx, t, port, step)
+		if err != nil {
+			results = append(results, StepResult{Index: i, Kind: step.Kind, Success: false, Error: err.Error()})
+			if t.StopOnError {
+				stopErr = fmt.Errorf("step %d (%s): %w", i, step.Kind, err)
+				break
+			}
+			continue
+		}
+		results = append(results, StepResult{Index: i, Kind: step.Kind, Success: true, Result: res})
+	}
+
+	successful := 0
+	for _, r := range results {
+		if r.Success {
+			successful++
+		}
+	}
+	summary := map[string]any{
+		"kind":       StepsAction,
+		"results":    results,
+		"total":      len(t.Steps),
+		"successful": successful,
+		"failed":     len(t.Steps) - successful,
+	}
+	return summary, stopErr
+}
+
+func (s *Scheduler) executeStep(ctx context.Context, t *Task, port string, step TaskStep) (any, error) {
+	path := func(route string) string {
+		return fmt.Sprintf("/tabs/%s/%s", t.TabID, route)
+	}
+
+	switch step.Kind {
+	case StepNavigate, StepWait, StepFind:
+		body := make(map[string]any, len(step.Params))
+		for k, v := range step.Params {
+			body[k] = v
+		}
+		return s.callTab(ctx, t, port, http.MethodPost, path(step.Kind), nil, body)
+	case StepSnapshot, StepText:
+		query := url.Values{}
+		for k, v := range step.Params {
+			query.Set(k, fmt.Sprint(v))
+		}
+		return s.callTab(ctx, t, port, http.MethodGet, path(step.Kind), query, nil)
+	default:
+		body := map[string]any{"kind": step.Kind}
+		if step.Ref != "" {
+			body["ref"] = step.Ref
+		}
+		for k, v := range step.Params {
+			body[k] = v
+		}
+		return s.callTab(ctx, t, port, http.MethodPost, path("action"), nil, body)
+	}
+}
@@ -0,0 +1,141 @@
+package scheduler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func newStepsExecutor(t *testing.T, failPath string) (*httptest.Server, string, *[]string) {
+	t.Helper()
+	var mu sync.Mutex
+	var calls []string
+	executor := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		calls = append(calls, r.Method+" "+r.URL.Path+"?"+r.URL.RawQuery)
+		mu.Unlock()
+		if r.URL.Path == failPath {
+			w.WriteHeader(500)
+			_, _ = w.Write([]byte(`{"error":"boom"}`))
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]string{"path": r.URL.Path})
+	}))
+	parts := strings.Split(executor.URL, ":")
+	return executor, parts[len(parts)-1], &calls
+}
+
+func TestSubmitRequestValidateSteps(t *testing.T) {
+	tests := []struct {
+		name    string
+		req     SubmitRequest
+		wantErr bool
+	}{
+		{"steps only", SubmitRequest{AgentID: "a1", Steps: []TaskStep{{Kind: "navigate"}}}, false},
+		{"action and steps", SubmitRequest{AgentID: "a1", Action: "click", Steps: []TaskStep{{Kind: "click"}}}, true},
+		{"missing kind", SubmitRequest{AgentID: "a1", Steps: []TaskStep{{Kind: ""}}}, true},
+		{"neither", SubmitRequest{AgentID: "a1"}, true},
+	}
+	for _, tt := range tests {
+		err := tt.req.Validate()
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: got err=%v, wantErr=%v", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestExecuteStepsRoutes(t *testing.T) {
+	executor, port, calls := newStepsExecutor(t, "")
+	defer executor.Close()
+
+	s := New(DefaultConfig(), &mockResolver{port: port})
+	task, err := s.Submit(SubmitRequest{
+		AgentID: "a1",
+		TabID:   "tab-1",
+		Steps: []TaskStep{
+			{Kind: "navigate", Params: map[string]any{"url": "https://pinchtab.com"}},
+			{Kind: "wait", Params: map[string]any{"selector": "#ready"}},
+			{Kind: "click", Ref: "e5"},
+			{Kind: "snapshot", Params: map[string]any{"filter": "interactive"}},
+			{Kind: "text"},
+			{Kind: "find", Params: map[string]any{"query": "login button"}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("submit failed: %v", err)
+	}
+	if task.Action != StepsAction {
+		t.Errorf("expected action %q, got %q", StepsAction, task.Action)
+	}
+
+	result, err := s.executeTask(context.Background(), task)
+	if err != nil {
+		t.Fatalf("execute failed: %v", err)
+	}
+
+	want := []string{
+		"POST /tabs/tab-1/navigate?",
+		"POST /tabs/tab-1/wait?",
+		"POST /tabs/tab-1/action?",
+		"GET /tabs/tab-1/snapshot?filter=interactive",
+		"GET /tabs/tab-1/text?",
+		"POST /tabs/tab-1/find?",
+	}
+	if len(*calls) != len(want) {
+		t.Fatalf("expected %d calls, got %v", len(want), *calls)
+	}
+	for i := range want {
+		if (*calls)[i] != want[i] {
+			t.Errorf("call %d: expected %q, got %q", i, want[i], (*calls)[i])
+		}
+	}
+
+	summary := result.(map[string]any)
+	if summary["successful"] != 6 || summary["failed"] != 0 {
+		t.Errorf("unexpected summary: %v", summary)
+	}
+}
+
+func TestExecuteStepsStopOnError(t *testing.T) {
+	executor, port, calls := newStepsExecutor(t, "/tabs/tab-1/action")
+	defer executor.Close()
+
+	s := New(DefaultConfig(), &mockResolver{port: port})
+	steps := []TaskStep{
+		{Kind: "click", Ref: "e1"},
+		{Kind: "text"},
+	}
+
+	task, _ := s.Submit(SubmitRequest{AgentID: "a1", TabID: "tab-1", Steps: steps})
+	result, err := s.executeTask(context.Background(), task)
+	if err != nil {
+		t.Fatalf("without stopOnError the task should succeed, got %v", err)
+	}
+	if got := result.(map[string]any)["failed"]; got != 1 {
+		t.Errorf("expected 1 failed step, got %v", got)
+	}
+	if len(*calls) != 2 {
+		t.Errorf("expected both steps to run, got %v", *calls)
+	}
+
+	*calls = nil
+	task, _ = s.Submit(SubmitRequest{AgentID: "a1", TabID: "tab-1", Steps: steps, StopOnError: true})
+	result, err = s.executeTask(context.Background(), task)
+	if err == nil {
+		t.Fatal("expected stopOnError task to fail")
+	}
+	if !strings.Contains(err.Error(), "step 0 (click)") {
+		t.Errorf("error should name the failing step, got %v", err)
+	}
+	if len(*calls) != 1 {
+		t.Errorf("expected execution to stop after the first step, got %v", *calls)
+	}
+	if results := result.(map[string]any)["results"].([]StepResult); len(results) != 1 {
+		t.Errorf("expected partial results for 1 step, got %d", len(results))
+	}
+}
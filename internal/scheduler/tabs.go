@@ -0,0 +1,316 @@
+package scheduler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/url"
+	"sort"
+	"time"
+
+	"github.com/pinchtab/pinchtab/internal/activity"
+)
+
+// Tab selection modes for TabTarget.Tab.
+const (
+	TabNew  = "new"  // open a fresh tab
+	TabIdle = "idle" // reuse an unlocked tab, opening one if none is free
+)
+
+// Release policies for TabTarget.Release.
+const (
+	ReleaseAuto  = "auto"  // close tabs the scheduler opened, keep reused ones
+	ReleaseKeep  = "keep"  // unlock the tab and leave it open
+	ReleaseClose = "close" // close the tab
+)
+
+// errNoTabAvailable means every matching instance is at MaxTabs with no
+// idle tab. It is retried like a locked tab.
+var errNoTabAvailable = errors.New("no tab available")
+
+// TabTarget lets a task name an instance or profile instead of a tab. The
+// scheduler picks or opens a tab when the task is dispatched, locks it for
+// the task, and releases it afterwards. With neither InstanceID nor Profile
+// set, any running instance will do.
+type TabTarget struct {
+	InstanceID string `json:"instanceId,omitempty"`
+	Profile    string `json:"profile,omitempty"`
+	Tab        string `json:"tab,omitempty"`
+	Release    string `json:"release,omitempty"`
+}
+
+// Validate checks the selection mode and release policy.
+func (tt *TabTarget) Validate() error {
+	switch tt.Tab {
+	case "", TabNew, TabIdle:
+	default:
+		return fmt.Errorf("tab must be %q or %q", TabNew, TabIdle)
+	}
+	switch tt.Release {
+	case "", ReleaseAuto, ReleaseKeep, ReleaseClose:
+	default:
+		return fmt.Errorf("release must be %q, %q or %q", ReleaseAuto, ReleaseKeep, ReleaseClose)
+	}
+	return nil
+}
+
+// InstanceRef is a running browser instance a task can be placed on.
+type InstanceRef struct {
+	ID          string
+	ProfileName string
+	Port        string
+}
+
+// InstanceLocator lists running instances for tasks that use a TabTarget.
+// Resolvers that do not implement it only support explicit tab IDs.
+type InstanceLocator interface {
+	RunningInstances() []InstanceRef
+}
+
+// tabLease is a tab held by a running task.
+type tabLease struct {
+	tabID      string
+	instanceID string
+	port       string
+	owner      string
+	opened     bool
+}
+
+// instanceTab is one entry of an instance's GET /tabs response.
+type instanceTab struct {
+	ID    string `json:"id"`
+	Owner string `json:"owner,omitempty"`
+}
+
+// acquireTab finds or opens a tab for t on an instance matching its target
+// and locks it to the task.
+func (s *Scheduler) acquireTab(ctx context.Context, t *Task) (*tabLease, error) {
+	locator, ok := s.resolver.(InstanceLocator)
+	if !ok {
+		return nil, fmt.Errorf("tab targeting is not available; tabId is required")
+	}
+	var candidates []InstanceRef
+	for _, inst := range locator.RunningInstances() {
+		if t.Target.InstanceID != "" && inst.ID != t.Target.InstanceID {
+			continue
+		}
+		if t.Target.Profile != "" && inst.ProfileName != t.Target.Profile {
+			continue
+		}
+		candidates = append(candidates, inst)
+	}
+	if len(candidates) == 0 {
+		return nil, fmt.Errorf("no running instance matches target (instanceId=%q, profile=%q)", t.Target.InstanceID, t.Target.Profile)
+	}
+	sort.Slice(candidates, func(i, j int) bool { return candidates[i].ID < candidates[j].ID })
+
+	s.cfgMu.RLock()
+	maxTabs := s.cfg.MaxTabs
+	s.cfgMu.RUnlock()
+
+	// Instances are called without tabsMu held. Under it, an idle tab is
+	// reserved in leased and a tab being opened is counted in opening, so
+	// concurrent tasks neither pick the same idle tab nor open past MaxTabs
+	// together; a failed call rolls its reservation back.
+	owner := "scheduler:" + t.ID
+	var lastErr error
+	for _, inst := range candidates {
+		var tabs []instanceTab
+		if err := s.instanceJSON(ctx, inst.Port, http.MethodGet, "/tabs", nil, &struct {
+			Tabs *[]instanceTab `json:"tabs"`
+		}{&tabs}); err != nil {
+			lastErr = err
+			continue
+		}
+
+		if t.Target.Tab == TabIdle {
+			for _, tab := range tabs {
+				if tab.Owner != "" || !s.reserveTab(tab.ID, inst.ID) {
+					continue
+				}
+				if err := s.lockTab(ctx, inst.Port, tab.ID, owner, t.Deadline); err != nil {
+					s.unreserveTab(tab.ID)
+					continue
+				}
+				return &tabLease{tabID: tab.ID, instanceID: inst.ID, port: inst.Port, owner: owner}, nil
+			}
+		}
+
+		if !s.reserveOpen(inst.ID, tabs, maxTabs) {
+			continue
+		}
+		var opened struct {
+			TabID string `json:"tabId"`
+		}
+		err := s.instanceJSON(ctx, inst.Port, http.MethodPost, "/tab", map[string]any{"action": "new"}, &opened)
+		if err == nil && opened.TabID == "" {
+			err = fmt.Errorf("instance %s did not return a tab id", inst.ID)
+		}
+		s.finishOpen(inst.ID, opened.TabID, err == nil)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		l := &tabLease{tabID: opened.TabID, instanceID: inst.ID, port: inst.Port, owner: owner, opened: true}
+		if err := s.lockTab(ctx, inst.Port, opened.TabID, owner, t.Deadline); err != nil {
+			s.closeTab(l)
+			s.unreserveTab(opened.TabID)
+			lastErr = err
+			continue
+		}
+		return l, nil
+	}
+	if lastErr != nil {
+		return nil, lastErr
+	}
+	return nil, fmt.Errorf("%w: matching instances are at maxTabs (%d) with no idle tab", errNoTabAvailable, maxTabs)
+}
+
+// reserveTab marks an idle tab as taken, reporting false when another task
+// already holds it.
+func (s *Scheduler) reserveTab(tabID, instanceID string) bool {
+	s.tabsMu.Lock()
+	defer s.tabsMu.Unlock()
+	if _, held := s.leased[tabID]; held {
+		return false
+	}
+	s.leased[tabID] = instanceID
+	return true
+}
+
+// unreserveTab gives back a tab reserved by reserveTab or finishOpen.
+func (s *Scheduler) unreserveTab(tabID string) {
+	s.tabsMu.Lock()
+	delete(s.leased, tabID)
+	s.tabsMu.Unlock()
+}
+
+// reserveOpen counts a tab about to be opened on an instance, reporting
+// false when that would go past maxTabs. tabs may predate tabs other tasks
+// have opened since, so leased tabs missing from it count as well.
+func (s *Scheduler) reserveOpen(instanceID string, tabs []instanceTab, maxTabs int) bool {
+	s.tabsMu.Lock()
+	defer s.tabsMu.Unlock()
+	if maxTabs > 0 {
+		listed := make(map[string]bool, len(tabs))
+		for _, tab := range tabs {
+			listed[tab.ID] = true
+		}
+		open := len(tabs) + s.opening[instanceID]
+		for tabID, inst := range s.leased {
+			if inst == instanceID && !listed[tabID] {
+				open++
+			}
+		}
+		if open >= maxTabs {
+			return false
+		}
+	}
+	s.opening[instanceID]++
+	return true
+}
+
+// finishOpen settles a reserveOpen, leasing the new tab when it opened.
+func (s *Scheduler) finishOpen(instanceID, tabID string, ok bool) {
+	s.tabsMu.Lock()
+	defer s.tabsMu.Unlock()
+	if s.opening[instanceID]--; s.opening[instanceID] <= 0 {
+		delete(s.opening, instanceID)
+	}
+	if ok {
+		s.leased[tabID] = instanceID
+	}
+}
+
+// releaseTab unlocks or closes a leased tab according to the task's policy.
+func (s *Scheduler) releaseTab(t *Task, l *tabLease) {
+	policy := t.Target.Release
+	if policy == "" || policy == ReleaseAuto {
+		policy = ReleaseKeep
+		if l.opened {
+			policy = ReleaseClose
+		}
+	}
+
+	if policy == ReleaseClose {
+		s.closeTab(l)
+	} else {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		err := s.instanceJSON(ctx, l.port, http.MethodPost, "/tabs/"+url.PathEscape(l.tabID)+"/unlock", map[string]any{"owner": l.owner}, nil)
+		cancel()
+		if err != nil {
+			slog.Warn("scheduler: tab unlock failed", "task", t.ID, "tab", l.tabID, "err", err)
+		}
+	}
+
+	s.tabsMu.Lock()
+	delete(s.leased, l.tabID)
+	s.tabsMu.Unlock()
+}
+
+func (s *Scheduler) closeTab(l *tabLease) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := s.instanceJSON(ctx, l.port, http.MethodPost, "/tab", map[string]any{"action": "close", "tabId": l.tabID}, nil); err != nil {
+		slog.Warn("scheduler: tab close failed", "tab", l.tabID, "err", err)
+	}
+}
+
+// lockTab locks a tab to owner until the task deadline.
+func (s *Scheduler) lockTab(ctx context.Context, port, tabID, owner string, deadline time.Time) error {
+	ttl := time.Until(deadline) + 30*time.Second
+	if deadline.IsZero() || ttl < time.Minute {
+		ttl = time.Minute
+	}
+	body := map[string]any{"owner": owner, "timeoutSec": int(ttl.Seconds())}
+	return s.instanceJSON(ctx, port, http.MethodPost, "/tabs/"+url.PathEscape(tabID)+"/lock", body, nil)
+}
+
+// instanceJSON makes a bookkeeping call to an instance and decodes the
+// response into out when it is non-nil.
+func (s *Scheduler) instanceJSON(ctx context.Context, port, method, path string, body map[string]any, out any) error {
+	var reqBody io.Reader
+	if body != nil {
+		payload, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reqBody = bytes.NewReader(payload)
+	}
+	target := &url.URL{Scheme: "http", Host: net.JoinHostPort("localhost", port), Path: path}
+	req, err := http.NewRequestWithContext(ctx, method, target.String(), reqBody)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set(activity.HeaderPTSource, "scheduler")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return &executorError{Err: err}
+	}
+	defer func() { _ = resp.Body.Close() }()
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read instance response: %w", err)
+	}
+	if resp.StatusCode >= 400 {
+		var payload struct {
+			Code string `json:"code"`
+		}
+		_ = json.Unmarshal(respBody, &payload)
+		return &executorError{Status: resp.StatusCode, Code: payload.Code, Body: string(respBody)}
+	}
+	if out == nil {
+		return nil
+	}
+	return json.Unmarshal(respBody, out)
+}
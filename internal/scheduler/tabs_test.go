@@ -0,0 +1,277 @@
+package scheduler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeInstance serves the tab bookkeeping and action routes of one instance.
+type fakeInstance struct {
+	mu      sync.Mutex
+	tabs    []string
+	locks   map[string]string
+	nextID  int
+	actions []string // "<tabId> <owner>" per action call
+	srv     *httptest.Server
+
+	// listed, when set, receives each GET /tabs before it is answered, and
+	// hold then keeps the answer back until it is closed.
+	listed chan struct{}
+	hold   chan struct{}
+}
+
+func newFakeInstance(t *testing.T, tabs ...string) *fakeInstance {
+	t.Helper()
+	fi := &fakeInstance{tabs: tabs, locks: map[string]string{}}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /tabs", func(w http.ResponseWriter, r *http.Request) {
+		if fi.listed != nil {
+			fi.listed <- struct{}{}
+			<-fi.hold
+		}
+		fi.mu.Lock()
+		defer fi.mu.Unlock()
+		out := []map[string]string{}
+		for _, id := range fi.tabs {
+			entry := map[string]string{"id": id}
+			if owner := fi.locks[id]; owner != "" {
+				entry["owner"] = owner
+			}
+			out = append(out, entry)
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"tabs": out})
+	})
+	mux.HandleFunc("POST /tab", func(w http.ResponseWriter, r *http.Request) {
+		var req struct{ Action, TabID string }
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		fi.mu.Lock()
+		defer fi.mu.Unlock()
+		if req.Action == "new" {
+			fi.nextID++
+			id := fmt.Sprintf("new-%d", fi.nextID)
+			fi.tabs = append(fi.tabs, id)
+			_ = json.NewEncoder(w).Encode(map[string]string{"tabId": id})
+			return
+		}
+		for i, id := range fi.tabs {
+			if id == req.TabID {
+				fi.tabs = append(fi.tabs[:i], fi.tabs[i+1:]...)
+				delete(fi.locks, id)
+			}
+		}
+		_ = json.NewEncoder(w).Encode(map[string]bool{"closed": true})
+	})
+	mux.HandleFunc("POST /tabs/{id}/lock", func(w http.ResponseWriter, r *http.Request) {
+		var req struct{ Owner string }
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		fi.mu.Lock()
+		defer fi.mu.Unlock()
+		if owner := fi.locks[r.PathValue("id")]; owner != "" && owner != req.Owner {
+			w.WriteHeader(409)
+			return
+		}
+		fi.locks[r.PathValue("id")] = req.Owner
+		_ = json.NewEncoder(w).Encode(map[string]bool{"locked": true})
+	})
+	mux.HandleFunc("POST /tabs/{id}/unlock", func(w http.ResponseWriter, r *http.Request) {
+		fi.mu.Lock()
+		defer fi.mu.Unlock()
+		delete(fi.locks, r.PathValue("id"))
+		_ = json.NewEncoder(w).Encode(map[string]bool{"unlocked": true})
+	})
+	mux.HandleFunc("POST /tabs/{id}/action", func(w http.ResponseWriter, r *http.Request) {
+		fi.mu.Lock()
+		defer fi.mu.Unlock()
+		id, owner := r.PathValue("id"), r.Header.Get("X-Owner")
+		if lock := fi.locks[id]; lock != "" && lock != owner {
+			w.WriteHeader(423)
+			_, _ = w.Write([]byte(`{"code":"tab_locked"}`))
+			return
+		}
+		fi.actions = append(fi.actions, id+" "+owner)
+		_ = json.NewEncoder(w).Encode(map[string]bool{"success": true})
+	})
+	fi.srv = httptest.NewServer(mux)
+	t.Cleanup(fi.srv.Close)
+	return fi
+}
+
+func (fi *fakeInstance) port() string {
+	parts := strings.Split(fi.srv.URL, ":")
+	return parts[len(parts)-1]
+}
+
+type locatorResolver struct {
+	mockResolver
+	instances []InstanceRef
+}
+
+func (r *locatorResolver) RunningInstances() []InstanceRef { return r.instances }
+
+func runTargeted(t *testing.T, s *Scheduler, target *TabTarget) *Task {
+	t.Helper()
+	task, err := s.Submit(SubmitRequest{AgentID: "a1", Action: "click", Target: target})
+	if err != nil {
+		t.Fatalf("submit failed: %v", err)
+	}
+	s.dispatch(s.queue.Dequeue(s.inflightLimits()))
+	return task
+}
+
+func TestTargetOpensAndClosesTab(t *testing.T) {
+	fi := newFakeInstance(t)
+	s := New(DefaultConfig(), &locatorResolver{instances: []InstanceRef{
+		{ID: "inst_other", ProfileName: "other", Port: "1"},
+		{ID: "inst_work", ProfileName: "work", Port: fi.port()},
+	}})
+
+	task := runTargeted(t, s, &TabTarget{Profile: "work"})
+	snap := task.Snapshot()
+	if snap.State != StateDone {
+		t.Fatalf("expected done, got %s (%s)", snap.State, snap.Error)
+	}
+	if snap.TabID != "new-1" || snap.InstanceID != "inst_work" {
+		t.Errorf("unexpected placement: tab %q on %q", snap.TabID, snap.InstanceID)
+	}
+	if len(fi.actions) != 1 || fi.actions[0] != "new-1 scheduler:"+task.ID {
+		t.Errorf("expected the action to run under the task's lock, got %v", fi.actions)
+	}
+	if len(fi.tabs) != 0 || len(s.leased) != 0 {
+		t.Errorf("opened tab should be closed and released, tabs=%v leased=%v", fi.tabs, s.leased)
+	}
+}
+
+func TestTargetReusesIdleTab(t *testing.T) {
+	fi := newFakeInstance(t, "busy", "idle")
+	fi.locks["busy"] = "someone-else"
+	s := New(DefaultConfig(), &locatorResolver{instances: []InstanceRef{{ID: "inst_1", Port: fi.port()}}})
+
+	task := runTargeted(t, s, &TabTarget{Tab: TabIdle})
+	if task.GetState() != StateDone || task.Snapshot().TabID != "idle" {
+		t.Fatalf("expected the idle tab to be used, got %s on %q", task.GetState(), task.Snapshot().TabID)
+	}
+	if len(fi.tabs) != 2 || fi.locks["idle"] != "" || fi.locks["busy"] != "someone-else" {
+		t.Errorf("reused tab should stay open and unlocked, tabs=%v locks=%v", fi.tabs, fi.locks)
+	}
+}
+
+func TestTargetRespectsMaxTabs(t *testing.T) {
+	fi := newFakeInstance(t, "t1", "t2")
+	fi.locks["t1"], fi.locks["t2"] = "x", "y"
+	cfg := DefaultConfig()
+	cfg.MaxTabs = 2
+	s := New(cfg, &locatorResolver{instances: []InstanceRef{{ID: "inst_1", Port: fi.port()}}})
+
+	task := runTargeted(t, s, &TabTarget{Tab: TabIdle})
+	if task.GetState() != StateFailed || !strings.Contains(task.Snapshot().Error, "no tab available") {
+		t.Fatalf("expected a no-tab failure, got %s (%s)", task.GetState(), task.Snapshot().Error)
+	}
+	if len(fi.tabs) != 2 {
+		t.Errorf("no tab should be opened past maxTabs, got %v", fi.tabs)
+	}
+	if got := classifyError(t.Context(), fmt.Errorf("wrapped: %w", errNoTabAvailable)); got != RetryOnTabLocked {
+		t.Errorf("expected no-tab errors to retry as tab_locked, got %q", got)
+	}
+}
+
+func TestTargetWithoutMatchingInstance(t *testing.T) {
+	s := New(DefaultConfig(), &locatorResolver{})
+	task := runTargeted(t, s, &TabTarget{InstanceID: "inst_missing"})
+	if task.GetState() != StateFailed || !strings.Contains(task.Snapshot().Error, "no running instance") {
+		t.Errorf("expected failure, got %s (%s)", task.GetState(), task.Snapshot().Error)
+	}
+
+	s = New(DefaultConfig(), &mockResolver{})
+	task = runTargeted(t, s, &TabTarget{})
+	if task.GetState() != StateFailed || !strings.Contains(task.Snapshot().Error, "not available") {
+		t.Errorf("expected failure without a locator, got %s (%s)", task.GetState(), task.Snapshot().Error)
+	}
+}
+
+func TestSubmitRequestValidateTarget(t *testing.T) {
+	bad := []SubmitRequest{
+		{AgentID: "a1", Action: "click", TabID: "tab-1", Target: &TabTarget{}},
+		{AgentID: "a1", Action: "click", Target: &TabTarget{Tab: "any"}},
+		{AgentID: "a1", Action: "click", Target: &TabTarget{Release: "discard"}},
+	}
+	for _, req := range bad {
+		if err := req.Validate(); err == nil {
+			t.Errorf("expected error for %+v", req.Target)
+		}
+	}
+	ok := SubmitRequest{AgentID: "a1", Action: "click", Target: &TabTarget{Profile: "work", Tab: TabIdle, Release: ReleaseClose}}
+	if err := ok.Validate(); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestTargetSlowInstanceDoesNotBlockOthers(t *testing.T) {
+	slow, fast := newFakeInstance(t), newFakeInstance(t)
+	slow.listed, slow.hold = make(chan struct{}, 1), make(chan struct{})
+	s := New(DefaultConfig(), &locatorResolver{instances: []InstanceRef{
+		{ID: "inst_slow", Port: slow.port()},
+		{ID: "inst_fast", Port: fast.port()},
+	}})
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := s.acquireTab(context.Background(), &Task{ID: "tsk_slow", Target: &TabTarget{InstanceID: "inst_slow"}})
+		done <- err
+	}()
+	<-slow.listed
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	l, err := s.acquireTab(ctx, &Task{ID: "tsk_fast", Target: &TabTarget{InstanceID: "inst_fast"}})
+	if err != nil {
+		t.Fatalf("acquire on the fast instance waited on the slow one: %v", err)
+	}
+	if l.instanceID != "inst_fast" {
+		t.Errorf("unexpected instance %q", l.instanceID)
+	}
+
+	close(slow.hold)
+	if err := <-done; err != nil {
+		t.Errorf("slow acquire failed: %v", err)
+	}
+}
+
+func TestTargetConcurrentOpensRespectMaxTabs(t *testing.T) {
+	fi := newFakeInstance(t)
+	fi.listed, fi.hold = make(chan struct{}, 2), make(chan struct{})
+	cfg := DefaultConfig()
+	cfg.MaxTabs = 1
+	s := New(cfg, &locatorResolver{instances: []InstanceRef{{ID: "inst_1", Port: fi.port()}}})
+
+	// Both tasks list the instance before either opens a tab.
+	errs := make(chan error, 2)
+	for _, id := range []string{"tsk_a", "tsk_b"} {
+		go func() {
+			_, err := s.acquireTab(context.Background(), &Task{ID: id, Target: &TabTarget{}})
+			errs <- err
+		}()
+	}
+	<-fi.listed
+	<-fi.listed
+	close(fi.hold)
+
+	failed := 0
+	for range 2 {
+		if err := <-errs; err != nil {
+			if !strings.Contains(err.Error(), "no tab available") {
+				t.Errorf("unexpected error: %v", err)
+			}
+			failed++
+		}
+	}
+	if failed != 1 || len(fi.tabs) != 1 {
+		t.Errorf("expected one tab opened and one task refused, got %d refused and tabs %v", failed, fi.tabs)
+	}
+}
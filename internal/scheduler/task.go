@@ -44,7 +44,12 @@ type Task struct {
 	Params   map[string]any `json:"params,omitempty"`
 	Priority int            `json:"priority"`
 	State    TaskState      `json:"state"`
-	Deadline time.Time      `json:"deadline,omitempty"`
+
+	// Steps, when set, replaces the single action with an ordered workflow.
+	Steps       []TaskStep `json:"steps,omitempty"`
+	StopOnError bool       `json:"stopOnError,omitempty"`
+
+	Deadline time.Time `json:"deadline,omitempty"`
 
 	CreatedAt   time.Time `json:"createdAt"`
 	StartedAt   time.Time `json:"startedAt,omitempty"`
@@ -57,6 +62,30 @@ type Task struct {
 	// CallbackURL receives a POST with the task snapshot on completion.
 	CallbackURL string `json:"callbackUrl,omitempty"`
 
+	// RecurringID links a run to the recurring template that created it.
+	RecurringID string `json:"recurringId,omitempty"`
+
+	// Workflow membership: WorkflowRef is the batch-local ID, DependsOn
+	// holds upstream task IDs, and Inputs copy upstream results into Params
+	// when the task is released.
+	WorkflowID  string               `json:"workflowId,omitempty"`
+	WorkflowRef string               `json:"workflowRef,omitempty"`
+	DependsOn   []string             `json:"dependsOn,omitempty"`
+	Inputs      map[string]TaskInput `json:"inputs,omitempty"`
+
+	// Retry overrides the scheduler's default retry policy for this task.
+	// Attempts records every execution, including failed ones that were
+	// retried.
+	Retry    *RetryPolicy  `json:"retry,omitempty"`
+	Attempts []TaskAttempt `json:"attempts,omitempty"`
+
+	// Target asks the scheduler to pick or open the tab at dispatch time.
+	// TabID and InstanceID then report the tab of the latest attempt, and
+	// leaseOwner is the lock owner sent with every executor call.
+	Target     *TabTarget `json:"target,omitempty"`
+	InstanceID string     `json:"instanceId,omitempty"`
+	leaseOwner string
+
 	// position is the queue position at submission time.
 	Position int `json:"position,omitempty"`
 }
@@ -75,6 +104,8 @@ func (t *Task) SetState(next TaskState) error {
 	case t.State == StateQueued && (next == StateAssigned || next == StateCancelled || next == StateFailed || next == StateRejected):
 	case t.State == StateAssigned && (next == StateRunning || next == StateCancelled):
 	case t.State == StateRunning && (next == StateDone || next == StateFailed || next == StateCancelled):
+	case t.State == StateRunning && next == StateQueued:
+		// A failed attempt going back to the queue for a retry.
 	default:
 		return fmt.Errorf("invalid state transition: %q → %q", t.State, next)
 	}
@@ -84,7 +115,10 @@ func (t *Task) SetState(next TaskState) error {
 
 	switch next {
 	case StateAssigned:
-		t.StartedAt = now
+		// Retries keep the first attempt's start so latency spans all of them.
+		if t.StartedAt.IsZero() {
+			t.StartedAt = now
+		}
 	case StateRunning:
 		if t.StartedAt.IsZero() {
 			t.StartedAt = now
@@ -98,6 +132,20 @@ func (t *Task) SetState(next TaskState) error {
 	return nil
 }
 
+// recordAttempt appends an entry to the task's attempt history.
+func (t *Task) recordAttempt(a TaskAttempt) {
+	t.mu.Lock()
+	t.Attempts = append(t.Attempts, a)
+	t.mu.Unlock()
+}
+
+// setTab records the tab leased for the current attempt.
+func (t *Task) setTab(l *tabLease) {
+	t.mu.Lock()
+	t.TabID, t.InstanceID, t.leaseOwner = l.tabID, l.instanceID, l.owner
+	t.mu.Unlock()
+}
+
 // GetState returns the current task state.
 func (t *Task) GetState() TaskState {
 	t.mu.RLock()
@@ -117,6 +165,8 @@ func (t *Task) Snapshot() *Task {
 		Selector:    t.Selector,
 		Ref:         t.Ref,
 		Params:      t.Params,
+		Steps:       t.Steps,
+		StopOnError: t.StopOnError,
 		Priority:    t.Priority,
 		State:       t.State,
 		Deadline:    t.Deadline,
@@ -127,6 +177,15 @@ func (t *Task) Snapshot() *Task {
 		Result:      t.Result,
 		Error:       t.Error,
 		CallbackURL: t.CallbackURL,
+		RecurringID: t.RecurringID,
+		WorkflowID:  t.WorkflowID,
+		WorkflowRef: t.WorkflowRef,
+		DependsOn:   t.DependsOn,
+		Inputs:      t.Inputs,
+		Retry:       t.Retry,
+		Attempts:    append([]TaskAttempt(nil), t.Attempts...),
+		Target:      t.Target,
+		InstanceID:  t.InstanceID,
 		Position:    t.Position,
 	}
 }
@@ -139,9 +198,13 @@ type SubmitRequest struct {
 	Selector    string         `json:"selector,omitempty"`
 	Ref         string         `json:"ref,omitempty"` // deprecated: use Selector
 	Params      map[string]any `json:"params,omitempty"`
+	Steps       []TaskStep     `json:"steps,omitempty"`
+	StopOnError bool           `json:"stopOnError,omitempty"`
 	Priority    int            `json:"priority,omitempty"`
 	Deadline    string         `json:"deadline,omitempty"`
 	CallbackURL string         `json:"callbackUrl,omitempty"`
+	Retry       *RetryPolicy   `json:"retry,omitempty"`
+	Target      *TabTarget     `json:"target,omitempty"`
 }
 
 // Validate checks that the request has the minimum required fields.
@@ -149,9 +212,29 @@ func (r *SubmitRequest) Validate() error {
 	if r.AgentID == "" {
 		return fmt.Errorf("missing required field 'agentId'")
 	}
-	if r.Action == "" {
+	if len(r.Steps) > 0 {
+		if r.Action != "" {
+			return fmt.Errorf("'action' and 'steps' are mutually exclusive")
+		}
+		if err := validateSteps(r.Steps); err != nil {
+			return err
+		}
+	} else if r.Action == "" {
 		return fmt.Errorf("missing required field 'action'")
 	}
+	if r.Retry != nil {
+		if err := r.Retry.Validate(); err != nil {
+			return fmt.Errorf("invalid retry: %w", err)
+		}
+	}
+	if r.Target != nil {
+		if r.TabID != "" {
+			return fmt.Errorf("'tabId' and 'target' are mutually exclusive")
+		}
+		if err := r.Target.Validate(); err != nil {
+			return fmt.Errorf("invalid target: %w", err)
+		}
+	}
 	if strings.TrimSpace(r.CallbackURL) != "" {
 		if err := validateCallbackURL(r.CallbackURL); err != nil {
 			return fmt.Errorf("invalid callbackUrl: %w", err)
@@ -35,6 +35,7 @@ func TestTaskSetState_ValidTransitions(t *testing.T) {
 		{StateRunning, StateDone},
 		{StateRunning, StateFailed},
 		{StateRunning, StateCancelled},
+		{StateRunning, StateQueued},
 	}
 
 	for _, tc := range cases {
@@ -57,7 +58,7 @@ func TestTaskSetState_InvalidTransitions(t *testing.T) {
 		{StateQueued, StateDone},
 		{StateAssigned, StateDone},
 		{StateAssigned, StateFailed},
-		{StateRunning, StateQueued},
+		{StateAssigned, StateQueued},
 		{StateRunning, StateAssigned},
 	}
 
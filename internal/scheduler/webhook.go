@@ -3,12 +3,18 @@ package scheduler
 import (
 	"bytes"
 	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
 	"log/slog"
 	"net"
 	"net/http"
+	"strconv"
+	"strings"
 	"time"
 
 	internalurls "github.com/pinchtab/pinchtab/internal/urls"
@@ -48,35 +54,122 @@ func newPinnedWebhookClient(target *validatedCallbackTarget) *http.Client {
 	}
 }
 
-// sendWebhook delivers a task snapshot to the configured callbackUrl.
-// Delivery is best-effort: failures are logged but do not affect task state.
-func sendWebhook(callbackURL string, t *Task) {
-	if callbackURL == "" {
-		return
-	}
-	logURL := internalurls.RedactForLog(callbackURL)
+// Webhook headers. Signature is "sha256=" followed by the hex HMAC-SHA256
+// of "<timestamp>.<body>" under the configured secret; it is only sent when
+// a secret is set.
+const (
+	webhookHeaderEvent     = "X-PinchTab-Event"
+	webhookHeaderTaskID    = "X-PinchTab-Task-ID"
+	webhookHeaderDelivery  = "X-PinchTab-Delivery"
+	webhookHeaderTimestamp = "X-PinchTab-Timestamp"
+	webhookHeaderSignature = "X-PinchTab-Signature"
 
-	target, err := validateCallbackTarget(callbackURL)
-	if err != nil {
-		slog.Warn("webhook: callback rejected", "task", t.ID, "url", logURL, "err", err)
-		return
-	}
+	webhookEventCompleted = "task.completed"
+)
 
+// webhookSender delivers task callbacks. A zero value makes a single
+// unsigned attempt and only logs failures.
+type webhookSender struct {
+	secret  string
+	retry   RetryPolicy // MaxAttempts, BackoffMs and MaxBackoffMs apply
+	dead    *deadLetterStore
+	stopCh  <-chan struct{}
+	metrics *Metrics
+}
+
+// newWebhookDelivery builds the delivery for a task's terminal snapshot.
+func newWebhookDelivery(callbackURL string, t *Task) *DeadLetter {
+	if callbackURL == "" {
+		return nil
+	}
 	snap := t.Snapshot()
 	payload, err := json.Marshal(snap)
 	if err != nil {
 		slog.Warn("webhook: failed to marshal task", "task", t.ID, "err", err)
-		return
+		return nil
 	}
+	return &DeadLetter{
+		ID:      generateDeliveryID(),
+		TaskID:  snap.ID,
+		AgentID: snap.AgentID,
+		URL:     callbackURL,
+		Event:   webhookEventCompleted,
+		Payload: payload,
+	}
+}
 
-	req, err := http.NewRequest(http.MethodPost, target.URL.String(), bytes.NewReader(payload))
+// deliver posts d, retrying non-2xx responses and network errors with
+// backoff. Deliveries that still fail go to the dead-letter list. Task
+// state is never affected.
+func (ws *webhookSender) deliver(d *DeadLetter) {
+	attempts := max(ws.retry.MaxAttempts, 1)
+deliver:
+	for attempt := 1; attempt <= attempts; attempt++ {
+		if attempt > 1 {
+			select {
+			case <-time.After(ws.retry.backoff(attempt - 1)):
+			case <-ws.stopCh:
+				break deliver
+			}
+		}
+		d.Attempts = attempt
+		status, err := ws.post(d)
+		if err == nil {
+			return
+		}
+		d.LastStatus, d.LastError = status, err.Error()
+		if errors.Is(err, errWebhookRejected) {
+			break
+		}
+	}
+	ws.bury(d)
+}
+
+// bury records a delivery that will not be retried any further.
+func (ws *webhookSender) bury(d *DeadLetter) {
+	d.FailedAt = timeNow()
+	if ws.metrics != nil {
+		ws.metrics.recordWebhookDeadLetter()
+	}
+	if ws.dead != nil {
+		ws.dead.add(d)
+	}
+	slog.Warn("webhook: delivery failed, moved to dead-letter list", "task", d.TaskID, "delivery", d.ID, "url", internalurls.RedactForLog(d.URL), "attempts", d.Attempts, "err", d.LastError)
+}
+
+var errWebhookRejected = errors.New("callback rejected")
+
+// post makes a single delivery attempt. The target is re-validated on
+// every attempt so DNS changes cannot point a retry at a private address.
+// A host that does not resolve is retried like a network error; a target
+// the guard refuses is not.
+func (ws *webhookSender) post(d *DeadLetter) (int, error) {
+	logURL := internalurls.RedactForLog(d.URL)
+
+	target, err := validateCallbackTarget(d.URL)
+	if errors.Is(err, errCallbackUnresolved) {
+		slog.Warn("webhook: callback host did not resolve", "task", d.TaskID, "url", logURL, "attempt", d.Attempts)
+		return 0, err
+	}
 	if err != nil {
-		slog.Warn("webhook: failed to create request", "task", t.ID, "err", err)
-		return
+		slog.Warn("webhook: callback rejected", "task", d.TaskID, "url", logURL, "err", err)
+		return 0, fmt.Errorf("%w: %v", errWebhookRejected, err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, target.URL.String(), bytes.NewReader(d.Payload))
+	if err != nil {
+		slog.Warn("webhook: failed to create request", "task", d.TaskID, "err", err)
+		return 0, err
 	}
 	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("X-PinchTab-Event", "task.completed")
-	req.Header.Set("X-PinchTab-Task-ID", snap.ID)
+	req.Header.Set(webhookHeaderEvent, d.Event)
+	req.Header.Set(webhookHeaderTaskID, d.TaskID)
+	req.Header.Set(webhookHeaderDelivery, d.ID)
+	if ws.secret != "" {
+		ts := strconv.FormatInt(timeNow().Unix(), 10)
+		req.Header.Set(webhookHeaderTimestamp, ts)
+		req.Header.Set(webhookHeaderSignature, signWebhook(ws.secret, ts, d.Payload))
+	}
 
 	client := newPinnedWebhookClient(target)
 	if transport, ok := client.Transport.(*http.Transport); ok {
@@ -85,17 +178,32 @@ func sendWebhook(callbackURL string, t *Task) {
 
 	resp, err := client.Do(req)
 	if err != nil {
-		slog.Warn("webhook: delivery failed", "task", t.ID, "url", logURL, "err", err)
-		return
+		slog.Warn("webhook: delivery failed", "task", d.TaskID, "url", logURL, "attempt", d.Attempts, "err", err)
+		return 0, err
 	}
 	// Drain body so the underlying connection can be reused.
 	_, _ = io.Copy(io.Discard, resp.Body)
 	_ = resp.Body.Close()
 
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		slog.Warn("webhook: non-success response", "task", t.ID, "url", logURL, "status", resp.StatusCode)
-		return
+		slog.Warn("webhook: non-success response", "task", d.TaskID, "url", logURL, "attempt", d.Attempts, "status", resp.StatusCode)
+		return resp.StatusCode, fmt.Errorf("receiver returned %d", resp.StatusCode)
 	}
 
-	slog.Info("webhook: delivered", "task", t.ID, "url", logURL, "status", resp.StatusCode)
+	slog.Info("webhook: delivered", "task", d.TaskID, "url", logURL, "status", resp.StatusCode, "attempt", d.Attempts)
+	return resp.StatusCode, nil
+}
+
+// signWebhook returns the signature header value for a payload.
+func signWebhook(secret, timestamp string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(payload)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// generateDeliveryID produces a random delivery ID in the format whd_XXXXXXXX.
+func generateDeliveryID() string {
+	return "whd_" + strings.TrimPrefix(generateTaskID(), "tsk_")
 }
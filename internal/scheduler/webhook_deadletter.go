@@ -0,0 +1,225 @@
+package scheduler
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/pinchtab/pinchtab/internal/httpx"
+)
+
+// maxDeadLetters bounds the dead-letter list; the oldest entries are
+// dropped first.
+const maxDeadLetters = 1000
+
+// DeadLetter is a webhook delivery that failed on every attempt. Payload is
+// the exact body that was sent, so a replay delivers the original snapshot.
+type DeadLetter struct {
+	ID         string          `json:"id"`
+	TaskID     string          `json:"taskId"`
+	AgentID    string          `json:"agentId"`
+	URL        string          `json:"url"`
+	Event      string          `json:"event"`
+	Payload    json.RawMessage `json:"payload"`
+	Attempts   int             `json:"attempts"`
+	LastStatus int             `json:"lastStatus,omitempty"`
+	LastError  string          `json:"lastError"`
+	FailedAt   time.Time       `json:"failedAt"`
+	Replays    int             `json:"replays,omitempty"`
+}
+
+// deadLetterStore holds failed deliveries in failure order. When path is
+// set the list is saved there on every change.
+type deadLetterStore struct {
+	mu    sync.Mutex
+	items []*DeadLetter
+	path  string
+}
+
+func newDeadLetterStore(path string) *deadLetterStore {
+	ds := &deadLetterStore{path: path}
+	if path != "" {
+		ds.load()
+	}
+	return ds
+}
+
+func (ds *deadLetterStore) add(d *DeadLetter) {
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+	ds.items = append(ds.items, d)
+	if len(ds.items) > maxDeadLetters {
+		ds.items = ds.items[len(ds.items)-maxDeadLetters:]
+	}
+	ds.saveLocked()
+}
+
+func (ds *deadLetterStore) list(agentID string) []DeadLetter {
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+	out := make([]DeadLetter, 0, len(ds.items))
+	for _, d := range ds.items {
+		if agentID == "" || d.AgentID == agentID {
+			out = append(out, *d)
+		}
+	}
+	return out
+}
+
+func (ds *deadLetterStore) get(id string) (DeadLetter, bool) {
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+	if i := ds.indexLocked(id); i >= 0 {
+		return *ds.items[i], true
+	}
+	return DeadLetter{}, false
+}
+
+// update replaces the stored entry with d, if it is still present.
+func (ds *deadLetterStore) update(d DeadLetter) {
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+	if i := ds.indexLocked(d.ID); i >= 0 {
+		ds.items[i] = &d
+		ds.saveLocked()
+	}
+}
+
+func (ds *deadLetterStore) remove(id string) bool {
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+	i := ds.indexLocked(id)
+	if i < 0 {
+		return false
+	}
+	ds.items = append(ds.items[:i], ds.items[i+1:]...)
+	ds.saveLocked()
+	return true
+}
+
+func (ds *deadLetterStore) indexLocked(id string) int {
+	for i, d := range ds.items {
+		if d.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+func (ds *deadLetterStore) load() {
+	data, err := os.ReadFile(ds.path)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			slog.Warn("webhook dead letters: failed to read", "path", ds.path, "err", err)
+		}
+		return
+	}
+	if err := json.Unmarshal(data, &ds.items); err != nil {
+		slog.Warn("webhook dead letters: failed to parse", "path", ds.path, "err", err)
+		ds.items = nil
+	}
+}
+
+func (ds *deadLetterStore) saveLocked() {
+	if ds.path == "" {
+		return
+	}
+	data, err := json.MarshalIndent(ds.items, "", "  ")
+	if err != nil {
+		slog.Warn("webhook dead letters: failed to encode", "err", err)
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(ds.path), 0750); err != nil {
+		slog.Warn("webhook dead letters: failed to create dir", "err", err)
+		return
+	}
+	// Atomic write: temp file + rename
+	tmpPath := ds.path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0600); err != nil {
+		slog.Warn("webhook dead letters: failed to write", "err", err)
+		return
+	}
+	if err := os.Rename(tmpPath, ds.path); err != nil {
+		slog.Warn("webhook dead letters: failed to replace", "err", err)
+	}
+}
+
+// ListDeadLetters returns failed webhook deliveries, oldest first,
+// optionally filtered by agent.
+func (s *Scheduler) ListDeadLetters(agentID string) []DeadLetter {
+	return s.webhooks.dead.list(agentID)
+}
+
+// ReplayDeadLetter makes one new delivery attempt for a dead letter. On
+// success the entry is removed; on failure it stays with the new error.
+func (s *Scheduler) ReplayDeadLetter(id string) (DeadLetter, error) {
+	d, ok := s.webhooks.dead.get(id)
+	if !ok {
+		return DeadLetter{}, fmt.Errorf("dead letter %q not found", id)
+	}
+
+	d.Replays++
+	d.Attempts++
+	status, err := s.webhooks.post(&d)
+	if err == nil {
+		s.webhooks.dead.remove(id)
+		slog.Info("webhook: dead letter replayed", "delivery", id, "task", d.TaskID)
+		d.LastStatus, d.LastError = status, ""
+		return d, nil
+	}
+	d.LastStatus, d.LastError = status, err.Error()
+	d.FailedAt = timeNow()
+	s.webhooks.dead.update(d)
+	return d, err
+}
+
+func (s *Scheduler) handleDeadLetterList(w http.ResponseWriter, r *http.Request) {
+	items := s.ListDeadLetters(r.URL.Query().Get("agentId"))
+	httpx.JSON(w, 200, map[string]any{
+		"deadLetters": items,
+		"count":       len(items),
+	})
+}
+
+func (s *Scheduler) handleDeadLetterGet(w http.ResponseWriter, r *http.Request) {
+	d, ok := s.webhooks.dead.get(r.PathValue("id"))
+	if !ok {
+		httpx.ErrorCode(w, 404, "not_found", "dead letter not found", false, nil)
+		return
+	}
+	httpx.JSON(w, 200, d)
+}
+
+func (s *Scheduler) handleDeadLetterReplay(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if _, ok := s.webhooks.dead.get(id); !ok {
+		httpx.ErrorCode(w, 404, "not_found", "dead letter not found", false, nil)
+		return
+	}
+	d, err := s.ReplayDeadLetter(id)
+	if err != nil {
+		httpx.ErrorCode(w, 502, "webhook_failed", err.Error(), true, map[string]any{
+			"deadLetter": d,
+		})
+		return
+	}
+	httpx.JSON(w, 200, map[string]any{
+		"delivered":  true,
+		"status":     d.LastStatus,
+		"deadLetter": d,
+	})
+}
+
+func (s *Scheduler) handleDeadLetterDelete(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if !s.webhooks.dead.remove(id) {
+		httpx.ErrorCode(w, 404, "not_found", "dead letter not found", false, nil)
+		return
+	}
+	httpx.JSON(w, 200, map[string]string{"status": "deleted", "id": id})
+}
@@ -12,6 +12,10 @@ import (
 	"github.com/pinchtab/pinchtab/internal/netguard"
 )
 
+// errCallbackUnresolved is returned when the callback host does not resolve.
+// Unlike the other validation errors it may well be transient.
+var errCallbackUnresolved = errors.New("could not resolve callback host")
+
 type callbackURLGuard struct{}
 
 func newCallbackURLGuard() *callbackURLGuard { return &callbackURLGuard{} }
@@ -70,13 +74,10 @@ func (g *callbackURLGuard) ValidateTarget(rawURL string) (*validatedCallbackTarg
 
 	ips, err := netguard.ResolveAndValidatePublicIPs(ctx, host)
 	if err != nil {
-		if errors.Is(err, netguard.ErrResolveHost) {
-			return nil, fmt.Errorf("could not resolve callback host")
-		}
 		if errors.Is(err, netguard.ErrPrivateInternalIP) {
 			return nil, fmt.Errorf("callback URL host is not allowed")
 		}
-		return nil, fmt.Errorf("could not resolve callback host")
+		return nil, errCallbackUnresolved
 	}
 	target.IPs = append(target.IPs, ips...)
 	return target, nil
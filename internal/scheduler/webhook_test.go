@@ -8,7 +8,9 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"path/filepath"
 	"strings"
+	"sync"
 	"sync/atomic"
 	"testing"
 	"time"
@@ -63,7 +65,7 @@ func configureWebhookTestTarget(t *testing.T, serverURL string, timeout time.Dur
 	return callbackURL, dialedAddr, cleanup
 }
 
-func TestSendWebhookSuccess(t *testing.T) {
+func TestDeliverWebhookSuccess(t *testing.T) {
 	var received atomic.Bool
 	var gotBody []byte
 	var gotHeaders http.Header
@@ -92,7 +94,7 @@ func TestSendWebhookSuccess(t *testing.T) {
 		State:   StateDone,
 	}
 
-	sendWebhook(callbackURL, task)
+	(&webhookSender{}).deliver(newWebhookDelivery(callbackURL, task))
 
 	if !received.Load() {
 		t.Fatal("webhook was never received")
@@ -125,23 +127,25 @@ func TestSendWebhookSuccess(t *testing.T) {
 	}
 }
 
-func TestSendWebhookEmptyURL(t *testing.T) {
-	// Should be a no-op, no panic.
-	sendWebhook("", &Task{ID: "tsk_empty"})
+func TestDeliverWebhookEmptyURL(t *testing.T) {
+	// No callback URL, nothing to deliver.
+	if d := newWebhookDelivery("", &Task{ID: "tsk_empty"}); d != nil {
+		t.Errorf("expected no delivery without a callback URL, got %+v", d)
+	}
 }
 
-func TestSendWebhookUnsupportedScheme(t *testing.T) {
-	sendWebhook("file:///etc/passwd", &Task{ID: "tsk_scheme_1"})
-	sendWebhook("ftp://malicious.host/data", &Task{ID: "tsk_scheme_2"})
+func TestDeliverWebhookUnsupportedScheme(t *testing.T) {
+	(&webhookSender{}).deliver(newWebhookDelivery("file:///etc/passwd", &Task{ID: "tsk_scheme_1"}))
+	(&webhookSender{}).deliver(newWebhookDelivery("ftp://malicious.host/data", &Task{ID: "tsk_scheme_2"}))
 }
 
-func TestSendWebhookInvalidURL(t *testing.T) {
-	sendWebhook("://bad-url", &Task{ID: "tsk_bad_url"})
+func TestDeliverWebhookInvalidURL(t *testing.T) {
+	(&webhookSender{}).deliver(newWebhookDelivery("://bad-url", &Task{ID: "tsk_bad_url"}))
 }
 
-func TestSendWebhookRejectedHost(t *testing.T) {
-	sendWebhook("http://127.0.0.1/hook", &Task{ID: "tsk_blocked"})
-	sendWebhook("http://169.254.169.254/latest/meta-data", &Task{ID: "tsk_blocked2"})
+func TestDeliverWebhookRejectedHost(t *testing.T) {
+	(&webhookSender{}).deliver(newWebhookDelivery("http://127.0.0.1/hook", &Task{ID: "tsk_blocked"}))
+	(&webhookSender{}).deliver(newWebhookDelivery("http://169.254.169.254/latest/meta-data", &Task{ID: "tsk_blocked2"}))
 }
 
 func TestValidateCallbackURLRejectsResolvedBlockedHost(t *testing.T) {
@@ -156,7 +160,7 @@ func TestValidateCallbackURLRejectsResolvedBlockedHost(t *testing.T) {
 	}
 }
 
-func TestSendWebhookServerError(t *testing.T) {
+func TestDeliverWebhookServerError(t *testing.T) {
 	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(500)
 	}))
@@ -165,10 +169,10 @@ func TestSendWebhookServerError(t *testing.T) {
 	callbackURL, _, cleanup := configureWebhookTestTarget(t, srv.URL, 10*time.Second)
 	defer cleanup()
 
-	sendWebhook(callbackURL, &Task{ID: "tsk_500", State: StateFailed})
+	(&webhookSender{}).deliver(newWebhookDelivery(callbackURL, &Task{ID: "tsk_500", State: StateFailed}))
 }
 
-func TestSendWebhookRedirectNotFollowed(t *testing.T) {
+func TestDeliverWebhookRedirectNotFollowed(t *testing.T) {
 	var firstHit atomic.Bool
 	var secondHit atomic.Bool
 
@@ -187,7 +191,7 @@ func TestSendWebhookRedirectNotFollowed(t *testing.T) {
 	callbackURL, _, cleanup := configureWebhookTestTarget(t, srv.URL, 10*time.Second)
 	defer cleanup()
 
-	sendWebhook(callbackURL, &Task{ID: "tsk_redirect"})
+	(&webhookSender{}).deliver(newWebhookDelivery(callbackURL, &Task{ID: "tsk_redirect"}))
 
 	if !firstHit.Load() {
 		t.Fatal("expected initial webhook target to receive the request")
@@ -197,7 +201,7 @@ func TestSendWebhookRedirectNotFollowed(t *testing.T) {
 	}
 }
 
-func TestSendWebhookTimeout(t *testing.T) {
+func TestDeliverWebhookTimeout(t *testing.T) {
 	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		time.Sleep(100 * time.Millisecond)
 		w.WriteHeader(200)
@@ -207,7 +211,7 @@ func TestSendWebhookTimeout(t *testing.T) {
 	callbackURL, _, cleanup := configureWebhookTestTarget(t, srv.URL, 10*time.Millisecond)
 	defer cleanup()
 
-	sendWebhook(callbackURL, &Task{ID: "tsk_timeout"})
+	(&webhookSender{}).deliver(newWebhookDelivery(callbackURL, &Task{ID: "tsk_timeout"}))
 }
 
 func TestWebhookFiredOnFinishTask(t *testing.T) {
@@ -268,3 +272,305 @@ func TestWebhookNotFiredWithoutCallback(t *testing.T) {
 		t.Error("webhook should not fire when no callbackUrl")
 	}
 }
+
+func TestWebhookSignedDelivery(t *testing.T) {
+	var gotHeaders http.Header
+	var gotBody []byte
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotHeaders = r.Header.Clone()
+		gotBody, _ = io.ReadAll(r.Body)
+		w.WriteHeader(204)
+	}))
+	defer srv.Close()
+
+	callbackURL, _, cleanup := configureWebhookTestTarget(t, srv.URL, 10*time.Second)
+	defer cleanup()
+
+	ws := &webhookSender{secret: "s3cret"}
+	ws.deliver(newWebhookDelivery(callbackURL, &Task{ID: "tsk_signed", State: StateDone}))
+
+	ts := gotHeaders.Get(webhookHeaderTimestamp)
+	if ts == "" {
+		t.Fatal("expected timestamp header")
+	}
+	if got, want := gotHeaders.Get(webhookHeaderSignature), signWebhook("s3cret", ts, gotBody); got != want {
+		t.Errorf("signature mismatch: got %q, want %q", got, want)
+	}
+	if !strings.HasPrefix(gotHeaders.Get(webhookHeaderDelivery), "whd_") {
+		t.Errorf("expected delivery id header, got %q", gotHeaders.Get(webhookHeaderDelivery))
+	}
+
+	// Unsigned senders leave the signature headers out.
+	(&webhookSender{}).deliver(newWebhookDelivery(callbackURL, &Task{ID: "tsk_unsigned", State: StateDone}))
+	if gotHeaders.Get(webhookHeaderSignature) != "" || gotHeaders.Get(webhookHeaderTimestamp) != "" {
+		t.Error("unsigned delivery should not carry signature headers")
+	}
+}
+
+func TestWebhookRetriesThenDelivers(t *testing.T) {
+	var hits atomic.Int32
+	var deliveries sync.Map
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		deliveries.Store(r.Header.Get(webhookHeaderDelivery), true)
+		if hits.Add(1) < 3 {
+			w.WriteHeader(503)
+			return
+		}
+		w.WriteHeader(200)
+	}))
+	defer srv.Close()
+
+	callbackURL, _, cleanup := configureWebhookTestTarget(t, srv.URL, 10*time.Second)
+	defer cleanup()
+
+	dead := newDeadLetterStore("")
+	ws := &webhookSender{retry: RetryPolicy{MaxAttempts: 3, BackoffMs: 1}, dead: dead}
+	ws.deliver(newWebhookDelivery(callbackURL, &Task{ID: "tsk_retry", State: StateDone}))
+
+	if hits.Load() != 3 {
+		t.Errorf("expected 3 attempts, got %d", hits.Load())
+	}
+	if n := len(dead.list("")); n != 0 {
+		t.Errorf("expected no dead letters, got %d", n)
+	}
+	count := 0
+	deliveries.Range(func(_, _ any) bool { count++; return true })
+	if count != 1 {
+		t.Errorf("retries should reuse the delivery id, saw %d ids", count)
+	}
+}
+
+func TestWebhookDeadLetterAndReplay(t *testing.T) {
+	var healthy atomic.Bool
+	var hits atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		if !healthy.Load() {
+			w.WriteHeader(500)
+			return
+		}
+		w.WriteHeader(200)
+	}))
+	defer srv.Close()
+
+	callbackURL, _, cleanup := configureWebhookTestTarget(t, srv.URL, 10*time.Second)
+	defer cleanup()
+
+	cfg := DefaultConfig()
+	cfg.JournalPath = filepath.Join(t.TempDir(), "scheduler", "tasks.jsonl")
+	cfg.WebhookMaxAttempts = 2
+	cfg.WebhookBackoff = time.Millisecond
+	s := New(cfg, &mockResolver{})
+	defer s.Stop()
+	mux := http.NewServeMux()
+	s.RegisterHandlers(mux)
+
+	s.webhooks.deliver(newWebhookDelivery(callbackURL, &Task{ID: "tsk_dead", AgentID: "a1", State: StateFailed}))
+	if hits.Load() != 2 {
+		t.Fatalf("expected 2 attempts, got %d", hits.Load())
+	}
+
+	items := s.ListDeadLetters("a1")
+	if len(items) != 1 || items[0].Attempts != 2 || items[0].LastStatus != 500 {
+		t.Fatalf("unexpected dead letters: %+v", items)
+	}
+	if got := s.GetMetrics().WebhooksDeadLettered; got != 1 {
+		t.Errorf("expected 1 dead-lettered webhook, got %d", got)
+	}
+	id := items[0].ID
+
+	// The list survives a restart.
+	if reloaded := newDeadLetterStore(s.webhooks.dead.path).list(""); len(reloaded) != 1 || reloaded[0].ID != id {
+		t.Fatalf("expected dead letter to be persisted, got %+v", reloaded)
+	}
+
+	req := httptest.NewRequest("POST", "/scheduler/webhooks/dead-letters/"+id+"/replay", nil)
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, req)
+	if w.Code != 502 {
+		t.Fatalf("expected 502 while receiver is down, got %d: %s", w.Code, w.Body.String())
+	}
+
+	healthy.Store(true)
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest("POST", "/scheduler/webhooks/dead-letters/"+id+"/replay", nil))
+	if w.Code != 200 {
+		t.Fatalf("expected 200 on successful replay, got %d: %s", w.Code, w.Body.String())
+	}
+	if n := len(s.ListDeadLetters("")); n != 0 {
+		t.Errorf("replayed dead letter should be removed, %d left", n)
+	}
+
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest("GET", "/scheduler/webhooks/dead-letters/"+id, nil))
+	if w.Code != 404 {
+		t.Errorf("expected 404 after replay, got %d", w.Code)
+	}
+}
+
+func TestWebhookRejectedTargetNotRetried(t *testing.T) {
+	dead := newDeadLetterStore("")
+	ws := &webhookSender{retry: RetryPolicy{MaxAttempts: 5, BackoffMs: 1000}, dead: dead}
+
+	start := time.Now()
+	ws.deliver(newWebhookDelivery("http://127.0.0.1/hook", &Task{ID: "tsk_blocked", State: StateDone}))
+	if time.Since(start) > 500*time.Millisecond {
+		t.Error("rejected callback should not be retried")
+	}
+	items := dead.list("")
+	if len(items) != 1 || items[0].Attempts != 1 || !strings.Contains(items[0].LastError, "callback rejected") {
+		t.Errorf("unexpected dead letters: %+v", items)
+	}
+}
+
+func TestWebhookRetriesUnresolvedHost(t *testing.T) {
+	var hits atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		w.WriteHeader(200)
+	}))
+	defer srv.Close()
+
+	callbackURL, _, cleanup := configureWebhookTestTarget(t, srv.URL, 10*time.Second)
+	defer cleanup()
+
+	// The first lookup fails, as a flaky resolver would.
+	var lookups atomic.Int32
+	resolve := netguard.ResolveHostIPs
+	netguard.ResolveHostIPs = func(ctx context.Context, network, host string) ([]net.IP, error) {
+		if lookups.Add(1) == 1 {
+			return nil, &net.DNSError{Err: "server misbehaving", Name: host, IsTemporary: true}
+		}
+		return resolve(ctx, network, host)
+	}
+
+	dead := newDeadLetterStore("")
+	ws := &webhookSender{retry: RetryPolicy{MaxAttempts: 3, BackoffMs: 1}, dead: dead}
+	ws.deliver(newWebhookDelivery(callbackURL, &Task{ID: "tsk_dns", State: StateDone}))
+
+	if hits.Load() != 1 || lookups.Load() != 2 {
+		t.Errorf("expected delivery on the second attempt, got %d hits after %d lookups", hits.Load(), lookups.Load())
+	}
+	if n := len(dead.list("")); n != 0 {
+		t.Errorf("expected no dead letters, got %d", n)
+	}
+}
+
+// newWebhookTestScheduler returns a scheduler whose webhooks retry quickly
+// and sign with a known secret.
+func newWebhookTestScheduler(t *testing.T, attempts int) *Scheduler {
+	t.Helper()
+	cfg := DefaultConfig()
+	cfg.WebhookSecret = "s3cret"
+	cfg.WebhookMaxAttempts = attempts
+	cfg.WebhookBackoff = time.Millisecond
+	s := New(cfg, &mockResolver{})
+	t.Cleanup(s.Stop)
+	return s
+}
+
+// waitForDeadLetters polls until the scheduler holds n dead letters.
+func waitForDeadLetters(t *testing.T, s *Scheduler, n int) []DeadLetter {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		items := s.ListDeadLetters("")
+		if len(items) >= n {
+			return items
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("expected %d dead letters, got %d", n, len(items))
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestWebhookNotifyRetriesServerErrors(t *testing.T) {
+	var hits atomic.Int32
+	var signed atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if r.Header.Get(webhookHeaderSignature) == signWebhook("s3cret", r.Header.Get(webhookHeaderTimestamp), body) {
+			signed.Add(1)
+		}
+		if hits.Add(1) < 3 {
+			w.WriteHeader(503)
+			return
+		}
+		w.WriteHeader(200)
+	}))
+	defer srv.Close()
+
+	callbackURL, _, cleanup := configureWebhookTestTarget(t, srv.URL, 10*time.Second)
+	defer cleanup()
+
+	s := newWebhookTestScheduler(t, 3)
+	s.notify(&Task{ID: "tsk_notify_retry", AgentID: "a1", State: StateDone, CallbackURL: callbackURL})
+
+	deadline := time.Now().Add(5 * time.Second)
+	for hits.Load() < 3 {
+		if time.Now().After(deadline) {
+			t.Fatalf("expected 3 attempts, got %d", hits.Load())
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	if signed.Load() != 3 {
+		t.Errorf("expected every attempt signed, %d of 3 were", signed.Load())
+	}
+	time.Sleep(50 * time.Millisecond)
+	if n := len(s.ListDeadLetters("")); n != 0 {
+		t.Errorf("expected no dead letters after a successful retry, got %d", n)
+	}
+}
+
+func TestWebhookNotifyDeadLettersAfterRetries(t *testing.T) {
+	var hits atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		w.WriteHeader(500)
+	}))
+	defer srv.Close()
+
+	callbackURL, _, cleanup := configureWebhookTestTarget(t, srv.URL, 10*time.Second)
+	defer cleanup()
+
+	s := newWebhookTestScheduler(t, 2)
+	s.notify(&Task{ID: "tsk_notify_dead", AgentID: "a1", State: StateFailed, CallbackURL: callbackURL})
+
+	items := waitForDeadLetters(t, s, 1)
+	if items[0].TaskID != "tsk_notify_dead" || items[0].Attempts != 2 || items[0].LastStatus != 500 {
+		t.Errorf("unexpected dead letter: %+v", items[0])
+	}
+	if hits.Load() != 2 {
+		t.Errorf("expected 2 attempts, got %d", hits.Load())
+	}
+	if got := s.GetMetrics().WebhooksDeadLettered; got != 1 {
+		t.Errorf("expected 1 dead-lettered webhook, got %d", got)
+	}
+}
+
+func TestWebhookNotifyDeadLettersWhenSaturated(t *testing.T) {
+	var hits atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		w.WriteHeader(200)
+	}))
+	defer srv.Close()
+
+	callbackURL, _, cleanup := configureWebhookTestTarget(t, srv.URL, 10*time.Second)
+	defer cleanup()
+
+	s := newWebhookTestScheduler(t, 3)
+	for range cap(s.webhookSem) {
+		s.webhookSem <- struct{}{}
+	}
+	s.notify(&Task{ID: "tsk_notify_busy", AgentID: "a1", State: StateDone, CallbackURL: callbackURL})
+
+	items := waitForDeadLetters(t, s, 1)
+	if items[0].TaskID != "tsk_notify_busy" || items[0].LastError != "too many in-flight deliveries" {
+		t.Errorf("unexpected dead letter: %+v", items[0])
+	}
+	if hits.Load() != 0 {
+		t.Errorf("a saturated sender should not post, got %d hits", hits.Load())
+	}
+}
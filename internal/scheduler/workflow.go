@@ -0,0 +1,351 @@
+package scheduler
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pinchtab/pinchtab/internal/httpx"
+)
+
+// TaskInput copies a field from an upstream task's result into a downstream
+// task's params once the upstream task is done. From names the upstream task
+// (a batch-local ID in requests, a task ID on the stored task) and Path is a
+// dot-separated path into its result; numeric segments index arrays and an
+// empty path copies the whole result.
+type TaskInput struct {
+	From string `json:"from"`
+	Path string `json:"path,omitempty"`
+}
+
+// WorkflowStatus is the aggregate view of the tasks created by one batch.
+type WorkflowStatus struct {
+	WorkflowID string                `json:"workflowId"`
+	AgentID    string                `json:"agentId"`
+	State      TaskState             `json:"state"`
+	Total      int                   `json:"total"`
+	Counts     map[TaskState]int     `json:"counts"`
+	Tasks      []WorkflowTaskSummary `json:"tasks"`
+}
+
+// WorkflowTaskSummary is one task inside a WorkflowStatus.
+type WorkflowTaskSummary struct {
+	ID        string    `json:"id"`
+	TaskID    string    `json:"taskId"`
+	State     TaskState `json:"state"`
+	DependsOn []string  `json:"dependsOn,omitempty"`
+	Error     string    `json:"error,omitempty"`
+}
+
+// isWorkflowBatch reports whether any task in the batch declares an ID,
+// dependencies or inputs, which turns the batch into a workflow.
+func isWorkflowBatch(defs []BatchTaskDef) bool {
+	for _, td := range defs {
+		if td.ID != "" || len(td.DependsOn) > 0 || len(td.Inputs) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// planWorkflow validates the dependency graph of a batch and returns the
+// task indexes in an order where every task follows its dependencies.
+func planWorkflow(defs []BatchTaskDef) ([]int, error) {
+	index := make(map[string]int, len(defs))
+	for i, td := range defs {
+		if td.ID == "" {
+			continue
+		}
+		if _, dup := index[td.ID]; dup {
+			return nil, fmt.Errorf("duplicate task id %q", td.ID)
+		}
+		index[td.ID] = i
+	}
+
+	for i, td := range defs {
+		for _, dep := range td.DependsOn {
+			if _, ok := index[dep]; !ok {
+				return nil, fmt.Errorf("task %d depends on unknown id %q", i, dep)
+			}
+			if dep == td.ID {
+				return nil, fmt.Errorf("task %q depends on itself", td.ID)
+			}
+		}
+		for name, in := range td.Inputs {
+			if !slices.Contains(td.DependsOn, in.From) {
+				return nil, fmt.Errorf("task %d input %q reads from %q, which is not in dependsOn", i, name, in.From)
+			}
+		}
+	}
+
+	// Kahn's algorithm, keeping the submitted order among ready tasks.
+	indegree := make([]int, len(defs))
+	dependents := make([][]int, len(defs))
+	for i, td := range defs {
+		indegree[i] = len(td.DependsOn)
+		for _, dep := range td.DependsOn {
+			dependents[index[dep]] = append(dependents[index[dep]], i)
+		}
+	}
+	var ready, order []int
+	for i := range defs {
+		if indegree[i] == 0 {
+			ready = append(ready, i)
+		}
+	}
+	for len(ready) > 0 {
+		sort.Ints(ready)
+		i := ready[0]
+		ready = ready[1:]
+		order = append(order, i)
+		for _, d := range dependents[i] {
+			indegree[d]--
+			if indegree[d] == 0 {
+				ready = append(ready, d)
+			}
+		}
+	}
+	if len(order) != len(defs) {
+		return nil, fmt.Errorf("dependency cycle detected")
+	}
+	return order, nil
+}
+
+// hold parks a task whose dependencies have not finished. Held tasks stay
+// in the queued state but are kept out of the queue until released. Each
+// reserves a queue slot, so a workflow is held to the queue limits when it
+// is submitted rather than when its tasks become ready.
+func (s *Scheduler) hold(t *Task) error {
+	if err := s.queue.Reserve(t.AgentID); err != nil {
+		return err
+	}
+	s.heldMu.Lock()
+	s.held[t.ID] = t
+	s.heldMu.Unlock()
+	return nil
+}
+
+// reviewHeld releases a held task once all its dependencies are done, or
+// cancels it as soon as one of them ends in any other terminal state.
+func (s *Scheduler) reviewHeld(t *Task) {
+	s.heldMu.Lock()
+	if _, ok := s.held[t.ID]; !ok {
+		s.heldMu.Unlock()
+		return
+	}
+	ready, failedDep, failedState := s.dependencyStatus(t)
+	if ready || failedDep != "" {
+		delete(s.held, t.ID)
+	}
+	s.heldMu.Unlock()
+
+	switch {
+	case failedDep != "":
+		s.queue.Unreserve(t.AgentID)
+		t.Error = fmt.Sprintf("dependency %s ended %s", failedDep, failedState)
+		if err := t.SetState(StateCancelled); err != nil {
+			slog.Warn("workflow: cancel dependent failed", "task", t.ID, "err", err)
+			return
+		}
+		s.metrics.recordCancel(t.AgentID)
+		slog.Info("workflow task cancelled", "task", t.ID, "workflow", t.WorkflowID, "dependency", failedDep, "dependencyState", failedState)
+		s.retire(t)
+	case ready:
+		s.release(t)
+	}
+}
+
+func (s *Scheduler) dependencyStatus(t *Task) (ready bool, failedDep string, failedState TaskState) {
+	ready = true
+	for _, dep := range t.DependsOn {
+		up := s.GetTask(dep)
+		if up == nil {
+			return false, dep, "missing"
+		}
+		state := up.GetState()
+		switch {
+		case state == StateDone:
+		case state.IsTerminal():
+			return false, dep, state
+		default:
+			ready = false
+		}
+	}
+	return ready, "", ""
+}
+
+// release applies a held task's inputs and puts it on the queue. Tasks
+// without an explicit deadline get the default one, counted from now.
+func (s *Scheduler) release(t *Task) {
+	if err := s.applyInputs(t); err != nil {
+		s.queue.Unreserve(t.AgentID)
+		t.Error = err.Error()
+		_ = t.SetState(StateFailed)
+		s.metrics.recordFail(t.AgentID)
+		slog.Info("workflow task failed", "task", t.ID, "workflow", t.WorkflowID, "err", err)
+		s.retire(t)
+		return
+	}
+
+	if t.Deadline.IsZero() {
+		t.Deadline = timeNow().Add(60 * time.Second)
+	}
+	t.Position = s.queue.EnqueueReserved(t)
+	s.results.Store(t)
+	slog.Info("workflow task released", "task", t.ID, "workflow", t.WorkflowID, "position", t.Position)
+}
+
+func (s *Scheduler) applyInputs(t *Task) error {
+	if len(t.Inputs) == 0 {
+		return nil
+	}
+	params := make(map[string]any, len(t.Params)+len(t.Inputs))
+	for k, v := range t.Params {
+		params[k] = v
+	}
+	for name, in := range t.Inputs {
+		up := s.GetTask(in.From)
+		if up == nil {
+			return fmt.Errorf("input %q: task %s not found", name, in.From)
+		}
+		v, err := lookupResultPath(up.Snapshot().Result, in.Path)
+		if err != nil {
+			return fmt.Errorf("input %q: %w", name, err)
+		}
+		params[name] = v
+	}
+	t.Params = params
+	return nil
+}
+
+// lookupResultPath walks a dot-separated path through a task result.
+func lookupResultPath(result any, path string) (any, error) {
+	// Round-trip through JSON so typed results look like decoded ones.
+	raw, err := json.Marshal(result)
+	if err != nil {
+		return nil, fmt.Errorf("encode result: %w", err)
+	}
+	var cur any
+	if err := json.Unmarshal(raw, &cur); err != nil {
+		return nil, fmt.Errorf("decode result: %w", err)
+	}
+	if path == "" {
+		return cur, nil
+	}
+
+	for _, seg := range strings.Split(path, ".") {
+		switch node := cur.(type) {
+		case map[string]any:
+			v, ok := node[seg]
+			if !ok {
+				return nil, fmt.Errorf("path %q: no field %q", path, seg)
+			}
+			cur = v
+		case []any:
+			i, err := strconv.Atoi(seg)
+			if err != nil || i < 0 || i >= len(node) {
+				return nil, fmt.Errorf("path %q: invalid index %q", path, seg)
+			}
+			cur = node[i]
+		default:
+			return nil, fmt.Errorf("path %q: cannot descend into %q", path, seg)
+		}
+	}
+	return cur, nil
+}
+
+// resolveDependents re-checks every held task that waits on t.
+func (s *Scheduler) resolveDependents(t *Task) {
+	s.heldMu.Lock()
+	var waiting []*Task
+	for _, h := range s.held {
+		if slices.Contains(h.DependsOn, t.ID) {
+			waiting = append(waiting, h)
+		}
+	}
+	s.heldMu.Unlock()
+
+	for _, h := range waiting {
+		s.reviewHeld(h)
+	}
+}
+
+// GetWorkflow returns the aggregate status of a workflow, or nil if none of
+// its tasks are known.
+func (s *Scheduler) GetWorkflow(workflowID string) *WorkflowStatus {
+	var tasks []*Task
+	for _, t := range s.results.List("", nil) {
+		if t.WorkflowID == workflowID {
+			tasks = append(tasks, t)
+		}
+	}
+	if len(tasks) == 0 {
+		return nil
+	}
+	sort.Slice(tasks, func(i, j int) bool {
+		return tasks[i].CreatedAt.Before(tasks[j].CreatedAt)
+	})
+
+	refs := make(map[string]string, len(tasks))
+	for _, t := range tasks {
+		refs[t.ID] = t.WorkflowRef
+	}
+
+	ws := &WorkflowStatus{
+		WorkflowID: workflowID,
+		AgentID:    tasks[0].AgentID,
+		Total:      len(tasks),
+		Counts:     make(map[TaskState]int),
+		Tasks:      make([]WorkflowTaskSummary, 0, len(tasks)),
+	}
+	allTerminal := true
+	for _, t := range tasks {
+		ws.Counts[t.State]++
+		if !t.State.IsTerminal() {
+			allTerminal = false
+		}
+		deps := make([]string, 0, len(t.DependsOn))
+		for _, d := range t.DependsOn {
+			deps = append(deps, refs[d])
+		}
+		ws.Tasks = append(ws.Tasks, WorkflowTaskSummary{
+			ID:        t.WorkflowRef,
+			TaskID:    t.ID,
+			State:     t.State,
+			DependsOn: deps,
+			Error:     t.Error,
+		})
+	}
+
+	switch {
+	case !allTerminal:
+		ws.State = StateRunning
+	case ws.Counts[StateDone] == len(tasks):
+		ws.State = StateDone
+	case ws.Counts[StateFailed]+ws.Counts[StateRejected] > 0:
+		ws.State = StateFailed
+	default:
+		ws.State = StateCancelled
+	}
+	return ws
+}
+
+func (s *Scheduler) handleWorkflowGet(w http.ResponseWriter, r *http.Request) {
+	ws := s.GetWorkflow(r.PathValue("id"))
+	if ws == nil {
+		httpx.ErrorCode(w, 404, "not_found", "workflow not found", false, nil)
+		return
+	}
+	httpx.JSON(w, 200, ws)
+}
+
+// generateWorkflowID produces a random workflow ID in the format wf_XXXXXXXX.
+func generateWorkflowID() string {
+	return "wf_" + strings.TrimPrefix(generateTaskID(), "tsk_")
+}
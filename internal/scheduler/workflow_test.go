@@ -0,0 +1,222 @@
+package scheduler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestPlanWorkflow(t *testing.T) {
+	order, err := planWorkflow([]BatchTaskDef{
+		{ID: "c", DependsOn: []string{"a", "b"}},
+		{ID: "a"},
+		{ID: "b", DependsOn: []string{"a"}},
+	})
+	if err != nil {
+		t.Fatalf("plan failed: %v", err)
+	}
+	if len(order) != 3 || order[0] != 1 || order[1] != 2 || order[2] != 0 {
+		t.Errorf("expected order [1 2 0], got %v", order)
+	}
+
+	bad := []struct {
+		name string
+		defs []BatchTaskDef
+	}{
+		{"duplicate id", []BatchTaskDef{{ID: "a"}, {ID: "a"}}},
+		{"unknown dependency", []BatchTaskDef{{ID: "a", DependsOn: []string{"x"}}}},
+		{"self dependency", []BatchTaskDef{{ID: "a", DependsOn: []string{"a"}}}},
+		{"cycle", []BatchTaskDef{{ID: "a", DependsOn: []string{"b"}}, {ID: "b", DependsOn: []string{"a"}}}},
+		{"input not a dependency", []BatchTaskDef{{ID: "a"}, {ID: "b", Inputs: map[string]TaskInput{"url": {From: "a"}}}}},
+	}
+	for _, tt := range bad {
+		if _, err := planWorkflow(tt.defs); err == nil {
+			t.Errorf("%s: expected error", tt.name)
+		}
+	}
+}
+
+func TestLookupResultPath(t *testing.T) {
+	result := map[string]any{
+		"links": []any{map[string]any{"href": "https://pinchtab.com"}},
+	}
+	v, err := lookupResultPath(result, "links.0.href")
+	if err != nil || v != "https://pinchtab.com" {
+		t.Errorf("expected href, got %v (err=%v)", v, err)
+	}
+	if _, err := lookupResultPath(result, "links.3.href"); err == nil {
+		t.Error("expected error for out of range index")
+	}
+	if _, err := lookupResultPath(result, "missing"); err == nil {
+		t.Error("expected error for missing field")
+	}
+}
+
+func submitWorkflow(t *testing.T, mux *http.ServeMux, body string) map[string]any {
+	t.Helper()
+	req := httptest.NewRequest("POST", "/tasks/batch", strings.NewReader(body))
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, req)
+	if w.Code != 202 {
+		t.Fatalf("expected 202, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp map[string]any
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	return resp
+}
+
+func TestWorkflowReleasesDependentsWithInputs(t *testing.T) {
+	s, mux, executor := setupHandlerTest(t)
+	defer executor.Close()
+
+	resp := submitWorkflow(t, mux, `{
+		"agentId": "a1",
+		"tasks": [
+			{"id":"open","action":"navigate","tabId":"tab-1","dependsOn":["find"],"inputs":{"url":{"from":"find","path":"success"}}},
+			{"id":"find","action":"find","tabId":"tab-1"}
+		]
+	}`)
+	wfID, _ := resp["workflowId"].(string)
+	if !strings.HasPrefix(wfID, "wf_") {
+		t.Fatalf("expected workflow id, got %v", resp["workflowId"])
+	}
+	items := resp["tasks"].([]any)
+	openID := items[0].(map[string]any)["taskId"].(string)
+	findID := items[1].(map[string]any)["taskId"].(string)
+
+	if got := s.QueueStats().TotalQueued; got != 1 {
+		t.Fatalf("only the upstream task should be queued, got %d", got)
+	}
+
+	upstream := s.queue.Dequeue(s.inflightLimits())
+	if upstream == nil || upstream.ID != findID {
+		t.Fatalf("expected %s to be dequeued first, got %v", findID, upstream)
+	}
+	s.dispatch(upstream)
+
+	open := s.GetTask(openID)
+	if got := s.QueueStats().TotalQueued; got != 1 {
+		t.Fatalf("dependent should be queued after upstream finished, got %d", got)
+	}
+	if open.Deadline.IsZero() {
+		t.Error("released task should get a deadline")
+	}
+	if open.Params["url"] != true {
+		t.Errorf("expected input copied into params, got %v", open.Params)
+	}
+
+	ws := s.GetWorkflow(wfID)
+	if ws == nil || ws.State != StateRunning || ws.Total != 2 {
+		t.Fatalf("unexpected workflow status: %+v", ws)
+	}
+
+	s.dispatch(s.queue.Dequeue(s.inflightLimits()))
+	if ws = s.GetWorkflow(wfID); ws.State != StateDone {
+		t.Errorf("expected workflow done, got %s", ws.State)
+	}
+}
+
+func TestWorkflowFailureCancelsDependents(t *testing.T) {
+	s, mux, executor := setupHandlerTest(t)
+	defer executor.Close()
+
+	resp := submitWorkflow(t, mux, `{
+		"agentId": "a1",
+		"tasks": [
+			{"id":"a","action":"click","tabId":"tab-1"},
+			{"id":"b","action":"click","tabId":"tab-1","dependsOn":["a"]},
+			{"id":"c","action":"click","tabId":"tab-1","dependsOn":["b"]}
+		]
+	}`)
+	wfID := resp["workflowId"].(string)
+	items := resp["tasks"].([]any)
+	aID := items[0].(map[string]any)["taskId"].(string)
+
+	if err := s.Cancel(aID); err != nil {
+		t.Fatalf("cancel failed: %v", err)
+	}
+
+	for _, item := range items[1:] {
+		task := s.GetTask(item.(map[string]any)["taskId"].(string))
+		if task.GetState() != StateCancelled {
+			t.Errorf("expected dependent %s cancelled, got %s", task.WorkflowRef, task.GetState())
+		}
+		if !strings.Contains(task.Error, "dependency") {
+			t.Errorf("expected dependency error, got %q", task.Error)
+		}
+	}
+
+	ws := s.GetWorkflow(wfID)
+	if ws.State != StateCancelled || ws.Counts[StateCancelled] != 3 {
+		t.Errorf("unexpected workflow status: %+v", ws)
+	}
+	if deps := ws.Tasks[2].DependsOn; len(deps) != 1 || deps[0] != "b" {
+		t.Errorf("expected summary to use batch ids, got %v", deps)
+	}
+}
+
+func TestWorkflowHandlers(t *testing.T) {
+	_, mux, executor := setupHandlerTest(t)
+	defer executor.Close()
+
+	req := httptest.NewRequest("POST", "/tasks/batch", strings.NewReader(`{
+		"agentId": "a1",
+		"tasks": [{"id":"a","action":"click","dependsOn":["a"]}]
+	}`))
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, req)
+	if w.Code != 400 || !strings.Contains(w.Body.String(), "invalid_workflow") {
+		t.Errorf("expected 400 invalid_workflow, got %d: %s", w.Code, w.Body.String())
+	}
+
+	resp := submitWorkflow(t, mux, `{"agentId":"a1","tasks":[{"id":"a","action":"click"}]}`)
+	req = httptest.NewRequest("GET", "/tasks/workflows/"+resp["workflowId"].(string), nil)
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, req)
+	if w.Code != 200 {
+		t.Errorf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	req = httptest.NewRequest("GET", "/tasks/workflows/wf_missing", nil)
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, req)
+	if w.Code != 404 {
+		t.Errorf("expected 404, got %d", w.Code)
+	}
+}
+
+func TestWorkflowHeldTasksCountAgainstLimits(t *testing.T) {
+	s, mux, executor := setupHandlerTest(t)
+	defer executor.Close()
+	s.queue.SetLimits(0, 2)
+
+	resp := submitWorkflow(t, mux, `{
+		"agentId": "a1",
+		"tasks": [
+			{"id":"a","action":"find","tabId":"tab-1"},
+			{"id":"b","action":"find","tabId":"tab-1","dependsOn":["a"]},
+			{"id":"c","action":"find","tabId":"tab-1","dependsOn":["b"]}
+		]
+	}`)
+	items := resp["tasks"].([]any)
+	if state := items[2].(map[string]any)["state"]; state != string(StateRejected) {
+		t.Fatalf("third task should be rejected by the agent limit, got %v", items[2])
+	}
+	stats := s.QueueStats()
+	if stats.TotalQueued != 1 || stats.TotalHeld != 1 || stats.Agents["a1"].Held != 1 {
+		t.Fatalf("unexpected queue stats: %+v", stats)
+	}
+	if _, err := s.Submit(SubmitRequest{AgentID: "a1", Action: "find", TabID: "tab-1"}); err == nil {
+		t.Error("held task should keep its slot against new submissions")
+	}
+
+	// Releasing the held task moves its slot onto the queue.
+	s.dispatch(s.queue.Dequeue(s.inflightLimits()))
+	if stats = s.QueueStats(); stats.TotalQueued != 1 || stats.TotalHeld != 0 {
+		t.Errorf("unexpected queue stats after release: %+v", stats)
+	}
+}
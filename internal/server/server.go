@@ -220,6 +220,38 @@ func RunDashboard(cfg *config.RuntimeConfig, version string) {
 		if cfg.Scheduler.WorkerCount > 0 {
 			schedCfg.WorkerCount = cfg.Scheduler.WorkerCount
 		}
+		if cfg.Scheduler.Persist {
+			schedCfg.JournalPath = filepath.Join(cfg.StateDir, "scheduler", "tasks.jsonl")
+		}
+		if cfg.Scheduler.RecoverRunning != "" {
+			schedCfg.RecoverRunning = cfg.Scheduler.RecoverRunning
+		}
+		if cfg.Scheduler.RetryMaxAttempts > 0 {
+			schedCfg.Retry.MaxAttempts = cfg.Scheduler.RetryMaxAttempts
+		}
+		if cfg.Scheduler.RetryBackoffMs > 0 {
+			schedCfg.Retry.BackoffMs = cfg.Scheduler.RetryBackoffMs
+		}
+		if cfg.Scheduler.RetryMaxBackoffMs > 0 {
+			schedCfg.Retry.MaxBackoffMs = cfg.Scheduler.RetryMaxBackoffMs
+		}
+		if len(cfg.Scheduler.RetryOn) > 0 {
+			schedCfg.Retry.RetryOn = cfg.Scheduler.RetryOn
+		}
+		schedCfg.MaxTabs = cfg.MaxTabs
+		schedCfg.WebhookSecret = cfg.Scheduler.WebhookSecret
+		if cfg.Scheduler.WebhookMaxAttempts > 0 {
+			schedCfg.WebhookMaxAttempts = cfg.Scheduler.WebhookMaxAttempts
+		}
+		if cfg.Scheduler.WebhookBackoffMs > 0 {
+			schedCfg.WebhookBackoff = time.Duration(cfg.Scheduler.WebhookBackoffMs) * time.Millisecond
+		}
+		if len(cfg.Scheduler.AgentGroups) > 0 {
+			schedCfg.AgentGroups = make(map[string]scheduler.AgentPolicy, len(cfg.Scheduler.AgentGroups))
+			for name, g := range cfg.Scheduler.AgentGroups {
+				schedCfg.AgentGroups[name] = scheduler.AgentPolicy(g)
+			}
+		}
 
 		resolver := &scheduler.ManagerResolver{Mgr: orch.InstanceManager()}
 		sched = scheduler.New(schedCfg, resolver)
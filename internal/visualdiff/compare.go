@@ -0,0 +1,332 @@
+// Package visualdiff compares screenshots pixel by pixel and keeps named
+// baseline screenshots on disk.
+//
+// The comparison follows pixelmatch: colours are compared in YIQ space, so
+// the threshold matches perceived difference, and pixels that differ only
+// because of anti-aliasing can be told apart from real changes.
+package visualdiff
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+	"sort"
+)
+
+// DefaultThreshold is the per-pixel colour threshold used when none is set.
+const DefaultThreshold = 0.1
+
+// maxYIQDelta is the largest possible YIQ distance between two colours.
+const maxYIQDelta = 35215
+
+// regionCell is the size, in pixels, of the grid used to group changed
+// pixels into regions.
+const regionCell = 8
+
+// Rect is an axis-aligned rectangle in image pixels.
+type Rect struct {
+	X      int `json:"x"`
+	Y      int `json:"y"`
+	Width  int `json:"width"`
+	Height int `json:"height"`
+}
+
+func (r Rect) image() image.Rectangle {
+	return image.Rect(r.X, r.Y, r.X+r.Width, r.Y+r.Height)
+}
+
+// Region is a group of nearby changed pixels.
+type Region struct {
+	Rect
+	Pixels int `json:"pixels"`
+}
+
+// Options control how two images are compared.
+type Options struct {
+	// Threshold is the colour difference, from 0 to 1, above which a pixel
+	// counts as changed. Zero means DefaultThreshold.
+	Threshold float64
+	// IncludeAntialiasing counts pixels that look like anti-aliasing as
+	// changed. By default they are tolerated and shown in yellow.
+	IncludeAntialiasing bool
+	// Masks are areas that are not compared.
+	Masks []Rect
+}
+
+// Result is the outcome of a comparison.
+type Result struct {
+	Width           int          `json:"width"`
+	Height          int          `json:"height"`
+	ComparedPixels  int          `json:"comparedPixels"`
+	ChangedPixels   int          `json:"changedPixels"`
+	AntialiasPixels int          `json:"antialiasPixels"`
+	MismatchPercent float64      `json:"mismatchPercent"`
+	SizeChanged     bool         `json:"sizeChanged,omitempty"`
+	Regions         []Region     `json:"regions"`
+	Diff            *image.NRGBA `json:"-"`
+}
+
+var (
+	changedColor   = color.NRGBA{R: 255, A: 255}
+	antialiasColor = color.NRGBA{R: 255, G: 255, A: 255}
+	maskColor      = color.NRGBA{R: 70, G: 110, B: 255, A: 255}
+)
+
+// Compare compares current against baseline. When the sizes differ the
+// images are aligned at the top-left corner and every pixel that only one
+// of them covers counts as changed.
+//
+// The diff image is the baseline faded to grey with changed pixels in red,
+// tolerated anti-aliasing in yellow and masked areas tinted blue.
+func Compare(baseline, current image.Image, opts Options) Result {
+	a, b := toNRGBA(baseline), toNRGBA(current)
+	threshold := opts.Threshold
+	if threshold <= 0 {
+		threshold = DefaultThreshold
+	}
+	maxDelta := maxYIQDelta * threshold * threshold
+
+	aw, ah := a.Rect.Dx(), a.Rect.Dy()
+	bw, bh := b.Rect.Dx(), b.Rect.Dy()
+	w, h := max(aw, bw), max(ah, bh)
+	res := Result{Width: w, Height: h, SizeChanged: aw != bw || ah != bh}
+	diff := image.NewNRGBA(image.Rect(0, 0, w, h))
+
+	masked := make([]bool, w*h)
+	for _, m := range opts.Masks {
+		r := m.image().Intersect(diff.Rect)
+		for y := r.Min.Y; y < r.Max.Y; y++ {
+			for x := r.Min.X; x < r.Max.X; x++ {
+				masked[y*w+x] = true
+			}
+		}
+	}
+
+	changed := make([]bool, w*h)
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			i := y*w + x
+			if masked[i] {
+				var base color.NRGBA
+				if x < aw && y < ah {
+					base = a.NRGBAAt(x, y)
+				}
+				diff.SetNRGBA(x, y, blend(fade(base), maskColor, 0.35))
+				continue
+			}
+			res.ComparedPixels++
+			if x >= aw || y >= ah || x >= bw || y >= bh {
+				changed[i] = true
+				res.ChangedPixels++
+				diff.SetNRGBA(x, y, changedColor)
+				continue
+			}
+			pa, pb := a.NRGBAAt(x, y), b.NRGBAAt(x, y)
+			if pa == pb || math.Abs(colorDelta(pa, pb, false)) <= maxDelta {
+				diff.SetNRGBA(x, y, fade(pa))
+				continue
+			}
+			if !opts.IncludeAntialiasing && (antialiased(a, x, y, b) || antialiased(b, x, y, a)) {
+				res.AntialiasPixels++
+				diff.SetNRGBA(x, y, antialiasColor)
+				continue
+			}
+			changed[i] = true
+			res.ChangedPixels++
+			diff.SetNRGBA(x, y, changedColor)
+		}
+	}
+
+	if res.ComparedPixels > 0 {
+		pct := float64(res.ChangedPixels) * 100 / float64(res.ComparedPixels)
+		res.MismatchPercent = math.Round(pct*1000) / 1000
+	}
+	res.Regions = regions(changed, w, h)
+	res.Diff = diff
+	return res
+}
+
+func toNRGBA(img image.Image) *image.NRGBA {
+	if n, ok := img.(*image.NRGBA); ok && n.Rect.Min == (image.Point{}) {
+		return n
+	}
+	b := img.Bounds()
+	n := image.NewNRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(n, n.Rect, img, b.Min, draw.Src)
+	return n
+}
+
+// colorDelta returns the YIQ distance between two colours blended over
+// white. With yOnly it returns the signed brightness difference instead.
+func colorDelta(p, q color.NRGBA, yOnly bool) float64 {
+	r1, g1, b1 := overWhite(p)
+	r2, g2, b2 := overWhite(q)
+	y := rgb2y(r1, g1, b1) - rgb2y(r2, g2, b2)
+	if yOnly {
+		return y
+	}
+	i := rgb2i(r1, g1, b1) - rgb2i(r2, g2, b2)
+	qq := rgb2q(r1, g1, b1) - rgb2q(r2, g2, b2)
+	return 0.5053*y*y + 0.299*i*i + 0.1957*qq*qq
+}
+
+func overWhite(c color.NRGBA) (r, g, b float64) {
+	a := float64(c.A) / 255
+	return 255 + (float64(c.R)-255)*a, 255 + (float64(c.G)-255)*a, 255 + (float64(c.B)-255)*a
+}
+
+func rgb2y(r, g, b float64) float64 { return r*0.29889531 + g*0.58662247 + b*0.11448223 }
+func rgb2i(r, g, b float64) float64 { return r*0.59597799 - g*0.27417610 - b*0.32180189 }
+func rgb2q(r, g, b float64) float64 { return r*0.21147017 - g*0.52261711 + b*0.31114694 }
+
+// antialiased reports whether the pixel at x, y of img looks like an
+// anti-aliased edge: it sits between a darkest and a brightest neighbour,
+// and one of those lies in a flat area of both images.
+func antialiased(img *image.NRGBA, x, y int, other *image.NRGBA) bool {
+	w, h := img.Rect.Dx(), img.Rect.Dy()
+	x0, y0 := max(x-1, 0), max(y-1, 0)
+	x1, y1 := min(x+1, w-1), min(y+1, h-1)
+	zeroes := 0
+	if x == x0 || x == x1 || y == y0 || y == y1 {
+		zeroes = 1
+	}
+	var minDelta, maxDelta float64
+	var minX, minY, maxX, maxY int
+	c := img.NRGBAAt(x, y)
+	for nx := x0; nx <= x1; nx++ {
+		for ny := y0; ny <= y1; ny++ {
+			if nx == x && ny == y {
+				continue
+			}
+			delta := colorDelta(c, img.NRGBAAt(nx, ny), true)
+			switch {
+			case delta == 0:
+				zeroes++
+				if zeroes > 2 {
+					return false
+				}
+			case delta < minDelta:
+				minDelta, minX, minY = delta, nx, ny
+			case delta > maxDelta:
+				maxDelta, maxX, maxY = delta, nx, ny
+			}
+		}
+	}
+	if minDelta == 0 || maxDelta == 0 {
+		return false
+	}
+	return (flatAround(img, minX, minY) && flatAround(other, minX, minY)) ||
+		(flatAround(img, maxX, maxY) && flatAround(other, maxX, maxY))
+}
+
+// flatAround reports whether at least three neighbours of x, y share its
+// exact colour.
+func flatAround(img *image.NRGBA, x, y int) bool {
+	w, h := img.Rect.Dx(), img.Rect.Dy()
+	if x >= w || y >= h {
+		return false
+	}
+	x0, y0 := max(x-1, 0), max(y-1, 0)
+	x1, y1 := min(x+1, w-1), min(y+1, h-1)
+	zeroes := 0
+	if x == x0 || x == x1 || y == y0 || y == y1 {
+		zeroes = 1
+	}
+	c := img.NRGBAAt(x, y)
+	for nx := x0; nx <= x1; nx++ {
+		for ny := y0; ny <= y1; ny++ {
+			if nx == x && ny == y {
+				continue
+			}
+			if img.NRGBAAt(nx, ny) == c {
+				zeroes++
+				if zeroes > 2 {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// fade turns a colour into a light grey of the same brightness.
+func fade(c color.NRGBA) color.NRGBA {
+	r, g, b := overWhite(c)
+	v := uint8(255 + (rgb2y(r, g, b)-255)*0.1)
+	return color.NRGBA{R: v, G: v, B: v, A: 255}
+}
+
+func blend(c, over color.NRGBA, alpha float64) color.NRGBA {
+	mix := func(x, y uint8) uint8 { return uint8(float64(x)*(1-alpha) + float64(y)*alpha) }
+	return color.NRGBA{R: mix(c.R, over.R), G: mix(c.G, over.G), B: mix(c.B, over.B), A: 255}
+}
+
+// regions groups changed pixels that are within one grid cell of each other
+// and returns their bounding boxes, top to bottom.
+func regions(changed []bool, w, h int) []Region {
+	cw, ch := (w+regionCell-1)/regionCell, (h+regionCell-1)/regionCell
+	counts := make([]int, cw*ch)
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			if changed[y*w+x] {
+				counts[(y/regionCell)*cw+x/regionCell]++
+			}
+		}
+	}
+
+	out := []Region{}
+	seen := make([]bool, len(counts))
+	var stack []int
+	for start, n := range counts {
+		if n == 0 || seen[start] {
+			continue
+		}
+		seen[start] = true
+		stack = append(stack[:0], start)
+		minX, minY, maxX, maxY := cw, ch, -1, -1
+		pixels := 0
+		for len(stack) > 0 {
+			cell := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			cx, cy := cell%cw, cell/cw
+			minX, minY, maxX, maxY = min(minX, cx), min(minY, cy), max(maxX, cx), max(maxY, cy)
+			pixels += counts[cell]
+			for dy := -1; dy <= 1; dy++ {
+				for dx := -1; dx <= 1; dx++ {
+					nx, ny := cx+dx, cy+dy
+					if nx < 0 || ny < 0 || nx >= cw || ny >= ch {
+						continue
+					}
+					if n := ny*cw + nx; counts[n] > 0 && !seen[n] {
+						seen[n] = true
+						stack = append(stack, n)
+					}
+				}
+			}
+		}
+		out = append(out, Region{Rect: tighten(changed, w, h, minX, minY, maxX, maxY), Pixels: pixels})
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		if out[i].Y != out[j].Y {
+			return out[i].Y < out[j].Y
+		}
+		return out[i].X < out[j].X
+	})
+	return out
+}
+
+// tighten shrinks a box of grid cells to the changed pixels inside it.
+func tighten(changed []bool, w, h, minCX, minCY, maxCX, maxCY int) Rect {
+	x0, y0 := minCX*regionCell, minCY*regionCell
+	x1, y1 := min((maxCX+1)*regionCell, w), min((maxCY+1)*regionCell, h)
+	minX, minY, maxX, maxY := x1, y1, x0-1, y0-1
+	for y := y0; y < y1; y++ {
+		for x := x0; x < x1; x++ {
+			if changed[y*w+x] {
+				minX, minY, maxX, maxY = min(minX, x), min(minY, y), max(maxX, x), max(maxY, y)
+			}
+		}
+	}
+	return Rect{X: minX, Y: minY, Width: maxX - minX + 1, Height: maxY - minY + 1}
+}
@@ -0,0 +1,173 @@
+package visualdiff
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ErrNotFound is returned when a baseline does not exist.
+var ErrNotFound = errors.New("baseline not found")
+
+var validName = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]{0,63}$`)
+
+// Mask is an area left out of comparisons: a rectangle in CSS pixels
+// relative to the captured area, or the element a selector matches.
+type Mask struct {
+	Selector string  `json:"selector,omitempty"`
+	X        float64 `json:"x,omitempty"`
+	Y        float64 `json:"y,omitempty"`
+	Width    float64 `json:"width,omitempty"`
+	Height   float64 `json:"height,omitempty"`
+}
+
+// Baseline describes a stored baseline screenshot.
+type Baseline struct {
+	Name      string    `json:"name"`
+	URL       string    `json:"url,omitempty"`
+	Selector  string    `json:"selector,omitempty"`
+	FullPage  bool      `json:"fullPage,omitempty"`
+	Masks     []Mask    `json:"masks,omitempty"`
+	Width     int       `json:"width"`
+	Height    int       `json:"height"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// Dir returns the directory baselines are stored in.
+func Dir(stateDir string) string {
+	return filepath.Join(stateDir, "baselines")
+}
+
+// ValidateName checks that a baseline name is safe to use as a file name.
+func ValidateName(name string) error {
+	if !validName.MatchString(name) || strings.Contains(name, "..") {
+		return fmt.Errorf("invalid baseline name %q: use up to 64 letters, digits, '.', '_' or '-'", name)
+	}
+	return nil
+}
+
+func paths(stateDir, name string) (img, meta string) {
+	dir := Dir(stateDir)
+	return filepath.Join(dir, name+".png"), filepath.Join(dir, name+".json")
+}
+
+// Save stores a PNG screenshot as the named baseline, replacing any
+// existing one. Width and height are taken from the image.
+func Save(stateDir string, b *Baseline, pngData []byte) error {
+	if err := ValidateName(b.Name); err != nil {
+		return err
+	}
+	cfg, err := png.DecodeConfig(bytes.NewReader(pngData))
+	if err != nil {
+		return fmt.Errorf("decode screenshot: %w", err)
+	}
+	b.Width, b.Height = cfg.Width, cfg.Height
+	if b.CreatedAt.IsZero() {
+		b.CreatedAt = time.Now().UTC()
+	}
+	meta, err := json.MarshalIndent(b, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal baseline: %w", err)
+	}
+	if err := os.MkdirAll(Dir(stateDir), 0750); err != nil {
+		return fmt.Errorf("create baselines dir: %w", err)
+	}
+	imgPath, metaPath := paths(stateDir, b.Name)
+	if err := os.WriteFile(imgPath, pngData, 0600); err != nil {
+		return fmt.Errorf("write baseline: %w", err)
+	}
+	if err := os.WriteFile(metaPath, meta, 0600); err != nil {
+		return fmt.Errorf("write baseline: %w", err)
+	}
+	return nil
+}
+
+// Load returns a baseline's metadata and PNG bytes.
+func Load(stateDir, name string) (*Baseline, []byte, error) {
+	if err := ValidateName(name); err != nil {
+		return nil, nil, err
+	}
+	imgPath, metaPath := paths(stateDir, name)
+	data, err := os.ReadFile(imgPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil, ErrNotFound
+		}
+		return nil, nil, fmt.Errorf("read baseline: %w", err)
+	}
+	b := &Baseline{Name: name}
+	if meta, err := os.ReadFile(metaPath); err == nil {
+		if err := json.Unmarshal(meta, b); err != nil {
+			return nil, nil, fmt.Errorf("parse baseline: %w", err)
+		}
+	}
+	return b, data, nil
+}
+
+// Decode decodes PNG bytes.
+func Decode(pngData []byte) (image.Image, error) {
+	img, err := png.Decode(bytes.NewReader(pngData))
+	if err != nil {
+		return nil, fmt.Errorf("decode png: %w", err)
+	}
+	return img, nil
+}
+
+// Encode encodes an image as PNG.
+func Encode(img image.Image) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// List returns every stored baseline, sorted by name.
+func List(stateDir string) ([]Baseline, error) {
+	entries, err := os.ReadDir(Dir(stateDir))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []Baseline{}, nil
+		}
+		return nil, fmt.Errorf("read baselines dir: %w", err)
+	}
+	out := []Baseline{}
+	for _, e := range entries {
+		name, ok := strings.CutSuffix(e.Name(), ".png")
+		if e.IsDir() || !ok || ValidateName(name) != nil {
+			continue
+		}
+		b, _, err := Load(stateDir, name)
+		if err != nil {
+			continue
+		}
+		out = append(out, *b)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out, nil
+}
+
+// Delete removes a baseline.
+func Delete(stateDir, name string) error {
+	if err := ValidateName(name); err != nil {
+		return err
+	}
+	imgPath, metaPath := paths(stateDir, name)
+	if err := os.Remove(imgPath); err != nil {
+		if os.IsNotExist(err) {
+			return ErrNotFound
+		}
+		return fmt.Errorf("delete baseline: %w", err)
+	}
+	_ = os.Remove(metaPath)
+	return nil
+}
@@ -0,0 +1,143 @@
+package visualdiff
+
+import (
+	"errors"
+	"image"
+	"image/color"
+	"testing"
+)
+
+func solid(w, h int, c color.NRGBA) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.SetNRGBA(x, y, c)
+		}
+	}
+	return img
+}
+
+func fill(img *image.NRGBA, r Rect, c color.NRGBA) {
+	for y := r.Y; y < r.Y+r.Height; y++ {
+		for x := r.X; x < r.X+r.Width; x++ {
+			img.SetNRGBA(x, y, c)
+		}
+	}
+}
+
+var (
+	white = color.NRGBA{R: 255, G: 255, B: 255, A: 255}
+	black = color.NRGBA{A: 255}
+)
+
+func TestCompareIdentical(t *testing.T) {
+	a := solid(40, 30, white)
+	res := Compare(a, solid(40, 30, white), Options{})
+	if res.ChangedPixels != 0 || res.MismatchPercent != 0 || len(res.Regions) != 0 {
+		t.Errorf("expected no changes, got %+v", res)
+	}
+	if res.Diff.Rect.Dx() != 40 || res.Diff.Rect.Dy() != 30 {
+		t.Errorf("diff size = %v", res.Diff.Rect)
+	}
+}
+
+func TestCompareRegions(t *testing.T) {
+	a := solid(100, 100, white)
+	b := solid(100, 100, white)
+	fill(b, Rect{X: 10, Y: 10, Width: 10, Height: 5}, black)
+	fill(b, Rect{X: 60, Y: 70, Width: 20, Height: 20}, black)
+
+	res := Compare(a, b, Options{})
+	if res.ChangedPixels != 450 {
+		t.Errorf("changed = %d, want 450", res.ChangedPixels)
+	}
+	if res.MismatchPercent != 4.5 {
+		t.Errorf("mismatch = %v, want 4.5", res.MismatchPercent)
+	}
+	want := []Rect{{X: 10, Y: 10, Width: 10, Height: 5}, {X: 60, Y: 70, Width: 20, Height: 20}}
+	if len(res.Regions) != 2 || res.Regions[0].Rect != want[0] || res.Regions[1].Rect != want[1] {
+		t.Errorf("regions = %+v", res.Regions)
+	}
+	if got := res.Diff.NRGBAAt(15, 12); got != changedColor {
+		t.Errorf("changed pixel drawn as %v", got)
+	}
+}
+
+func TestCompareThresholdAndMasks(t *testing.T) {
+	a := solid(20, 20, white)
+	b := solid(20, 20, white)
+	fill(b, Rect{X: 0, Y: 0, Width: 20, Height: 10}, color.NRGBA{R: 250, G: 250, B: 250, A: 255})
+	fill(b, Rect{X: 5, Y: 15, Width: 4, Height: 4}, black)
+
+	if res := Compare(a, b, Options{}); res.ChangedPixels != 16 {
+		t.Errorf("a faint change should be under the default threshold, got %d changed", res.ChangedPixels)
+	}
+	if res := Compare(a, b, Options{Threshold: 0.01}); res.ChangedPixels != 216 {
+		t.Errorf("a low threshold should catch the faint change, got %d changed", res.ChangedPixels)
+	}
+	res := Compare(a, b, Options{Masks: []Rect{{X: 0, Y: 12, Width: 20, Height: 8}}})
+	if res.ChangedPixels != 0 || res.ComparedPixels != 240 {
+		t.Errorf("masked change still counted: %+v", res)
+	}
+}
+
+func TestCompareAntialiasing(t *testing.T) {
+	// A hard vertical edge, shifted by a grey column: the grey pixels sit
+	// between flat black and flat white areas, as anti-aliasing does.
+	a := solid(12, 12, white)
+	fill(a, Rect{Width: 6, Height: 12}, black)
+	b := solid(12, 12, white)
+	fill(b, Rect{Width: 6, Height: 12}, black)
+	fill(b, Rect{X: 6, Width: 1, Height: 12}, color.NRGBA{R: 128, G: 128, B: 128, A: 255})
+
+	res := Compare(a, b, Options{})
+	if res.ChangedPixels != 0 || res.AntialiasPixels != 12 {
+		t.Errorf("expected the grey column to be tolerated, got %+v", res)
+	}
+	res = Compare(a, b, Options{IncludeAntialiasing: true})
+	if res.ChangedPixels != 12 {
+		t.Errorf("expected the grey column to count, got %+v", res)
+	}
+}
+
+func TestCompareSizeChange(t *testing.T) {
+	res := Compare(solid(10, 10, white), solid(10, 12, white), Options{})
+	if !res.SizeChanged || res.Height != 12 || res.ChangedPixels != 20 {
+		t.Errorf("unexpected result for a taller image: %+v", res)
+	}
+}
+
+func TestStore(t *testing.T) {
+	dir := t.TempDir()
+	data, err := Encode(solid(8, 6, white))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := Save(dir, &Baseline{Name: "home.header", Selector: "header"}, data); err != nil {
+		t.Fatal(err)
+	}
+
+	b, got, err := Load(dir, "home.header")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b.Width != 8 || b.Height != 6 || b.Selector != "header" || len(got) != len(data) {
+		t.Errorf("unexpected baseline: %+v", b)
+	}
+	list, err := List(dir)
+	if err != nil || len(list) != 1 || list[0].Name != "home.header" {
+		t.Errorf("list = %+v, %v", list, err)
+	}
+	if err := Delete(dir, "home.header"); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := Load(dir, "home.header"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound after delete, got %v", err)
+	}
+
+	for _, name := range []string{"", "../x", "a/b", ".hidden", "a..b"} {
+		if err := Save(dir, &Baseline{Name: name}, data); err == nil {
+			t.Errorf("expected %q to be rejected", name)
+		}
+	}
+}
@@ -227,6 +227,24 @@ assert_input_not_contains "#username" "Escape" "Escape key should dispatch event
 
 end_test
 
+# ─────────────────────────────────────────────────────────────────
+start_test "extract: password values are not returned"
+
+pt_post /navigate -d "{\"url\":\"${FIXTURES_URL}/form.html\"}"
+sleep 1
+
+pt_post /action -d '{"kind":"type","selector":"#username","text":"alice"}'
+pt_post /action -d '{"kind":"type","selector":"#password","text":"hunter2"}'
+assert_ok "type credentials"
+
+pt_post /extract -d '{"schema":{"type":"object","properties":{"username":{"type":"string"},"password":{"type":"string"}}},"hints":{"username":"#username","password":"#password"}}'
+assert_ok "extract"
+assert_json_eq "$RESULT" '.data.username' 'alice' "username is extracted"
+assert_json_eq "$RESULT" '.data.password' 'null' "password is withheld"
+assert_json_eq "$RESULT" '[.. | strings | select(contains("hunter2"))] | length' '0' "password never appears in the response"
+
+end_test
+
 # Migrated from: tests/integration/actions_test.go (error cases)
 
 pt_post /navigate "{\"url\":\"${FIXTURES_URL}/buttons.html\"}"